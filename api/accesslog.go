@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// withAccessLog logs a single line for each request handled, after the
+// response is sent, with the method, path, status code, response size
+// (in bytes) and duration (in seconds) of the request.
+func withAccessLog(logger *log.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		recorder := &accessRecorder{ResponseWriter: res, status: http.StatusOK}
+		start := time.Now()
+
+		next.ServeHTTP(recorder, req)
+
+		logger.WithFields(log.Fields{
+			"method":    req.Method,
+			"path":      req.URL.Path,
+			"requestID": requestID(req),
+			"status":    recorder.status,
+			"size":      recorder.size,
+			"duration":  time.Since(start).Seconds(),
+		}).Info("request handled")
+	})
+}
+
+// accessRecorder records the status code and size of the response.
+type accessRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (r *accessRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *accessRecorder) Write(data []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(data)
+	r.size += n
+	return n, err
+}
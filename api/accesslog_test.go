@@ -0,0 +1,116 @@
+package api_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+	"github.com/sirupsen/logrus"
+)
+
+func TestAccessLog(t *testing.T) {
+	logs := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.SetOutput(logs)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+
+	service := api.New(loan.CreatePlanForCurrencyContext, api.WithLogger(logger))
+
+	req := newRequest(t, http.MethodPost, api.CreateLoanPlanPath, validCreateLoanRequestBody(t))
+	res := httptest.NewRecorder()
+	service.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("got response %d want %d", res.Code, http.StatusOK)
+	}
+
+	type accessLogEntry struct {
+		Level     string  `json:"level"`
+		Msg       string  `json:"msg"`
+		Method    string  `json:"method"`
+		Path      string  `json:"path"`
+		RequestID string  `json:"requestID"`
+		Status    int     `json:"status"`
+		Size      int     `json:"size"`
+		Duration  float64 `json:"duration"`
+	}
+
+	var entries []accessLogEntry
+	scanner := bufio.NewScanner(logs)
+	for scanner.Scan() {
+		entry := accessLogEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("parsing log line %q: %v", scanner.Text(), err)
+		}
+		if entry.Msg == "request handled" {
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("got %d access log entries; want 1; logs:\n%s", len(entries), logs)
+	}
+
+	entry := entries[0]
+	if entry.Level != "info" {
+		t.Errorf("got level %q; want info", entry.Level)
+	}
+	if entry.Method != http.MethodPost {
+		t.Errorf("got method %q; want %q", entry.Method, http.MethodPost)
+	}
+	if entry.Path != api.CreateLoanPlanPath {
+		t.Errorf("got path %q; want %q", entry.Path, api.CreateLoanPlanPath)
+	}
+	if entry.Status != http.StatusOK {
+		t.Errorf("got status %d; want %d", entry.Status, http.StatusOK)
+	}
+	if entry.Size != res.Body.Len() {
+		t.Errorf("got size %d; want %d", entry.Size, res.Body.Len())
+	}
+	if entry.RequestID != res.Header().Get(api.RequestIDHeader) {
+		t.Errorf("got request ID %q; want %q", entry.RequestID, res.Header().Get(api.RequestIDHeader))
+	}
+	if entry.Duration <= 0 {
+		t.Errorf("got duration %v; want it to be positive", entry.Duration)
+	}
+}
+
+func TestAccessLogStatusOfFailedRequests(t *testing.T) {
+	logs := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.SetOutput(logs)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+
+	service := api.New(loan.CreatePlanForCurrencyContext, api.WithLogger(logger))
+
+	req := newRequest(t, http.MethodDelete, api.CreateLoanPlanPath, nil)
+	res := httptest.NewRecorder()
+	service.ServeHTTP(res, req)
+
+	scanner := bufio.NewScanner(logs)
+	found := false
+	for scanner.Scan() {
+		entry := struct {
+			Msg    string `json:"msg"`
+			Status int    `json:"status"`
+		}{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+		if entry.Msg != "request handled" {
+			continue
+		}
+		found = true
+		if entry.Status != http.StatusMethodNotAllowed {
+			t.Errorf("got status %d; want %d", entry.Status, http.StatusMethodNotAllowed)
+		}
+	}
+	if !found {
+		t.Fatalf("no access log entry found; logs:\n%s", logs)
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/katcipis/loaner/loan"
+	"github.com/katcipis/loaner/money"
+)
+
+const (
+	amountFormatQueryParam = "amountFormat"
+	// plainAmountFormat formats money values with only the
+	// decimal places they need, like "2000" and "1000.42".
+	plainAmountFormat = "plain"
+	// fixedAmountFormat formats money values always with the
+	// minor units of the currency, like "2000.00" and "1000.42".
+	fixedAmountFormat = "fixed"
+)
+
+// parseAmountFormat parses the format of the money values informed on
+// the amountFormat query parameter, returning true when the fixed format
+// is requested. The default is the plain format.
+func parseAmountFormat(query url.Values) (bool, []FieldError) {
+	switch query.Get(amountFormatQueryParam) {
+	case "", plainAmountFormat:
+		return false, nil
+	case fixedAmountFormat:
+		return true, nil
+	}
+	err := errors.New(amountFormatQueryParam + " must be " + plainAmountFormat + " or " + fixedAmountFormat)
+	return false, []FieldError{newFieldError(amountFormatQueryParam, err)}
+}
+
+// fixAmounts formats all the money values of the response with the
+// minor units of the currency of the request, like "2000.00" instead
+// of "2000". The request must be already validated.
+func fixAmounts(parsedReq CreateLoanPlanRequest, resp CreateLoanPlanResponse) CreateLoanPlanResponse {
+	currency := defaultCurrency
+	if parsedReq.Currency != "" {
+		if c, err := loan.CurrencyFromCode(parsedReq.Currency); err == nil {
+			currency = c
+		}
+	}
+	places := int32(currency.MinorUnits)
+	return mapAmounts(resp, func(amount money.Amount) money.Amount {
+		return amount.Fixed(places)
+	})
+}
+
+// mapAmounts maps all the money values of the response with
+// the given function, keeping all the other values.
+func mapAmounts(resp CreateLoanPlanResponse, mapAmount func(money.Amount) money.Amount) CreateLoanPlanResponse {
+	payments := make([]BorrowerPayment, len(resp.BorrowerPayments))
+	for i, p := range resp.BorrowerPayments {
+		payments[i] = BorrowerPayment{
+			Date:                          p.Date,
+			PaymentAmount:                 mapAmount(p.PaymentAmount),
+			Interest:                      mapAmount(p.Interest),
+			Principal:                     mapAmount(p.Principal),
+			InitialOutstandingPrincipal:   mapAmount(p.InitialOutstandingPrincipal),
+			RemainingOutstandingPrincipal: mapAmount(p.RemainingOutstandingPrincipal),
+			DaysInPeriod:                  p.DaysInPeriod,
+		}
+		if p.Fee != nil {
+			fee := mapAmount(*p.Fee)
+			payments[i].Fee = &fee
+		}
+	}
+	return CreateLoanPlanResponse{
+		BorrowerPayments: payments,
+		Total:            resp.Total,
+		MonthlyPayment:   mapAmount(resp.MonthlyPayment),
+		PayoffDate:       resp.PayoffDate,
+		Summary: LoanPlanSummary{
+			TotalPrincipal: mapAmount(resp.Summary.TotalPrincipal),
+			TotalInterest:  mapAmount(resp.Summary.TotalInterest),
+			TotalPayment:   mapAmount(resp.Summary.TotalPayment),
+		},
+		Warnings: resp.Warnings,
+	}
+}
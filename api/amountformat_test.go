@@ -0,0 +1,95 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestLoanPlanAmountFormat(t *testing.T) {
+	type Test struct {
+		name                 string
+		query                string
+		wantInitialPrincipal []string
+		wantTotalPrincipal   string
+	}
+
+	tests := []Test{
+		{
+			name:                 "PlainByDefault",
+			query:                "loanAmount=2000&nominalRate=1.0&duration=2&startDate=2018-01-01T00:00:00Z",
+			wantInitialPrincipal: []string{"2000", "1000.42"},
+			wantTotalPrincipal:   "2000",
+		},
+		{
+			name:                 "Plain",
+			query:                "loanAmount=2000&nominalRate=1.0&duration=2&startDate=2018-01-01T00:00:00Z&amountFormat=plain",
+			wantInitialPrincipal: []string{"2000", "1000.42"},
+			wantTotalPrincipal:   "2000",
+		},
+		{
+			name:                 "Fixed",
+			query:                "loanAmount=2000&nominalRate=1.0&duration=2&startDate=2018-01-01T00:00:00Z&amountFormat=fixed",
+			wantInitialPrincipal: []string{"2000.00", "1000.42"},
+			wantTotalPrincipal:   "2000.00",
+		},
+		{
+			name:                 "FixedWithCurrencyMinorUnits",
+			query:                "loanAmount=2000&nominalRate=1.0&duration=2&startDate=2018-01-01T00:00:00Z&currency=BHD&amountFormat=fixed",
+			wantInitialPrincipal: []string{"2000.000", "1000.417"},
+			wantTotalPrincipal:   "2000.000",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(loan.CreatePlanForCurrencyContext)
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, httptest.NewRequest(http.MethodGet, api.CreateLoanPlanPath+"?"+test.query, nil))
+
+			if res.Code != http.StatusOK {
+				t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+			}
+
+			resp := formattedLoanPlan{}
+			fromJSON(t, res.Body, &resp)
+
+			got := []string{}
+			for _, payment := range resp.BorrowerPayments {
+				got = append(got, payment.InitialOutstandingPrincipal)
+			}
+			if diff := cmp.Diff(test.wantInitialPrincipal, got); diff != "" {
+				t.Errorf("initial outstanding principal mismatch (-want +got):\n%s", diff)
+			}
+			if resp.Summary.TotalPrincipal != test.wantTotalPrincipal {
+				t.Errorf("got total principal %q; want %q", resp.Summary.TotalPrincipal, test.wantTotalPrincipal)
+			}
+		})
+	}
+}
+
+func TestLoanPlanInvalidAmountFormat(t *testing.T) {
+	service := api.New(loan.CreatePlanForCurrencyContext)
+	query := "loanAmount=2000&nominalRate=1.0&duration=2&startDate=2018-01-01T00:00:00Z&amountFormat=pretty"
+	res := httptest.NewRecorder()
+	service.ServeHTTP(res, httptest.NewRequest(http.MethodGet, api.CreateLoanPlanPath+"?"+query, nil))
+
+	if res.Code != http.StatusBadRequest {
+		t.Fatalf("got response %d want %d", res.Code, http.StatusBadRequest)
+	}
+
+	errResponse := api.ErrorResponse{}
+	fromJSON(t, res.Body, &errResponse)
+
+	gotFields := []string{}
+	for _, field := range errResponse.Error.Fields {
+		gotFields = append(gotFields, field.Field)
+	}
+	if diff := cmp.Diff([]string{"amountFormat"}, gotFields); diff != "" {
+		t.Errorf("error fields mismatch (-want +got):\n%s", diff)
+	}
+}
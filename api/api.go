@@ -3,45 +3,218 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/katcipis/loaner/loan"
+	"github.com/katcipis/loaner/money"
 )
 
-// CreateLoanPlanRequest is the request body required to create loan plans
+// CreateLoanPlanRequest is the request body required to create loan plans.
+// The StartDate is optional, when it is empty the loan starts on the
+// current date (or on the first day of the next month, when the current
+// day is bigger than 28).
+// The nominal rate can be informed as a percent, on the NominalRate, or
+// in basis points (like 500 for 5.0%), on the NominalRateBps, but
+// only one of them must be informed.
+// The duration, in months, can also be informed as the EndDate of the
+// loan, that many months after the start date, but one (and only one)
+// of them must be informed.
 type CreateLoanPlanRequest struct {
-	LoanAmount  string `json:"loanAmount"`
-	NominalRate string `json:"nominalRate"`
-	Duration    int    `json:"duration"`
-	StartDate   string `json:"startDate"`
+	LoanAmount     *money.Amount `json:"loanAmount,omitempty"`
+	Price          *money.Amount `json:"price,omitempty"`
+	DownPayment    *money.Amount `json:"downPayment,omitempty"`
+	NominalRate    *money.Amount `json:"nominalRate,omitempty"`
+	NominalRateBps *int          `json:"nominalRateBps,omitempty"`
+	RateIsFraction bool          `json:"rateIsFraction,omitempty"`
+	Duration       *int          `json:"duration,omitempty"`
+	StartDate      string        `json:"startDate,omitempty"`
+	EndDate        string        `json:"endDate,omitempty"`
+	Currency       string        `json:"currency,omitempty"`
+
+	// texts are the decimal fields as informed on the JSON body or on the
+	// query, by name. The service normalizes them before parsing (like
+	// removing thousands separators, see parseDecimal), so they are used
+	// instead of the amounts when informed.
+	texts map[string]string
+}
+
+// UnmarshalJSON unmarshals the request accepting the decimal fields,
+// like the loan amount and the nominal rate, both as JSON strings and
+// numbers. Numbers are kept as their original text, so there is no
+// loss of precision (as there would be if they were parsed as floats).
+// The amounts are set only when their text is a valid decimal, the
+// texts are validated by the service with the other fields.
+func (r *CreateLoanPlanRequest) UnmarshalJSON(data []byte) error {
+	type request CreateLoanPlanRequest
+	parsed := struct {
+		*request
+		LoanAmount  decimalText `json:"loanAmount"`
+		Price       decimalText `json:"price"`
+		DownPayment decimalText `json:"downPayment"`
+		NominalRate decimalText `json:"nominalRate"`
+	}{
+		request: (*request)(r),
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+	r.texts = nil
+	r.LoanAmount = r.setText("loanAmount", string(parsed.LoanAmount))
+	r.Price = r.setText("price", string(parsed.Price))
+	r.DownPayment = r.setText("downPayment", string(parsed.DownPayment))
+	r.NominalRate = r.setText("nominalRate", string(parsed.NominalRate))
+	return nil
+}
+
+// setText sets the text of the decimal field with the given name, if
+// it was informed, returning its amount (nil if it is not valid).
+func (r *CreateLoanPlanRequest) setText(name string, text string) *money.Amount {
+	if text == "" {
+		return nil
+	}
+	if r.texts == nil {
+		r.texts = map[string]string{}
+	}
+	r.texts[name] = text
+	amount, err := money.Parse(strings.TrimSpace(text))
+	if err != nil {
+		return nil
+	}
+	return &amount
+}
+
+// text returns the text of the decimal field with the given name and
+// amount, as informed on the request, or empty if it was not informed.
+func (r CreateLoanPlanRequest) text(name string, amount *money.Amount) string {
+	if text, ok := r.texts[name]; ok {
+		return text
+	}
+	if amount == nil {
+		return ""
+	}
+	return amount.String()
+}
+
+// decimalText is the text of a decimal that can be informed as a
+// JSON string or number. The text is kept as informed, so it can be
+// normalized before being parsed (see parseDecimal).
+type decimalText string
+
+func (d *decimalText) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	text, err := money.JSONText(data)
+	if err != nil {
+		return err
+	}
+	*d = decimalText(text)
+	return nil
 }
 
 // BorrowerPayment is part of the CreateLoanPlanResponse
 type BorrowerPayment struct {
-	Date                          string `json:"date"`
-	PaymentAmount                 string `json:"borrowerPaymentAmount"`
-	Interest                      string `json:"interest"`
-	Principal                     string `json:"principal"`
-	InitialOutstandingPrincipal   string `json:"initialOutstandingPrincipal"`
-	RemainingOutstandingPrincipal string `json:"remainingOutstandingPrincipal"`
+	Date                          string       `json:"date"`
+	PaymentAmount                 money.Amount `json:"borrowerPaymentAmount"`
+	Interest                      money.Amount `json:"interest"`
+	Principal                     money.Amount `json:"principal"`
+	InitialOutstandingPrincipal   money.Amount `json:"initialOutstandingPrincipal"`
+	RemainingOutstandingPrincipal money.Amount `json:"remainingOutstandingPrincipal"`
+	// Fee is the recurring fee (like a servicing fee) included
+	// on the payment amount, omitted when no fee is charged.
+	Fee *money.Amount `json:"fee,omitempty"`
+	// DaysInPeriod is the number of days of the period of the payment
+	// used to calculate its interest, always 30 with the 30/360 day count.
+	DaysInPeriod int `json:"daysInPeriod"`
+}
+
+// LoanPlanSummary is part of the CreateLoanPlanResponse, it has
+// the totals of all the payments of the loan plan.
+type LoanPlanSummary struct {
+	TotalPrincipal money.Amount `json:"totalPrincipal"`
+	TotalInterest  money.Amount `json:"totalInterest"`
+	TotalPayment   money.Amount `json:"totalPayment"`
 }
 
-// CreateLoanPlanResponse is the response of the create loan plan request
+// CreateLoanPlanResponse is the response of the create loan plan request.
+// The MonthlyPayment is the fixed payment (annuity) of the loan.
+// The Total is the number of payments of the whole loan plan, which may
+// be more than the BorrowerPayments when only a page of them is requested.
+// The PayoffDate is the date of the last payment of the whole loan plan,
+// it is empty if the loan plan has no payments.
+// The Warnings flag unusual, but valid, loan parameters (like
+// WarningLongDuration), they are omitted when there is no warning.
 type CreateLoanPlanResponse struct {
 	BorrowerPayments []BorrowerPayment `json:"borrowerPayments"`
+	Total            int               `json:"total"`
+	MonthlyPayment   money.Amount      `json:"monthlyPayment"`
+	PayoffDate       string            `json:"payoffDate"`
+	Summary          LoanPlanSummary   `json:"summary"`
+	Warnings         []string          `json:"warnings,omitempty"`
 }
 
 // Error contains error information used in error responses
 type Error struct {
-	Message string `json:"message"`
+	Code    ErrorCode    `json:"code"`
+	Message string       `json:"message"`
+	TraceID string       `json:"traceId"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// ErrorCode is a stable machine-readable code that identifies
+// the class of an error, so clients can handle errors without
+// parsing the human readable messages.
+type ErrorCode string
+
+const (
+	// ErrorCodeInvalidParameter indicates that one or more of the
+	// request parameters are invalid.
+	ErrorCodeInvalidParameter ErrorCode = "INVALID_PARAMETER"
+	// ErrorCodeMissingField indicates that one or more of the
+	// required fields are missing (or blank) on the request.
+	ErrorCodeMissingField ErrorCode = "MISSING_FIELD"
+	// ErrorCodeMalformedJSON indicates that the request body is not valid JSON.
+	ErrorCodeMalformedJSON ErrorCode = "MALFORMED_JSON"
+	// ErrorCodeRequestTooLarge indicates that the request body
+	// is bigger than the max size allowed by the service.
+	ErrorCodeRequestTooLarge ErrorCode = "REQUEST_TOO_LARGE"
+	// ErrorCodeUnsupportedMediaType indicates that the request body
+	// has a media type (Content-Type) that is not supported.
+	ErrorCodeUnsupportedMediaType ErrorCode = "UNSUPPORTED_MEDIA_TYPE"
+	// ErrorCodeIdempotencyConflict indicates that an idempotency key
+	// was reused with a different request.
+	ErrorCodeIdempotencyConflict ErrorCode = "IDEMPOTENCY_CONFLICT"
+	// ErrorCodeNotFound indicates that the requested resource does not exist.
+	ErrorCodeNotFound ErrorCode = "NOT_FOUND"
+	// ErrorCodeMethodNotAllowed indicates that the HTTP method is not
+	// allowed on the requested resource.
+	ErrorCodeMethodNotAllowed ErrorCode = "METHOD_NOT_ALLOWED"
+	// ErrorCodeCanceled indicates that the request was canceled
+	// (or timed out) before the service could finish it.
+	ErrorCodeCanceled ErrorCode = "CANCELED"
+	// ErrorCodeOverloaded indicates that the service is handling too
+	// many requests, the request may be retried later.
+	ErrorCodeOverloaded ErrorCode = "OVERLOADED"
+	// ErrorCodeInternal indicates an unexpected failure on the service.
+	ErrorCodeInternal ErrorCode = "INTERNAL"
+)
+
+// FieldError describes why a specific field of a request is invalid.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
 }
 
 // ErrorResponse represents the response body
@@ -52,11 +225,16 @@ type ErrorResponse struct {
 
 // LoanPlanCreator is a function that given the loan parameters
 // will create a loan plan in the form of a list of payments.
+// All money values of the payments are expected to be rounded
+// according to the given currency. The context is the one of the
+// request, so the creation can be aborted if the request is cancelled.
 type LoanPlanCreator func(
+	ctx context.Context,
 	totalLoanAmount decimal.Decimal,
 	annualInterestRate decimal.Decimal,
 	durationInMonths int,
 	start time.Time,
+	currency loan.Currency,
 ) ([]loan.Payment, error)
 
 const (
@@ -65,102 +243,665 @@ const (
 )
 
 // New creates a new HTTP handler with all the service routes.
-func New(createLoanPlan LoanPlanCreator) http.Handler {
+// Options can be provided to customize the service.
+func New(createLoanPlan LoanPlanCreator, opts ...Option) http.Handler {
+
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.cacheSize > 0 {
+		createLoanPlan = newPlanCache(cfg.cacheSize).wrap(createLoanPlan)
+	}
 
 	mux := http.NewServeMux()
-	logger := log.WithFields(log.Fields{"path": CreateLoanPlanPath})
-
-	mux.HandleFunc(CreateLoanPlanPath, func(res http.ResponseWriter, req *http.Request) {
-		if req.Method != http.MethodPost {
-			res.WriteHeader(http.StatusMethodNotAllowed)
-			msg := fmt.Sprintf("method %q is not allowed", req.Method)
-			logResponseBodyWrite(logger, res, newErrorResponse(logger, msg))
-			logger.WithFields(log.Fields{"error": msg}).Warning("method not allowed")
+	mux.HandleFunc(HealthPath, healthHandler(cfg))
+	mux.HandleFunc(CreateLoanPlansPath, batchHandler(cfg, createLoanPlan))
+	mux.HandleFunc(CreateLoanPlansCSVPath, batchCSVHandler(cfg, createLoanPlan))
+	mux.HandleFunc(OpenAPIPath, openAPIHandler(cfg))
+	mux.HandleFunc(VersionPath, versionHandler(cfg))
+	mux.HandleFunc(CompareLoanPlansPath, compareHandler(cfg, createLoanPlan))
+	mux.HandleFunc(LoanPlanPaymentPath, paymentHandler(cfg, createLoanPlan))
+	mux.HandleFunc(LoanPlanSchemaPath, schemaHandler(cfg))
+	mux.HandleFunc(ValidateLoanPlanPath, validateHandler(cfg))
+	mux.HandleFunc(LoanPlanAPRPath, aprHandler(cfg, createLoanPlan))
+	mux.HandleFunc(LoanPlanSeriesPath, seriesHandler(cfg, createLoanPlan))
+	mux.HandleFunc(CapabilitiesPath, capabilitiesHandler(cfg))
+	mux.HandleFunc(notFoundPattern, notFoundHandler(cfg))
+
+	pathLogger := cfg.logger.WithFields(log.Fields{"path": CreateLoanPlanPath})
+
+	var hook *webhook
+	if cfg.webhookURL != "" {
+		hook = newWebhook(cfg.webhookURL)
+	}
+
+	var loanPlanHandler http.Handler = http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		logger := pathLogger.WithFields(log.Fields{"requestID": requestID(req)})
+		parsedReq, fieldErrs, ok := readLoanPlanRequest(cfg, logger, res, req)
+		if !ok {
 			return
 		}
-		dec := json.NewDecoder(req.Body)
-		parsedReq := CreateLoanPlanRequest{}
 
-		err := dec.Decode(&parsedReq)
-		if err != nil {
-			msg := fmt.Sprintf("cant parse request body as JSON:%v", err)
-			res.WriteHeader(http.StatusBadRequest)
-			logResponseBodyWrite(logger, res, newErrorResponse(logger, msg))
-			logger.WithFields(log.Fields{"error": msg}).Warning("invalid request body")
+		pg, pageFieldErrs := parsePage(req.URL.Query())
+		fieldErrs = append(fieldErrs, pageFieldErrs...)
+
+		descending, orderFieldErrs := parseOrder(req.URL.Query())
+		fieldErrs = append(fieldErrs, orderFieldErrs...)
+
+		fixed, amountFormatFieldErrs := parseAmountFormat(req.URL.Query())
+		fieldErrs = append(fieldErrs, amountFormatFieldErrs...)
+
+		resp, statusCode, apiErr := planLoan(req.Context(), logger, createLoanPlan, cfg, parsedReq, fieldErrs)
+		if apiErr != nil {
+			writeErrorResponse(logger, res, req, statusCode, *apiErr)
 			return
 		}
+		if hook != nil {
+			hook.notify(logger, requestID(req), resp)
+		}
+		if descending {
+			resp.BorrowerPayments = reversePayments(resp.BorrowerPayments)
+		}
+		resp.BorrowerPayments = pg.apply(resp.BorrowerPayments)
+
+		if fixed {
+			resp = fixAmounts(parsedReq, resp)
+		}
+
+		if req.URL.Query().Get(localeQueryParam) == "true" {
+			if locale, format, ok := negotiateLocale(req); ok {
+				resp = format.localize(resp)
+				res.Header().Set("Content-Language", locale)
+			}
+		}
+
+		switch negotiateContentType(req, jsonContentType, csvContentType, pdfContentType) {
+		case csvContentType:
+			res.Header().Set("Content-Type", csvContentType)
+			res.WriteHeader(http.StatusOK)
+			logResponseBodyWrite(logger, res, toCSV(logger, resp))
+		case pdfContentType:
+			res.Header().Set("Content-Type", pdfContentType)
+			res.WriteHeader(http.StatusOK)
+			logResponseBodyWrite(logger, res, toPDF(parsedReq, resp))
+		default:
+			res.Header().Set("Content-Type", jsonContentType)
+			res.WriteHeader(http.StatusOK)
+			logResponseBodyWrite(logger, res, toJSON(logger, resp))
+		}
+	})
+
+	if cfg.computationTimeout > 0 {
+		loanPlanHandler = withTimeout(cfg, CreateLoanPlanPath, cfg.computationTimeout, loanPlanHandler)
+	}
+	mux.Handle(CreateLoanPlanPath, withIdempotency(cfg, CreateLoanPlanPath, loanPlanHandler))
+
+	var handler http.Handler = mux
+
+	if cfg.metrics {
+		m := newMetrics()
+		mux.HandleFunc(MetricsPath, metricsHandler(cfg, m))
+		handler = withMetrics(m, mux)
+	}
+
+	if cfg.cacheSize > 0 {
+		handler = withCacheStatus(handler)
+	}
+
+	if cfg.maxInFlight > 0 {
+		handler = withLoadShedding(cfg, handler)
+	}
+
+	handler = withGzip(handler)
+
+	if len(cfg.corsOrigins) > 0 {
+		handler = withCORS(cfg.corsOrigins, handler)
+	}
+
+	return withRequestID(withAccessLog(cfg.logger, withRecovery(cfg.logger, handler)))
+}
+
+const (
+	dateLayout = time.RFC3339
+)
+
+// readLoanPlanRequest reads the loan plan parameters of the request,
+// from the JSON body on POST or from the query parameters on GET.
+// Query parameters that can't be parsed are returned as field errors.
+// If the request can't be read at all the error response is already
+// written and false is returned.
+func readLoanPlanRequest(
+	cfg config,
+	logger *log.Entry,
+	res http.ResponseWriter,
+	req *http.Request,
+) (CreateLoanPlanRequest, []FieldError, bool) {
+	parsedReq := CreateLoanPlanRequest{}
+	var fieldErrs []FieldError
+
+	switch req.Method {
+	case http.MethodPost:
+		if !readJSONBody(cfg, logger, res, req, &parsedReq) {
+			return parsedReq, nil, false
+		}
+	case http.MethodGet:
+		parsedReq, fieldErrs = parseCreateLoanPlanQuery(req.URL.Query())
+	default:
+		handleMethodNotAllowed(logger, res, req, http.MethodGet, http.MethodPost)
+		return parsedReq, nil, false
+	}
+
+	return parsedReq, fieldErrs, true
+}
+
+// readJSONBody decodes the JSON body of the request into v, enforcing
+// the JSON content type and the max body size of the config.
+// If the body can't be read the error response is already
+// written and false is returned.
+func readJSONBody(
+	cfg config,
+	logger *log.Entry,
+	res http.ResponseWriter,
+	req *http.Request,
+	v interface{},
+) bool {
+	if !hasJSONBody(req) {
+		handleUnsupportedMediaType(logger, res, req)
+		return false
+	}
+
+	dec := json.NewDecoder(limitBody(res, req, cfg.maxBodySize))
+	err := dec.Decode(v)
+	if isBodyTooLarge(err) {
+		handleBodyTooLarge(logger, res, req, cfg.maxBodySize)
+		return false
+	}
+	if err != nil {
+		msg := fmt.Sprintf("cant parse request body as JSON:%v", err)
+		writeErrorResponse(logger, res, req, http.StatusBadRequest, Error{
+			Code:    ErrorCodeMalformedJSON,
+			Message: msg,
+		})
+		logger.WithFields(log.Fields{"error": msg}).Warning("invalid request body")
+		return false
+	}
+	return true
+}
+
+// planLoan creates the loan plan for the given request. Field errors that
+// happened before (like while parsing query parameters) are reported
+// together with the ones found on the request. On failure the returned
+// error must be sent to the client with the returned status code.
+func planLoan(
+	ctx context.Context,
+	logger *log.Entry,
+	createLoanPlan LoanPlanCreator,
+	cfg config,
+	parsedReq CreateLoanPlanRequest,
+	fieldErrs []FieldError,
+) (CreateLoanPlanResponse, int, *Error) {
+	payments, annuity, statusCode, apiErr := planPayments(ctx, logger, createLoanPlan, cfg, parsedReq, fieldErrs)
+	if apiErr != nil {
+		return CreateLoanPlanResponse{}, statusCode, apiErr
+	}
+
+	resp := NewCreateLoanPlanResponse(payments)
+	resp.MonthlyPayment = money.New(annuity)
+	return resp, http.StatusOK, nil
+}
+
+// planPayments works as planLoan but returns the payments of the loan
+// plan, together with its annuity, instead of the response.
+func planPayments(
+	ctx context.Context,
+	logger *log.Entry,
+	createLoanPlan LoanPlanCreator,
+	cfg config,
+	parsedReq CreateLoanPlanRequest,
+	fieldErrs []FieldError,
+) ([]loan.Payment, decimal.Decimal, int, *Error) {
+	params, paramsFieldErrs := parseLoanPlanParams(parsedReq, cfg)
+	for _, fieldErr := range paramsFieldErrs {
+		// Fields that already failed to be parsed before,
+		// like a non integer duration, are reported only once.
+		if !hasFieldError(fieldErrs, fieldErr.Field) {
+			fieldErrs = append(fieldErrs, fieldErr)
+		}
+	}
+	if len(fieldErrs) > 0 {
+		apiErr := newFieldErrorsError(logger, fieldErrs)
+		return nil, decimal.Zero, http.StatusBadRequest, &apiErr
+	}
+
+	payments, err := createLoanPlan(
+		ctx,
+		params.loanAmount,
+		params.annualInterestRate,
+		params.durationInMonths,
+		params.startDate,
+		params.currency,
+	)
+
+	// The annuity is calculated apart from the payments, since
+	// the first payment is not guaranteed to be the annuity
+	// (like on plans with interest-only or balloon payments).
+	var annuity decimal.Decimal
+	if err == nil {
+		annuity, err = loan.CalculateAnnuityWithMaxDuration(
+			params.loanAmount,
+			params.annualInterestRate,
+			params.durationInMonths,
+			params.currency.MinorUnits,
+			cfg.limits.maxDuration,
+		)
+	}
+	if err != nil {
+		if errors.Is(err, loan.ErrInvalidParameter) {
+			// Invalid params errors are guaranteed
+			// to be safe to send to users in this case
+			// (not much info added on the error context).
+			// If a service is external care must be taken to not leak details
+			// that can be a potential security threat.
+			// When that is not the case I like the idea of
+			// informative error responses as detailed here:
+			//
+			// - https://commandcenter.blogspot.com/2017/12/error-handling-in-upspin.html
+			//
+			// I'm specially fond to the idea of a cross service
+			// operational trace (instead of stack traces).
+			// But I never tried it yet :-).
+			logger.WithError(err).Warning("bad request error")
+			return nil, decimal.Zero, http.StatusBadRequest, &Error{
+				Code:    ErrorCodeInvalidParameter,
+				Message: err.Error(),
+			}
+		}
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			// Usually the client is gone already, but if it is
+			// still there it is informed why the request failed.
+			logger.WithError(err).Warning("request canceled")
+			return nil, decimal.Zero, http.StatusServiceUnavailable, &Error{
+				Code:    ErrorCodeCanceled,
+				Message: "request canceled",
+			}
+		}
+		// Specially when you can't give much detail on errors for
+		// security reasons the trace ID sent on the error response
+		// helps to map the error to the logs.
+		logger.WithError(err).Error("internal server error")
+		return nil, decimal.Zero, http.StatusInternalServerError, &Error{
+			Code:    ErrorCodeInternal,
+			Message: "internal server error",
+		}
+	}
+
+	return payments, annuity, http.StatusOK, nil
+}
 
-		loanAmount, err := decimal.NewFromString(parsedReq.LoanAmount)
+// defaultCurrency is used when no currency is informed on the request,
+// it has the same rounding behavior of the loan.CreatePlan function.
+var defaultCurrency = loan.Currency{MinorUnits: 2}
+
+// loanPlanParams are the parameters required to create a loan plan,
+// parsed from a CreateLoanPlanRequest.
+type loanPlanParams struct {
+	loanAmount         decimal.Decimal
+	annualInterestRate decimal.Decimal
+	durationInMonths   int
+	startDate          time.Time
+	currency           loan.Currency
+}
+
+// parseLoanPlanParams parses all the fields of the request, reporting
+// all the invalid fields at once instead of failing on the first one.
+// Fields out of the limits of the config are also reported as invalid.
+// When no start date is informed the default start date is used, according
+// to the clock of the config.
+func parseLoanPlanParams(parsedReq CreateLoanPlanRequest, cfg config) (loanPlanParams, []FieldError) {
+	var fieldErrs []FieldError
+
+	lim := cfg.limits
+
+	loanAmount, fieldErr, ok := parseLoanAmount(parsedReq, cfg)
+	if !ok {
+		fieldErrs = append(fieldErrs, fieldErr)
+	} else if fieldErr, ok := checkBounds("loanAmount", loanAmount, lim.minAmount, lim.maxAmount); !ok {
+		fieldErrs = append(fieldErrs, fieldErr)
+	}
+
+	annualInterestRate, fieldErr, ok := parseNominalRate(parsedReq, cfg)
+	if !ok {
+		fieldErrs = append(fieldErrs, fieldErr)
+	} else if fieldErr, ok := checkBounds("nominalRate", annualInterestRate, lim.minRate, lim.maxRate); !ok {
+		fieldErrs = append(fieldErrs, fieldErr)
+	}
+
+	var err error
+	startDate := loan.DefaultStartDate(loan.WithClock(loan.ClockFunc(cfg.now)))
+	validStartDate := true
+	if !isBlank(parsedReq.StartDate) {
+		startDate, err = time.Parse(dateLayout, parsedReq.StartDate)
 		if err != nil {
-			handleFieldParsingError(logger, res, "loanAmount", err)
-			return
+			fieldErrs = append(fieldErrs, newFieldError("startDate", err))
+			validStartDate = false
+		}
+	}
+
+	// When the end date is informed the duration is derived from it,
+	// which depends on a valid start date.
+	durationField := "duration"
+	if parsedReq.EndDate != "" {
+		durationField = "endDate"
+	}
+	duration, fieldErr, ok := parseDuration(parsedReq, startDate)
+	if !ok {
+		if validStartDate {
+			fieldErrs = append(fieldErrs, fieldErr)
 		}
+	} else if duration < lim.minDuration || duration > lim.maxDuration {
+		fieldErrs = append(fieldErrs, FieldError{
+			Field:  durationField,
+			Reason: fmt.Sprintf("duration must be between %d and %d", lim.minDuration, lim.maxDuration),
+		})
+	}
 
-		annualInterestRate, err := decimal.NewFromString(parsedReq.NominalRate)
+	currency := defaultCurrency
+	if parsedReq.Currency != "" {
+		currency, err = loan.CurrencyFromCode(parsedReq.Currency)
 		if err != nil {
-			handleFieldParsingError(logger, res, "nominalRate", err)
-			return
+			fieldErrs = append(fieldErrs, newFieldError("currency", err))
 		}
+	}
+
+	return loanPlanParams{
+		loanAmount:         loanAmount,
+		annualInterestRate: annualInterestRate,
+		durationInMonths:   duration,
+		startDate:          startDate,
+		currency:           currency,
+	}, fieldErrs
+}
 
-		startDate, err := time.Parse(dateLayout, parsedReq.StartDate)
+// parseDuration parses the duration of the request, in months, which
+// can be informed directly or as the end date of the loan, but not both.
+// The end date must be a whole number of months after the start date.
+// On failure the field error is returned.
+func parseDuration(parsedReq CreateLoanPlanRequest, startDate time.Time) (int, FieldError, bool) {
+	if parsedReq.EndDate == "" {
+		if parsedReq.Duration == nil {
+			return 0, newMissingFieldError("duration"), false
+		}
+		return *parsedReq.Duration, FieldError{}, true
+	}
+	if parsedReq.Duration != nil {
+		return 0, FieldError{
+			Field:  "endDate",
+			Reason: "endDate and duration can't be informed together",
+		}, false
+	}
+
+	endDate, err := time.Parse(dateLayout, parsedReq.EndDate)
+	if err != nil {
+		return 0, newFieldError("endDate", err), false
+	}
+	if !endDate.After(startDate) {
+		return 0, FieldError{
+			Field:  "endDate",
+			Reason: "endDate must be after the startDate",
+		}, false
+	}
+
+	months := (endDate.Year()-startDate.Year())*12 + int(endDate.Month()-startDate.Month())
+	if !startDate.AddDate(0, months, 0).Equal(endDate) {
+		return 0, FieldError{
+			Field:  "endDate",
+			Reason: "endDate must be a whole number of months after the startDate",
+		}, false
+	}
+	return months, FieldError{}, true
+}
+
+// parseLoanAmount parses the loan amount of the request, which can be
+// informed directly or as a price and an optional down payment, but not
+// both. The loan amount is then the financed amount, the price minus the
+// down payment, so a down payment that meets or exceeds the price is
+// invalid. On failure the field error is returned.
+func parseLoanAmount(parsedReq CreateLoanPlanRequest, cfg config) (decimal.Decimal, FieldError, bool) {
+	loanAmountText := parsedReq.text("loanAmount", parsedReq.LoanAmount)
+	priceText := parsedReq.text("price", parsedReq.Price)
+	downPaymentText := parsedReq.text("downPayment", parsedReq.DownPayment)
+
+	if isBlank(priceText) {
+		if downPaymentText != "" {
+			return decimal.Zero, FieldError{
+				Field:  "downPayment",
+				Reason: "downPayment requires the price, inform the price or only the loanAmount",
+			}, false
+		}
+		if isBlank(loanAmountText) {
+			return decimal.Zero, newMissingFieldError("loanAmount"), false
+		}
+		loanAmount, err := parseDecimal(loanAmountText, cfg)
 		if err != nil {
-			handleFieldParsingError(logger, res, "startDate", err)
-			return
+			return decimal.Zero, newFieldError("loanAmount", err), false
 		}
+		return loanAmount, FieldError{}, true
+	}
+	if loanAmountText != "" {
+		return decimal.Zero, FieldError{
+			Field:  "price",
+			Reason: "loanAmount and price are mutually exclusive, inform only one of them",
+		}, false
+	}
 
-		payments, err := createLoanPlan(loanAmount, annualInterestRate, parsedReq.Duration, startDate)
+	price, err := parseDecimal(priceText, cfg)
+	if err != nil {
+		return decimal.Zero, newFieldError("price", err), false
+	}
+	downPayment := decimal.Zero
+	if downPaymentText != "" {
+		downPayment, err = parseDecimal(downPaymentText, cfg)
 		if err != nil {
-			if errors.Is(err, loan.ErrInvalidParameter) {
-				res.WriteHeader(http.StatusBadRequest)
-				// Invalid params errors are guaranteed
-				// to be safe to send to users in this case
-				// (not much info added on the error context).
-				// If a service is external care must be taken to not leak details
-				// that can be a potential security threat.
-				// When that is not the case I like the idea of
-				// informative error responses as detailed here:
-				//
-				// - https://commandcenter.blogspot.com/2017/12/error-handling-in-upspin.html
-				//
-				// I'm specially fond to the idea of a cross service
-				// operational trace (instead of stack traces).
-				// But I never tried it yet :-).
-				logResponseBodyWrite(logger, res, newErrorResponse(logger, err.Error()))
-				logger.WithError(err).Warning("bad request error")
-				return
-			}
-			// Specially when you can't give much detail on errors for
-			// security reasons it would be a good idea to have
-			// a tracing id for errors to help map the error to the logs.
-			res.WriteHeader(http.StatusInternalServerError)
-			logResponseBodyWrite(logger, res, newErrorResponse(logger, "internal server error"))
-			logger.WithError(err).Error("internal server error")
-			return
+			return decimal.Zero, newFieldError("downPayment", err), false
+		}
+	}
+	if downPayment.IsNegative() || downPayment.GreaterThanOrEqual(price) {
+		return decimal.Zero, FieldError{
+			Field:  "downPayment",
+			Reason: fmt.Sprintf("downPayment must be at least 0 and smaller than the price %s", price),
+		}, false
+	}
+	return price.Sub(downPayment), FieldError{}, true
+}
+
+// parseNominalRate parses the nominal rate of the request, which can be
+// informed as a percent or in basis points, but not both. On failure
+// the field error is returned.
+func parseNominalRate(parsedReq CreateLoanPlanRequest, cfg config) (decimal.Decimal, FieldError, bool) {
+	nominalRateText := parsedReq.text("nominalRate", parsedReq.NominalRate)
+	if parsedReq.NominalRateBps == nil {
+		if isBlank(nominalRateText) {
+			return decimal.Zero, newMissingFieldError("nominalRate"), false
+		}
+		rate, err := parseDecimal(nominalRateText, cfg)
+		if err != nil {
+			return decimal.Zero, newFieldError("nominalRate", err), false
 		}
+		if parsedReq.RateIsFraction {
+			rate = fractionToPercent(rate)
+		}
+		return rate, FieldError{}, true
+	}
+	if nominalRateText != "" {
+		return decimal.Zero, FieldError{
+			Field:  "nominalRateBps",
+			Reason: "nominalRate and nominalRateBps are mutually exclusive, inform only one of them",
+		}, false
+	}
+	if parsedReq.RateIsFraction {
+		return decimal.Zero, FieldError{
+			Field:  "rateIsFraction",
+			Reason: "rateIsFraction applies only to the nominalRate, not to the nominalRateBps",
+		}, false
+	}
+	return bpsToPercent(*parsedReq.NominalRateBps), FieldError{}, true
+}
+
+// fractionToPercent converts a fraction to a percent, like 0.05 to 5.
+func fractionToPercent(fraction decimal.Decimal) decimal.Decimal {
+	return fraction.Shift(2)
+}
+
+// bpsToPercent converts basis points to a percent, like 500 to 5.0.
+func bpsToPercent(bps int) decimal.Decimal {
+	return decimal.New(int64(bps), -2)
+}
+
+// checkBounds checks if the value of the field is within the given bounds,
+// returning the field error if it is not. Zero bounds are not checked.
+func checkBounds(fieldName string, value, min, max decimal.Decimal) (FieldError, bool) {
+	belowMin := !min.IsZero() && value.LessThan(min)
+	aboveMax := !max.IsZero() && value.GreaterThan(max)
+	if !belowMin && !aboveMax {
+		return FieldError{}, true
+	}
 
-		resp := CreateLoanPlanResponse{
-			BorrowerPayments: toBorrowerPayments(payments),
+	var reason string
+	switch {
+	case !min.IsZero() && !max.IsZero():
+		reason = fmt.Sprintf("%s must be between %s and %s", fieldName, min, max)
+	case belowMin:
+		reason = fmt.Sprintf("%s must be at least %s", fieldName, min)
+	default:
+		reason = fmt.Sprintf("%s must be at most %s", fieldName, max)
+	}
+	return FieldError{Field: fieldName, Reason: reason}, false
+}
+
+// parseDecimal parses a decimal field of a request after normalizing it,
+// trimming whitespace and removing the thousands separator of the config,
+// so copy-pasted values like " 5,000.00 " are accepted. The separators
+// must group the digits by thousands, or the value is invalid.
+func parseDecimal(value string, cfg config) (decimal.Decimal, error) {
+	value = strings.TrimSpace(value)
+	if cfg.thousandsSeparator != "" && strings.Contains(value, cfg.thousandsSeparator) {
+		if err := validateThousandsGrouping(value, cfg.thousandsSeparator); err != nil {
+			return decimal.Zero, err
 		}
-		res.WriteHeader(http.StatusOK)
-		logResponseBodyWrite(logger, res, toJSON(logger, resp))
-	})
-	return mux
+		value = strings.ReplaceAll(value, cfg.thousandsSeparator, "")
+	}
+	return decimal.NewFromString(value)
 }
 
-const (
-	dateLayout = time.RFC3339
-)
+// validateThousandsGrouping validates that the thousands separators of the
+// value are only on its integer part, with a first group of 1 to 3 digits
+// and all other groups with exactly 3 digits, like "1,005,000.50".
+func validateThousandsGrouping(value string, separator string) error {
+	integer := strings.TrimLeft(value, "+-")
+	if i := strings.Index(integer, "."); i >= 0 {
+		if strings.Contains(integer[i+1:], separator) {
+			return fmt.Errorf("thousands separator %q not allowed after the decimal point on %q", separator, value)
+		}
+		integer = integer[:i]
+	}
+	for i, group := range strings.Split(integer, separator) {
+		if i == 0 && len(group) >= 1 && len(group) <= 3 {
+			continue
+		}
+		if i > 0 && len(group) == 3 {
+			continue
+		}
+		return fmt.Errorf("invalid thousands grouping on %q, the separator %q must group the digits by thousands", value, separator)
+	}
+	return nil
+}
+
+// parseCreateLoanPlanQuery parses the create loan plan request from
+// URL query parameters, which have the same names of the JSON fields
+// of the request body.
+func parseCreateLoanPlanQuery(query url.Values) (CreateLoanPlanRequest, []FieldError) {
+	var fieldErrs []FieldError
+
+	parsedReq := CreateLoanPlanRequest{
+		StartDate: query.Get("startDate"),
+		EndDate:   query.Get("endDate"),
+		Currency:  query.Get("currency"),
+	}
+	parsedReq.LoanAmount = parsedReq.setText("loanAmount", query.Get("loanAmount"))
+	parsedReq.Price = parsedReq.setText("price", query.Get("price"))
+	parsedReq.DownPayment = parsedReq.setText("downPayment", query.Get("downPayment"))
+	parsedReq.NominalRate = parsedReq.setText("nominalRate", query.Get("nominalRate"))
+
+	// The duration is required, unless the end date is informed instead,
+	// which is validated together with the other fields of the request.
+	if duration := query.Get("duration"); !isBlank(duration) {
+		parsed, err := strconv.Atoi(duration)
+		if err != nil {
+			fieldErrs = append(fieldErrs, newFieldError("duration", err))
+		}
+		parsedReq.Duration = &parsed
+	}
+
+	if bps := query.Get("nominalRateBps"); bps != "" {
+		nominalRateBps, err := strconv.Atoi(bps)
+		if err != nil {
+			fieldErrs = append(fieldErrs, newFieldError("nominalRateBps", err))
+		}
+		parsedReq.NominalRateBps = &nominalRateBps
+	}
+
+	if rateIsFraction := query.Get("rateIsFraction"); rateIsFraction != "" {
+		parsed, err := strconv.ParseBool(rateIsFraction)
+		if err != nil {
+			fieldErrs = append(fieldErrs, newFieldError("rateIsFraction", err))
+		}
+		parsedReq.RateIsFraction = parsed
+	}
+
+	return parsedReq, fieldErrs
+}
+
+// NewCreateLoanPlanResponse creates the response of a
+// create loan plan request from the payments of the loan plan.
+func NewCreateLoanPlanResponse(payments []loan.Payment) CreateLoanPlanResponse {
+	summary := loan.Summarize(payments)
+	resp := CreateLoanPlanResponse{
+		BorrowerPayments: toBorrowerPayments(payments),
+		Total:            len(payments),
+		Summary:          toLoanPlanSummary(summary),
+		Warnings:         loanPlanWarnings(payments, summary),
+	}
+	if len(payments) > 0 {
+		resp.PayoffDate = payments[len(payments)-1].Date.Format(dateLayout)
+	}
+	return resp
+}
+
+func toLoanPlanSummary(summary loan.Summary) LoanPlanSummary {
+	return LoanPlanSummary{
+		TotalPrincipal: money.New(summary.TotalPrincipal),
+		TotalInterest:  money.New(summary.TotalInterest),
+		TotalPayment:   money.New(summary.TotalPaid),
+	}
+}
 
 func toBorrowerPayments(payments []loan.Payment) []BorrowerPayment {
 	res := make([]BorrowerPayment, len(payments))
 	for i, p := range payments {
 		res[i] = BorrowerPayment{
 			Date:                          p.Date.Format(dateLayout),
-			PaymentAmount:                 p.PaymentAmount.String(),
-			Interest:                      p.Interest.String(),
-			Principal:                     p.Principal.String(),
-			InitialOutstandingPrincipal:   p.InitialOutstandingPrincipal.String(),
-			RemainingOutstandingPrincipal: p.RemainingOutstandingPrincipal.String(),
+			PaymentAmount:                 money.New(p.PaymentAmount),
+			Interest:                      money.New(p.Interest),
+			Principal:                     money.New(p.Principal),
+			InitialOutstandingPrincipal:   money.New(p.InitialOutstandingPrincipal),
+			RemainingOutstandingPrincipal: money.New(p.RemainingOutstandingPrincipal),
+			DaysInPeriod:                  p.DaysInPeriod,
+		}
+		if !p.RecurringFee.IsZero() {
+			fee := money.New(p.RecurringFee)
+			res[i].Fee = &fee
 		}
 	}
 	return res
@@ -173,10 +914,19 @@ func logResponseBodyWrite(logger *log.Entry, w io.Writer, data []byte) {
 	}
 }
 
-func newErrorResponse(logger *log.Entry, message string) []byte {
-	return toJSON(logger, ErrorResponse{
-		Error: Error{Message: message},
-	})
+// writeErrorResponse writes the error response, as JSON, with the given
+// status code. The trace ID of the error is always the ID of the request.
+func writeErrorResponse(
+	logger *log.Entry,
+	res http.ResponseWriter,
+	req *http.Request,
+	statusCode int,
+	apiErr Error,
+) {
+	apiErr.TraceID = requestID(req)
+	res.Header().Set("Content-Type", jsonContentType)
+	res.WriteHeader(statusCode)
+	logResponseBodyWrite(logger, res, toJSON(logger, ErrorResponse{Error: apiErr}))
 }
 
 func toJSON(logger *log.Entry, v interface{}) []byte {
@@ -187,9 +937,107 @@ func toJSON(logger *log.Entry, v interface{}) []byte {
 	return res
 }
 
-func handleFieldParsingError(logger *log.Entry, res http.ResponseWriter, fieldName string, err error) {
-	msg := fmt.Sprintf("can't parse %q from request:%v", fieldName, err)
-	res.WriteHeader(http.StatusBadRequest)
-	logResponseBodyWrite(logger, res, newErrorResponse(logger, msg))
-	logger.WithError(err).WithFields(log.Fields{"field": fieldName}).Warning("invalid field on request")
+func newFieldError(fieldName string, err error) FieldError {
+	return FieldError{
+		Field:  fieldName,
+		Reason: fmt.Sprintf("can't parse %q from request:%v", fieldName, err),
+	}
+}
+
+// newMissingFieldError creates the field error of a required field
+// that is missing (or blank) on the request.
+func newMissingFieldError(fieldName string) FieldError {
+	return FieldError{
+		Field:  fieldName,
+		Reason: fmt.Sprintf("%s is required", fieldName),
+	}
+}
+
+// isMissingFieldError checks if the field error is of a missing field.
+func isMissingFieldError(fieldErr FieldError) bool {
+	return fieldErr == newMissingFieldError(fieldErr.Field)
+}
+
+// isBlank checks if the value of a field is empty or only whitespace,
+// which is handled as if the field was not informed at all.
+func isBlank(value string) bool {
+	return strings.TrimSpace(value) == ""
+}
+
+func hasFieldError(fieldErrs []FieldError, fieldName string) bool {
+	for _, fieldErr := range fieldErrs {
+		if fieldErr.Field == fieldName {
+			return true
+		}
+	}
+	return false
+}
+
+// newFieldErrorsError creates an invalid parameter error
+// that aggregates all the given field errors. When all the
+// fields are missing it is a missing field error instead.
+func newFieldErrorsError(logger *log.Entry, fieldErrs []FieldError) Error {
+	code := ErrorCodeMissingField
+	reasons := make([]string, len(fieldErrs))
+	fieldNames := make([]string, len(fieldErrs))
+	for i, fieldErr := range fieldErrs {
+		reasons[i] = fieldErr.Reason
+		fieldNames[i] = fieldErr.Field
+		if !isMissingFieldError(fieldErr) {
+			code = ErrorCodeInvalidParameter
+		}
+	}
+
+	logger.WithFields(log.Fields{
+		"error":  strings.Join(reasons, "; "),
+		"fields": fieldNames,
+	}).Warning("invalid fields on request")
+
+	return Error{
+		Code:    code,
+		Message: strings.Join(reasons, "; "),
+		Fields:  fieldErrs,
+	}
+}
+
+// limitBody limits the size of the request body, reading more
+// than maxSize bytes from the returned reader fails.
+func limitBody(res http.ResponseWriter, req *http.Request, maxSize int64) io.Reader {
+	return http.MaxBytesReader(res, req.Body, maxSize)
+}
+
+// isBodyTooLarge checks if the error was caused by a request
+// body bigger than the limit set with limitBody.
+func isBodyTooLarge(err error) bool {
+	// There is no error type/sentinel for this (on Go 1.15),
+	// so the error message is the only way to check it.
+	return err != nil && strings.Contains(err.Error(), "http: request body too large")
+}
+
+func handleBodyTooLarge(logger *log.Entry, res http.ResponseWriter, req *http.Request, maxSize int64) {
+	msg := fmt.Sprintf("request body is bigger than the max size of %d bytes", maxSize)
+	writeErrorResponse(logger, res, req, http.StatusRequestEntityTooLarge, Error{
+		Code:    ErrorCodeRequestTooLarge,
+		Message: msg,
+	})
+	logger.WithFields(log.Fields{"error": msg}).Warning("request body too large")
+}
+
+func handleMethodNotAllowed(logger *log.Entry, res http.ResponseWriter, req *http.Request, allowed ...string) {
+	msg := fmt.Sprintf("method %q is not allowed", req.Method)
+	res.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeErrorResponse(logger, res, req, http.StatusMethodNotAllowed, Error{
+		Code:    ErrorCodeMethodNotAllowed,
+		Message: msg,
+	})
+	logger.WithFields(log.Fields{"error": msg}).Warning("method not allowed")
+}
+
+func handleUnsupportedMediaType(logger *log.Entry, res http.ResponseWriter, req *http.Request) {
+	msg := fmt.Sprintf("content type %q is not supported, use %q", req.Header.Get("Content-Type"), jsonContentType)
+	writeErrorResponse(logger, res, req, http.StatusUnsupportedMediaType, Error{
+		Code:    ErrorCodeUnsupportedMediaType,
+		Message: msg,
+	})
+	logger.WithFields(log.Fields{"error": msg}).Warning("unsupported media type")
 }
@@ -1,8 +1,11 @@
 package api_test
 
 import (
+	"encoding/csv"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -13,6 +16,7 @@ import (
 func TestLoanPlanCreationIntegration(t *testing.T) {
 	type Test struct {
 		name           string
+		method         string
 		request        api.CreateLoanPlanRequest
 		wantStatusCode int
 		want           api.CreateLoanPlanResponse
@@ -22,9 +26,9 @@ func TestLoanPlanCreationIntegration(t *testing.T) {
 		{
 			name: "SuccessOn2000LoanWith1.0RateIn2Months",
 			request: api.CreateLoanPlanRequest{
-				LoanAmount:  "2000.0",
-				NominalRate: "1.0",
-				Duration:    2,
+				LoanAmount:  amountPtr(t, "2000.0"),
+				NominalRate: amountPtr(t, "1.0"),
+				Duration:    months(2),
 				StartDate:   "2018-01-01T00:00:00Z",
 			},
 			want: api.CreateLoanPlanResponse{
@@ -32,34 +36,147 @@ func TestLoanPlanCreationIntegration(t *testing.T) {
 
 					{
 						Date:                          "2018-01-01T00:00:00Z",
-						PaymentAmount:                 "1001.25",
-						Interest:                      "1.67",
-						Principal:                     "999.58",
-						InitialOutstandingPrincipal:   "2000",
-						RemainingOutstandingPrincipal: "1000.42",
+						PaymentAmount:                 amount(t, "1001.25"),
+						Interest:                      amount(t, "1.67"),
+						Principal:                     amount(t, "999.58"),
+						InitialOutstandingPrincipal:   amount(t, "2000"),
+						RemainingOutstandingPrincipal: amount(t, "1000.42"),
+						DaysInPeriod:                  30,
 					},
 					{
 						Date:                          "2018-02-01T00:00:00Z",
-						PaymentAmount:                 "1001.25",
-						Interest:                      "0.83",
-						Principal:                     "1000.42",
-						InitialOutstandingPrincipal:   "1000.42",
-						RemainingOutstandingPrincipal: "0",
+						PaymentAmount:                 amount(t, "1001.25"),
+						Interest:                      amount(t, "0.83"),
+						Principal:                     amount(t, "1000.42"),
+						InitialOutstandingPrincipal:   amount(t, "1000.42"),
+						RemainingOutstandingPrincipal: amount(t, "0"),
+						DaysInPeriod:                  30,
 					},
 				},
+				Total:          2,
+				MonthlyPayment: amount(t, "1001.25"),
+				PayoffDate:     "2018-02-01T00:00:00Z",
+				Summary: api.LoanPlanSummary{
+					TotalPrincipal: amount(t, "2000"),
+					TotalInterest:  amount(t, "2.5"),
+					TotalPayment:   amount(t, "2002.5"),
+				},
 			},
 			wantStatusCode: http.StatusOK,
 		},
+		{
+			name: "SuccessOn200000JPYLoanWith1.0RateIn2Months",
+			request: api.CreateLoanPlanRequest{
+				LoanAmount:  amountPtr(t, "200000"),
+				NominalRate: amountPtr(t, "1.0"),
+				Duration:    months(2),
+				StartDate:   "2018-01-01T00:00:00Z",
+				Currency:    "JPY",
+			},
+			want: api.CreateLoanPlanResponse{
+				BorrowerPayments: []api.BorrowerPayment{
+
+					{
+						Date:                          "2018-01-01T00:00:00Z",
+						PaymentAmount:                 amount(t, "100125"),
+						Interest:                      amount(t, "167"),
+						Principal:                     amount(t, "99958"),
+						InitialOutstandingPrincipal:   amount(t, "200000"),
+						RemainingOutstandingPrincipal: amount(t, "100042"),
+						DaysInPeriod:                  30,
+					},
+					{
+						Date:                          "2018-02-01T00:00:00Z",
+						PaymentAmount:                 amount(t, "100125"),
+						Interest:                      amount(t, "83"),
+						Principal:                     amount(t, "100042"),
+						InitialOutstandingPrincipal:   amount(t, "100042"),
+						RemainingOutstandingPrincipal: amount(t, "0"),
+						DaysInPeriod:                  30,
+					},
+				},
+				Total:          2,
+				MonthlyPayment: amount(t, "100125"),
+				PayoffDate:     "2018-02-01T00:00:00Z",
+				Summary: api.LoanPlanSummary{
+					TotalPrincipal: amount(t, "200000"),
+					TotalInterest:  amount(t, "250"),
+					TotalPayment:   amount(t, "200250"),
+				},
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:   "SuccessOn2000LoanWith1.0RateIn2MonthsOnGet",
+			method: http.MethodGet,
+			request: api.CreateLoanPlanRequest{
+				LoanAmount:  amountPtr(t, "2000.0"),
+				NominalRate: amountPtr(t, "1.0"),
+				Duration:    months(2),
+				StartDate:   "2018-01-01T00:00:00Z",
+			},
+			want: api.CreateLoanPlanResponse{
+				BorrowerPayments: []api.BorrowerPayment{
+
+					{
+						Date:                          "2018-01-01T00:00:00Z",
+						PaymentAmount:                 amount(t, "1001.25"),
+						Interest:                      amount(t, "1.67"),
+						Principal:                     amount(t, "999.58"),
+						InitialOutstandingPrincipal:   amount(t, "2000"),
+						RemainingOutstandingPrincipal: amount(t, "1000.42"),
+						DaysInPeriod:                  30,
+					},
+					{
+						Date:                          "2018-02-01T00:00:00Z",
+						PaymentAmount:                 amount(t, "1001.25"),
+						Interest:                      amount(t, "0.83"),
+						Principal:                     amount(t, "1000.42"),
+						InitialOutstandingPrincipal:   amount(t, "1000.42"),
+						RemainingOutstandingPrincipal: amount(t, "0"),
+						DaysInPeriod:                  30,
+					},
+				},
+				Total:          2,
+				MonthlyPayment: amount(t, "1001.25"),
+				PayoffDate:     "2018-02-01T00:00:00Z",
+				Summary: api.LoanPlanSummary{
+					TotalPrincipal: amount(t, "2000"),
+					TotalInterest:  amount(t, "2.5"),
+					TotalPayment:   amount(t, "2002.5"),
+				},
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "BadRequestOnDurationOfOneBillionMonths",
+			request: api.CreateLoanPlanRequest{
+				LoanAmount:  amountPtr(t, "2000.0"),
+				NominalRate: amountPtr(t, "1.0"),
+				Duration:    months(1000000000),
+				StartDate:   "2018-01-01T00:00:00Z",
+			},
+			wantStatusCode: http.StatusBadRequest,
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			service := api.New(loan.CreatePlan)
+			service := api.New(loan.CreatePlanForCurrencyContext)
 			server := httptest.NewServer(service)
 			defer server.Close()
 
 			createLoanPlanURL := server.URL + api.CreateLoanPlanPath
 			request := newRequest(t, http.MethodPost, createLoanPlanURL, toJSON(t, test.request))
+
+			if test.method == http.MethodGet {
+				query := url.Values{}
+				query.Set("loanAmount", test.request.LoanAmount.String())
+				query.Set("nominalRate", test.request.NominalRate.String())
+				query.Set("duration", strconv.Itoa(*test.request.Duration))
+				query.Set("startDate", test.request.StartDate)
+				request = newRequest(t, http.MethodGet, createLoanPlanURL+"?"+query.Encode(), nil)
+			}
 			client := server.Client()
 
 			res, err := client.Do(request)
@@ -82,3 +199,54 @@ func TestLoanPlanCreationIntegration(t *testing.T) {
 		})
 	}
 }
+
+func TestLoanPlanCreationAsCSVIntegration(t *testing.T) {
+	service := api.New(loan.CreatePlanForCurrencyContext)
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	createLoanPlanURL := server.URL + api.CreateLoanPlanPath
+	request := newRequest(t, http.MethodPost, createLoanPlanURL, toJSON(t, api.CreateLoanPlanRequest{
+		LoanAmount:  amountPtr(t, "2000.0"),
+		NominalRate: amountPtr(t, "1.0"),
+		Duration:    months(2),
+		StartDate:   "2018-01-01T00:00:00Z",
+	}))
+	request.Header.Set("Accept", "text/csv")
+
+	res, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got response %d want %d", res.StatusCode, http.StatusOK)
+	}
+
+	if got := res.Header.Get("Content-Type"); got != "text/csv" {
+		t.Errorf("got content type %q; want %q", got, "text/csv")
+	}
+
+	got, err := csv.NewReader(res.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{
+			"date",
+			"borrowerPaymentAmount",
+			"interest",
+			"principal",
+			"initialOutstandingPrincipal",
+			"remainingOutstandingPrincipal",
+		},
+		{"2018-01-01T00:00:00Z", "1001.25", "1.67", "999.58", "2000", "1000.42"},
+		{"2018-02-01T00:00:00Z", "1001.25", "0.83", "1000.42", "1000.42", "0"},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("api: POST %s as CSV mismatch (-want +got):\n%s", api.CreateLoanPlanPath, diff)
+	}
+}
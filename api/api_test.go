@@ -2,18 +2,23 @@ package api_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/katcipis/loaner/api"
 	"github.com/katcipis/loaner/loan"
+	"github.com/katcipis/loaner/money"
 	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
 )
 
 func TestLoanPlanCreation(t *testing.T) {
@@ -21,70 +26,232 @@ func TestLoanPlanCreation(t *testing.T) {
 		name           string
 		requestBody    []byte
 		method         string
+		query          string
 		injectResponse []loan.Payment
 		injectErr      error
 		wantStatusCode int
+		wantErrCode    api.ErrorCode
+		wantErrFields  []string
 		want           api.CreateLoanPlanResponse
 	}
 
 	tests := []Test{
 		{
-			name:           "MethodNotAllowedForGet",
-			method:         "GET",
+			name:           "MethodNotAllowedForPut",
+			method:         "PUT",
 			requestBody:    validCreateLoanRequestBody(t),
 			wantStatusCode: http.StatusMethodNotAllowed,
+			wantErrCode:    api.ErrorCodeMethodNotAllowed,
+		},
+		{
+			name:           "MethodNotAllowedForDelete",
+			method:         "DELETE",
+			wantStatusCode: http.StatusMethodNotAllowed,
+			wantErrCode:    api.ErrorCodeMethodNotAllowed,
+		},
+		{
+			name:           "BadRequestIfQueryIsEmptyOnGet",
+			method:         "GET",
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeMissingField,
+			wantErrFields:  []string{"loanAmount", "nominalRate", "duration"},
+		},
+		{
+			name:           "BadRequestIfQueryDurationIsNotIntOnGet",
+			method:         "GET",
+			query:          "loanAmount=1000.00&nominalRate=5.0&duration=notInt&startDate=2020-12-01T00:00:00Z",
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+			wantErrFields:  []string{"duration"},
+		},
+		{
+			name:           "BadRequestIfQueryLoanAmountIsNotDecimalOnGet",
+			method:         "GET",
+			query:          "loanAmount=notADecimal&nominalRate=5.0&duration=1&startDate=2020-12-01T00:00:00Z",
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+			wantErrFields:  []string{"loanAmount"},
+		},
+		{
+			name:           "BadRequestIfQueryNominalRateIsNotDecimalOnGet",
+			method:         "GET",
+			query:          "loanAmount=1000.00&nominalRate=wrongValue&duration=1&startDate=2020-12-01T00:00:00Z",
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+			wantErrFields:  []string{"nominalRate"},
+		},
+		{
+			name:           "BadRequestIfQueryStartDateIsNotValidDateOnGet",
+			method:         "GET",
+			query:          "loanAmount=1000.00&nominalRate=5.0&duration=1&startDate=notDate",
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+			wantErrFields:  []string{"startDate"},
+		},
+		{
+			name:           "BadRequestIfQueryCurrencyIsUnknownOnGet",
+			method:         "GET",
+			query:          "loanAmount=1000.00&nominalRate=5.0&duration=1&startDate=2020-12-01T00:00:00Z&currency=notACurrency",
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+			wantErrFields:  []string{"currency"},
+		},
+		{
+			name:           "BadRequestIfParametersAreConsideredInvalidByLoanPlanCreatorOnGet",
+			method:         "GET",
+			query:          validCreateLoanRequestQuery(),
+			injectErr:      loan.ErrInvalidParameter,
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+		},
+		{
+			name:   "SuccessBuildingLoanPlanOnGet",
+			method: "GET",
+			query:  validCreateLoanRequestQuery(),
+			injectResponse: []loan.Payment{
+				{
+					Date:                          parseTime(t, "2018-01-01T00:00:00Z"),
+					PaymentAmount:                 parseDecimal(t, "1001.25"),
+					Interest:                      parseDecimal(t, "1.67"),
+					Principal:                     parseDecimal(t, "999.58"),
+					InitialOutstandingPrincipal:   parseDecimal(t, "2000"),
+					RemainingOutstandingPrincipal: parseDecimal(t, "1000.42"),
+				},
+			},
+			want: api.CreateLoanPlanResponse{
+				BorrowerPayments: []api.BorrowerPayment{
+					{
+						Date:                          "2018-01-01T00:00:00Z",
+						PaymentAmount:                 amount(t, "1001.25"),
+						Interest:                      amount(t, "1.67"),
+						Principal:                     amount(t, "999.58"),
+						InitialOutstandingPrincipal:   amount(t, "2000"),
+						RemainingOutstandingPrincipal: amount(t, "1000.42"),
+					},
+				},
+				Total:          1,
+				MonthlyPayment: amount(t, "1004.17"),
+				PayoffDate:     "2018-01-01T00:00:00Z",
+				Summary: api.LoanPlanSummary{
+					TotalPrincipal: amount(t, "999.58"),
+					TotalInterest:  amount(t, "1.67"),
+					TotalPayment:   amount(t, "1001.25"),
+				},
+				Warnings: []string{api.WarningSinglePayment},
+			},
+			wantStatusCode: http.StatusOK,
 		},
 		{
 			name:           "BadRequestIfParametersAreConsideredInvalidByLoanPlanCreator",
 			requestBody:    validCreateLoanRequestBody(t),
 			injectErr:      loan.ErrInvalidParameter,
 			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
 		},
 		{
 			name:           "BadRequestIfRequestBodyIsEmpty",
 			requestBody:    []byte{},
 			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeMalformedJSON,
 		},
 		{
-			name: "BadRequestIfRequestLoanAmountIsNotDecimal",
+			name: "BadRequestIfRequestHasNoDurationNorEndDate",
 			requestBody: toJSON(t, api.CreateLoanPlanRequest{
-				LoanAmount:  "notADecimal",
-				NominalRate: "5.0",
-				Duration:    1,
+				LoanAmount:  amountPtr(t, "1000.00"),
+				NominalRate: amountPtr(t, "5.0"),
 				StartDate:   "2020-12-01T00:00:00Z",
 			}),
 			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeMissingField,
+			wantErrFields:  []string{"duration"},
 		},
 		{
-			name: "BadRequestIfRequestNominalRateIsNotDecimal",
-			requestBody: toJSON(t, api.CreateLoanPlanRequest{
-				LoanAmount:  "1.00",
-				NominalRate: "wrongValue",
-				Duration:    1,
-				StartDate:   "2020-12-01T00:00:00Z",
-			}),
+			name:           "BadRequestIfRequestDurationIsZero",
+			requestBody:    []byte(`{"loanAmount":"1000.00","nominalRate":"5.0","duration":0,"startDate":"2020-12-01T00:00:00Z"}`),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+			wantErrFields:  []string{"duration"},
+		},
+		{
+			name:           "BadRequestIfRequestLoanAmountIsNotDecimal",
+			requestBody:    []byte(`{"loanAmount":"notADecimal","nominalRate":"5.0","duration":1,"startDate":"2020-12-01T00:00:00Z"}`),
 			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+			wantErrFields:  []string{"loanAmount"},
+		},
+		{
+			name:           "BadRequestIfRequestNominalRateIsNotDecimal",
+			requestBody:    []byte(`{"loanAmount":"1.00","nominalRate":"wrongValue","duration":1,"startDate":"2020-12-01T00:00:00Z"}`),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+			wantErrFields:  []string{"nominalRate"},
 		},
 		{
 			name: "BadRequestIfRequestStartDateIsNotValidDate",
 			requestBody: toJSON(t, api.CreateLoanPlanRequest{
-				LoanAmount:  "1.00",
-				NominalRate: "1.0",
-				Duration:    1,
+				LoanAmount:  amountPtr(t, "1.00"),
+				NominalRate: amountPtr(t, "1.0"),
+				Duration:    months(1),
 				StartDate:   "notDate",
 			}),
 			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+			wantErrFields:  []string{"startDate"},
+		},
+		{
+			name: "BadRequestIfRequestCurrencyIsUnknown",
+			requestBody: toJSON(t, api.CreateLoanPlanRequest{
+				LoanAmount:  amountPtr(t, "1.00"),
+				NominalRate: amountPtr(t, "1.0"),
+				Duration:    months(1),
+				StartDate:   "2020-12-01T00:00:00Z",
+				Currency:    "notACurrency",
+			}),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+			wantErrFields:  []string{"currency"},
+		},
+		{
+			name:           "BadRequestReportsAllInvalidFields",
+			requestBody:    []byte(`{"loanAmount":"1.00","nominalRate":"wrongValue","duration":1,"startDate":"notDate"}`),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+			wantErrFields:  []string{"nominalRate", "startDate"},
+		},
+		{
+			name:           "BadRequestReportsAllInvalidFieldsOnGet",
+			method:         "GET",
+			query:          "loanAmount=notADecimal&nominalRate=5.0&duration=notInt&startDate=2020-12-01T00:00:00Z",
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+			wantErrFields:  []string{"duration", "loanAmount"},
 		},
 		{
 			name:           "BadRequestIfRequestBodyIsNotValidJSON",
 			requestBody:    []byte("{notvalidjson]"),
 			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeMalformedJSON,
 		},
 		{
 			name:           "InternalServerErrorOnLoanCalculationError",
 			requestBody:    validCreateLoanRequestBody(t),
 			injectErr:      errors.New("injected generic error"),
 			wantStatusCode: http.StatusInternalServerError,
+			wantErrCode:    api.ErrorCodeInternal,
+		},
+		{
+			name:           "ServiceUnavailableOnCanceledLoanCalculation",
+			requestBody:    validCreateLoanRequestBody(t),
+			injectErr:      fmt.Errorf("injected cancel:%w", context.Canceled),
+			wantStatusCode: http.StatusServiceUnavailable,
+			wantErrCode:    api.ErrorCodeCanceled,
+		},
+		{
+			name:           "ServiceUnavailableOnLoanCalculationTimeout",
+			requestBody:    validCreateLoanRequestBody(t),
+			injectErr:      fmt.Errorf("injected timeout:%w", context.DeadlineExceeded),
+			wantStatusCode: http.StatusServiceUnavailable,
+			wantErrCode:    api.ErrorCodeCanceled,
 		},
 		{
 			name:        "SuccessBuildingLoanPlan",
@@ -111,21 +278,29 @@ func TestLoanPlanCreation(t *testing.T) {
 				BorrowerPayments: []api.BorrowerPayment{
 					{
 						Date:                          "2018-01-01T00:00:00Z",
-						PaymentAmount:                 "1001.25",
-						Interest:                      "1.67",
-						Principal:                     "999.58",
-						InitialOutstandingPrincipal:   "2000",
-						RemainingOutstandingPrincipal: "1000.42",
+						PaymentAmount:                 amount(t, "1001.25"),
+						Interest:                      amount(t, "1.67"),
+						Principal:                     amount(t, "999.58"),
+						InitialOutstandingPrincipal:   amount(t, "2000"),
+						RemainingOutstandingPrincipal: amount(t, "1000.42"),
 					},
 					{
 						Date:                          "2018-02-01T00:00:00Z",
-						PaymentAmount:                 "1001.25",
-						Interest:                      "0.83",
-						Principal:                     "1000.42",
-						InitialOutstandingPrincipal:   "1000.42",
-						RemainingOutstandingPrincipal: "0",
+						PaymentAmount:                 amount(t, "1001.25"),
+						Interest:                      amount(t, "0.83"),
+						Principal:                     amount(t, "1000.42"),
+						InitialOutstandingPrincipal:   amount(t, "1000.42"),
+						RemainingOutstandingPrincipal: amount(t, "0"),
 					},
 				},
+				Total:          2,
+				MonthlyPayment: amount(t, "1004.17"),
+				PayoffDate:     "2018-02-01T00:00:00Z",
+				Summary: api.LoanPlanSummary{
+					TotalPrincipal: amount(t, "2000"),
+					TotalInterest:  amount(t, "2.5"),
+					TotalPayment:   amount(t, "2002.5"),
+				},
 			},
 			wantStatusCode: http.StatusOK,
 		},
@@ -146,13 +321,60 @@ func TestLoanPlanCreation(t *testing.T) {
 				BorrowerPayments: []api.BorrowerPayment{
 					{
 						Date:                          "2018-01-01T00:00:00Z",
-						PaymentAmount:                 "1001.25",
-						Interest:                      "1.67",
-						Principal:                     "999.58",
-						InitialOutstandingPrincipal:   "2000",
-						RemainingOutstandingPrincipal: "1000.42",
+						PaymentAmount:                 amount(t, "1001.25"),
+						Interest:                      amount(t, "1.67"),
+						Principal:                     amount(t, "999.58"),
+						InitialOutstandingPrincipal:   amount(t, "2000"),
+						RemainingOutstandingPrincipal: amount(t, "1000.42"),
+					},
+				},
+				Total:          1,
+				MonthlyPayment: amount(t, "1004.17"),
+				PayoffDate:     "2018-01-01T00:00:00Z",
+				Summary: api.LoanPlanSummary{
+					TotalPrincipal: amount(t, "999.58"),
+					TotalInterest:  amount(t, "1.67"),
+					TotalPayment:   amount(t, "1001.25"),
+				},
+				Warnings: []string{api.WarningSinglePayment},
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:        "SuccessBuildingLoanPlanWithRecurringFee",
+			requestBody: validCreateLoanRequestBody(t),
+			injectResponse: []loan.Payment{
+				{
+					Date:                          parseTime(t, "2018-01-01T00:00:00Z"),
+					PaymentAmount:                 parseDecimal(t, "1011.25"),
+					Interest:                      parseDecimal(t, "1.67"),
+					Principal:                     parseDecimal(t, "999.58"),
+					InitialOutstandingPrincipal:   parseDecimal(t, "2000"),
+					RemainingOutstandingPrincipal: parseDecimal(t, "1000.42"),
+					RecurringFee:                  parseDecimal(t, "10"),
+				},
+			},
+			want: api.CreateLoanPlanResponse{
+				BorrowerPayments: []api.BorrowerPayment{
+					{
+						Date:                          "2018-01-01T00:00:00Z",
+						PaymentAmount:                 amount(t, "1011.25"),
+						Interest:                      amount(t, "1.67"),
+						Principal:                     amount(t, "999.58"),
+						InitialOutstandingPrincipal:   amount(t, "2000"),
+						RemainingOutstandingPrincipal: amount(t, "1000.42"),
+						Fee:                           amountPtr(t, "10"),
 					},
 				},
+				Total:          1,
+				MonthlyPayment: amount(t, "1004.17"),
+				PayoffDate:     "2018-01-01T00:00:00Z",
+				Summary: api.LoanPlanSummary{
+					TotalPrincipal: amount(t, "999.58"),
+					TotalInterest:  amount(t, "1.67"),
+					TotalPayment:   amount(t, "1011.25"),
+				},
+				Warnings: []string{api.WarningSinglePayment},
 			},
 			wantStatusCode: http.StatusOK,
 		},
@@ -165,6 +387,13 @@ func TestLoanPlanCreation(t *testing.T) {
 			injectResponse: []loan.Payment{},
 			want: api.CreateLoanPlanResponse{
 				BorrowerPayments: []api.BorrowerPayment{},
+				Total:            0,
+				MonthlyPayment:   amount(t, "1004.17"),
+				Summary: api.LoanPlanSummary{
+					TotalPrincipal: amount(t, "0"),
+					TotalInterest:  amount(t, "0"),
+					TotalPayment:   amount(t, "0"),
+				},
 			},
 			wantStatusCode: http.StatusOK,
 		},
@@ -190,10 +419,12 @@ func TestLoanPlanCreation(t *testing.T) {
 			// There is a good post from Kent Beck that relates to this:
 			// https://medium.com/@kentbeck_7670/programmer-test-principles-d01c064d7934
 			service := api.New(func(
+				ctx context.Context,
 				totalLoanAmount decimal.Decimal,
 				annualInterestRate decimal.Decimal,
 				durationInMonths int,
 				start time.Time,
+				currency loan.Currency,
 			) ([]loan.Payment, error) {
 				// There is no validation of parameters passed here
 				// because proper parameter passing is covered
@@ -209,6 +440,9 @@ func TestLoanPlanCreation(t *testing.T) {
 			}
 
 			createLoanPlanURL := server.URL + api.CreateLoanPlanPath
+			if test.query != "" {
+				createLoanPlanURL += "?" + test.query
+			}
 			request := newRequest(t, method, createLoanPlanURL, test.requestBody)
 			client := server.Client()
 
@@ -222,6 +456,13 @@ func TestLoanPlanCreation(t *testing.T) {
 				t.Fatalf("got response %d want %d", res.StatusCode, test.wantStatusCode)
 			}
 
+			if test.wantStatusCode == http.StatusMethodNotAllowed {
+				const wantAllow = "GET, POST"
+				if allow := res.Header.Get("Allow"); allow != wantAllow {
+					t.Errorf("got Allow header %q; want %q", allow, wantAllow)
+				}
+			}
+
 			if test.wantStatusCode != http.StatusOK {
 				wantErr := api.ErrorResponse{}
 				fromJSON(t, res.Body, &wantErr)
@@ -229,13 +470,36 @@ func TestLoanPlanCreation(t *testing.T) {
 				// Validate that a message is sent, but not its contents
 				// since the message is for human inspection only and
 				// should be handled opaquely by code.
-				// If necessary we can introduce error codes (strings or ints),
-				// but it does not seem necessary for now.
-				// If we add some tracing ID for errors this would also
-				// be the place to check for them.
+				// Programmatic decisions should be made using the error code.
 				if wantErr.Error.Message == "" {
 					t.Fatalf("expected an error message on status code %d", test.wantStatusCode)
 				}
+
+				requestID := res.Header.Get(api.RequestIDHeader)
+				if requestID == "" || wantErr.Error.TraceID != requestID {
+					t.Errorf("got trace ID %q; want it to match request ID header %q",
+						wantErr.Error.TraceID, requestID)
+				}
+
+				if wantErr.Error.Code != test.wantErrCode {
+					t.Errorf("got error code %q; want %q", wantErr.Error.Code, test.wantErrCode)
+				}
+
+				// Same as the message, the reason of each field error
+				// is for human inspection only.
+				gotErrFields := []string{}
+				for _, fieldErr := range wantErr.Error.Fields {
+					if fieldErr.Reason == "" {
+						t.Errorf("expected a reason on field error %v", fieldErr)
+					}
+					gotErrFields = append(gotErrFields, fieldErr.Field)
+				}
+				if test.wantErrFields == nil {
+					test.wantErrFields = []string{}
+				}
+				if diff := cmp.Diff(test.wantErrFields, gotErrFields); diff != "" {
+					t.Errorf("error fields mismatch (-want +got):\n%s", diff)
+				}
 				return
 			}
 
@@ -250,59 +514,1939 @@ func TestLoanPlanCreation(t *testing.T) {
 	}
 }
 
-func fromJSON(t *testing.T, data io.Reader, v interface{}) {
-	t.Helper()
+func TestLoanPlanCreationContentNegotiation(t *testing.T) {
+	type Test struct {
+		name            string
+		accept          string
+		wantContentType string
+	}
 
-	dec := json.NewDecoder(data)
-	err := dec.Decode(&v)
-	if err != nil {
-		t.Fatal(err)
+	tests := []Test{
+		{
+			name:            "DefaultsToJSON",
+			wantContentType: "application/json",
+		},
+		{
+			name:            "JSON",
+			accept:          "application/json",
+			wantContentType: "application/json",
+		},
+		{
+			name:            "CSV",
+			accept:          "text/csv",
+			wantContentType: "text/csv",
+		},
+		{
+			name:            "CSVWithParameters",
+			accept:          "text/csv; charset=utf-8",
+			wantContentType: "text/csv",
+		},
+		{
+			name:            "PDF",
+			accept:          "application/pdf",
+			wantContentType: "application/pdf",
+		},
+		{
+			name:            "FirstSupportedMediaTypeWins",
+			accept:          "text/html, application/json, text/csv",
+			wantContentType: "application/json",
+		},
+		{
+			name:            "UnsupportedMediaTypeDefaultsToJSON",
+			accept:          "text/html",
+			wantContentType: "application/json",
+		},
 	}
-}
 
-func toJSON(t *testing.T, v interface{}) []byte {
-	t.Helper()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(func(
+				ctx context.Context,
+				totalLoanAmount decimal.Decimal,
+				annualInterestRate decimal.Decimal,
+				durationInMonths int,
+				start time.Time,
+				currency loan.Currency,
+			) ([]loan.Payment, error) {
+				return []loan.Payment{
+					{
+						Date:                          parseTime(t, "2018-01-01T00:00:00Z"),
+						PaymentAmount:                 parseDecimal(t, "1001.25"),
+						Interest:                      parseDecimal(t, "1.67"),
+						Principal:                     parseDecimal(t, "999.58"),
+						InitialOutstandingPrincipal:   parseDecimal(t, "2000"),
+						RemainingOutstandingPrincipal: parseDecimal(t, "1000.42"),
+					},
+				}, nil
+			})
+			server := httptest.NewServer(service)
+			defer server.Close()
 
-	j, err := json.Marshal(v)
-	if err != nil {
-		t.Fatal(err)
+			createLoanPlanURL := server.URL + api.CreateLoanPlanPath
+			request := newRequest(t, http.MethodPost, createLoanPlanURL, validCreateLoanRequestBody(t))
+			if test.accept != "" {
+				request.Header.Set("Accept", test.accept)
+			}
+
+			res, err := server.Client().Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("got response %d want %d", res.StatusCode, http.StatusOK)
+			}
+
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gotContentType := res.Header.Get("Content-Type")
+			if json.Valid(body) != (gotContentType == "application/json") {
+				t.Errorf("got body %q that doesn't match content type %q", body, gotContentType)
+			}
+
+			if gotContentType != test.wantContentType {
+				t.Errorf("got content type %q; want %q", gotContentType, test.wantContentType)
+			}
+		})
 	}
-	return j
 }
 
-func newRequest(t *testing.T, method string, url string, body []byte) *http.Request {
-	t.Helper()
+func TestRequestID(t *testing.T) {
+	type Test struct {
+		name           string
+		requestID      string
+		injectErr      error
+		wantStatusCode int
+	}
 
-	req, err := http.NewRequest(method, url, bytes.NewReader(body))
-	if err != nil {
-		t.Fatal(err)
+	tests := []Test{
+		{
+			name:           "GeneratedOnSuccess",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "GeneratedOnError",
+			injectErr:      errors.New("injected generic error"),
+			wantStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name:           "HonoredOnSuccess",
+			requestID:      "client-request-id",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "HonoredOnError",
+			requestID:      "client-request-id",
+			injectErr:      errors.New("injected generic error"),
+			wantStatusCode: http.StatusInternalServerError,
+		},
 	}
-	return req
-}
 
-func validCreateLoanRequestBody(t *testing.T) []byte {
-	return toJSON(t, api.CreateLoanPlanRequest{
-		LoanAmount:  "1000.00",
-		NominalRate: "5.0",
-		Duration:    1,
-		StartDate:   "2020-12-01T00:00:00Z",
-	})
-}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(func(
+				ctx context.Context,
+				totalLoanAmount decimal.Decimal,
+				annualInterestRate decimal.Decimal,
+				durationInMonths int,
+				start time.Time,
+				currency loan.Currency,
+			) ([]loan.Payment, error) {
+				return nil, test.injectErr
+			})
+			server := httptest.NewServer(service)
+			defer server.Close()
 
-func parseDecimal(t *testing.T, v string) decimal.Decimal {
-	t.Helper()
-	d, err := decimal.NewFromString(v)
-	if err != nil {
-		t.Fatal(err)
+			createLoanPlanURL := server.URL + api.CreateLoanPlanPath
+			gotRequestIDs := map[string]bool{}
+
+			for i := 0; i < 2; i++ {
+				request := newRequest(t, http.MethodPost, createLoanPlanURL, validCreateLoanRequestBody(t))
+				if test.requestID != "" {
+					request.Header.Set(api.RequestIDHeader, test.requestID)
+				}
+
+				res, err := server.Client().Do(request)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer res.Body.Close()
+
+				if res.StatusCode != test.wantStatusCode {
+					t.Fatalf("got response %d want %d", res.StatusCode, test.wantStatusCode)
+				}
+
+				gotRequestID := res.Header.Get(api.RequestIDHeader)
+				if gotRequestID == "" {
+					t.Fatal("expected request ID header on response")
+				}
+
+				if test.requestID != "" && gotRequestID != test.requestID {
+					t.Errorf("got request ID %q; want %q", gotRequestID, test.requestID)
+				}
+
+				if test.wantStatusCode != http.StatusOK {
+					errResponse := api.ErrorResponse{}
+					fromJSON(t, res.Body, &errResponse)
+					if errResponse.Error.TraceID != gotRequestID {
+						t.Errorf("got trace ID %q; want %q", errResponse.Error.TraceID, gotRequestID)
+					}
+				}
+
+				gotRequestIDs[gotRequestID] = true
+			}
+
+			wantUniqueIDs := 2
+			if test.requestID != "" {
+				wantUniqueIDs = 1
+			}
+			if len(gotRequestIDs) != wantUniqueIDs {
+				t.Errorf("got request IDs %v; want %d unique IDs", gotRequestIDs, wantUniqueIDs)
+			}
+		})
 	}
-	return d
 }
 
-func parseTime(t *testing.T, s string) time.Time {
-	t.Helper()
-	v, err := time.Parse(time.RFC3339, s)
+func TestInjectedLogger(t *testing.T) {
+	logs := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.SetOutput(logs)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+
+	service := api.New(func(
+		ctx context.Context,
+		totalLoanAmount decimal.Decimal,
+		annualInterestRate decimal.Decimal,
+		durationInMonths int,
+		start time.Time,
+		currency loan.Currency,
+	) ([]loan.Payment, error) {
+		return nil, errors.New("injected generic error")
+	}, api.WithLogger(logger))
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	request := newRequest(t, http.MethodPost, server.URL+api.CreateLoanPlanPath, validCreateLoanRequestBody(t))
+	res, err := server.Client().Do(request)
 	if err != nil {
 		t.Fatal(err)
 	}
-	return v
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("got response %d want %d", res.StatusCode, http.StatusInternalServerError)
+	}
+
+	type logEntry struct {
+		Level     string `json:"level"`
+		Msg       string `json:"msg"`
+		Error     string `json:"error"`
+		Path      string `json:"path"`
+		RequestID string `json:"requestID"`
+	}
+
+	got := logEntry{}
+	fromJSON(t, logs, &got)
+
+	want := logEntry{
+		Level:     "error",
+		Msg:       "internal server error",
+		Error:     "injected generic error",
+		Path:      api.CreateLoanPlanPath,
+		RequestID: res.Header.Get(api.RequestIDHeader),
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("log entry mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoanPlanCreationPagination(t *testing.T) {
+	type Test struct {
+		name          string
+		method        string
+		query         string
+		wantDates     []string
+		wantErrFields []string
+	}
+
+	tests := []Test{
+		{
+			name:      "FirstPage",
+			method:    http.MethodGet,
+			query:     "offset=0&limit=2",
+			wantDates: []string{"2018-01-01T00:00:00Z", "2018-02-01T00:00:00Z"},
+		},
+		{
+			name:      "PageWithinRange",
+			method:    http.MethodGet,
+			query:     "offset=2&limit=3",
+			wantDates: []string{"2018-03-01T00:00:00Z", "2018-04-01T00:00:00Z", "2018-05-01T00:00:00Z"},
+		},
+		{
+			name:      "PageWithinRangeOnPost",
+			method:    http.MethodPost,
+			query:     "offset=2&limit=3",
+			wantDates: []string{"2018-03-01T00:00:00Z", "2018-04-01T00:00:00Z", "2018-05-01T00:00:00Z"},
+		},
+		{
+			name:      "OnlyOffset",
+			method:    http.MethodGet,
+			query:     "offset=10",
+			wantDates: []string{"2018-11-01T00:00:00Z", "2018-12-01T00:00:00Z"},
+		},
+		{
+			name:      "LimitPastTheEnd",
+			method:    http.MethodGet,
+			query:     "offset=11&limit=5",
+			wantDates: []string{"2018-12-01T00:00:00Z"},
+		},
+		{
+			name:      "OffsetPastTheEnd",
+			method:    http.MethodGet,
+			query:     "offset=12&limit=5",
+			wantDates: []string{},
+		},
+		{
+			name:          "NegativeOffset",
+			method:        http.MethodGet,
+			query:         "offset=-1&limit=5",
+			wantErrFields: []string{"offset"},
+		},
+		{
+			name:          "NegativeLimit",
+			method:        http.MethodGet,
+			query:         "offset=0&limit=-5",
+			wantErrFields: []string{"limit"},
+		},
+		{
+			name:          "ZeroLimit",
+			method:        http.MethodPost,
+			query:         "limit=0",
+			wantErrFields: []string{"limit"},
+		},
+		{
+			name:          "InvalidOffsetAndLimit",
+			method:        http.MethodGet,
+			query:         "offset=first&limit=all",
+			wantErrFields: []string{"offset", "limit"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(loan.CreatePlanForCurrencyContext)
+
+			request := api.CreateLoanPlanRequest{
+				LoanAmount:  amountPtr(t, "5000"),
+				NominalRate: amountPtr(t, "5.0"),
+				Duration:    months(12),
+				StartDate:   "2018-01-01T00:00:00Z",
+			}
+
+			var req *http.Request
+			if test.method == http.MethodPost {
+				req = httptest.NewRequest(http.MethodPost, api.CreateLoanPlanPath+"?"+test.query, bytes.NewReader(toJSON(t, request)))
+			} else {
+				query := "loanAmount=5000&nominalRate=5.0&duration=12&startDate=2018-01-01T00:00:00Z&" + test.query
+				req = httptest.NewRequest(http.MethodGet, api.CreateLoanPlanPath+"?"+query, nil)
+			}
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			if len(test.wantErrFields) > 0 {
+				if res.Code != http.StatusBadRequest {
+					t.Fatalf("got response %d want %d", res.Code, http.StatusBadRequest)
+				}
+				errResponse := api.ErrorResponse{}
+				fromJSON(t, res.Body, &errResponse)
+
+				gotFields := []string{}
+				for _, field := range errResponse.Error.Fields {
+					gotFields = append(gotFields, field.Field)
+				}
+				if diff := cmp.Diff(test.wantErrFields, gotFields); diff != "" {
+					t.Errorf("error fields mismatch (-want +got):\n%s", diff)
+				}
+				return
+			}
+
+			if res.Code != http.StatusOK {
+				t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+			}
+
+			resp := api.CreateLoanPlanResponse{}
+			fromJSON(t, res.Body, &resp)
+
+			if resp.Total != 12 {
+				t.Errorf("got total %d; want 12", resp.Total)
+			}
+			if !resp.Summary.TotalPrincipal.Equal(amount(t, "5000")) {
+				t.Errorf("got summary total principal %q; want the whole loan plan summary", resp.Summary.TotalPrincipal)
+			}
+
+			gotDates := []string{}
+			for _, payment := range resp.BorrowerPayments {
+				gotDates = append(gotDates, payment.Date)
+			}
+			if diff := cmp.Diff(test.wantDates, gotDates); diff != "" {
+				t.Errorf("payment dates mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRequestBodySizeLimit(t *testing.T) {
+	type Test struct {
+		name        string
+		path        string
+		opts        []api.Option
+		wantMaxRead int64
+	}
+
+	tests := []Test{
+		{
+			name:        "DefaultLimitOnLoanPlan",
+			path:        api.CreateLoanPlanPath,
+			wantMaxRead: api.DefaultMaxBodySize,
+		},
+		{
+			name:        "CustomLimitOnLoanPlan",
+			path:        api.CreateLoanPlanPath,
+			opts:        []api.Option{api.WithMaxBodySize(1024)},
+			wantMaxRead: 1024,
+		},
+		{
+			name:        "DefaultLimitOnBatch",
+			path:        api.CreateLoanPlansPath,
+			wantMaxRead: api.DefaultMaxBodySize,
+		},
+		{
+			name:        "CustomLimitOnBatch",
+			path:        api.CreateLoanPlansPath,
+			opts:        []api.Option{api.WithMaxBodySize(1024)},
+			wantMaxRead: 1024,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(func(
+				ctx context.Context,
+				totalLoanAmount decimal.Decimal,
+				annualInterestRate decimal.Decimal,
+				durationInMonths int,
+				start time.Time,
+				currency loan.Currency,
+			) ([]loan.Payment, error) {
+				t.Error("loan plans must not be created for oversized requests")
+				return nil, nil
+			}, test.opts...)
+
+			// A never ending JSON string, so the decoder would
+			// consume it forever if the body was not limited.
+			body := &endlessBody{prefix: []byte(`[{"loanAmount":"`)}
+			if test.path == api.CreateLoanPlanPath {
+				body.prefix = body.prefix[1:]
+			}
+
+			req := httptest.NewRequest(http.MethodPost, test.path, body)
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			if res.Code != http.StatusRequestEntityTooLarge {
+				t.Fatalf("got response %d want %d", res.Code, http.StatusRequestEntityTooLarge)
+			}
+
+			errResponse := api.ErrorResponse{}
+			fromJSON(t, res.Body, &errResponse)
+			if errResponse.Error.Code != api.ErrorCodeRequestTooLarge {
+				t.Errorf("got error code %q; want %q", errResponse.Error.Code, api.ErrorCodeRequestTooLarge)
+			}
+
+			// The decoder reads in chunks, so it may read a little bit more
+			// than the limit from the body, but not much more than that.
+			if body.read > 2*test.wantMaxRead {
+				t.Errorf("read %d bytes from body; want at most %d", body.read, 2*test.wantMaxRead)
+			}
+		})
+	}
+}
+
+func TestRequestContentType(t *testing.T) {
+	type Test struct {
+		name        string
+		path        string
+		contentType string
+		wantStatus  int
+	}
+
+	tests := []Test{
+		{
+			name:       "NoContentTypeOnLoanPlan",
+			path:       api.CreateLoanPlanPath,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "JSONOnLoanPlan",
+			path:        api.CreateLoanPlanPath,
+			contentType: "application/json",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "JSONWithCharsetOnLoanPlan",
+			path:        api.CreateLoanPlanPath,
+			contentType: "application/json; charset=utf-8",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "TextOnLoanPlan",
+			path:        api.CreateLoanPlanPath,
+			contentType: "text/plain",
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "FormOnLoanPlan",
+			path:        api.CreateLoanPlanPath,
+			contentType: "application/x-www-form-urlencoded",
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "InvalidContentTypeOnLoanPlan",
+			path:        api.CreateLoanPlanPath,
+			contentType: "application/json;;",
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "JSONOnBatch",
+			path:        api.CreateLoanPlansPath,
+			contentType: "application/json",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "TextOnBatch",
+			path:        api.CreateLoanPlansPath,
+			contentType: "text/plain",
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "JSONOnCompare",
+			path:        api.CompareLoanPlansPath,
+			contentType: "application/json",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "TextOnCompare",
+			path:        api.CompareLoanPlansPath,
+			contentType: "text/plain",
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(loan.CreatePlanForCurrencyContext)
+
+			body := validCreateLoanRequestBody(t)
+			switch test.path {
+			case api.CreateLoanPlansPath:
+				body = []byte("[" + string(body) + "]")
+			case api.CompareLoanPlansPath:
+				body = []byte(`{"first":` + string(body) + `,"second":` + string(body) + "}")
+			}
+
+			req := httptest.NewRequest(http.MethodPost, test.path, bytes.NewReader(body))
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			if res.Code != test.wantStatus {
+				t.Fatalf("got response %d want %d; body: %s", res.Code, test.wantStatus, res.Body)
+			}
+			if test.wantStatus == http.StatusOK {
+				return
+			}
+
+			errResponse := api.ErrorResponse{}
+			fromJSON(t, res.Body, &errResponse)
+			if errResponse.Error.Code != api.ErrorCodeUnsupportedMediaType {
+				t.Errorf("got error code %q; want %q", errResponse.Error.Code, api.ErrorCodeUnsupportedMediaType)
+			}
+		})
+	}
+}
+
+func TestDurationBounds(t *testing.T) {
+	type Test struct {
+		name        string
+		opts        []api.Option
+		duration    int
+		wantFailure string
+	}
+
+	tests := []Test{
+		{
+			name:        "ZeroDuration",
+			duration:    0,
+			wantFailure: "duration must be between 1 and 360",
+		},
+		{
+			name:     "MinDuration",
+			duration: 1,
+		},
+		{
+			name:     "MaxDuration",
+			duration: 360,
+		},
+		{
+			name:        "AboveMaxDuration",
+			duration:    361,
+			wantFailure: "duration must be between 1 and 360",
+		},
+		{
+			name:        "BelowCustomMinDuration",
+			opts:        []api.Option{api.WithDurationBounds(12, 24)},
+			duration:    11,
+			wantFailure: "duration must be between 12 and 24",
+		},
+		{
+			name:     "CustomMaxDuration",
+			opts:     []api.Option{api.WithDurationBounds(12, 24)},
+			duration: 24,
+		},
+		{
+			name:        "AboveCustomMaxDuration",
+			opts:        []api.Option{api.WithDurationBounds(12, 24)},
+			duration:    25,
+			wantFailure: "duration must be between 12 and 24",
+		},
+		{
+			name:     "CustomMaxDurationAboveLoanDefaultMax",
+			opts:     []api.Option{api.WithDurationBounds(1, 720)},
+			duration: 700,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			created := false
+			service := api.New(func(
+				ctx context.Context,
+				totalLoanAmount decimal.Decimal,
+				annualInterestRate decimal.Decimal,
+				durationInMonths int,
+				start time.Time,
+				currency loan.Currency,
+			) ([]loan.Payment, error) {
+				created = true
+				// Plans can be longer than loan.DefaultMaxDurationInMonths,
+				// so only the bounds of the service limit the duration.
+				return loan.BuildPlan(
+					totalLoanAmount,
+					annualInterestRate,
+					durationInMonths,
+					start,
+					loan.WithCurrency(currency),
+					loan.WithMaxDuration(720),
+				)
+			}, test.opts...)
+
+			body := toJSON(t, api.CreateLoanPlanRequest{
+				LoanAmount:  amountPtr(t, "5000"),
+				NominalRate: amountPtr(t, "5.0"),
+				Duration:    months(test.duration),
+				StartDate:   "2018-01-01T00:00:00Z",
+			})
+			req := httptest.NewRequest(http.MethodPost, api.CreateLoanPlanPath, bytes.NewReader(body))
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			if test.wantFailure == "" {
+				if res.Code != http.StatusOK {
+					t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+				}
+				return
+			}
+
+			if res.Code != http.StatusBadRequest {
+				t.Fatalf("got response %d want %d", res.Code, http.StatusBadRequest)
+			}
+			if created {
+				t.Error("loan plan must not be created when the duration is out of bounds")
+			}
+
+			errResponse := api.ErrorResponse{}
+			fromJSON(t, res.Body, &errResponse)
+
+			want := []api.FieldError{{Field: "duration", Reason: test.wantFailure}}
+			if diff := cmp.Diff(want, errResponse.Error.Fields); diff != "" {
+				t.Errorf("error fields mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAmountAndRateBounds(t *testing.T) {
+	type Test struct {
+		name         string
+		opts         []api.Option
+		loanAmount   string
+		nominalRate  string
+		wantFailures []api.FieldError
+	}
+
+	businessBounds := []api.Option{
+		api.WithAmountBounds(decimal.NewFromInt(100), decimal.NewFromInt(1000000)),
+		api.WithRateBounds(decimal.Zero, decimal.NewFromInt(50)),
+	}
+
+	tests := []Test{
+		{
+			name:        "WithinBounds",
+			opts:        businessBounds,
+			loanAmount:  "500",
+			nominalRate: "10",
+		},
+		{
+			name:        "OnBounds",
+			opts:        businessBounds,
+			loanAmount:  "1000000",
+			nominalRate: "50",
+		},
+		{
+			name:        "AmountBelowMin",
+			opts:        businessBounds,
+			loanAmount:  "50",
+			nominalRate: "10",
+			wantFailures: []api.FieldError{
+				{Field: "loanAmount", Reason: "loanAmount must be between 100 and 1000000"},
+			},
+		},
+		{
+			name:        "AmountAboveMax",
+			opts:        businessBounds,
+			loanAmount:  "1000000.01",
+			nominalRate: "10",
+			wantFailures: []api.FieldError{
+				{Field: "loanAmount", Reason: "loanAmount must be between 100 and 1000000"},
+			},
+		},
+		{
+			name:        "RateAboveMax",
+			opts:        businessBounds,
+			loanAmount:  "500",
+			nominalRate: "75",
+			wantFailures: []api.FieldError{
+				{Field: "nominalRate", Reason: "nominalRate must be at most 50"},
+			},
+		},
+		{
+			name:        "RateBelowMin",
+			opts:        []api.Option{api.WithRateBounds(decimal.NewFromInt(1), decimal.Zero)},
+			loanAmount:  "500",
+			nominalRate: "0.5",
+			wantFailures: []api.FieldError{
+				{Field: "nominalRate", Reason: "nominalRate must be at least 1"},
+			},
+		},
+		{
+			name:        "AmountAndRateOutOfBounds",
+			opts:        businessBounds,
+			loanAmount:  "50",
+			nominalRate: "75",
+			wantFailures: []api.FieldError{
+				{Field: "loanAmount", Reason: "loanAmount must be between 100 and 1000000"},
+				{Field: "nominalRate", Reason: "nominalRate must be at most 50"},
+			},
+		},
+		{
+			name:        "NoBoundsByDefault",
+			loanAmount:  "50",
+			nominalRate: "75",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			created := false
+			service := api.New(func(
+				ctx context.Context,
+				totalLoanAmount decimal.Decimal,
+				annualInterestRate decimal.Decimal,
+				durationInMonths int,
+				start time.Time,
+				currency loan.Currency,
+			) ([]loan.Payment, error) {
+				created = true
+				return loan.CreatePlanForCurrencyContext(ctx, totalLoanAmount, annualInterestRate, durationInMonths, start, currency)
+			}, test.opts...)
+
+			body := toJSON(t, api.CreateLoanPlanRequest{
+				LoanAmount:  amountPtr(t, test.loanAmount),
+				NominalRate: amountPtr(t, test.nominalRate),
+				Duration:    months(24),
+				StartDate:   "2018-01-01T00:00:00Z",
+			})
+			req := newRequest(t, http.MethodPost, api.CreateLoanPlanPath, body)
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			if len(test.wantFailures) == 0 {
+				if res.Code != http.StatusOK {
+					t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+				}
+				return
+			}
+
+			if res.Code != http.StatusBadRequest {
+				t.Fatalf("got response %d want %d", res.Code, http.StatusBadRequest)
+			}
+			if created {
+				t.Error("loan plan must not be created when the parameters are out of bounds")
+			}
+
+			errResponse := api.ErrorResponse{}
+			fromJSON(t, res.Body, &errResponse)
+
+			if diff := cmp.Diff(test.wantFailures, errResponse.Error.Fields); diff != "" {
+				t.Errorf("error fields mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNominalRateInBasisPoints(t *testing.T) {
+	service := api.New(loan.CreatePlanForCurrencyContext)
+
+	createPlan := func(t *testing.T, req *http.Request) *httptest.ResponseRecorder {
+		t.Helper()
+		res := httptest.NewRecorder()
+		service.ServeHTTP(res, req)
+		return res
+	}
+
+	wantRes := createPlan(t, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, toJSON(t, api.CreateLoanPlanRequest{
+		LoanAmount:  amountPtr(t, "5000"),
+		NominalRate: amountPtr(t, "5.0"),
+		Duration:    months(24),
+		StartDate:   "2018-01-01T00:00:00Z",
+	})))
+	if wantRes.Code != http.StatusOK {
+		t.Fatalf("got response %d want %d; body: %s", wantRes.Code, http.StatusOK, wantRes.Body)
+	}
+	want := api.CreateLoanPlanResponse{}
+	fromJSON(t, wantRes.Body, &want)
+
+	bps := 500
+
+	t.Run("Post", func(t *testing.T) {
+		res := createPlan(t, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, toJSON(t, api.CreateLoanPlanRequest{
+			LoanAmount:     amountPtr(t, "5000"),
+			NominalRateBps: &bps,
+			Duration:       months(24),
+			StartDate:      "2018-01-01T00:00:00Z",
+		})))
+		if res.Code != http.StatusOK {
+			t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+		}
+
+		got := api.CreateLoanPlanResponse{}
+		fromJSON(t, res.Body, &got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("loan plan mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		query := "loanAmount=5000&nominalRateBps=500&duration=24&startDate=2018-01-01T00:00:00Z"
+		res := createPlan(t, httptest.NewRequest(http.MethodGet, api.CreateLoanPlanPath+"?"+query, nil))
+		if res.Code != http.StatusOK {
+			t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+		}
+
+		got := api.CreateLoanPlanResponse{}
+		fromJSON(t, res.Body, &got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("loan plan mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("BothRates", func(t *testing.T) {
+		res := createPlan(t, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, toJSON(t, api.CreateLoanPlanRequest{
+			LoanAmount:     amountPtr(t, "5000"),
+			NominalRate:    amountPtr(t, "5.0"),
+			NominalRateBps: &bps,
+			Duration:       months(24),
+			StartDate:      "2018-01-01T00:00:00Z",
+		})))
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusBadRequest, res.Body)
+		}
+
+		errResponse := api.ErrorResponse{}
+		fromJSON(t, res.Body, &errResponse)
+
+		wantFields := []api.FieldError{{
+			Field:  "nominalRateBps",
+			Reason: "nominalRate and nominalRateBps are mutually exclusive, inform only one of them",
+		}}
+		if diff := cmp.Diff(wantFields, errResponse.Error.Fields); diff != "" {
+			t.Errorf("error fields mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("InvalidBasisPointsOnGet", func(t *testing.T) {
+		query := "loanAmount=5000&nominalRateBps=5.0&duration=24&startDate=2018-01-01T00:00:00Z"
+		res := createPlan(t, httptest.NewRequest(http.MethodGet, api.CreateLoanPlanPath+"?"+query, nil))
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusBadRequest, res.Body)
+		}
+
+		errResponse := api.ErrorResponse{}
+		fromJSON(t, res.Body, &errResponse)
+		if len(errResponse.Error.Fields) != 1 || errResponse.Error.Fields[0].Field != "nominalRateBps" {
+			t.Errorf("got error fields %v; want only nominalRateBps", errResponse.Error.Fields)
+		}
+	})
+}
+
+func TestNominalRateAsFraction(t *testing.T) {
+	service := api.New(loan.CreatePlanForCurrencyContext)
+
+	createPlan := func(t *testing.T, req *http.Request) *httptest.ResponseRecorder {
+		t.Helper()
+		res := httptest.NewRecorder()
+		service.ServeHTTP(res, req)
+		return res
+	}
+
+	wantRes := createPlan(t, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, toJSON(t, api.CreateLoanPlanRequest{
+		LoanAmount:  amountPtr(t, "5000"),
+		NominalRate: amountPtr(t, "5.0"),
+		Duration:    months(24),
+		StartDate:   "2018-01-01T00:00:00Z",
+	})))
+	if wantRes.Code != http.StatusOK {
+		t.Fatalf("got response %d want %d; body: %s", wantRes.Code, http.StatusOK, wantRes.Body)
+	}
+	want := api.CreateLoanPlanResponse{}
+	fromJSON(t, wantRes.Body, &want)
+
+	t.Run("Post", func(t *testing.T) {
+		res := createPlan(t, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, toJSON(t, api.CreateLoanPlanRequest{
+			LoanAmount:     amountPtr(t, "5000"),
+			NominalRate:    amountPtr(t, "0.05"),
+			RateIsFraction: true,
+			Duration:       months(24),
+			StartDate:      "2018-01-01T00:00:00Z",
+		})))
+		if res.Code != http.StatusOK {
+			t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+		}
+
+		got := api.CreateLoanPlanResponse{}
+		fromJSON(t, res.Body, &got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("loan plan mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		query := "loanAmount=5000&nominalRate=0.05&rateIsFraction=true&duration=24&startDate=2018-01-01T00:00:00Z"
+		res := createPlan(t, httptest.NewRequest(http.MethodGet, api.CreateLoanPlanPath+"?"+query, nil))
+		if res.Code != http.StatusOK {
+			t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+		}
+
+		got := api.CreateLoanPlanResponse{}
+		fromJSON(t, res.Body, &got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("loan plan mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("FractionWithoutFlagIsPercent", func(t *testing.T) {
+		res := createPlan(t, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, toJSON(t, api.CreateLoanPlanRequest{
+			LoanAmount:  amountPtr(t, "5000"),
+			NominalRate: amountPtr(t, "0.05"),
+			Duration:    months(24),
+			StartDate:   "2018-01-01T00:00:00Z",
+		})))
+		if res.Code != http.StatusOK {
+			t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+		}
+
+		got := api.CreateLoanPlanResponse{}
+		fromJSON(t, res.Body, &got)
+		if got.MonthlyPayment == want.MonthlyPayment {
+			t.Errorf("got monthly payment %q for 0.05%%; want it different from the one for 5%%", got.MonthlyPayment)
+		}
+	})
+
+	type Test struct {
+		name       string
+		method     string
+		body       []byte
+		query      string
+		wantFields []string
+	}
+
+	bps := 500
+	tests := []Test{
+		{
+			name:   "WithBasisPoints",
+			method: http.MethodPost,
+			body: toJSON(t, api.CreateLoanPlanRequest{
+				LoanAmount:     amountPtr(t, "5000"),
+				NominalRateBps: &bps,
+				RateIsFraction: true,
+				Duration:       months(24),
+				StartDate:      "2018-01-01T00:00:00Z",
+			}),
+			wantFields: []string{"rateIsFraction"},
+		},
+		{
+			name:       "InvalidFlagOnGet",
+			method:     http.MethodGet,
+			query:      "loanAmount=5000&nominalRate=0.05&rateIsFraction=yes&duration=24&startDate=2018-01-01T00:00:00Z",
+			wantFields: []string{"rateIsFraction"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var req *http.Request
+			if test.method == http.MethodPost {
+				req = newRequest(t, http.MethodPost, api.CreateLoanPlanPath, test.body)
+			} else {
+				req = httptest.NewRequest(http.MethodGet, api.CreateLoanPlanPath+"?"+test.query, nil)
+			}
+			res := createPlan(t, req)
+			if res.Code != http.StatusBadRequest {
+				t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusBadRequest, res.Body)
+			}
+
+			errResponse := api.ErrorResponse{}
+			fromJSON(t, res.Body, &errResponse)
+
+			gotFields := []string{}
+			for _, field := range errResponse.Error.Fields {
+				gotFields = append(gotFields, field.Field)
+			}
+			if diff := cmp.Diff(test.wantFields, gotFields); diff != "" {
+				t.Errorf("error fields mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoanAmountFromPriceAndDownPayment(t *testing.T) {
+	service := api.New(loan.CreatePlanForCurrencyContext)
+
+	createPlan := func(t *testing.T, req *http.Request) *httptest.ResponseRecorder {
+		t.Helper()
+		res := httptest.NewRecorder()
+		service.ServeHTTP(res, req)
+		return res
+	}
+
+	wantRes := createPlan(t, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, toJSON(t, api.CreateLoanPlanRequest{
+		LoanAmount:  amountPtr(t, "2000"),
+		NominalRate: amountPtr(t, "5.0"),
+		Duration:    months(24),
+		StartDate:   "2018-01-01T00:00:00Z",
+	})))
+	if wantRes.Code != http.StatusOK {
+		t.Fatalf("got response %d want %d; body: %s", wantRes.Code, http.StatusOK, wantRes.Body)
+	}
+	want := api.CreateLoanPlanResponse{}
+	fromJSON(t, wantRes.Body, &want)
+
+	t.Run("Post", func(t *testing.T) {
+		res := createPlan(t, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, toJSON(t, api.CreateLoanPlanRequest{
+			Price:       amountPtr(t, "2100"),
+			DownPayment: amountPtr(t, "100"),
+			NominalRate: amountPtr(t, "5.0"),
+			Duration:    months(24),
+			StartDate:   "2018-01-01T00:00:00Z",
+		})))
+		if res.Code != http.StatusOK {
+			t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+		}
+
+		got := api.CreateLoanPlanResponse{}
+		fromJSON(t, res.Body, &got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("loan plan mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		query := "price=2100&downPayment=100&nominalRate=5.0&duration=24&startDate=2018-01-01T00:00:00Z"
+		res := createPlan(t, httptest.NewRequest(http.MethodGet, api.CreateLoanPlanPath+"?"+query, nil))
+		if res.Code != http.StatusOK {
+			t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+		}
+
+		got := api.CreateLoanPlanResponse{}
+		fromJSON(t, res.Body, &got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("loan plan mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("PriceWithoutDownPayment", func(t *testing.T) {
+		res := createPlan(t, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, toJSON(t, api.CreateLoanPlanRequest{
+			Price:       amountPtr(t, "2000"),
+			NominalRate: amountPtr(t, "5.0"),
+			Duration:    months(24),
+			StartDate:   "2018-01-01T00:00:00Z",
+		})))
+		if res.Code != http.StatusOK {
+			t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+		}
+
+		got := api.CreateLoanPlanResponse{}
+		fromJSON(t, res.Body, &got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("loan plan mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("InvalidPrice", func(t *testing.T) {
+		body := []byte(`{"price":"expensive","nominalRate":"5.0","duration":24,"startDate":"2018-01-01T00:00:00Z"}`)
+		res := createPlan(t, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, body))
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusBadRequest, res.Body)
+		}
+
+		errResponse := api.ErrorResponse{}
+		fromJSON(t, res.Body, &errResponse)
+
+		gotFields := []string{}
+		for _, field := range errResponse.Error.Fields {
+			gotFields = append(gotFields, field.Field)
+		}
+		if diff := cmp.Diff([]string{"price"}, gotFields); diff != "" {
+			t.Errorf("error fields mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	type Test struct {
+		name       string
+		request    api.CreateLoanPlanRequest
+		wantFields []string
+	}
+
+	tests := []Test{
+		{
+			name:       "DownPaymentEqualToPrice",
+			request:    api.CreateLoanPlanRequest{Price: amountPtr(t, "2100"), DownPayment: amountPtr(t, "2100")},
+			wantFields: []string{"downPayment"},
+		},
+		{
+			name:       "DownPaymentBiggerThanPrice",
+			request:    api.CreateLoanPlanRequest{Price: amountPtr(t, "2100"), DownPayment: amountPtr(t, "3000")},
+			wantFields: []string{"downPayment"},
+		},
+		{
+			name:       "NegativeDownPayment",
+			request:    api.CreateLoanPlanRequest{Price: amountPtr(t, "2100"), DownPayment: amountPtr(t, "-100")},
+			wantFields: []string{"downPayment"},
+		},
+		{
+			name:       "DownPaymentWithoutPrice",
+			request:    api.CreateLoanPlanRequest{LoanAmount: amountPtr(t, "2000"), DownPayment: amountPtr(t, "100")},
+			wantFields: []string{"downPayment"},
+		},
+		{
+			name:       "LoanAmountAndPrice",
+			request:    api.CreateLoanPlanRequest{LoanAmount: amountPtr(t, "2000"), Price: amountPtr(t, "2100"), DownPayment: amountPtr(t, "100")},
+			wantFields: []string{"price"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := test.request
+			request.NominalRate = amountPtr(t, "5.0")
+			request.Duration = months(24)
+			request.StartDate = "2018-01-01T00:00:00Z"
+
+			res := createPlan(t, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, toJSON(t, request)))
+			if res.Code != http.StatusBadRequest {
+				t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusBadRequest, res.Body)
+			}
+
+			errResponse := api.ErrorResponse{}
+			fromJSON(t, res.Body, &errResponse)
+
+			gotFields := []string{}
+			for _, field := range errResponse.Error.Fields {
+				gotFields = append(gotFields, field.Field)
+			}
+			if diff := cmp.Diff(test.wantFields, gotFields); diff != "" {
+				t.Errorf("error fields mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoanPlanCreationIsCanceledWithRequest(t *testing.T) {
+	started := make(chan struct{})
+	canceled := make(chan error, 1)
+
+	service := api.New(func(
+		ctx context.Context,
+		totalLoanAmount decimal.Decimal,
+		annualInterestRate decimal.Decimal,
+		durationInMonths int,
+		start time.Time,
+		currency loan.Currency,
+	) ([]loan.Payment, error) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			canceled <- ctx.Err()
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Second):
+			canceled <- nil
+			return nil, errors.New("context not canceled")
+		}
+	})
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	request := newRequest(t, http.MethodPost, server.URL+api.CreateLoanPlanPath, validCreateLoanRequestBody(t))
+	request = request.WithContext(ctx)
+
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	if _, err := server.Client().Do(request); err == nil {
+		t.Fatal("expected error on canceled request, got none")
+	}
+
+	if err := <-canceled; !errors.Is(err, context.Canceled) {
+		t.Errorf("got context error %v; want %v", err, context.Canceled)
+	}
+}
+
+func TestLoanPlanCreationWithNumericFields(t *testing.T) {
+	type Test struct {
+		name        string
+		stringBody  string
+		numericBody string
+	}
+
+	tests := []Test{
+		{
+			name:        "Integers",
+			stringBody:  `{"loanAmount":"5000","nominalRate":"5","duration":24,"startDate":"2018-01-01T00:00:00Z"}`,
+			numericBody: `{"loanAmount":5000,"nominalRate":5,"duration":24,"startDate":"2018-01-01T00:00:00Z"}`,
+		},
+		{
+			name:        "Decimals",
+			stringBody:  `{"loanAmount":"5000.0","nominalRate":"5.0","duration":24,"startDate":"2018-01-01T00:00:00Z"}`,
+			numericBody: `{"loanAmount":5000.0,"nominalRate":5.0,"duration":24,"startDate":"2018-01-01T00:00:00Z"}`,
+		},
+		{
+			name:        "MoreDigitsThanFloat64Precision",
+			stringBody:  `{"loanAmount":"1234567.891234567891","nominalRate":"3.141592653589793238","duration":12,"startDate":"2018-01-01T00:00:00Z"}`,
+			numericBody: `{"loanAmount":1234567.891234567891,"nominalRate":3.141592653589793238,"duration":12,"startDate":"2018-01-01T00:00:00Z"}`,
+		},
+		{
+			name:        "Exponent",
+			stringBody:  `{"loanAmount":"5000","nominalRate":"5","duration":24,"startDate":"2018-01-01T00:00:00Z"}`,
+			numericBody: `{"loanAmount":5e3,"nominalRate":0.5E1,"duration":24,"startDate":"2018-01-01T00:00:00Z"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(api.New(loan.CreatePlanForCurrencyContext))
+			defer server.Close()
+
+			createPlan := func(body string) api.CreateLoanPlanResponse {
+				request := newRequest(t, http.MethodPost, server.URL+api.CreateLoanPlanPath, []byte(body))
+				res, err := server.Client().Do(request)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer res.Body.Close()
+
+				if res.StatusCode != http.StatusOK {
+					t.Fatalf("got response %d want %d", res.StatusCode, http.StatusOK)
+				}
+				resp := api.CreateLoanPlanResponse{}
+				fromJSON(t, res.Body, &resp)
+				return resp
+			}
+
+			want := createPlan(test.stringBody)
+			got := createPlan(test.numericBody)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("numeric request plan mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateLoanPlanRequestUnmarshal(t *testing.T) {
+	type Test struct {
+		name    string
+		body    string
+		want    api.CreateLoanPlanRequest
+		wantErr bool
+	}
+
+	tests := []Test{
+		{
+			name: "Strings",
+			body: `{"loanAmount":"5000.10","nominalRate":"5.0","duration":24,"startDate":"2018-01-01T00:00:00Z","currency":"EUR"}`,
+			want: api.CreateLoanPlanRequest{
+				LoanAmount:  amountPtr(t, "5000.10"),
+				NominalRate: amountPtr(t, "5.0"),
+				Duration:    months(24),
+				StartDate:   "2018-01-01T00:00:00Z",
+				Currency:    "EUR",
+			},
+		},
+		{
+			name: "NumbersKeepTheirText",
+			body: `{"loanAmount":0.1000000000000000055511151231257827,"nominalRate":5.10,"duration":24}`,
+			want: api.CreateLoanPlanRequest{
+				LoanAmount:  amountPtr(t, "0.1000000000000000055511151231257827"),
+				NominalRate: amountPtr(t, "5.10"),
+				Duration:    months(24),
+			},
+		},
+		{
+			name: "Null",
+			body: `{"loanAmount":null,"nominalRate":null}`,
+			want: api.CreateLoanPlanRequest{},
+		},
+		{
+			name:    "Boolean",
+			body:    `{"loanAmount":true}`,
+			wantErr: true,
+		},
+		{
+			name:    "Object",
+			body:    `{"nominalRate":{}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := api.CreateLoanPlanRequest{}
+			err := json.Unmarshal([]byte(test.body), &got)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got request %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The texts of the fields are only used by the service
+			// to normalize them, the amounts are what matter.
+			ignoreTexts := cmp.FilterPath(func(p cmp.Path) bool {
+				return p.Last().String() == ".texts"
+			}, cmp.Ignore())
+			if diff := cmp.Diff(test.want, got, ignoreTexts); diff != "" {
+				t.Errorf("unmarshal mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDefaultStartDate(t *testing.T) {
+	type Test struct {
+		name          string
+		now           string
+		startDate     string
+		wantFirstDate string
+	}
+
+	tests := []Test{
+		{
+			name:          "Today",
+			now:           "2020-03-15T10:30:00Z",
+			wantFirstDate: "2020-03-15T00:00:00Z",
+		},
+		{
+			name:          "TodayOnDay28",
+			now:           "2020-02-28T23:59:59Z",
+			wantFirstDate: "2020-02-28T00:00:00Z",
+		},
+		{
+			name:          "NextMonthWhenDayIsBiggerThan28",
+			now:           "2020-01-30T08:00:00Z",
+			wantFirstDate: "2020-02-01T00:00:00Z",
+		},
+		{
+			name:          "NextYearWhenDayIsBiggerThan28OnDecember",
+			now:           "2020-12-31T08:00:00Z",
+			wantFirstDate: "2021-01-01T00:00:00Z",
+		},
+		{
+			name:          "TodayIsTakenOnUTC",
+			now:           "2020-03-15T22:00:00-05:00",
+			wantFirstDate: "2020-03-16T00:00:00Z",
+		},
+		{
+			name:          "ExplicitStartDateWins",
+			now:           "2020-03-15T10:30:00Z",
+			startDate:     "2018-01-01T00:00:00Z",
+			wantFirstDate: "2018-01-01T00:00:00Z",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			now := parseTime(t, test.now)
+			service := api.New(loan.CreatePlanForCurrencyContext, api.WithClock(func() time.Time {
+				return now
+			}))
+
+			body := toJSON(t, api.CreateLoanPlanRequest{
+				LoanAmount:  amountPtr(t, "5000"),
+				NominalRate: amountPtr(t, "5.0"),
+				Duration:    months(24),
+				StartDate:   test.startDate,
+			})
+			req := newRequest(t, http.MethodPost, api.CreateLoanPlanPath, body)
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			if res.Code != http.StatusOK {
+				t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+			}
+
+			got := api.CreateLoanPlanResponse{}
+			fromJSON(t, res.Body, &got)
+
+			if got.BorrowerPayments[0].Date != test.wantFirstDate {
+				t.Errorf("got first payment date %q; want %q", got.BorrowerPayments[0].Date, test.wantFirstDate)
+			}
+		})
+	}
+}
+
+func TestDecimalFieldsNormalization(t *testing.T) {
+	type Test struct {
+		name            string
+		opts            []api.Option
+		loanAmount      string
+		nominalRate     string
+		wantLoanAmount  string
+		wantNominalRate string
+		wantFailures    []string
+	}
+
+	tests := []Test{
+		{
+			name:            "ThousandsSeparator",
+			loanAmount:      "5,000.00",
+			nominalRate:     "5.0",
+			wantLoanAmount:  "5000",
+			wantNominalRate: "5",
+		},
+		{
+			name:            "Whitespace",
+			loanAmount:      "5000",
+			nominalRate:     " 5.0 ",
+			wantLoanAmount:  "5000",
+			wantNominalRate: "5",
+		},
+		{
+			name:            "WhitespaceAndThousandsSeparator",
+			loanAmount:      "\t1,005,000.50\n",
+			nominalRate:     "5.0",
+			wantLoanAmount:  "1005000.5",
+			wantNominalRate: "5",
+		},
+		{
+			name:            "CustomThousandsSeparator",
+			opts:            []api.Option{api.WithThousandsSeparator("_")},
+			loanAmount:      "5_000.00",
+			nominalRate:     "5.0",
+			wantLoanAmount:  "5000",
+			wantNominalRate: "5",
+		},
+		{
+			name:         "DefaultThousandsSeparatorWithCustomSeparator",
+			opts:         []api.Option{api.WithThousandsSeparator("_")},
+			loanAmount:   "5,000.00",
+			nominalRate:  "5.0",
+			wantFailures: []string{"loanAmount"},
+		},
+		{
+			name:         "ThousandsSeparatorDisabled",
+			opts:         []api.Option{api.WithThousandsSeparator("")},
+			loanAmount:   "5,000.00",
+			nominalRate:  "5.0",
+			wantFailures: []string{"loanAmount"},
+		},
+		{
+			name:         "WhitespaceInsideTheNumber",
+			loanAmount:   "5 000.00",
+			nominalRate:  "5.0",
+			wantFailures: []string{"loanAmount"},
+		},
+		{
+			name:         "NotANumber",
+			loanAmount:   "5000",
+			nominalRate:  " five ",
+			wantFailures: []string{"nominalRate"},
+		},
+		{
+			name:         "OnlyThousandsSeparator",
+			loanAmount:   ",",
+			nominalRate:  "5.0",
+			wantFailures: []string{"loanAmount"},
+		},
+		{
+			name:         "ThousandsSeparatorOnEveryDigit",
+			loanAmount:   "5,0,0,0",
+			nominalRate:  "5.0",
+			wantFailures: []string{"loanAmount"},
+		},
+		{
+			name:         "LeadingThousandsSeparator",
+			loanAmount:   ",5000",
+			nominalRate:  "5.0",
+			wantFailures: []string{"loanAmount"},
+		},
+		{
+			name:         "ThousandsSeparatorAsDecimalPoint",
+			loanAmount:   "1000,50",
+			nominalRate:  "5.0",
+			wantFailures: []string{"loanAmount"},
+		},
+		{
+			name:         "TrailingThousandsSeparator",
+			loanAmount:   "5,000,",
+			nominalRate:  "5.0",
+			wantFailures: []string{"loanAmount"},
+		},
+		{
+			name:         "FirstGroupTooBig",
+			loanAmount:   "5000,000",
+			nominalRate:  "5.0",
+			wantFailures: []string{"loanAmount"},
+		},
+		{
+			name:         "ThousandsSeparatorAfterDecimalPoint",
+			loanAmount:   "5,000.000,5",
+			nominalRate:  "5.0",
+			wantFailures: []string{"loanAmount"},
+		},
+		{
+			name:         "ThousandsSeparatorOnNominalRate",
+			loanAmount:   "5000",
+			nominalRate:  "0,5",
+			wantFailures: []string{"nominalRate"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var gotLoanAmount, gotNominalRate decimal.Decimal
+
+			service := api.New(func(
+				ctx context.Context,
+				totalLoanAmount decimal.Decimal,
+				annualInterestRate decimal.Decimal,
+				durationInMonths int,
+				start time.Time,
+				currency loan.Currency,
+			) ([]loan.Payment, error) {
+				gotLoanAmount = totalLoanAmount
+				gotNominalRate = annualInterestRate
+				return loan.CreatePlanForCurrencyContext(ctx, totalLoanAmount, annualInterestRate, durationInMonths, start, currency)
+			}, test.opts...)
+
+			body := toJSON(t, map[string]interface{}{
+				"loanAmount":  test.loanAmount,
+				"nominalRate": test.nominalRate,
+				"duration":    24,
+				"startDate":   "2018-01-01T00:00:00Z",
+			})
+			req := newRequest(t, http.MethodPost, api.CreateLoanPlanPath, body)
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			if len(test.wantFailures) > 0 {
+				if res.Code != http.StatusBadRequest {
+					t.Fatalf("got response %d want %d", res.Code, http.StatusBadRequest)
+				}
+
+				errResponse := api.ErrorResponse{}
+				fromJSON(t, res.Body, &errResponse)
+
+				if errResponse.Error.Code != api.ErrorCodeInvalidParameter {
+					t.Errorf("got error code %q; want %q", errResponse.Error.Code, api.ErrorCodeInvalidParameter)
+				}
+				gotFailures := make([]string, len(errResponse.Error.Fields))
+				for i, field := range errResponse.Error.Fields {
+					gotFailures[i] = field.Field
+				}
+				if diff := cmp.Diff(test.wantFailures, gotFailures); diff != "" {
+					t.Errorf("failed fields mismatch (-want +got):\n%s", diff)
+				}
+				return
+			}
+
+			if res.Code != http.StatusOK {
+				t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+			}
+			if gotLoanAmount.String() != test.wantLoanAmount {
+				t.Errorf("got loan amount %q; want %q", gotLoanAmount, test.wantLoanAmount)
+			}
+			if gotNominalRate.String() != test.wantNominalRate {
+				t.Errorf("got nominal rate %q; want %q", gotNominalRate, test.wantNominalRate)
+			}
+		})
+	}
+}
+
+// endlessBody is a request body that never ends,
+// keeping track of how many bytes were read from it.
+type endlessBody struct {
+	prefix []byte
+	read   int64
+}
+
+func (b *endlessBody) Read(p []byte) (int, error) {
+	n := copy(p, b.prefix)
+	b.prefix = b.prefix[n:]
+	for i := n; i < len(p); i++ {
+		p[i] = '1'
+	}
+	b.read += int64(len(p))
+	return len(p), nil
+}
+
+func fromJSON(t *testing.T, data io.Reader, v interface{}) {
+	t.Helper()
+
+	dec := json.NewDecoder(data)
+	err := dec.Decode(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func toJSON(t *testing.T, v interface{}) []byte {
+	t.Helper()
+
+	j, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return j
+}
+
+func newRequest(t *testing.T, method string, url string, body []byte) *http.Request {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func validCreateLoanRequestBody(t *testing.T) []byte {
+	return toJSON(t, api.CreateLoanPlanRequest{
+		LoanAmount:  amountPtr(t, "1000.00"),
+		NominalRate: amountPtr(t, "5.0"),
+		Duration:    months(1),
+		StartDate:   "2020-12-01T00:00:00Z",
+	})
+}
+
+func validCreateLoanRequestQuery() string {
+	return "loanAmount=1000.00&nominalRate=5.0&duration=1&startDate=2020-12-01T00:00:00Z"
+}
+
+func amount(t *testing.T, v string) money.Amount {
+	t.Helper()
+	return money.New(parseDecimal(t, v))
+}
+
+func amountPtr(t *testing.T, v string) *money.Amount {
+	t.Helper()
+	a := amount(t, v)
+	return &a
+}
+
+// months returns the duration field of a request with the given months.
+func months(n int) *int {
+	return &n
+}
+
+// formattedLoanPlan is a loan plan response with the amounts kept
+// as text, so tests can check how they are formatted (localized
+// amounts can't be parsed back as money.Amount).
+type formattedLoanPlan struct {
+	BorrowerPayments []struct {
+		PaymentAmount                 string `json:"borrowerPaymentAmount"`
+		Interest                      string `json:"interest"`
+		Principal                     string `json:"principal"`
+		InitialOutstandingPrincipal   string `json:"initialOutstandingPrincipal"`
+		RemainingOutstandingPrincipal string `json:"remainingOutstandingPrincipal"`
+		Date                          string `json:"date"`
+	} `json:"borrowerPayments"`
+	Summary struct {
+		TotalPrincipal string `json:"totalPrincipal"`
+		TotalPayment   string `json:"totalPayment"`
+	} `json:"summary"`
+}
+
+func parseDecimal(t *testing.T, v string) decimal.Decimal {
+	t.Helper()
+	d, err := decimal.NewFromString(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func parseTime(t *testing.T, s string) time.Time {
+	t.Helper()
+	v, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestDurationFromEndDate(t *testing.T) {
+	service := api.New(loan.CreatePlanForCurrencyContext)
+
+	createPlan := func(t *testing.T, req *http.Request) *httptest.ResponseRecorder {
+		t.Helper()
+		res := httptest.NewRecorder()
+		service.ServeHTTP(res, req)
+		return res
+	}
+
+	wantRes := createPlan(t, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, toJSON(t, api.CreateLoanPlanRequest{
+		LoanAmount:  amountPtr(t, "5000"),
+		NominalRate: amountPtr(t, "5.0"),
+		Duration:    months(24),
+		StartDate:   "2018-01-01T00:00:00Z",
+	})))
+	if wantRes.Code != http.StatusOK {
+		t.Fatalf("got response %d want %d; body: %s", wantRes.Code, http.StatusOK, wantRes.Body)
+	}
+	want := api.CreateLoanPlanResponse{}
+	fromJSON(t, wantRes.Body, &want)
+
+	t.Run("Post", func(t *testing.T) {
+		res := createPlan(t, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, toJSON(t, api.CreateLoanPlanRequest{
+			LoanAmount:  amountPtr(t, "5000"),
+			NominalRate: amountPtr(t, "5.0"),
+			StartDate:   "2018-01-01T00:00:00Z",
+			EndDate:     "2020-01-01T00:00:00Z",
+		})))
+		if res.Code != http.StatusOK {
+			t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+		}
+
+		got := api.CreateLoanPlanResponse{}
+		fromJSON(t, res.Body, &got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("loan plan mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		query := "loanAmount=5000&nominalRate=5.0&startDate=2018-01-01T00:00:00Z&endDate=2020-01-01T00:00:00Z"
+		res := createPlan(t, httptest.NewRequest(http.MethodGet, api.CreateLoanPlanPath+"?"+query, nil))
+		if res.Code != http.StatusOK {
+			t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+		}
+
+		got := api.CreateLoanPlanResponse{}
+		fromJSON(t, res.Body, &got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("loan plan mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	type Test struct {
+		name       string
+		request    api.CreateLoanPlanRequest
+		wantFields []string
+	}
+
+	tests := []Test{
+		{
+			name:       "EndDateNotWholeMonthsAfterStart",
+			request:    api.CreateLoanPlanRequest{EndDate: "2019-07-16T00:00:00Z"},
+			wantFields: []string{"endDate"},
+		},
+		{
+			name:       "EndDateBeforeStart",
+			request:    api.CreateLoanPlanRequest{EndDate: "2017-01-01T00:00:00Z"},
+			wantFields: []string{"endDate"},
+		},
+		{
+			name:       "EndDateEqualToStart",
+			request:    api.CreateLoanPlanRequest{EndDate: "2018-01-01T00:00:00Z"},
+			wantFields: []string{"endDate"},
+		},
+		{
+			name:       "EndDateAndDuration",
+			request:    api.CreateLoanPlanRequest{EndDate: "2020-01-01T00:00:00Z", Duration: months(24)},
+			wantFields: []string{"endDate"},
+		},
+		{
+			name:       "EndDateOutOfDurationBounds",
+			request:    api.CreateLoanPlanRequest{EndDate: "2050-01-01T00:00:00Z"},
+			wantFields: []string{"endDate"},
+		},
+		{
+			name:       "InvalidEndDate",
+			request:    api.CreateLoanPlanRequest{EndDate: "someday"},
+			wantFields: []string{"endDate"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := test.request
+			request.LoanAmount = amountPtr(t, "5000")
+			request.NominalRate = amountPtr(t, "5.0")
+			request.StartDate = "2018-01-01T00:00:00Z"
+
+			res := createPlan(t, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, toJSON(t, request)))
+			if res.Code != http.StatusBadRequest {
+				t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusBadRequest, res.Body)
+			}
+
+			errResponse := api.ErrorResponse{}
+			fromJSON(t, res.Body, &errResponse)
+
+			gotFields := []string{}
+			for _, field := range errResponse.Error.Fields {
+				gotFields = append(gotFields, field.Field)
+			}
+			if diff := cmp.Diff(test.wantFields, gotFields); diff != "" {
+				t.Errorf("error fields mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMissingFields(t *testing.T) {
+	type Test struct {
+		name      string
+		query     url.Values
+		wantField api.FieldError
+	}
+
+	validQuery := func() url.Values {
+		return url.Values{
+			"loanAmount":  []string{"5000"},
+			"nominalRate": []string{"5.0"},
+			"duration":    []string{"24"},
+			"startDate":   []string{"2018-01-01T00:00:00Z"},
+		}
+	}
+
+	tests := []Test{}
+	for _, field := range []string{"loanAmount", "nominalRate", "duration"} {
+		for name, value := range map[string]string{"Empty": "", "Whitespace": "  \t"} {
+			query := validQuery()
+			query.Set(field, value)
+			tests = append(tests, Test{
+				name:      field + name,
+				query:     query,
+				wantField: api.FieldError{Field: field, Reason: field + " is required"},
+			})
+		}
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(loan.CreatePlanForCurrencyContext)
+
+			req := httptest.NewRequest(http.MethodGet, api.CreateLoanPlanPath+"?"+test.query.Encode(), nil)
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			if res.Code != http.StatusBadRequest {
+				t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusBadRequest, res.Body)
+			}
+
+			errResponse := api.ErrorResponse{}
+			fromJSON(t, res.Body, &errResponse)
+
+			if errResponse.Error.Code != api.ErrorCodeMissingField {
+				t.Errorf("got error code %q; want %q", errResponse.Error.Code, api.ErrorCodeMissingField)
+			}
+			want := []api.FieldError{test.wantField}
+			if diff := cmp.Diff(want, errResponse.Error.Fields); diff != "" {
+				t.Errorf("error fields mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("MissingAndInvalidFields", func(t *testing.T) {
+		service := api.New(loan.CreatePlanForCurrencyContext)
+
+		body := []byte(`{"loanAmount":" ","nominalRate":"wrong","duration":24}`)
+		res := httptest.NewRecorder()
+		service.ServeHTTP(res, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, body))
+
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusBadRequest, res.Body)
+		}
+
+		errResponse := api.ErrorResponse{}
+		fromJSON(t, res.Body, &errResponse)
+
+		if errResponse.Error.Code != api.ErrorCodeInvalidParameter {
+			t.Errorf("got error code %q; want %q", errResponse.Error.Code, api.ErrorCodeInvalidParameter)
+		}
+		gotFields := []string{}
+		for _, field := range errResponse.Error.Fields {
+			gotFields = append(gotFields, field.Field)
+		}
+		if diff := cmp.Diff([]string{"loanAmount", "nominalRate"}, gotFields); diff != "" {
+			t.Errorf("error fields mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("BlankStartDateIsTheDefault", func(t *testing.T) {
+		service := api.New(loan.CreatePlanForCurrencyContext)
+
+		query := validQuery()
+		query.Set("startDate", " ")
+		req := httptest.NewRequest(http.MethodGet, api.CreateLoanPlanPath+"?"+query.Encode(), nil)
+		res := httptest.NewRecorder()
+		service.ServeHTTP(res, req)
+
+		if res.Code != http.StatusOK {
+			t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+		}
+	})
 }
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/katcipis/loaner/loan"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// LoanPlanAPRPath is the resource path used to get the annual
+	// percentage rate (APR) of a loan plan.
+	LoanPlanAPRPath = "/loan-plan/apr"
+)
+
+// LoanPlanAPRResponse is the response of the loan plan APR request.
+// The APR is a percent, like "5.12", meaning 5.12 per cent an year.
+type LoanPlanAPRResponse struct {
+	APR string `json:"apr"`
+}
+
+// aprHandler discloses the APR of a loan plan, accepting the same
+// parameters (and methods) used to create loan plans.
+//
+// The loan plan is created through the injected LoanPlanCreator, so the APR
+// is calculated from the actual cash flow of the plan: the disbursed amount
+// (minus fees) and then the stream of monthly payments.
+func aprHandler(cfg config, createLoanPlan LoanPlanCreator) http.HandlerFunc {
+	pathLogger := cfg.logger.WithFields(log.Fields{"path": LoanPlanAPRPath})
+
+	return func(res http.ResponseWriter, req *http.Request) {
+		logger := pathLogger.WithFields(log.Fields{"requestID": requestID(req)})
+
+		parsedReq, fieldErrs, ok := readLoanPlanRequest(cfg, logger, res, req)
+		if !ok {
+			return
+		}
+
+		payments, _, statusCode, apiErr := planPayments(req.Context(), logger, createLoanPlan, cfg, parsedReq, fieldErrs)
+		if apiErr != nil {
+			writeErrorResponse(logger, res, req, statusCode, *apiErr)
+			return
+		}
+
+		apr, err := loan.APR(payments, loan.Monthly)
+		if err != nil {
+			if errors.Is(err, loan.ErrInvalidParameter) {
+				logger.WithError(err).Warning("bad request error")
+				writeErrorResponse(logger, res, req, http.StatusBadRequest, Error{
+					Code:    ErrorCodeInvalidParameter,
+					Message: err.Error(),
+				})
+				return
+			}
+			logger.WithError(err).Error("internal server error")
+			writeErrorResponse(logger, res, req, http.StatusInternalServerError, Error{
+				Code:    ErrorCodeInternal,
+				Message: "internal server error",
+			})
+			return
+		}
+
+		res.Header().Set("Content-Type", jsonContentType)
+		res.WriteHeader(http.StatusOK)
+		logResponseBodyWrite(logger, res, toJSON(logger, LoanPlanAPRResponse{APR: apr.StringFixed(2)}))
+	}
+}
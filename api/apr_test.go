@@ -0,0 +1,132 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+	"github.com/shopspring/decimal"
+)
+
+func TestLoanPlanAPR(t *testing.T) {
+	withFee := func(
+		ctx context.Context,
+		totalLoanAmount decimal.Decimal,
+		annualInterestRate decimal.Decimal,
+		durationInMonths int,
+		start time.Time,
+		currency loan.Currency,
+	) ([]loan.Payment, error) {
+		return loan.BuildPlan(
+			totalLoanAmount,
+			annualInterestRate,
+			durationInMonths,
+			start,
+			loan.WithCurrency(currency),
+			loan.WithFee(loan.Fee{Amount: decimal.NewFromInt(100)}),
+		)
+	}
+
+	type Test struct {
+		name           string
+		creator        api.LoanPlanCreator
+		method         string
+		url            string
+		body           []byte
+		wantStatusCode int
+		wantAPR        string
+		wantErrCode    api.ErrorCode
+	}
+
+	validRequest := api.CreateLoanPlanRequest{
+		LoanAmount:  amountPtr(t, "5000"),
+		NominalRate: amountPtr(t, "5.0"),
+		Duration:    months(24),
+		StartDate:   "2018-01-01T00:00:00Z",
+	}
+	validQuery := "?loanAmount=5000&nominalRate=5.0&duration=24&startDate=2018-01-01T00:00:00Z"
+
+	tests := []Test{
+		{
+			// Without fees the APR is the nominal rate itself.
+			name:           "WithoutFeesMatchesNominalRate",
+			creator:        loan.CreatePlanForCurrencyContext,
+			method:         http.MethodPost,
+			body:           toJSON(t, validRequest),
+			wantStatusCode: http.StatusOK,
+			wantAPR:        "5.00",
+		},
+		{
+			name:           "WithoutFeesFromQuery",
+			creator:        loan.CreatePlanForCurrencyContext,
+			method:         http.MethodGet,
+			url:            validQuery,
+			wantStatusCode: http.StatusOK,
+			wantAPR:        "5.00",
+		},
+		{
+			name:           "FeesIncreaseTheAPR",
+			creator:        withFee,
+			method:         http.MethodPost,
+			body:           toJSON(t, validRequest),
+			wantStatusCode: http.StatusOK,
+			wantAPR:        "6.99",
+		},
+		{
+			name:           "InvalidParameters",
+			creator:        loan.CreatePlanForCurrencyContext,
+			method:         http.MethodGet,
+			url:            "?loanAmount=invalid&nominalRate=5.0&duration=24&startDate=2018-01-01T00:00:00Z",
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+		},
+		{
+			name:           "MalformedJSON",
+			creator:        loan.CreatePlanForCurrencyContext,
+			method:         http.MethodPost,
+			body:           []byte("{"),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeMalformedJSON,
+		},
+		{
+			name:           "MethodNotAllowed",
+			creator:        loan.CreatePlanForCurrencyContext,
+			method:         http.MethodDelete,
+			wantStatusCode: http.StatusMethodNotAllowed,
+			wantErrCode:    api.ErrorCodeMethodNotAllowed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(test.creator)
+
+			req := newRequest(t, test.method, api.LoanPlanAPRPath+test.url, test.body)
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			if res.Code != test.wantStatusCode {
+				t.Fatalf("got response %d want %d; body: %s", res.Code, test.wantStatusCode, res.Body)
+			}
+
+			if test.wantStatusCode != http.StatusOK {
+				errResponse := api.ErrorResponse{}
+				fromJSON(t, res.Body, &errResponse)
+				if errResponse.Error.Code != test.wantErrCode {
+					t.Errorf("got error code %q; want %q", errResponse.Error.Code, test.wantErrCode)
+				}
+				return
+			}
+
+			got := api.LoanPlanAPRResponse{}
+			fromJSON(t, res.Body, &got)
+			if got.APR != test.wantAPR {
+				t.Errorf("got APR %q; want %q", got.APR, test.wantAPR)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/katcipis/loaner/money"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// CreateLoanPlansPath is the resource path used to create
+	// multiple loan plans on a single request.
+	CreateLoanPlansPath = "/loan-plans"
+
+	// MaxBatchSize is the max number of loan plans
+	// that can be created on a single batch request.
+	MaxBatchSize = 1000
+)
+
+// LoanPlanResult is the result of a single item of a batch request.
+// On success it has the same fields of a CreateLoanPlanResponse,
+// on failure only the error is set.
+type LoanPlanResult struct {
+	BorrowerPayments []BorrowerPayment `json:"borrowerPayments,omitempty"`
+	MonthlyPayment   *money.Amount     `json:"monthlyPayment,omitempty"`
+	PayoffDate       string            `json:"payoffDate,omitempty"`
+	Summary          *LoanPlanSummary  `json:"summary,omitempty"`
+	Warnings         []string          `json:"warnings,omitempty"`
+	Error            *Error            `json:"error,omitempty"`
+}
+
+// batchHandler creates multiple loan plans. The request is a JSON
+// array of CreateLoanPlanRequest and the response an array with one
+// LoanPlanResult for each request item, on the same position.
+// Invalid items fail individually instead of failing the whole batch.
+func batchHandler(cfg config, createLoanPlan LoanPlanCreator) http.HandlerFunc {
+	pathLogger := cfg.logger.WithFields(log.Fields{"path": CreateLoanPlansPath})
+
+	return func(res http.ResponseWriter, req *http.Request) {
+		logger := pathLogger.WithFields(log.Fields{"requestID": requestID(req)})
+
+		if req.Method != http.MethodPost {
+			handleMethodNotAllowed(logger, res, req, http.MethodPost)
+			return
+		}
+
+		// Each item is decoded individually so a malformed
+		// item does not fail the whole batch.
+		var items []json.RawMessage
+		if !readJSONBody(cfg, logger, res, req, &items) {
+			return
+		}
+
+		if len(items) > MaxBatchSize {
+			msg := fmt.Sprintf("batch has %d items, max is %d", len(items), MaxBatchSize)
+			writeErrorResponse(logger, res, req, http.StatusBadRequest, Error{
+				Code:    ErrorCodeInvalidParameter,
+				Message: msg,
+			})
+			logger.WithFields(log.Fields{"error": msg}).Warning("batch too big")
+			return
+		}
+
+		results := make([]LoanPlanResult, len(items))
+		for i, item := range items {
+			itemLogger := logger.WithFields(log.Fields{"batchIndex": i})
+			results[i] = createBatchItem(itemLogger, req, createLoanPlan, cfg, item)
+		}
+
+		res.Header().Set("Content-Type", jsonContentType)
+		res.WriteHeader(http.StatusOK)
+		logResponseBodyWrite(logger, res, toJSON(logger, results))
+	}
+}
+
+func createBatchItem(
+	logger *log.Entry,
+	req *http.Request,
+	createLoanPlan LoanPlanCreator,
+	cfg config,
+	item json.RawMessage,
+) LoanPlanResult {
+	parsedReq := CreateLoanPlanRequest{}
+	if err := json.Unmarshal(item, &parsedReq); err != nil {
+		msg := fmt.Sprintf("cant parse batch item as JSON:%v", err)
+		logger.WithFields(log.Fields{"error": msg}).Warning("invalid batch item")
+		return LoanPlanResult{
+			Error: &Error{
+				Code:    ErrorCodeMalformedJSON,
+				Message: msg,
+				TraceID: requestID(req),
+			},
+		}
+	}
+
+	resp, _, apiErr := planLoan(req.Context(), logger, createLoanPlan, cfg, parsedReq, nil)
+	if apiErr != nil {
+		apiErr.TraceID = requestID(req)
+		return LoanPlanResult{Error: apiErr}
+	}
+	return LoanPlanResult{
+		BorrowerPayments: resp.BorrowerPayments,
+		MonthlyPayment:   &resp.MonthlyPayment,
+		PayoffDate:       resp.PayoffDate,
+		Summary:          &resp.Summary,
+		Warnings:         resp.Warnings,
+	}
+}
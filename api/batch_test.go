@@ -0,0 +1,226 @@
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+	"github.com/shopspring/decimal"
+)
+
+func TestLoanPlanBatchCreation(t *testing.T) {
+	service := api.New(func(
+		ctx context.Context,
+		totalLoanAmount decimal.Decimal,
+		annualInterestRate decimal.Decimal,
+		durationInMonths int,
+		start time.Time,
+		currency loan.Currency,
+	) ([]loan.Payment, error) {
+		if totalLoanAmount.IsNegative() {
+			return nil, fmt.Errorf("%w: negative amount", loan.ErrInvalidParameter)
+		}
+		if totalLoanAmount.Equal(decimal.NewFromInt(666)) {
+			return nil, fmt.Errorf("unexpected failure")
+		}
+		return []loan.Payment{
+			{
+				Date:                          start,
+				PaymentAmount:                 totalLoanAmount,
+				Principal:                     totalLoanAmount,
+				InitialOutstandingPrincipal:   totalLoanAmount,
+				Interest:                      decimal.Zero,
+				RemainingOutstandingPrincipal: decimal.Zero,
+			},
+		}, nil
+	})
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	body := []byte(`[
+		{"loanAmount":"1000","nominalRate":"5","duration":1,"startDate":"2020-01-01T00:00:00Z"},
+		"not a loan plan request",
+		{"loanAmount":"wrong","nominalRate":"5","duration":1,"startDate":"invalid"},
+		{"loanAmount":"-1","nominalRate":"5","duration":1,"startDate":"2020-01-01T00:00:00Z"},
+		{"loanAmount":"666","nominalRate":"5","duration":1,"startDate":"2020-01-01T00:00:00Z"},
+		{"loanAmount":"2000","nominalRate":"5","duration":1,"startDate":"2020-02-01T00:00:00Z"}
+	]`)
+
+	request := newRequest(t, http.MethodPost, server.URL+api.CreateLoanPlansPath, body)
+	res, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got response %d want %d", res.StatusCode, http.StatusOK)
+	}
+
+	got := []api.LoanPlanResult{}
+	fromJSON(t, res.Body, &got)
+
+	if len(got) != 6 {
+		t.Fatalf("got %d results; want 6: %+v", len(got), got)
+	}
+
+	wantPayments := func(value, date string) []api.BorrowerPayment {
+		return []api.BorrowerPayment{
+			{
+				Date:                          date,
+				PaymentAmount:                 amount(t, value),
+				Interest:                      amount(t, "0"),
+				Principal:                     amount(t, value),
+				InitialOutstandingPrincipal:   amount(t, value),
+				RemainingOutstandingPrincipal: amount(t, "0"),
+			},
+		}
+	}
+
+	assertPlan := func(index int, want []api.BorrowerPayment) {
+		t.Helper()
+
+		result := got[index]
+		if result.Error != nil {
+			t.Errorf("result[%d]: unexpected error: %+v", index, result.Error)
+			return
+		}
+		if diff := cmp.Diff(result.BorrowerPayments, want); diff != "" {
+			t.Errorf("result[%d]: got(-) want(+):\n%s", index, diff)
+		}
+		if result.Summary == nil || !result.Summary.TotalPrincipal.Equal(want[0].Principal) {
+			t.Errorf("result[%d]: got summary %+v; want total principal %s", index, result.Summary, want[0].Principal)
+		}
+	}
+
+	assertErr := func(index int, wantCode api.ErrorCode, wantFields []string) {
+		t.Helper()
+
+		result := got[index]
+		if result.Error == nil {
+			t.Errorf("result[%d]: want error, got %+v", index, result)
+			return
+		}
+		if len(result.BorrowerPayments) != 0 {
+			t.Errorf("result[%d]: want no payments on error, got %+v", index, result.BorrowerPayments)
+		}
+		if result.Error.Code != wantCode {
+			t.Errorf("result[%d]: got error code %q; want %q", index, result.Error.Code, wantCode)
+		}
+		if result.Error.Message == "" {
+			t.Errorf("result[%d]: want error message, got none", index)
+		}
+		if result.Error.TraceID != res.Header.Get(api.RequestIDHeader) {
+			t.Errorf("result[%d]: got trace ID %q; want %q",
+				index, result.Error.TraceID, res.Header.Get(api.RequestIDHeader))
+		}
+
+		gotFields := []string{}
+		for _, field := range result.Error.Fields {
+			gotFields = append(gotFields, field.Field)
+		}
+		if wantFields == nil {
+			wantFields = []string{}
+		}
+		if diff := cmp.Diff(gotFields, wantFields); diff != "" {
+			t.Errorf("result[%d]: fields got(-) want(+):\n%s", index, diff)
+		}
+	}
+
+	assertPlan(0, wantPayments("1000", "2020-01-01T00:00:00Z"))
+	assertErr(1, api.ErrorCodeMalformedJSON, nil)
+	assertErr(2, api.ErrorCodeInvalidParameter, []string{"loanAmount", "startDate"})
+	assertErr(3, api.ErrorCodeInvalidParameter, nil)
+	assertErr(4, api.ErrorCodeInternal, nil)
+	assertPlan(5, wantPayments("2000", "2020-02-01T00:00:00Z"))
+}
+
+func TestLoanPlanBatchCreationFailures(t *testing.T) {
+	type Test struct {
+		name           string
+		method         string
+		requestBody    []byte
+		wantStatusCode int
+		wantErrCode    api.ErrorCode
+	}
+
+	tooBig := []byte("[")
+	for i := 0; i <= api.MaxBatchSize; i++ {
+		if i > 0 {
+			tooBig = append(tooBig, ',')
+		}
+		tooBig = append(tooBig, "{}"...)
+	}
+	tooBig = append(tooBig, ']')
+
+	tests := []Test{
+		{
+			name:           "MethodNotAllowedForGet",
+			method:         http.MethodGet,
+			wantStatusCode: http.StatusMethodNotAllowed,
+			wantErrCode:    api.ErrorCodeMethodNotAllowed,
+		},
+		{
+			name:           "BodyIsNotJSON",
+			method:         http.MethodPost,
+			requestBody:    []byte("{"),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeMalformedJSON,
+		},
+		{
+			name:           "BodyIsNotArray",
+			method:         http.MethodPost,
+			requestBody:    validCreateLoanRequestBody(t),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeMalformedJSON,
+		},
+		{
+			name:           "BatchTooBig",
+			method:         http.MethodPost,
+			requestBody:    tooBig,
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(func(
+				ctx context.Context,
+				totalLoanAmount decimal.Decimal,
+				annualInterestRate decimal.Decimal,
+				durationInMonths int,
+				start time.Time,
+				currency loan.Currency,
+			) ([]loan.Payment, error) {
+				t.Error("loan plans must not be created on failed batches")
+				return nil, nil
+			})
+			server := httptest.NewServer(service)
+			defer server.Close()
+
+			request := newRequest(t, test.method, server.URL+api.CreateLoanPlansPath, test.requestBody)
+			res, err := server.Client().Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != test.wantStatusCode {
+				t.Fatalf("got response %d want %d", res.StatusCode, test.wantStatusCode)
+			}
+
+			errResponse := api.ErrorResponse{}
+			fromJSON(t, res.Body, &errResponse)
+			if errResponse.Error.Code != test.wantErrCode {
+				t.Errorf("got error code %q; want %q", errResponse.Error.Code, test.wantErrCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// CreateLoanPlansCSVPath is the resource path used to create
+// multiple loan plans from a CSV, one loan plan for each row.
+const CreateLoanPlansCSVPath = "/loan-plans/csv"
+
+// batchCSVHeader is the header of the CSV response of a CSV batch request.
+// The row is the number of the row on the request (the header excluded),
+// the same for all the payments of the loan plan of a row.
+var batchCSVHeader = append(append([]string{"row"}, csvHeader...), "error")
+
+// batchCSVHandler creates multiple loan plans from a CSV. The first row of
+// the request CSV is a header with the names of the columns, which are the
+// same names of the query parameters of a loan plan creation (like loanAmount,
+// nominalRate, duration and startDate). The response is a CSV with all the
+// payments of the loan plan of each row, on the same order. Invalid rows fail
+// individually, with a single row on the response with the error message,
+// instead of failing the whole batch.
+func batchCSVHandler(cfg config, createLoanPlan LoanPlanCreator) http.HandlerFunc {
+	pathLogger := cfg.logger.WithFields(log.Fields{"path": CreateLoanPlansCSVPath})
+
+	return func(res http.ResponseWriter, req *http.Request) {
+		logger := pathLogger.WithFields(log.Fields{"requestID": requestID(req)})
+
+		if req.Method != http.MethodPost {
+			handleMethodNotAllowed(logger, res, req, http.MethodPost)
+			return
+		}
+
+		if !hasCSVBody(req) {
+			msg := fmt.Sprintf("content type %q is not supported, use %q", req.Header.Get("Content-Type"), csvContentType)
+			writeErrorResponse(logger, res, req, http.StatusUnsupportedMediaType, Error{
+				Code:    ErrorCodeUnsupportedMediaType,
+				Message: msg,
+			})
+			logger.WithFields(log.Fields{"error": msg}).Warning("unsupported media type")
+			return
+		}
+
+		r := csv.NewReader(limitBody(res, req, cfg.maxBodySize))
+		// Rows with a different number of columns are
+		// reported as invalid rows instead of failing the
+		// whole batch.
+		r.FieldsPerRecord = -1
+
+		header, err := r.Read()
+		if isBodyTooLarge(err) {
+			handleBodyTooLarge(logger, res, req, cfg.maxBodySize)
+			return
+		}
+		if err != nil {
+			msg := fmt.Sprintf("cant parse request body CSV header:%v", err)
+			writeErrorResponse(logger, res, req, http.StatusBadRequest, Error{
+				Code:    ErrorCodeInvalidParameter,
+				Message: msg,
+			})
+			logger.WithFields(log.Fields{"error": msg}).Warning("invalid request body")
+			return
+		}
+
+		records := [][]string{batchCSVHeader}
+		for row := 1; ; row++ {
+			record, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if isBodyTooLarge(err) {
+				handleBodyTooLarge(logger, res, req, cfg.maxBodySize)
+				return
+			}
+
+			var parseErr *csv.ParseError
+			if err != nil && !errors.As(err, &parseErr) {
+				msg := fmt.Sprintf("cant read request body CSV:%v", err)
+				writeErrorResponse(logger, res, req, http.StatusBadRequest, Error{
+					Code:    ErrorCodeInvalidParameter,
+					Message: msg,
+				})
+				logger.WithFields(log.Fields{"error": msg}).Warning("invalid request body")
+				return
+			}
+
+			if row > MaxBatchSize {
+				msg := fmt.Sprintf("batch has more than %d rows", MaxBatchSize)
+				writeErrorResponse(logger, res, req, http.StatusBadRequest, Error{
+					Code:    ErrorCodeInvalidParameter,
+					Message: msg,
+				})
+				logger.WithFields(log.Fields{"error": msg}).Warning("batch too big")
+				return
+			}
+
+			rowLogger := logger.WithFields(log.Fields{"batchRow": row})
+			records = append(records, createBatchCSVRow(rowLogger, req, createLoanPlan, cfg, row, header, record, err)...)
+		}
+
+		res.Header().Set("Content-Type", csvContentType)
+		res.WriteHeader(http.StatusOK)
+
+		w := csv.NewWriter(res)
+		if err := w.WriteAll(records); err != nil {
+			logger.WithError(err).Warning("unable to write response body")
+		}
+	}
+}
+
+// createBatchCSVRow creates the loan plan of a single row of a CSV batch,
+// returning the rows of the response for it. The error is the error of
+// reading the row, if any, reported as the error of the row.
+func createBatchCSVRow(
+	logger *log.Entry,
+	req *http.Request,
+	createLoanPlan LoanPlanCreator,
+	cfg config,
+	row int,
+	header []string,
+	record []string,
+	err error,
+) [][]string {
+	rowNumber := strconv.Itoa(row)
+	errorRow := func(msg string) [][]string {
+		record := make([]string, len(batchCSVHeader))
+		record[0] = rowNumber
+		record[len(record)-1] = msg
+		return [][]string{record}
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cant parse row as CSV:%v", err)
+		logger.WithFields(log.Fields{"error": msg}).Warning("invalid batch row")
+		return errorRow(msg)
+	}
+	if len(record) != len(header) {
+		msg := fmt.Sprintf("row has %d columns, header has %d", len(record), len(header))
+		logger.WithFields(log.Fields{"error": msg}).Warning("invalid batch row")
+		return errorRow(msg)
+	}
+
+	query := url.Values{}
+	for i, name := range header {
+		query.Set(name, record[i])
+	}
+	parsedReq, fieldErrs := parseCreateLoanPlanQuery(query)
+
+	resp, _, apiErr := planLoan(req.Context(), logger, createLoanPlan, cfg, parsedReq, fieldErrs)
+	if apiErr != nil {
+		return errorRow(apiErr.Message)
+	}
+
+	records := make([][]string, len(resp.BorrowerPayments))
+	for i, p := range resp.BorrowerPayments {
+		records[i] = []string{
+			rowNumber,
+			p.Date,
+			p.PaymentAmount.String(),
+			p.Interest.String(),
+			p.Principal.String(),
+			p.InitialOutstandingPrincipal.String(),
+			p.RemainingOutstandingPrincipal.String(),
+			"",
+		}
+	}
+	return records
+}
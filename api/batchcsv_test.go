@@ -0,0 +1,161 @@
+package api_test
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestLoanPlansFromCSV(t *testing.T) {
+	body := strings.Join([]string{
+		"loanAmount,nominalRate,duration,startDate",
+		"2000,1.0,2,2018-01-01T00:00:00Z",
+		"lots,1.0,2,2018-01-01T00:00:00Z",
+	}, "\n")
+
+	service := api.New(loan.CreatePlanForCurrencyContext)
+	req := httptest.NewRequest(http.MethodPost, api.CreateLoanPlansCSVPath, strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+	res := httptest.NewRecorder()
+	service.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+	}
+	if got := res.Header().Get("Content-Type"); got != "text/csv" {
+		t.Errorf("got content type %q; want text/csv", got)
+	}
+
+	got, err := csv.NewReader(res.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{
+			"row",
+			"date",
+			"borrowerPaymentAmount",
+			"interest",
+			"principal",
+			"initialOutstandingPrincipal",
+			"remainingOutstandingPrincipal",
+			"error",
+		},
+		{"1", "2018-01-01T00:00:00Z", "1001.25", "1.67", "999.58", "2000", "1000.42", ""},
+		{"1", "2018-02-01T00:00:00Z", "1001.25", "0.83", "1000.42", "1000.42", "0", ""},
+		{"2", "", "", "", "", "", "", `can't parse "loanAmount" from request:can't convert lots to decimal`},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CSV response mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoanPlansFromCSVInvalidRows(t *testing.T) {
+	type Test struct {
+		name      string
+		row       string
+		wantError string
+	}
+
+	tests := []Test{
+		{
+			name:      "MissingColumn",
+			row:       "2000,1.0,2",
+			wantError: "row has 3 columns, header has 4",
+		},
+		{
+			name:      "InvalidDuration",
+			row:       "2000,1.0,two,2018-01-01T00:00:00Z",
+			wantError: `can't parse "duration" from request:strconv.Atoi: parsing "two": invalid syntax`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body := "loanAmount,nominalRate,duration,startDate\n" + test.row + "\n"
+
+			service := api.New(loan.CreatePlanForCurrencyContext)
+			req := httptest.NewRequest(http.MethodPost, api.CreateLoanPlansCSVPath, strings.NewReader(body))
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			if res.Code != http.StatusOK {
+				t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+			}
+
+			got, err := csv.NewReader(res.Body).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 2 {
+				t.Fatalf("got %d CSV rows; want header and error rows: %v", len(got), got)
+			}
+
+			gotRow := got[1]
+			if gotRow[0] != "1" {
+				t.Errorf("got row %q; want 1", gotRow[0])
+			}
+			if gotErr := gotRow[len(gotRow)-1]; gotErr != test.wantError {
+				t.Errorf("got error %q; want %q", gotErr, test.wantError)
+			}
+		})
+	}
+}
+
+func TestLoanPlansFromCSVFailures(t *testing.T) {
+	type Test struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		wantStatus  int
+	}
+
+	tests := []Test{
+		{
+			name:       "MethodNotAllowed",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:        "JSONBody",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			body:        `[{"loanAmount":"2000"}]`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:       "EmptyBody",
+			method:     http.MethodPost,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(loan.CreatePlanForCurrencyContext)
+			req := httptest.NewRequest(test.method, api.CreateLoanPlansCSVPath, strings.NewReader(test.body))
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			if res.Code != test.wantStatus {
+				t.Fatalf("got response %d want %d; body: %s", res.Code, test.wantStatus, res.Body)
+			}
+			errResponse := api.ErrorResponse{}
+			fromJSON(t, res.Body, &errResponse)
+			if errResponse.Error.Code == "" {
+				t.Error("missing error code on error response")
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/katcipis/loaner/loan"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// CacheHeader informs if the loan plans of the response were
+	// served from the plan cache ("HIT") or created ("MISS").
+	// It is only sent when the plan cache is enabled.
+	CacheHeader = "X-Cache"
+
+	cacheHit  = "HIT"
+	cacheMiss = "MISS"
+)
+
+// planCache is a LRU cache of loan plans, indexed by the normalized
+// parameters used to create them. It is safe for concurrent use.
+type planCache struct {
+	mutex   sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	// recency has the cached plans, from the most
+	// recently used to the least recently used.
+	recency *list.List
+}
+
+type planCacheEntry struct {
+	key      string
+	payments []loan.Payment
+}
+
+func newPlanCache(size int) *planCache {
+	return &planCache{
+		size:    size,
+		entries: map[string]*list.Element{},
+		recency: list.New(),
+	}
+}
+
+func (c *planCache) get(key string) ([]loan.Payment, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.recency.MoveToFront(elem)
+	return elem.Value.(*planCacheEntry).payments, true
+}
+
+func (c *planCache) put(key string, payments []loan.Payment) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*planCacheEntry).payments = payments
+		c.recency.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.recency.PushFront(&planCacheEntry{key: key, payments: payments})
+	if c.recency.Len() > c.size {
+		oldest := c.recency.Back()
+		c.recency.Remove(oldest)
+		delete(c.entries, oldest.Value.(*planCacheEntry).key)
+	}
+}
+
+// wrap returns a LoanPlanCreator that serves the loan plans from the cache,
+// creating (and caching) them with the given creator on misses. Failures
+// are not cached. Whether the plan was cached is recorded on the request
+// context (see withCacheStatus).
+func (c *planCache) wrap(createLoanPlan LoanPlanCreator) LoanPlanCreator {
+	return func(
+		ctx context.Context,
+		totalLoanAmount decimal.Decimal,
+		annualInterestRate decimal.Decimal,
+		durationInMonths int,
+		start time.Time,
+		currency loan.Currency,
+	) ([]loan.Payment, error) {
+		key := planCacheKey(totalLoanAmount, annualInterestRate, durationInMonths, start, currency)
+
+		if payments, ok := c.get(key); ok {
+			setCacheStatus(ctx, cacheHit)
+			return copyPayments(payments), nil
+		}
+
+		payments, err := createLoanPlan(ctx, totalLoanAmount, annualInterestRate, durationInMonths, start, currency)
+		if err != nil {
+			return nil, err
+		}
+		setCacheStatus(ctx, cacheMiss)
+		c.put(key, copyPayments(payments))
+		return payments, nil
+	}
+}
+
+// planCacheKey normalizes the loan plan parameters, so parameters that
+// create the same loan plan, like the loan amounts "5000" and "5000.00",
+// have the same key. The time of the start date is ignored, since
+// all payment dates are at midnight UTC.
+func planCacheKey(
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	durationInMonths int,
+	start time.Time,
+	currency loan.Currency,
+) string {
+	return fmt.Sprintf(
+		"%s|%s|%d|%s|%s|%d",
+		totalLoanAmount.String(),
+		annualInterestRate.String(),
+		durationInMonths,
+		start.UTC().Format("2006-01-02"),
+		currency.Code,
+		currency.MinorUnits,
+	)
+}
+
+// copyPayments copies the payments, so the cached
+// plans are never changed by their users.
+func copyPayments(payments []loan.Payment) []loan.Payment {
+	return append([]loan.Payment(nil), payments...)
+}
+
+// withCacheStatus sends the CacheHeader on responses of requests
+// that created loan plans through the plan cache. When a request
+// creates multiple loan plans it is a HIT only if all of them were.
+func withCacheStatus(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		status := &cacheStatus{}
+		ctx := context.WithValue(req.Context(), cacheStatusKey, status)
+		next.ServeHTTP(&cacheStatusWriter{ResponseWriter: res, status: status}, req.WithContext(ctx))
+	})
+}
+
+type cacheStatus struct {
+	mutex sync.Mutex
+	value string
+}
+
+func setCacheStatus(ctx context.Context, value string) {
+	status, ok := ctx.Value(cacheStatusKey).(*cacheStatus)
+	if !ok {
+		return
+	}
+	status.mutex.Lock()
+	defer status.mutex.Unlock()
+
+	if status.value != cacheMiss {
+		status.value = value
+	}
+}
+
+func (s *cacheStatus) get() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.value
+}
+
+// cacheStatusWriter sets the CacheHeader right before the response
+// header is written, when the loan plans were already created.
+type cacheStatusWriter struct {
+	http.ResponseWriter
+	status      *cacheStatus
+	wroteHeader bool
+}
+
+func (w *cacheStatusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if value := w.status.get(); value != "" {
+			w.Header().Set(CacheHeader, value)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *cacheStatusWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(data)
+}
@@ -0,0 +1,148 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+	"github.com/shopspring/decimal"
+)
+
+func TestPlanCache(t *testing.T) {
+	var mutex sync.Mutex
+	created := 0
+	service := api.New(func(
+		ctx context.Context,
+		totalLoanAmount decimal.Decimal,
+		annualInterestRate decimal.Decimal,
+		durationInMonths int,
+		start time.Time,
+		currency loan.Currency,
+	) ([]loan.Payment, error) {
+		mutex.Lock()
+		created++
+		mutex.Unlock()
+		return loan.CreatePlanForCurrencyContext(ctx, totalLoanAmount, annualInterestRate, durationInMonths, start, currency)
+	}, api.WithPlanCache(2))
+
+	createLoanPlan := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, api.CreateLoanPlanPath+"?"+query, nil)
+		res := httptest.NewRecorder()
+		service.ServeHTTP(res, req)
+		if res.Code != http.StatusOK {
+			t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+		}
+		return res
+	}
+
+	type Test struct {
+		name      string
+		query     string
+		wantCache string
+	}
+
+	tests := []Test{
+		{
+			name:      "FirstRequestMisses",
+			query:     "loanAmount=5000&nominalRate=5.0&duration=24&startDate=2018-01-01T00:00:00Z",
+			wantCache: "MISS",
+		},
+		{
+			name:      "IdenticalRequestHits",
+			query:     "loanAmount=5000&nominalRate=5.0&duration=24&startDate=2018-01-01T00:00:00Z",
+			wantCache: "HIT",
+		},
+		{
+			name:      "EquivalentParametersHit",
+			query:     "loanAmount=5000.00&nominalRate=5&duration=24&startDate=2018-01-01T10:00:00Z",
+			wantCache: "HIT",
+		},
+		{
+			name:      "DifferentAmountMisses",
+			query:     "loanAmount=6000&nominalRate=5.0&duration=24&startDate=2018-01-01T00:00:00Z",
+			wantCache: "MISS",
+		},
+		{
+			name:      "DifferentCurrencyMisses",
+			query:     "loanAmount=5000&nominalRate=5.0&duration=24&startDate=2018-01-01T00:00:00Z&currency=JPY",
+			wantCache: "MISS",
+		},
+		{
+			// The cache has room for 2 plans, so the
+			// least recently used one was evicted.
+			name:      "LeastRecentlyUsedIsEvicted",
+			query:     "loanAmount=5000&nominalRate=5.0&duration=24&startDate=2018-01-01T00:00:00Z",
+			wantCache: "MISS",
+		},
+		{
+			name:      "MostRecentlyUsedIsKept",
+			query:     "loanAmount=5000&nominalRate=5.0&duration=24&startDate=2018-01-01T00:00:00Z&currency=JPY",
+			wantCache: "HIT",
+		},
+	}
+
+	wantCreated := 0
+	var first *httptest.ResponseRecorder
+	for _, test := range tests {
+		res := createLoanPlan(test.query)
+		if first == nil {
+			first = res
+		}
+		if test.wantCache == "MISS" {
+			wantCreated++
+		}
+
+		if got := res.Header().Get(api.CacheHeader); got != test.wantCache {
+			t.Errorf("%s: got %s %q; want %q", test.name, api.CacheHeader, got, test.wantCache)
+		}
+		if created != wantCreated {
+			t.Errorf("%s: got %d loan plans created; want %d", test.name, created, wantCreated)
+		}
+		if test.name == "IdenticalRequestHits" && res.Body.String() != first.Body.String() {
+			t.Errorf("%s: got body %s; want %s", test.name, res.Body, first.Body)
+		}
+	}
+}
+
+func TestPlanCacheConcurrentAccess(t *testing.T) {
+	service := api.New(loan.CreatePlanForCurrencyContext, api.WithPlanCache(4))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			req := httptest.NewRequest(http.MethodGet, api.CreateLoanPlanPath+"?"+
+				"loanAmount=5000&nominalRate=5.0&startDate=2018-01-01T00:00:00Z&duration="+
+				[]string{"12", "24", "36", "48", "60", "72"}[i%6], nil)
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			if res.Code != http.StatusOK {
+				t.Errorf("got response %d want %d", res.Code, http.StatusOK)
+			}
+			if got := res.Header().Get(api.CacheHeader); got != "HIT" && got != "MISS" {
+				t.Errorf("got %s %q; want HIT or MISS", api.CacheHeader, got)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestPlanCacheDisabledByDefault(t *testing.T) {
+	service := api.New(loan.CreatePlanForCurrencyContext)
+
+	req := httptest.NewRequest(http.MethodGet, api.CreateLoanPlanPath+"?"+validCreateLoanRequestQuery(), nil)
+	res := httptest.NewRecorder()
+	service.ServeHTTP(res, req)
+
+	if got := res.Header().Get(api.CacheHeader); got != "" {
+		t.Errorf("got %s %q; want none", api.CacheHeader, got)
+	}
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/katcipis/loaner/loan"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// CapabilitiesPath is the resource path used to discover
+	// the loan features supported by the service.
+	CapabilitiesPath = "/capabilities"
+)
+
+// CapabilitiesResponse is the response of the capabilities request.
+// It lists the day counts, payment frequencies, rounding modes and
+// currencies supported by the service when creating loan plans.
+type CapabilitiesResponse struct {
+	DayCounts     []string             `json:"dayCounts"`
+	Frequencies   []string             `json:"frequencies"`
+	RoundingModes []string             `json:"roundingModes"`
+	Currencies    []CurrencyCapability `json:"currencies"`
+}
+
+// CurrencyCapability is a currency supported by the service, with
+// the number of decimal places of its minor unit (like 2 for EUR),
+// which all the money values of its loan plans are rounded to.
+type CurrencyCapability struct {
+	Code       string `json:"code"`
+	MinorUnits int    `json:"minorUnits"`
+}
+
+// capabilitiesHandler informs the loan features supported by the service,
+// so clients can discover them instead of guessing. Loan plans created by
+// the service are always monthly, with the 30/360 day count and rounded
+// with the half even (banker's) rounding.
+func capabilitiesHandler(cfg config) http.HandlerFunc {
+	pathLogger := cfg.logger.WithFields(log.Fields{"path": CapabilitiesPath})
+	capabilities := newCapabilitiesResponse()
+
+	return func(res http.ResponseWriter, req *http.Request) {
+		logger := pathLogger.WithFields(log.Fields{"requestID": requestID(req)})
+
+		if req.Method != http.MethodGet {
+			handleMethodNotAllowed(logger, res, req, http.MethodGet)
+			return
+		}
+
+		res.Header().Set("Content-Type", jsonContentType)
+		res.WriteHeader(http.StatusOK)
+		logResponseBodyWrite(logger, res, toJSON(logger, capabilities))
+	}
+}
+
+func newCapabilitiesResponse() CapabilitiesResponse {
+	currencies := []CurrencyCapability{}
+	for _, c := range loan.Currencies() {
+		currencies = append(currencies, CurrencyCapability{
+			Code:       c.Code,
+			MinorUnits: c.MinorUnits,
+		})
+	}
+	return CapabilitiesResponse{
+		DayCounts:     []string{"thirty360"},
+		Frequencies:   []string{loan.Monthly.String()},
+		RoundingModes: []string{"halfEven"},
+		Currencies:    currencies,
+	}
+}
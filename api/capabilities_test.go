@@ -0,0 +1,94 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestCapabilities(t *testing.T) {
+	service := api.New(loan.CreatePlanForCurrencyContext)
+
+	req := httptest.NewRequest(http.MethodGet, api.CapabilitiesPath, nil)
+	res := httptest.NewRecorder()
+	service.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+	}
+	if got := res.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("got content type %q; want %q", got, "application/json")
+	}
+
+	got := api.CapabilitiesResponse{}
+	fromJSON(t, res.Body, &got)
+
+	if !containsString(got.DayCounts, "thirty360") {
+		t.Errorf("got day counts %v; want thirty360 on them", got.DayCounts)
+	}
+	if !containsString(got.Frequencies, "monthly") {
+		t.Errorf("got frequencies %v; want monthly on them", got.Frequencies)
+	}
+	if !containsString(got.RoundingModes, "halfEven") {
+		t.Errorf("got rounding modes %v; want halfEven on them", got.RoundingModes)
+	}
+
+	wantCurrencies := map[string]int{"EUR": 2, "JPY": 0, "BHD": 3}
+	for _, currency := range got.Currencies {
+		if minorUnits, ok := wantCurrencies[currency.Code]; ok {
+			if currency.MinorUnits != minorUnits {
+				t.Errorf("got %d minor units for %s; want %d", currency.MinorUnits, currency.Code, minorUnits)
+			}
+			delete(wantCurrencies, currency.Code)
+		}
+	}
+	if len(wantCurrencies) > 0 {
+		t.Errorf("missing currencies %v on %v", wantCurrencies, got.Currencies)
+	}
+
+	// All the currencies listed can be used to create loan plans.
+	for _, currency := range got.Currencies {
+		body := toJSON(t, api.CreateLoanPlanRequest{
+			LoanAmount:  amountPtr(t, "5000"),
+			NominalRate: amountPtr(t, "5.0"),
+			Duration:    months(12),
+			StartDate:   "2018-01-01T00:00:00Z",
+			Currency:    currency.Code,
+		})
+		res := httptest.NewRecorder()
+		service.ServeHTTP(res, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, body))
+		if res.Code != http.StatusOK {
+			t.Errorf("got response %d want %d for currency %s; body: %s", res.Code, http.StatusOK, currency.Code, res.Body)
+		}
+	}
+}
+
+func TestCapabilitiesMethodNotAllowed(t *testing.T) {
+	service := api.New(loan.CreatePlanForCurrencyContext)
+
+	req := httptest.NewRequest(http.MethodPost, api.CapabilitiesPath, nil)
+	res := httptest.NewRecorder()
+	service.ServeHTTP(res, req)
+
+	if res.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("got response %d want %d", res.Code, http.StatusMethodNotAllowed)
+	}
+
+	errResponse := api.ErrorResponse{}
+	fromJSON(t, res.Body, &errResponse)
+	if errResponse.Error.Code != api.ErrorCodeMethodNotAllowed {
+		t.Errorf("got error code %q; want %q", errResponse.Error.Code, api.ErrorCodeMethodNotAllowed)
+	}
+}
+
+func containsString(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
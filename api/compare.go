@@ -0,0 +1,103 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/katcipis/loaner/money"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// CompareLoanPlansPath is the resource path used
+	// to compare the loan plans of two loan scenarios.
+	CompareLoanPlansPath = "/loan-plan/compare"
+)
+
+// CompareLoanPlansRequest is the request body required to compare
+// the loan plans of two loan scenarios.
+type CompareLoanPlansRequest struct {
+	First  CreateLoanPlanRequest `json:"first"`
+	Second CreateLoanPlanRequest `json:"second"`
+}
+
+// LoanPlansDelta is part of the CompareLoanPlansResponse, it has
+// the differences between the second and the first loan plans, so
+// a negative value means that the second plan has a smaller value.
+type LoanPlansDelta struct {
+	TotalInterest  money.Amount `json:"totalInterest"`
+	MonthlyPayment money.Amount `json:"monthlyPayment"`
+}
+
+// CompareLoanPlansResponse is the response of the compare loan plans request.
+type CompareLoanPlansResponse struct {
+	First  CreateLoanPlanResponse `json:"first"`
+	Second CreateLoanPlanResponse `json:"second"`
+	Delta  LoanPlansDelta         `json:"delta"`
+}
+
+// compareHandler creates the loan plans of two loan scenarios and
+// compares them. If any of the scenarios is invalid the request fails,
+// with the invalid fields prefixed by the scenario, like "second.duration".
+func compareHandler(cfg config, createLoanPlan LoanPlanCreator) http.HandlerFunc {
+	pathLogger := cfg.logger.WithFields(log.Fields{"path": CompareLoanPlansPath})
+
+	return func(res http.ResponseWriter, req *http.Request) {
+		logger := pathLogger.WithFields(log.Fields{"requestID": requestID(req)})
+
+		if req.Method != http.MethodPost {
+			handleMethodNotAllowed(logger, res, req, http.MethodPost)
+			return
+		}
+
+		parsedReq := CompareLoanPlansRequest{}
+		if !readJSONBody(cfg, logger, res, req, &parsedReq) {
+			return
+		}
+
+		first, statusCode, apiErr := planLoan(req.Context(), logger.WithFields(log.Fields{"scenario": "first"}), createLoanPlan, cfg, parsedReq.First, nil)
+		if apiErr != nil {
+			writeErrorResponse(logger, res, req, statusCode, prefixFields(*apiErr, "first"))
+			return
+		}
+
+		second, statusCode, apiErr := planLoan(req.Context(), logger.WithFields(log.Fields{"scenario": "second"}), createLoanPlan, cfg, parsedReq.Second, nil)
+		if apiErr != nil {
+			writeErrorResponse(logger, res, req, statusCode, prefixFields(*apiErr, "second"))
+			return
+		}
+
+		resp := CompareLoanPlansResponse{
+			First:  first,
+			Second: second,
+			Delta: LoanPlansDelta{
+				TotalInterest:  delta(first.Summary.TotalInterest, second.Summary.TotalInterest),
+				MonthlyPayment: delta(first.MonthlyPayment, second.MonthlyPayment),
+			},
+		}
+
+		res.Header().Set("Content-Type", jsonContentType)
+		res.WriteHeader(http.StatusOK)
+		logResponseBodyWrite(logger, res, toJSON(logger, resp))
+	}
+}
+
+// prefixFields prefixes the names of the invalid fields of the error.
+func prefixFields(apiErr Error, prefix string) Error {
+	fields := make([]FieldError, len(apiErr.Fields))
+	for i, field := range apiErr.Fields {
+		fields[i] = FieldError{
+			Field:  prefix + "." + field.Field,
+			Reason: field.Reason,
+		}
+	}
+	if len(fields) > 0 {
+		apiErr.Fields = fields
+	}
+	apiErr.Message = prefix + ":" + apiErr.Message
+	return apiErr
+}
+
+// delta calculates second - first.
+func delta(first money.Amount, second money.Amount) money.Amount {
+	return money.New(second.Decimal().Sub(first.Decimal()))
+}
@@ -0,0 +1,229 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+	"github.com/katcipis/loaner/money"
+	"github.com/shopspring/decimal"
+)
+
+func TestLoanPlansComparison(t *testing.T) {
+	server := httptest.NewServer(api.New(loan.CreatePlanForCurrencyContext))
+	defer server.Close()
+
+	body := toJSON(t, api.CompareLoanPlansRequest{
+		First: api.CreateLoanPlanRequest{
+			LoanAmount:  amountPtr(t, "5000"),
+			NominalRate: amountPtr(t, "5.0"),
+			Duration:    months(24),
+			StartDate:   "2018-01-01T00:00:00Z",
+		},
+		Second: api.CreateLoanPlanRequest{
+			LoanAmount:  amountPtr(t, "5000"),
+			NominalRate: amountPtr(t, "5.0"),
+			Duration:    months(36),
+			StartDate:   "2018-01-01T00:00:00Z",
+		},
+	})
+
+	request := newRequest(t, http.MethodPost, server.URL+api.CompareLoanPlansPath, body)
+	res, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got response %d want %d", res.StatusCode, http.StatusOK)
+	}
+
+	got := api.CompareLoanPlansResponse{}
+	fromJSON(t, res.Body, &got)
+
+	if len(got.First.BorrowerPayments) != 24 {
+		t.Errorf("got %d payments on first plan; want 24", len(got.First.BorrowerPayments))
+	}
+	if len(got.Second.BorrowerPayments) != 36 {
+		t.Errorf("got %d payments on second plan; want 36", len(got.Second.BorrowerPayments))
+	}
+
+	firstPayment := got.First.BorrowerPayments[0].PaymentAmount.Decimal()
+	secondPayment := got.Second.BorrowerPayments[0].PaymentAmount.Decimal()
+	if !secondPayment.LessThan(firstPayment) {
+		t.Errorf("got longer term payment %v; want it lower than %v", secondPayment, firstPayment)
+	}
+
+	firstInterest := got.First.Summary.TotalInterest.Decimal()
+	secondInterest := got.Second.Summary.TotalInterest.Decimal()
+	if !secondInterest.GreaterThan(firstInterest) {
+		t.Errorf("got longer term total interest %v; want it higher than %v", secondInterest, firstInterest)
+	}
+
+	wantDelta := api.LoanPlansDelta{
+		TotalInterest:  money.New(secondInterest.Sub(firstInterest)),
+		MonthlyPayment: money.New(got.Second.MonthlyPayment.Decimal().Sub(got.First.MonthlyPayment.Decimal())),
+	}
+	if diff := cmp.Diff(wantDelta, got.Delta); diff != "" {
+		t.Errorf("delta mismatch (-want +got):\n%s", diff)
+	}
+	if !got.Delta.MonthlyPayment.Decimal().IsNegative() {
+		t.Errorf("got monthly payment delta %s; want it negative", got.Delta.MonthlyPayment)
+	}
+	if !got.Delta.TotalInterest.Decimal().IsPositive() {
+		t.Errorf("got total interest delta %s; want it positive", got.Delta.TotalInterest)
+	}
+}
+
+func TestLoanPlansComparisonUsesMonthlyPayment(t *testing.T) {
+	// The first payment of the plans has an extra fee,
+	// so it is not the monthly payment of the plans.
+	createLoanPlan := func(
+		ctx context.Context,
+		totalLoanAmount decimal.Decimal,
+		annualInterestRate decimal.Decimal,
+		durationInMonths int,
+		start time.Time,
+		currency loan.Currency,
+	) ([]loan.Payment, error) {
+		payments, err := loan.CreatePlanForCurrencyContext(ctx, totalLoanAmount, annualInterestRate, durationInMonths, start, currency)
+		if err != nil {
+			return nil, err
+		}
+		payments[0].PaymentAmount = payments[0].PaymentAmount.Add(decimal.NewFromInt(int64(durationInMonths)))
+		return payments, nil
+	}
+	server := httptest.NewServer(api.New(createLoanPlan))
+	defer server.Close()
+
+	body := toJSON(t, api.CompareLoanPlansRequest{
+		First: api.CreateLoanPlanRequest{
+			LoanAmount:  amountPtr(t, "5000"),
+			NominalRate: amountPtr(t, "5.0"),
+			Duration:    months(24),
+			StartDate:   "2018-01-01T00:00:00Z",
+		},
+		Second: api.CreateLoanPlanRequest{
+			LoanAmount:  amountPtr(t, "5000"),
+			NominalRate: amountPtr(t, "5.0"),
+			Duration:    months(36),
+			StartDate:   "2018-01-01T00:00:00Z",
+		},
+	})
+
+	request := newRequest(t, http.MethodPost, server.URL+api.CompareLoanPlansPath, body)
+	res, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got response %d want %d", res.StatusCode, http.StatusOK)
+	}
+
+	got := api.CompareLoanPlansResponse{}
+	fromJSON(t, res.Body, &got)
+
+	// 5000 at 5.0% has a monthly payment of 219.36 in 24 months
+	// and of 149.85 in 36 months.
+	want := amount(t, "-69.51")
+	if !got.Delta.MonthlyPayment.Equal(want) {
+		t.Errorf("got monthly payment delta %s; want %s", got.Delta.MonthlyPayment, want)
+	}
+}
+
+func TestLoanPlansComparisonFailures(t *testing.T) {
+	type Test struct {
+		name       string
+		method     string
+		body       []byte
+		wantStatus int
+		wantCode   api.ErrorCode
+		wantFields []string
+	}
+
+	validRequest := api.CreateLoanPlanRequest{
+		LoanAmount:  amountPtr(t, "5000"),
+		NominalRate: amountPtr(t, "5.0"),
+		Duration:    months(24),
+		StartDate:   "2018-01-01T00:00:00Z",
+	}
+	invalidRequest := map[string]interface{}{
+		"loanAmount":  "wrong",
+		"nominalRate": "5.0",
+		"duration":    24,
+		"startDate":   "2018-01-01T00:00:00Z",
+	}
+
+	tests := []Test{
+		{
+			name:       "InvalidFirstScenario",
+			method:     http.MethodPost,
+			body:       toJSON(t, map[string]interface{}{"first": invalidRequest, "second": validRequest}),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   api.ErrorCodeInvalidParameter,
+			wantFields: []string{"first.loanAmount"},
+		},
+		{
+			name:       "InvalidSecondScenario",
+			method:     http.MethodPost,
+			body:       toJSON(t, map[string]interface{}{"first": validRequest, "second": invalidRequest}),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   api.ErrorCodeInvalidParameter,
+			wantFields: []string{"second.loanAmount"},
+		},
+		{
+			name:       "MalformedJSON",
+			method:     http.MethodPost,
+			body:       []byte("{"),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   api.ErrorCodeMalformedJSON,
+		},
+		{
+			name:       "MethodNotAllowed",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantCode:   api.ErrorCodeMethodNotAllowed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(api.New(loan.CreatePlanForCurrencyContext))
+			defer server.Close()
+
+			request := newRequest(t, test.method, server.URL+api.CompareLoanPlansPath, test.body)
+			res, err := server.Client().Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != test.wantStatus {
+				t.Fatalf("got response %d want %d", res.StatusCode, test.wantStatus)
+			}
+
+			got := api.ErrorResponse{}
+			fromJSON(t, res.Body, &got)
+
+			if got.Error.Code != test.wantCode {
+				t.Errorf("got error code %q; want %q", got.Error.Code, test.wantCode)
+			}
+
+			var gotFields []string
+			for _, field := range got.Error.Fields {
+				gotFields = append(gotFields, field.Field)
+			}
+			if diff := cmp.Diff(test.wantFields, gotFields); diff != "" {
+				t.Errorf("fields mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
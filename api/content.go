@@ -0,0 +1,108 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	jsonContentType = "application/json"
+	csvContentType  = "text/csv"
+)
+
+// negotiateContentType returns the first media type accepted by the
+// request (according to its Accept header) that is on the supported list.
+// If the request has no Accept header or accepts none of the supported
+// media types the first supported one is returned.
+func negotiateContentType(req *http.Request, supported ...string) string {
+	accept := req.Header.Get("Accept")
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		for _, s := range supported {
+			if mediaType == s {
+				return s
+			}
+		}
+	}
+	return supported[0]
+}
+
+// hasJSONBody checks if the request body is declared as JSON on
+// its Content-Type header. To be lenient with clients that don't
+// send the header, requests without a Content-Type are accepted.
+func hasJSONBody(req *http.Request) bool {
+	return hasBodyOfType(req, jsonContentType)
+}
+
+// hasCSVBody works as hasJSONBody, but for CSV bodies.
+func hasCSVBody(req *http.Request) bool {
+	return hasBodyOfType(req, csvContentType)
+}
+
+func hasBodyOfType(req *http.Request, wantMediaType string) bool {
+	contentType := req.Header.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == wantMediaType
+}
+
+// refused checks if the parameters of a value on a negotiation header,
+// like "gzip;q=0" on Accept-Encoding, have a quality of zero, which means
+// that the value is explicitly not acceptable.
+func refused(params []string) bool {
+	for _, param := range params {
+		param = strings.TrimSpace(param)
+		if !strings.HasPrefix(param, "q=") {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+		if err == nil && q == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+var csvHeader = []string{
+	"date",
+	"borrowerPaymentAmount",
+	"interest",
+	"principal",
+	"initialOutstandingPrincipal",
+	"remainingOutstandingPrincipal",
+}
+
+// toCSV represents the loan plan as CSV, with a header row
+// and one row for each payment.
+func toCSV(logger *log.Entry, resp CreateLoanPlanResponse) []byte {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+
+	records := [][]string{csvHeader}
+	for _, p := range resp.BorrowerPayments {
+		records = append(records, []string{
+			p.Date,
+			p.PaymentAmount.String(),
+			p.Interest.String(),
+			p.Principal.String(),
+			p.InitialOutstandingPrincipal.String(),
+			p.RemainingOutstandingPrincipal.String(),
+		})
+	}
+
+	if err := w.WriteAll(records); err != nil {
+		logger.WithError(err).Warning("unable to write as CSV")
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	corsAllowedMethods = "POST, GET, OPTIONS"
+	corsAllowedHeaders = "Accept, Accept-Encoding, Content-Type, " + RequestIDHeader + ", " + IdempotencyKeyHeader
+	corsMaxAge         = 10 * time.Minute
+)
+
+// withCORS adds CORS headers to the responses of requests from the
+// allowed origins, also answering their preflight requests.
+// Requests from other origins are handled as if CORS was disabled,
+// without any CORS headers, so browsers will block them.
+func withCORS(allowedOrigins []string, next http.Handler) http.Handler {
+	allowed := map[string]bool{}
+	for _, origin := range allowedOrigins {
+		allowed[origin] = true
+	}
+
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		origin := req.Header.Get("Origin")
+		res.Header().Add("Vary", "Origin")
+
+		if origin == "" || (!allowed[origin] && !allowed["*"]) {
+			next.ServeHTTP(res, req)
+			return
+		}
+
+		headers := res.Header()
+		headers.Set("Access-Control-Allow-Origin", origin)
+		headers.Set("Access-Control-Expose-Headers", RequestIDHeader)
+
+		isPreflight := req.Method == http.MethodOptions &&
+			req.Header.Get("Access-Control-Request-Method") != ""
+		if !isPreflight {
+			next.ServeHTTP(res, req)
+			return
+		}
+
+		headers.Set("Access-Control-Allow-Methods", corsAllowedMethods)
+		headers.Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+		headers.Set("Access-Control-Max-Age", strconv.Itoa(int(corsMaxAge.Seconds())))
+		res.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,146 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestCORS(t *testing.T) {
+	type Test struct {
+		name           string
+		opts           []api.Option
+		method         string
+		origin         string
+		preflight      bool
+		wantStatusCode int
+		wantHeaders    map[string]string
+	}
+
+	const origin = "https://app.example.com"
+
+	allowedPreflightHeaders := map[string]string{
+		"Access-Control-Allow-Origin":   origin,
+		"Access-Control-Allow-Methods":  "POST, GET, OPTIONS",
+		"Access-Control-Allow-Headers":  "Accept, Accept-Encoding, Content-Type, X-Request-ID, Idempotency-Key",
+		"Access-Control-Expose-Headers": "X-Request-ID",
+		"Access-Control-Max-Age":        "600",
+	}
+	noCORSHeaders := map[string]string{
+		"Access-Control-Allow-Origin":   "",
+		"Access-Control-Allow-Methods":  "",
+		"Access-Control-Allow-Headers":  "",
+		"Access-Control-Expose-Headers": "",
+		"Access-Control-Max-Age":        "",
+	}
+
+	tests := []Test{
+		{
+			name:           "PreflightFromAllowedOrigin",
+			opts:           []api.Option{api.WithCORS("https://other.example.com", origin)},
+			method:         http.MethodOptions,
+			origin:         origin,
+			preflight:      true,
+			wantStatusCode: http.StatusNoContent,
+			wantHeaders:    allowedPreflightHeaders,
+		},
+		{
+			name:           "PreflightWithAnyOriginAllowed",
+			opts:           []api.Option{api.WithCORS("*")},
+			method:         http.MethodOptions,
+			origin:         origin,
+			preflight:      true,
+			wantStatusCode: http.StatusNoContent,
+			wantHeaders:    allowedPreflightHeaders,
+		},
+		{
+			name:           "PreflightFromNotAllowedOrigin",
+			opts:           []api.Option{api.WithCORS("https://other.example.com")},
+			method:         http.MethodOptions,
+			origin:         origin,
+			preflight:      true,
+			wantStatusCode: http.StatusMethodNotAllowed,
+			wantHeaders:    noCORSHeaders,
+		},
+		{
+			name:           "PreflightWithCORSDisabled",
+			method:         http.MethodOptions,
+			origin:         origin,
+			preflight:      true,
+			wantStatusCode: http.StatusMethodNotAllowed,
+			wantHeaders:    noCORSHeaders,
+		},
+		{
+			name:           "RequestFromAllowedOrigin",
+			opts:           []api.Option{api.WithCORS(origin)},
+			method:         http.MethodPost,
+			origin:         origin,
+			wantStatusCode: http.StatusOK,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":   origin,
+				"Access-Control-Expose-Headers": "X-Request-ID",
+				"Access-Control-Allow-Methods":  "",
+			},
+		},
+		{
+			name:           "RequestFromNotAllowedOrigin",
+			opts:           []api.Option{api.WithCORS("https://other.example.com")},
+			method:         http.MethodPost,
+			origin:         origin,
+			wantStatusCode: http.StatusOK,
+			wantHeaders:    noCORSHeaders,
+		},
+		{
+			name:           "RequestWithoutOrigin",
+			opts:           []api.Option{api.WithCORS(origin)},
+			method:         http.MethodPost,
+			wantStatusCode: http.StatusOK,
+			wantHeaders:    noCORSHeaders,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(loan.CreatePlanForCurrencyContext, test.opts...)
+			server := httptest.NewServer(service)
+			defer server.Close()
+
+			var body []byte
+			if test.method == http.MethodPost {
+				body = validCreateLoanRequestBody(t)
+			}
+
+			request := newRequest(t, test.method, server.URL+api.CreateLoanPlanPath, body)
+			if test.origin != "" {
+				request.Header.Set("Origin", test.origin)
+			}
+			if test.preflight {
+				request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+				request.Header.Set("Access-Control-Request-Headers", "Content-Type, Idempotency-Key")
+			}
+
+			res, err := server.Client().Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != test.wantStatusCode {
+				t.Fatalf("got response %d want %d", res.StatusCode, test.wantStatusCode)
+			}
+
+			gotHeaders := map[string]string{}
+			for name := range test.wantHeaders {
+				gotHeaders[name] = res.Header.Get(name)
+			}
+
+			if diff := cmp.Diff(test.wantHeaders, gotHeaders); diff != "" {
+				t.Errorf("CORS headers mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package api
+
+import "sync/atomic"
+
+// Drain informs the service that it is shutting down and draining its
+// in-flight requests. Once the drain begins the health check fails with
+// 503 (Service Unavailable), so load balancers stop routing new requests
+// to the service, while all the other resources keep serving requests.
+// It is safe to use concurrently, the zero value is not draining.
+type Drain struct {
+	// draining is accessed atomically (no atomic.Bool on Go 1.15).
+	draining int32
+}
+
+// Begin begins the drain, it is safe to call it more than once.
+func (d *Drain) Begin() {
+	atomic.StoreInt32(&d.draining, 1)
+}
+
+// Draining returns true if the drain has begun.
+func (d *Drain) Draining() bool {
+	return atomic.LoadInt32(&d.draining) == 1
+}
@@ -0,0 +1,84 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/katcipis/loaner/api"
+)
+
+// Run the tests with -update to regenerate the golden files,
+// like: go test ./api -run TestResponseMarshallingIsStable -update
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestResponseMarshallingIsStable(t *testing.T) {
+	type Test struct {
+		name     string
+		response api.CreateLoanPlanResponse
+	}
+
+	tests := []Test{
+		{
+			name: "2000LoanWith1.0RateIn2Months",
+			response: api.CreateLoanPlanResponse{
+				BorrowerPayments: []api.BorrowerPayment{
+					{
+						Date:                          "2018-01-01T00:00:00Z",
+						PaymentAmount:                 amount(t, "1001.25"),
+						Interest:                      amount(t, "1.67"),
+						Principal:                     amount(t, "999.58"),
+						InitialOutstandingPrincipal:   amount(t, "2000"),
+						RemainingOutstandingPrincipal: amount(t, "1000.42"),
+						DaysInPeriod:                  30,
+					},
+					{
+						Date:                          "2018-02-01T00:00:00Z",
+						PaymentAmount:                 amount(t, "1001.25"),
+						Interest:                      amount(t, "0.83"),
+						Principal:                     amount(t, "1000.42"),
+						InitialOutstandingPrincipal:   amount(t, "1000.42"),
+						RemainingOutstandingPrincipal: amount(t, "0"),
+						DaysInPeriod:                  30,
+					},
+				},
+				Total:          2,
+				MonthlyPayment: amount(t, "1001.25"),
+				PayoffDate:     "2018-02-01T00:00:00Z",
+				Summary: api.LoanPlanSummary{
+					TotalPrincipal: amount(t, "2000"),
+					TotalInterest:  amount(t, "2.5"),
+					TotalPayment:   amount(t, "2002.5"),
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := json.MarshalIndent(test.response, "", "    ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := filepath.Join("testdata", test.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("can't read golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(want, got) {
+				t.Errorf("marshalled response mismatch with %s (run with -update if the change is intended)\ngot:\n%s\nwant:\n%s", golden, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strings"
+)
+
+// gzipMinSize is the min size of a response body for it to be
+// compressed. Small responses don't benefit much from compression
+// and may even get bigger because of the gzip headers.
+const gzipMinSize = 1024
+
+// withGzip compresses responses with gzip when the client accepts it
+// (according to its Accept-Encoding header). Small responses are
+// always sent uncompressed.
+//
+// The response body is buffered to decide if it is worth compressing,
+// which is fine since all responses of the service are fully built in
+// memory before being written anyway.
+func withGzip(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Add("Vary", "Accept-Encoding")
+
+		if !acceptsGzip(req) {
+			next.ServeHTTP(res, req)
+			return
+		}
+
+		buffered := &bufferedResponse{ResponseWriter: res, status: http.StatusOK}
+		next.ServeHTTP(buffered, req)
+
+		if buffered.body.Len() < gzipMinSize || res.Header().Get("Content-Encoding") != "" {
+			res.WriteHeader(buffered.status)
+			_, _ = res.Write(buffered.body.Bytes())
+			return
+		}
+
+		res.Header().Set("Content-Encoding", "gzip")
+		res.Header().Del("Content-Length")
+		res.WriteHeader(buffered.status)
+
+		gz := gzip.NewWriter(res)
+		_, _ = gz.Write(buffered.body.Bytes())
+		_ = gz.Close()
+	})
+}
+
+// acceptsGzip checks if the Accept-Encoding header of the request
+// includes gzip, ignoring it if explicitly not accepted (q=0).
+func acceptsGzip(req *http.Request) bool {
+	for _, header := range req.Header.Values("Accept-Encoding") {
+		for _, coding := range strings.Split(header, ",") {
+			params := strings.Split(coding, ";")
+			if strings.TrimSpace(params[0]) != "gzip" {
+				continue
+			}
+			return !refused(params[1:])
+		}
+	}
+	return false
+}
+
+// bufferedResponse buffers the status and body of a response,
+// headers are written directly on the wrapped response.
+type bufferedResponse struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *bufferedResponse) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *bufferedResponse) Write(data []byte) (int, error) {
+	return r.body.Write(data)
+}
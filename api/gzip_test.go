@@ -0,0 +1,138 @@
+package api_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestGzipCompression(t *testing.T) {
+	type Test struct {
+		name           string
+		path           string
+		method         string
+		requestBody    []byte
+		acceptEncoding string
+		wantGzip       bool
+	}
+
+	largePlan := toJSON(t, api.CreateLoanPlanRequest{
+		LoanAmount:  amountPtr(t, "200000"),
+		NominalRate: amountPtr(t, "4.0"),
+		Duration:    months(360),
+		StartDate:   "2018-01-01T00:00:00Z",
+	})
+
+	tests := []Test{
+		{
+			name:           "LargeResponseIsCompressed",
+			path:           api.CreateLoanPlanPath,
+			method:         http.MethodPost,
+			requestBody:    largePlan,
+			acceptEncoding: "gzip",
+			wantGzip:       true,
+		},
+		{
+			name:           "LargeResponseIsCompressedWithMultipleEncodings",
+			path:           api.CreateLoanPlanPath,
+			method:         http.MethodPost,
+			requestBody:    largePlan,
+			acceptEncoding: "deflate, gzip;q=0.8, br",
+			wantGzip:       true,
+		},
+		{
+			name:        "LargeResponseIsNotCompressedWithoutAcceptEncoding",
+			path:        api.CreateLoanPlanPath,
+			method:      http.MethodPost,
+			requestBody: largePlan,
+		},
+		{
+			name:           "LargeResponseIsNotCompressedIfGzipIsNotAccepted",
+			path:           api.CreateLoanPlanPath,
+			method:         http.MethodPost,
+			requestBody:    largePlan,
+			acceptEncoding: "deflate, br",
+		},
+		{
+			name:           "LargeResponseIsNotCompressedIfGzipIsExplicitlyRefused",
+			path:           api.CreateLoanPlanPath,
+			method:         http.MethodPost,
+			requestBody:    largePlan,
+			acceptEncoding: "gzip;q=0",
+		},
+		{
+			name:           "SmallResponseIsNotCompressed",
+			path:           api.HealthPath,
+			method:         http.MethodGet,
+			acceptEncoding: "gzip",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(loan.CreatePlanForCurrencyContext)
+			server := httptest.NewServer(service)
+			defer server.Close()
+
+			// Explicitly setting Accept-Encoding disables the
+			// transparent decompression of the client.
+			request := newRequest(t, test.method, server.URL+test.path, test.requestBody)
+			request.Header.Set("Accept-Encoding", test.acceptEncoding)
+			res, err := server.Client().Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			uncompressedRequest := newRequest(t, test.method, server.URL+test.path, test.requestBody)
+			uncompressedRequest.Header.Set("Accept-Encoding", "identity")
+			uncompressedRes, err := server.Client().Do(uncompressedRequest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer uncompressedRes.Body.Close()
+
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("got response %d want %d", res.StatusCode, http.StatusOK)
+			}
+
+			body := res.Body
+			gotEncoding := res.Header.Get("Content-Encoding")
+
+			if test.wantGzip {
+				if gotEncoding != "gzip" {
+					t.Fatalf("got Content-Encoding %q; want gzip", gotEncoding)
+				}
+				gzipBody, err := gzip.NewReader(res.Body)
+				if err != nil {
+					t.Fatalf("response is not valid gzip: %v", err)
+				}
+				body = gzipBody
+			} else if gotEncoding != "" {
+				t.Fatalf("got Content-Encoding %q; want none", gotEncoding)
+			}
+
+			got, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := io.ReadAll(uncompressedRes.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != string(want) {
+				t.Errorf("got body:\n%s\n\nwant body:\n%s", got, want)
+			}
+
+			if got, want := res.Header.Get("Content-Type"), uncompressedRes.Header.Get("Content-Type"); got != want {
+				t.Errorf("got Content-Type %q; want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// HealthPath is the resource path used to check the health of the service
+	HealthPath = "/healthz"
+)
+
+// HealthResponse is the response of the health check request
+type HealthResponse struct {
+	Status  string `json:"status"`
+	Version string `json:"version,omitempty"`
+}
+
+// healthHandler answers liveness/readiness probes. It does not
+// run any loan computation, it just informs that the service is up,
+// or that it is draining (with 503) when it is shutting down.
+func healthHandler(cfg config) http.HandlerFunc {
+	pathLogger := cfg.logger.WithFields(log.Fields{"path": HealthPath})
+
+	return func(res http.ResponseWriter, req *http.Request) {
+		logger := pathLogger.WithFields(log.Fields{"requestID": requestID(req)})
+
+		if req.Method != http.MethodGet {
+			handleMethodNotAllowed(logger, res, req, http.MethodGet)
+			return
+		}
+
+		res.Header().Set("Content-Type", jsonContentType)
+
+		if cfg.drain != nil && cfg.drain.Draining() {
+			res.WriteHeader(http.StatusServiceUnavailable)
+			logResponseBodyWrite(logger, res, toJSON(logger, HealthResponse{
+				Status:  "draining",
+				Version: cfg.version,
+			}))
+			return
+		}
+
+		res.WriteHeader(http.StatusOK)
+		logResponseBodyWrite(logger, res, toJSON(logger, HealthResponse{
+			Status:  "ok",
+			Version: cfg.version,
+		}))
+	}
+}
@@ -0,0 +1,182 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+	"github.com/shopspring/decimal"
+)
+
+func TestHealth(t *testing.T) {
+	type Test struct {
+		name           string
+		method         string
+		opts           []api.Option
+		wantStatusCode int
+		want           api.HealthResponse
+	}
+
+	tests := []Test{
+		{
+			name:           "Healthy",
+			method:         http.MethodGet,
+			wantStatusCode: http.StatusOK,
+			want:           api.HealthResponse{Status: "ok"},
+		},
+		{
+			name:           "HealthyWithVersion",
+			method:         http.MethodGet,
+			opts:           []api.Option{api.WithVersion("1.12.0")},
+			wantStatusCode: http.StatusOK,
+			want:           api.HealthResponse{Status: "ok", Version: "1.12.0"},
+		},
+		{
+			name:           "MethodNotAllowedForPost",
+			method:         http.MethodPost,
+			wantStatusCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(func(
+				ctx context.Context,
+				totalLoanAmount decimal.Decimal,
+				annualInterestRate decimal.Decimal,
+				durationInMonths int,
+				start time.Time,
+				currency loan.Currency,
+			) ([]loan.Payment, error) {
+				t.Error("health check must not compute loan plans")
+				return nil, nil
+			}, test.opts...)
+			server := httptest.NewServer(service)
+			defer server.Close()
+
+			request := newRequest(t, test.method, server.URL+api.HealthPath, nil)
+			res, err := server.Client().Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != test.wantStatusCode {
+				t.Fatalf("got response %d want %d", res.StatusCode, test.wantStatusCode)
+			}
+
+			if test.wantStatusCode != http.StatusOK {
+				errResponse := api.ErrorResponse{}
+				fromJSON(t, res.Body, &errResponse)
+				if errResponse.Error.Code != api.ErrorCodeMethodNotAllowed {
+					t.Errorf("got error code %q; want %q", errResponse.Error.Code, api.ErrorCodeMethodNotAllowed)
+				}
+				return
+			}
+
+			got := api.HealthResponse{}
+			fromJSON(t, res.Body, &got)
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("api: GET %s mismatch (-want +got):\n%s", api.HealthPath, diff)
+			}
+		})
+	}
+}
+
+func TestHealthWhileDraining(t *testing.T) {
+	planStarted := make(chan struct{}, 1)
+	releasePlan := make(chan struct{})
+
+	drain := &api.Drain{}
+	service := api.New(func(
+		ctx context.Context,
+		totalLoanAmount decimal.Decimal,
+		annualInterestRate decimal.Decimal,
+		durationInMonths int,
+		start time.Time,
+		currency loan.Currency,
+	) ([]loan.Payment, error) {
+		select {
+		case planStarted <- struct{}{}:
+		default:
+		}
+		<-releasePlan
+		return loan.CreatePlanForCurrencyContext(ctx, totalLoanAmount, annualInterestRate, durationInMonths, start, currency)
+	}, api.WithDrain(drain))
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	checkHealth := func(t *testing.T, wantStatusCode int, want api.HealthResponse) {
+		t.Helper()
+
+		res, err := server.Client().Do(newRequest(t, http.MethodGet, server.URL+api.HealthPath, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != wantStatusCode {
+			t.Fatalf("got health response %d want %d", res.StatusCode, wantStatusCode)
+		}
+		got := api.HealthResponse{}
+		fromJSON(t, res.Body, &got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("api: GET %s mismatch (-want +got):\n%s", api.HealthPath, diff)
+		}
+	}
+
+	createPlan := func() (int, error) {
+		req, err := http.NewRequest(http.MethodPost, server.URL+api.CreateLoanPlanPath, bytes.NewReader(validCreateLoanRequestBody(t)))
+		if err != nil {
+			return 0, err
+		}
+		res, err := server.Client().Do(req)
+		if err != nil {
+			return 0, err
+		}
+		res.Body.Close()
+		return res.StatusCode, nil
+	}
+
+	checkHealth(t, http.StatusOK, api.HealthResponse{Status: "ok"})
+
+	type result struct {
+		statusCode int
+		err        error
+	}
+	inFlight := make(chan result, 1)
+	go func() {
+		statusCode, err := createPlan()
+		inFlight <- result{statusCode: statusCode, err: err}
+	}()
+	<-planStarted
+
+	drain.Begin()
+
+	checkHealth(t, http.StatusServiceUnavailable, api.HealthResponse{Status: "draining"})
+
+	close(releasePlan)
+
+	got := <-inFlight
+	if got.err != nil {
+		t.Fatalf("in-flight loan plan request failed: %v", got.err)
+	}
+	if got.statusCode != http.StatusOK {
+		t.Errorf("got in-flight loan plan response %d want %d", got.statusCode, http.StatusOK)
+	}
+
+	statusCode, err := createPlan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statusCode != http.StatusOK {
+		t.Errorf("got loan plan response %d while draining want %d", statusCode, http.StatusOK)
+	}
+}
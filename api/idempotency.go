@@ -0,0 +1,226 @@
+package api
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// IdempotencyKeyHeader is the header used by clients to make
+	// a POST request idempotent. Repeating a request with the same key
+	// and body returns the response of the first request.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader is set to "true" on
+	// responses that are a replay of a previous response.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// DefaultIdempotencyTTL is the default duration
+	// that responses are kept for replay.
+	DefaultIdempotencyTTL = 24 * time.Hour
+
+	// DefaultIdempotencyMaxKeys is the default max number
+	// of idempotency keys that are kept for replay.
+	DefaultIdempotencyMaxKeys = 10000
+)
+
+// maxIdempotencyKeySize limits the size of the idempotency keys
+// since they are kept in memory.
+const maxIdempotencyKeySize = 255
+
+// replayedHeaders are the headers of the response set by the
+// handlers that are stored and sent again on replays.
+var replayedHeaders = []string{"Content-Type", "Content-Language"}
+
+// idempotentResponse is a response stored for replay.
+// The fingerprint identifies the request of the response.
+type idempotentResponse struct {
+	fingerprint [sha256.Size]byte
+	status      int
+	header      http.Header
+	body        []byte
+	expiresAt   time.Time
+}
+
+// idempotencyStore is an in-memory LRU store of responses, indexed by
+// idempotency key. Responses expire after the TTL and the least recently
+// used ones are evicted when the store is full, so clients sending new
+// keys can't grow it without limit. It is safe for concurrent use.
+type idempotencyStore struct {
+	mutex   sync.Mutex
+	ttl     time.Duration
+	maxKeys int
+	now     func() time.Time
+	entries map[string]*list.Element
+	// recency has the stored responses, from the most
+	// recently used to the least recently used.
+	recency *list.List
+}
+
+type idempotencyEntry struct {
+	key  string
+	resp idempotentResponse
+}
+
+func newIdempotencyStore(ttl time.Duration, maxKeys int) *idempotencyStore {
+	return &idempotencyStore{
+		ttl:     ttl,
+		maxKeys: maxKeys,
+		now:     time.Now,
+		entries: map[string]*list.Element{},
+		recency: list.New(),
+	}
+}
+
+// get returns the response stored for the key, if it didn't expire yet.
+// Expired responses are removed.
+func (s *idempotencyStore) get(key string) (idempotentResponse, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	elem, ok := s.entries[key]
+	if !ok {
+		return idempotentResponse{}, false
+	}
+	entry := elem.Value.(*idempotencyEntry)
+	if !s.now().Before(entry.resp.expiresAt) {
+		s.remove(elem)
+		return idempotentResponse{}, false
+	}
+	s.recency.MoveToFront(elem)
+	return entry.resp, true
+}
+
+// put stores the response for the key, evicting the least
+// recently used response when the store is full.
+func (s *idempotencyStore) put(key string, resp idempotentResponse) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	resp.expiresAt = s.now().Add(s.ttl)
+
+	if elem, ok := s.entries[key]; ok {
+		elem.Value.(*idempotencyEntry).resp = resp
+		s.recency.MoveToFront(elem)
+		return
+	}
+
+	s.entries[key] = s.recency.PushFront(&idempotencyEntry{key: key, resp: resp})
+	if s.recency.Len() > s.maxKeys {
+		s.remove(s.recency.Back())
+	}
+}
+
+func (s *idempotencyStore) remove(elem *list.Element) {
+	s.recency.Remove(elem)
+	delete(s.entries, elem.Value.(*idempotencyEntry).key)
+}
+
+// idempotencyFingerprint identifies the request made with an idempotency
+// key, so reusing the key on a different request can be detected. Besides
+// the body it includes the method and the URI of the request, since the
+// same body sent to a different path, or with different query parameters,
+// has a different response.
+func idempotencyFingerprint(req *http.Request, body []byte) [sha256.Size]byte {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", req.Method, req.URL.RequestURI())
+	hash.Write(body)
+
+	var fingerprint [sha256.Size]byte
+	copy(fingerprint[:], hash.Sum(nil))
+	return fingerprint
+}
+
+// withIdempotency replays stored responses for POST requests with
+// an idempotency key, instead of handling them again. Reusing a key on
+// a different request (a different body, method or URI) fails with 409
+// (Conflict). Server errors are not stored, so requests that failed on
+// them can be retried.
+func withIdempotency(cfg config, path string, next http.Handler) http.Handler {
+	store := newIdempotencyStore(cfg.idempotencyTTL, cfg.idempotencyMaxKeys)
+	pathLogger := cfg.logger.WithFields(log.Fields{"path": path})
+
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		key := req.Header.Get(IdempotencyKeyHeader)
+		if req.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(res, req)
+			return
+		}
+
+		logger := pathLogger.WithFields(log.Fields{
+			"requestID":      requestID(req),
+			"idempotencyKey": key,
+		})
+
+		if len(key) > maxIdempotencyKeySize {
+			msg := fmt.Sprintf("idempotency key is bigger than the max size of %d bytes", maxIdempotencyKeySize)
+			writeErrorResponse(logger, res, req, http.StatusBadRequest, Error{
+				Code:    ErrorCodeInvalidParameter,
+				Message: msg,
+			})
+			logger.WithFields(log.Fields{"error": msg}).Warning("invalid idempotency key")
+			return
+		}
+
+		// Bodies bigger than the limit are not buffered
+		// entirely, the handler is responsible for rejecting them.
+		body, err := io.ReadAll(io.LimitReader(req.Body, cfg.maxBodySize+1))
+		if err != nil || int64(len(body)) > cfg.maxBodySize {
+			req.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), req.Body))
+			next.ServeHTTP(res, req)
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := idempotencyFingerprint(req, body)
+
+		if stored, ok := store.get(key); ok {
+			if stored.fingerprint != fingerprint {
+				msg := fmt.Sprintf("idempotency key %q was already used with a different request", key)
+				writeErrorResponse(logger, res, req, http.StatusConflict, Error{
+					Code:    ErrorCodeIdempotencyConflict,
+					Message: msg,
+				})
+				logger.WithFields(log.Fields{"error": msg}).Warning("idempotency key conflict")
+				return
+			}
+
+			logger.Info("replaying stored response")
+			for name, values := range stored.header {
+				res.Header()[name] = values
+			}
+			res.Header().Set(IdempotentReplayedHeader, "true")
+			res.WriteHeader(stored.status)
+			logResponseBodyWrite(logger, res, stored.body)
+			return
+		}
+
+		buffered := &bufferedResponse{ResponseWriter: res, status: http.StatusOK}
+		next.ServeHTTP(buffered, req)
+
+		if buffered.status < http.StatusInternalServerError {
+			header := http.Header{}
+			for _, name := range replayedHeaders {
+				if values := res.Header().Values(name); len(values) > 0 {
+					header[name] = values
+				}
+			}
+			store.put(key, idempotentResponse{
+				fingerprint: fingerprint,
+				status:      buffered.status,
+				header:      header,
+				body:        buffered.body.Bytes(),
+			})
+		}
+
+		res.WriteHeader(buffered.status)
+		logResponseBodyWrite(logger, res, buffered.body.Bytes())
+	})
+}
@@ -0,0 +1,200 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+	"github.com/shopspring/decimal"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+
+	type Test struct {
+		name       string
+		opts       []api.Option
+		firstKey   string
+		secondKey  string
+		secondBody string
+		// secondQuery is sent on the URI of the second request
+		secondQuery string
+		wantStatus  int
+		wantCalls   int
+		wantReplay  bool
+	}
+
+	const body = `{"loanAmount":"1000","nominalRate":"5","duration":1,"startDate":"2020-01-01T00:00:00Z"}`
+	const otherBody = `{"loanAmount":"2000","nominalRate":"5","duration":1,"startDate":"2020-01-01T00:00:00Z"}`
+
+	tests := []Test{
+		{
+			name:       "ReplaysSameKeyAndBody",
+			firstKey:   "key",
+			secondKey:  "key",
+			secondBody: body,
+			wantStatus: http.StatusOK,
+			wantCalls:  1,
+			wantReplay: true,
+		},
+		{
+			name:       "ConflictOnSameKeyAndDifferentBody",
+			firstKey:   "key",
+			secondKey:  "key",
+			secondBody: otherBody,
+			wantStatus: http.StatusConflict,
+			wantCalls:  1,
+		},
+		{
+			name:        "ConflictOnSameKeyAndBodyWithDifferentURI",
+			firstKey:    "key",
+			secondKey:   "key",
+			secondBody:  body,
+			secondQuery: "?order=desc",
+			wantStatus:  http.StatusConflict,
+			wantCalls:   1,
+		},
+		{
+			name:       "DifferentKeys",
+			firstKey:   "key",
+			secondKey:  "other-key",
+			secondBody: body,
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+		},
+		{
+			name:       "NoKey",
+			secondBody: body,
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+		},
+		{
+			name:       "ExpiredKey",
+			opts:       []api.Option{api.WithIdempotencyTTL(time.Nanosecond)},
+			firstKey:   "key",
+			secondKey:  "key",
+			secondBody: otherBody,
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			service := api.New(func(
+				ctx context.Context,
+				totalLoanAmount decimal.Decimal,
+				annualInterestRate decimal.Decimal,
+				durationInMonths int,
+				start time.Time,
+				currency loan.Currency,
+			) ([]loan.Payment, error) {
+				calls++
+				return loan.CreatePlan(totalLoanAmount, annualInterestRate, durationInMonths, start)
+			}, test.opts...)
+			server := httptest.NewServer(service)
+			defer server.Close()
+
+			post := func(key string, body string, query string) (*http.Response, []byte) {
+				request := newRequest(t, http.MethodPost, server.URL+api.CreateLoanPlanPath+query, []byte(body))
+				if key != "" {
+					request.Header.Set(api.IdempotencyKeyHeader, key)
+				}
+				res, err := server.Client().Do(request)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer res.Body.Close()
+
+				resBody, err := io.ReadAll(res.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return res, resBody
+			}
+
+			first, firstBody := post(test.firstKey, body, "")
+			if first.StatusCode != http.StatusOK {
+				t.Fatalf("got first response %d want %d", first.StatusCode, http.StatusOK)
+			}
+			if first.Header.Get(api.IdempotentReplayedHeader) != "" {
+				t.Errorf("first response should not be a replay")
+			}
+
+			second, secondBody := post(test.secondKey, test.secondBody, test.secondQuery)
+			if second.StatusCode != test.wantStatus {
+				t.Fatalf("got second response %d want %d: %s", second.StatusCode, test.wantStatus, secondBody)
+			}
+			if calls != test.wantCalls {
+				t.Errorf("got %d calls to create loan plan; want %d", calls, test.wantCalls)
+			}
+
+			if test.wantStatus == http.StatusConflict {
+				resErr := api.ErrorResponse{}
+				fromJSON(t, bytes.NewReader(secondBody), &resErr)
+				if resErr.Error.Code != api.ErrorCodeIdempotencyConflict {
+					t.Errorf("got error code %q; want %q", resErr.Error.Code, api.ErrorCodeIdempotencyConflict)
+				}
+				return
+			}
+
+			replayed := second.Header.Get(api.IdempotentReplayedHeader) == "true"
+			if replayed != test.wantReplay {
+				t.Errorf("got replayed %t; want %t", replayed, test.wantReplay)
+			}
+			if test.wantReplay {
+				if !bytes.Equal(firstBody, secondBody) {
+					t.Errorf("replayed body %s differs from original %s", secondBody, firstBody)
+				}
+				if second.Header.Get("Content-Type") != first.Header.Get("Content-Type") {
+					t.Errorf("replayed content type %q differs from original %q",
+						second.Header.Get("Content-Type"), first.Header.Get("Content-Type"))
+				}
+			}
+		})
+	}
+}
+
+func TestIdempotencyKeysAreEvictedWhenFull(t *testing.T) {
+	service := api.New(loan.CreatePlanForCurrencyContext, api.WithIdempotencyMaxKeys(2))
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	post := func(key string, loanAmount string) int {
+		t.Helper()
+
+		body := fmt.Sprintf(`{"loanAmount":%q,"nominalRate":"5","duration":1,"startDate":"2020-01-01T00:00:00Z"}`, loanAmount)
+		request := newRequest(t, http.MethodPost, server.URL+api.CreateLoanPlanPath, []byte(body))
+		request.Header.Set(api.IdempotencyKeyHeader, key)
+		res, err := server.Client().Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		return res.StatusCode
+	}
+
+	for _, key := range []string{"first", "second", "first", "third"} {
+		if status := post(key, "1000"); status != http.StatusOK {
+			t.Fatalf("got response %d for key %q; want %d", status, key, http.StatusOK)
+		}
+	}
+
+	// The "first" key was used again right before the "third" key was
+	// stored, so it is still stored. The "second" key was the least
+	// recently used one, so it was evicted and can be reused with
+	// a different body.
+	if status := post("first", "2000"); status != http.StatusConflict {
+		t.Errorf("got response %d for stored key; want %d", status, http.StatusConflict)
+	}
+	if status := post("second", "2000"); status != http.StatusOK {
+		t.Errorf("got response %d for evicted key; want %d", status, http.StatusOK)
+	}
+}
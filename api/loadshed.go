@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// overloadRetryAfter is for how long, in seconds, clients are
+// asked to wait before retrying requests rejected by load shedding.
+const overloadRetryAfter = 1
+
+// withLoadShedding limits the number of requests handled concurrently,
+// requests beyond the limit are rejected right away with 503
+// (Service Unavailable) and a Retry-After header instead of waiting,
+// so the CPU is not exhausted by unbounded work. The health check and
+// the metrics are never rejected, since they are used to monitor
+// the service exactly when it is overloaded.
+func withLoadShedding(cfg config, next http.Handler) http.Handler {
+	inFlight := make(chan struct{}, cfg.maxInFlight)
+
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == HealthPath || req.URL.Path == MetricsPath {
+			next.ServeHTTP(res, req)
+			return
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+			defer func() { <-inFlight }()
+			next.ServeHTTP(res, req)
+		default:
+			logger := cfg.logger.WithFields(log.Fields{
+				"path":        req.URL.Path,
+				"requestID":   requestID(req),
+				"maxInFlight": cfg.maxInFlight,
+			})
+			res.Header().Set("Retry-After", strconv.Itoa(overloadRetryAfter))
+			writeErrorResponse(logger, res, req, http.StatusServiceUnavailable, Error{
+				Code:    ErrorCodeOverloaded,
+				Message: "service overloaded, try again later",
+			})
+			logger.Warning("request rejected, service overloaded")
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+	"github.com/shopspring/decimal"
+)
+
+func TestLoadShedding(t *testing.T) {
+	const maxInFlight = 2
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	service := api.New(func(
+		ctx context.Context,
+		totalLoanAmount decimal.Decimal,
+		annualInterestRate decimal.Decimal,
+		durationInMonths int,
+		start time.Time,
+		currency loan.Currency,
+	) ([]loan.Payment, error) {
+		started <- struct{}{}
+		<-release
+		return loan.CreatePlanForCurrencyContext(ctx, totalLoanAmount, annualInterestRate, durationInMonths, start, currency)
+	}, api.WithMaxInFlight(maxInFlight))
+
+	blocked := sync.WaitGroup{}
+	for i := 0; i < maxInFlight; i++ {
+		blocked.Add(1)
+		go func() {
+			defer blocked.Done()
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, validCreateLoanRequestBody(t)))
+			if res.Code != http.StatusOK {
+				t.Errorf("got response %d want %d on blocked request; body: %s", res.Code, http.StatusOK, res.Body)
+			}
+		}()
+	}
+	for i := 0; i < maxInFlight; i++ {
+		<-started
+	}
+
+	res := httptest.NewRecorder()
+	service.ServeHTTP(res, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, validCreateLoanRequestBody(t)))
+
+	if res.Code != http.StatusServiceUnavailable {
+		t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusServiceUnavailable, res.Body)
+	}
+	if got := res.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("got Retry-After %q; want %q", got, "1")
+	}
+
+	errResponse := api.ErrorResponse{}
+	fromJSON(t, res.Body, &errResponse)
+	if errResponse.Error.Code != api.ErrorCodeOverloaded {
+		t.Errorf("got error code %q; want %q", errResponse.Error.Code, api.ErrorCodeOverloaded)
+	}
+
+	healthRes := httptest.NewRecorder()
+	service.ServeHTTP(healthRes, httptest.NewRequest(http.MethodGet, api.HealthPath, nil))
+	if healthRes.Code != http.StatusOK {
+		t.Errorf("got health response %d want %d while overloaded", healthRes.Code, http.StatusOK)
+	}
+
+	close(release)
+	blocked.Wait()
+
+	go func() { <-started }()
+	res = httptest.NewRecorder()
+	service.ServeHTTP(res, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, validCreateLoanRequestBody(t)))
+	if res.Code != http.StatusOK {
+		t.Errorf("got response %d want %d after the load is gone; body: %s", res.Code, http.StatusOK, res.Body)
+	}
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/katcipis/loaner/money"
+)
+
+// localeQueryParam is the query parameter used by clients to opt in
+// to have the money values of the response formatted according to the
+// locale informed on the Accept-Language header.
+const localeQueryParam = "locale"
+
+// numberFormat has the separators used to format numbers on a locale.
+type numberFormat struct {
+	decimalSep string
+	groupSep   string
+}
+
+// numberFormats are the supported number formats, indexed by
+// lower case language tags. Tags with only the language are used
+// when there is no specific format for the region.
+var numberFormats = map[string]numberFormat{
+	"en":    {decimalSep: ".", groupSep: ","},
+	"de":    {decimalSep: ",", groupSep: "."},
+	"de-ch": {decimalSep: ".", groupSep: "'"},
+	"es":    {decimalSep: ",", groupSep: "."},
+	"fr":    {decimalSep: ",", groupSep: " "},
+	"it":    {decimalSep: ",", groupSep: "."},
+	"nl":    {decimalSep: ",", groupSep: "."},
+	"pt":    {decimalSep: ",", groupSep: "."},
+}
+
+// negotiateLocale returns the first language tag of the Accept-Language
+// header of the request that has a supported number format.
+// It returns false if none of the languages are supported.
+func negotiateLocale(req *http.Request) (string, numberFormat, bool) {
+	for _, header := range req.Header.Values("Accept-Language") {
+		for _, lang := range strings.Split(header, ",") {
+			params := strings.Split(lang, ";")
+			tag := strings.TrimSpace(params[0])
+			if tag == "" || tag == "*" || refused(params[1:]) {
+				continue
+			}
+
+			lowerTag := strings.ToLower(tag)
+			if format, ok := numberFormats[lowerTag]; ok {
+				return tag, format, true
+			}
+			primary := strings.SplitN(lowerTag, "-", 2)[0]
+			if format, ok := numberFormats[primary]; ok {
+				return tag, format, true
+			}
+		}
+	}
+	return "", numberFormat{}, false
+}
+
+// localize formats all the money values of the response with the number format.
+func (f numberFormat) localize(resp CreateLoanPlanResponse) CreateLoanPlanResponse {
+	return mapAmounts(resp, func(amount money.Amount) money.Amount {
+		return amount.Localized(f.decimalSep, f.groupSep)
+	})
+}
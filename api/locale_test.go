@@ -0,0 +1,148 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestLoanPlanCreationLocalized(t *testing.T) {
+	type Test struct {
+		name               string
+		query              string
+		acceptLanguage     string
+		wantContentLang    string
+		wantPayments       [][]string
+		wantSummaryPayment string
+	}
+
+	invariant := [][]string{
+		{"1001.25", "1.67", "999.58", "2000", "1000.42"},
+		{"1001.25", "0.83", "1000.42", "1000.42", "0"},
+	}
+	enUS := [][]string{
+		{"1,001.25", "1.67", "999.58", "2,000", "1,000.42"},
+		{"1,001.25", "0.83", "1,000.42", "1,000.42", "0"},
+	}
+	deDE := [][]string{
+		{"1.001,25", "1,67", "999,58", "2.000", "1.000,42"},
+		{"1.001,25", "0,83", "1.000,42", "1.000,42", "0"},
+	}
+
+	tests := []Test{
+		{
+			name:               "EnglishUS",
+			query:              "?locale=true",
+			acceptLanguage:     "en-US",
+			wantContentLang:    "en-US",
+			wantPayments:       enUS,
+			wantSummaryPayment: "2,002.5",
+		},
+		{
+			name:               "GermanGermany",
+			query:              "?locale=true",
+			acceptLanguage:     "de-DE",
+			wantContentLang:    "de-DE",
+			wantPayments:       deDE,
+			wantSummaryPayment: "2.002,5",
+		},
+		{
+			name:               "FirstSupportedLanguage",
+			query:              "?locale=true",
+			acceptLanguage:     "ja-JP, de-DE;q=0.9, en-US;q=0.8",
+			wantContentLang:    "de-DE",
+			wantPayments:       deDE,
+			wantSummaryPayment: "2.002,5",
+		},
+		{
+			name:               "RefusedLanguageIsIgnored",
+			query:              "?locale=true",
+			acceptLanguage:     "de-DE;q=0, en-US",
+			wantContentLang:    "en-US",
+			wantPayments:       enUS,
+			wantSummaryPayment: "2,002.5",
+		},
+		{
+			name:               "InvariantWithoutOptIn",
+			acceptLanguage:     "de-DE",
+			wantPayments:       invariant,
+			wantSummaryPayment: "2002.5",
+		},
+		{
+			name:               "InvariantWithoutAcceptLanguage",
+			query:              "?locale=true",
+			wantPayments:       invariant,
+			wantSummaryPayment: "2002.5",
+		},
+		{
+			name:               "InvariantOnUnsupportedLanguage",
+			query:              "?locale=true",
+			acceptLanguage:     "ja-JP",
+			wantPayments:       invariant,
+			wantSummaryPayment: "2002.5",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(loan.CreatePlanForCurrencyContext)
+			server := httptest.NewServer(service)
+			defer server.Close()
+
+			request := newRequest(t, http.MethodPost, server.URL+api.CreateLoanPlanPath+test.query,
+				toJSON(t, api.CreateLoanPlanRequest{
+					LoanAmount:  amountPtr(t, "2000.0"),
+					NominalRate: amountPtr(t, "1.0"),
+					Duration:    months(2),
+					StartDate:   "2018-01-01T00:00:00Z",
+				}))
+			if test.acceptLanguage != "" {
+				request.Header.Set("Accept-Language", test.acceptLanguage)
+			}
+
+			res, err := server.Client().Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("got response %d want %d", res.StatusCode, http.StatusOK)
+			}
+
+			if got := res.Header.Get("Content-Language"); got != test.wantContentLang {
+				t.Errorf("got Content-Language %q; want %q", got, test.wantContentLang)
+			}
+
+			got := formattedLoanPlan{}
+			fromJSON(t, res.Body, &got)
+
+			gotPayments := [][]string{}
+			for _, p := range got.BorrowerPayments {
+				gotPayments = append(gotPayments, []string{
+					p.PaymentAmount,
+					p.Interest,
+					p.Principal,
+					p.InitialOutstandingPrincipal,
+					p.RemainingOutstandingPrincipal,
+				})
+			}
+
+			if diff := cmp.Diff(test.wantPayments, gotPayments); diff != "" {
+				t.Errorf("payments mismatch (-want +got):\n%s", diff)
+			}
+
+			if got.Summary.TotalPayment != test.wantSummaryPayment {
+				t.Errorf("got summary total payment %q; want %q", got.Summary.TotalPayment, test.wantSummaryPayment)
+			}
+
+			if got.BorrowerPayments[0].Date != "2018-01-01T00:00:00Z" {
+				t.Errorf("dates must not be localized, got %q", got.BorrowerPayments[0].Date)
+			}
+		})
+	}
+}
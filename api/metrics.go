@@ -0,0 +1,152 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// MetricsPath is the resource path where metrics are exposed
+	// on the Prometheus text format (when metrics are enabled).
+	MetricsPath = "/metrics"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the
+// request duration histogram buckets (same as Prometheus defaults).
+var durationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// metrics is a minimal in-memory implementation of a request counter and a
+// request duration histogram that can be exposed on the Prometheus text
+// format. It avoids adding the whole Prometheus client as a dependency
+// for just two metrics.
+type metrics struct {
+	mu        sync.Mutex
+	requests  map[requestsKey]uint64
+	durations map[string]*histogram
+}
+
+type requestsKey struct {
+	path string
+	code int
+}
+
+type histogram struct {
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests:  map[requestsKey]uint64{},
+		durations: map[string]*histogram{},
+	}
+}
+
+func (m *metrics) observe(path string, code int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestsKey{path: path, code: code}]++
+
+	h, ok := m.durations[path]
+	if !ok {
+		h = &histogram{buckets: make([]uint64, len(durationBuckets))}
+		m.durations[path] = h
+	}
+
+	seconds := duration.Seconds()
+	for i, upperBound := range durationBuckets {
+		if seconds <= upperBound {
+			h.buckets[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// writeTo writes all metrics on the Prometheus text format.
+func (m *metrics) writeTo(buf *bytes.Buffer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	requestsKeys := make([]requestsKey, 0, len(m.requests))
+	for k := range m.requests {
+		requestsKeys = append(requestsKeys, k)
+	}
+	sort.Slice(requestsKeys, func(i, j int) bool {
+		if requestsKeys[i].path != requestsKeys[j].path {
+			return requestsKeys[i].path < requestsKeys[j].path
+		}
+		return requestsKeys[i].code < requestsKeys[j].code
+	})
+
+	fmt.Fprintln(buf, "# HELP loaner_http_requests_total Total number of HTTP requests by path and status code.")
+	fmt.Fprintln(buf, "# TYPE loaner_http_requests_total counter")
+	for _, k := range requestsKeys {
+		fmt.Fprintf(buf, "loaner_http_requests_total{code=\"%d\",path=%q} %d\n", k.code, k.path, m.requests[k])
+	}
+
+	paths := make([]string, 0, len(m.durations))
+	for path := range m.durations {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	fmt.Fprintln(buf, "# HELP loaner_http_request_duration_seconds Duration of HTTP requests by path.")
+	fmt.Fprintln(buf, "# TYPE loaner_http_request_duration_seconds histogram")
+	for _, path := range paths {
+		h := m.durations[path]
+		for i, upperBound := range durationBuckets {
+			le := strconv.FormatFloat(upperBound, 'g', -1, 64)
+			fmt.Fprintf(buf, "loaner_http_request_duration_seconds_bucket{path=%q,le=%q} %d\n", path, le, h.buckets[i])
+		}
+		fmt.Fprintf(buf, "loaner_http_request_duration_seconds_bucket{path=%q,le=\"+Inf\"} %d\n", path, h.count)
+		fmt.Fprintf(buf, "loaner_http_request_duration_seconds_sum{path=%q} %v\n", path, h.sum)
+		fmt.Fprintf(buf, "loaner_http_request_duration_seconds_count{path=%q} %d\n", path, h.count)
+	}
+}
+
+// withMetrics records metrics for all the requests handled by the given mux.
+// Requests are labeled by the mux pattern that matched them, so unknown paths
+// don't generate an unbounded number of metrics.
+func withMetrics(m *metrics, mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		_, path := mux.Handler(req)
+		if path == "" || path == notFoundPattern {
+			path = "unmatched"
+		}
+
+		recorder := &statusRecorder{ResponseWriter: res, status: http.StatusOK}
+		start := time.Now()
+		mux.ServeHTTP(recorder, req)
+		m.observe(path, recorder.status, time.Since(start))
+	})
+}
+
+func metricsHandler(cfg config, m *metrics) http.HandlerFunc {
+	pathLogger := cfg.logger.WithFields(log.Fields{"path": MetricsPath})
+
+	return func(res http.ResponseWriter, req *http.Request) {
+		logger := pathLogger.WithFields(log.Fields{"requestID": requestID(req)})
+
+		if req.Method != http.MethodGet {
+			handleMethodNotAllowed(logger, res, req, http.MethodGet)
+			return
+		}
+
+		buf := &bytes.Buffer{}
+		m.writeTo(buf)
+
+		res.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		res.WriteHeader(http.StatusOK)
+		logResponseBodyWrite(logger, res, buf.Bytes())
+	}
+}
@@ -0,0 +1,117 @@
+package api_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+	"github.com/shopspring/decimal"
+)
+
+func TestMetrics(t *testing.T) {
+	service := api.New(func(
+		ctx context.Context,
+		totalLoanAmount decimal.Decimal,
+		annualInterestRate decimal.Decimal,
+		durationInMonths int,
+		start time.Time,
+		currency loan.Currency,
+	) ([]loan.Payment, error) {
+		return []loan.Payment{}, nil
+	}, api.WithMetrics())
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	requestsCounter := `loaner_http_requests_total{code="200",path="/loan-plan"}`
+
+	metrics := getMetrics(t, server)
+	if strings.Contains(metrics, requestsCounter) {
+		t.Fatalf("unexpected requests counter before any request:\n%s", metrics)
+	}
+
+	for i := 0; i < 2; i++ {
+		createLoanPlanURL := server.URL + api.CreateLoanPlanPath
+		request := newRequest(t, http.MethodPost, createLoanPlanURL, validCreateLoanRequestBody(t))
+		res, err := server.Client().Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("got response %d want %d", res.StatusCode, http.StatusOK)
+		}
+	}
+
+	request := newRequest(t, http.MethodPut, server.URL+api.CreateLoanPlanPath, nil)
+	res, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	metrics = getMetrics(t, server)
+	wantLines := []string{
+		requestsCounter + " 2",
+		`loaner_http_requests_total{code="405",path="/loan-plan"} 1`,
+		`loaner_http_request_duration_seconds_bucket{path="/loan-plan",le="+Inf"} 3`,
+		`loaner_http_request_duration_seconds_count{path="/loan-plan"} 3`,
+	}
+
+	for _, wantLine := range wantLines {
+		if !strings.Contains(metrics, wantLine+"\n") {
+			t.Errorf("metrics missing line %q:\n%s", wantLine, metrics)
+		}
+	}
+}
+
+func TestMetricsAreDisabledByDefault(t *testing.T) {
+	service := api.New(func(
+		ctx context.Context,
+		totalLoanAmount decimal.Decimal,
+		annualInterestRate decimal.Decimal,
+		durationInMonths int,
+		start time.Time,
+		currency loan.Currency,
+	) ([]loan.Payment, error) {
+		return []loan.Payment{}, nil
+	})
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	res, err := server.Client().Get(server.URL + api.MetricsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("got response %d want %d", res.StatusCode, http.StatusNotFound)
+	}
+}
+
+func getMetrics(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+
+	res, err := server.Client().Get(server.URL + api.MetricsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got response %d want %d", res.StatusCode, http.StatusOK)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// RequestIDHeader is the header used to inform the ID of a request.
+// If a request already has this header its value is used as the
+// request ID, otherwise a new one is generated. The ID is always sent
+// back on the response with this same header.
+const RequestIDHeader = "X-Request-ID"
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	cacheStatusKey
+)
+
+// maxRequestIDSize limits the size of request IDs informed by clients
+// since they are echoed on responses and logs.
+const maxRequestIDSize = 128
+
+// withRequestID assigns an ID to each request, honoring the one
+// informed by the client, if any.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDSize {
+			id = newRequestID()
+		}
+		res.Header().Set(RequestIDHeader, id)
+		ctx := context.WithValue(req.Context(), requestIDKey, id)
+		next.ServeHTTP(res, req.WithContext(ctx))
+	})
+}
+
+// requestID returns the ID of the request.
+func requestID(req *http.Request) string {
+	id, _ := req.Context().Value(requestIDKey).(string)
+	return id
+}
+
+// newRequestID generates a random (version 4) UUID.
+func newRequestID() string {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		// Very unlikely, but a less unique ID is better than none.
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:])
+}
+
+// statusRecorder records the status code written on the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// notFoundPattern is the mux pattern that matches all
+// the paths that are not matched by any other pattern.
+const notFoundPattern = "/"
+
+// notFoundHandler answers requests to paths that don't exist with
+// the same JSON error response of the other resources, instead of
+// the plain text one of the http package.
+func notFoundHandler(cfg config) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		logger := cfg.logger.WithFields(log.Fields{
+			"path":      req.URL.Path,
+			"requestID": requestID(req),
+		})
+
+		msg := fmt.Sprintf("resource %q not found", req.URL.Path)
+		writeErrorResponse(logger, res, req, http.StatusNotFound, Error{
+			Code:    ErrorCodeNotFound,
+			Message: msg,
+		})
+		logger.WithFields(log.Fields{"error": msg}).Warning("resource not found")
+	}
+}
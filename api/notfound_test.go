@@ -0,0 +1,55 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestNotFound(t *testing.T) {
+	type Test struct {
+		name   string
+		method string
+		path   string
+	}
+
+	tests := []Test{
+		{name: "Nonexistent", method: http.MethodGet, path: "/nonexistent"},
+		{name: "NonexistentOnPost", method: http.MethodPost, path: "/nonexistent"},
+		{name: "Root", method: http.MethodGet, path: "/"},
+		{name: "SubpathOfLoanPlan", method: http.MethodGet, path: api.CreateLoanPlanPath + "/nonexistent"},
+		{name: "TrailingSlash", method: http.MethodPost, path: api.CreateLoanPlanPath + "/"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(loan.CreatePlanForCurrencyContext)
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, httptest.NewRequest(test.method, test.path, nil))
+
+			if res.Code != http.StatusNotFound {
+				t.Fatalf("got response %d want %d", res.Code, http.StatusNotFound)
+			}
+			if got := res.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("got content type %q; want application/json", got)
+			}
+
+			errResponse := api.ErrorResponse{}
+			fromJSON(t, res.Body, &errResponse)
+
+			if errResponse.Error.Code != api.ErrorCodeNotFound {
+				t.Errorf("got error code %q; want %q", errResponse.Error.Code, api.ErrorCodeNotFound)
+			}
+			if !strings.Contains(errResponse.Error.Message, test.path) {
+				t.Errorf("got error message %q; want it to inform the path %q", errResponse.Error.Message, test.path)
+			}
+			if errResponse.Error.TraceID == "" {
+				t.Error("missing trace ID on error response")
+			}
+		})
+	}
+}
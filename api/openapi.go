@@ -0,0 +1,471 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/katcipis/loaner/money"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// OpenAPIPath is the resource path where the OpenAPI 3
+	// document describing the API is served.
+	OpenAPIPath = "/openapi.json"
+)
+
+// openAPIHandler serves the OpenAPI document of the service.
+// The document is built only once, when the handler is created.
+func openAPIHandler(cfg config) http.HandlerFunc {
+	pathLogger := cfg.logger.WithFields(log.Fields{"path": OpenAPIPath})
+	doc := toJSON(pathLogger, newOpenAPIDoc(cfg))
+
+	return func(res http.ResponseWriter, req *http.Request) {
+		logger := pathLogger.WithFields(log.Fields{"requestID": requestID(req)})
+
+		if req.Method != http.MethodGet {
+			handleMethodNotAllowed(logger, res, req, http.MethodGet)
+			return
+		}
+
+		res.Header().Set("Content-Type", jsonContentType)
+		res.WriteHeader(http.StatusOK)
+		logResponseBodyWrite(logger, res, doc)
+	}
+}
+
+// newOpenAPIDoc builds the OpenAPI document of the service.
+//
+// The schemas are generated from the request/response types through
+// reflection (using their JSON tags), so the document is always in sync
+// with what the handlers actually send and receive.
+func newOpenAPIDoc(cfg config) map[string]interface{} {
+	schemas := openAPISchemas{}
+
+	version := orUnknown(cfg.version)
+
+	errResponses := func(statusCodes ...int) map[string]interface{} {
+		responses := map[string]interface{}{}
+		for _, statusCode := range statusCodes {
+			responses[strconv.Itoa(statusCode)] = jsonResponse(
+				http.StatusText(statusCode),
+				schemas.ref(ErrorResponse{}),
+			)
+		}
+		return responses
+	}
+
+	createLoanPlanResponses := errResponses(
+		http.StatusBadRequest,
+		http.StatusMethodNotAllowed,
+		http.StatusRequestEntityTooLarge,
+		http.StatusUnsupportedMediaType,
+		http.StatusInternalServerError,
+	)
+	createLoanPlanResponses["200"] = map[string]interface{}{
+		"description": "The loan plan",
+		"content": map[string]interface{}{
+			jsonContentType: map[string]interface{}{"schema": schemas.ref(CreateLoanPlanResponse{})},
+			csvContentType:  map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+			pdfContentType:  map[string]interface{}{"schema": map[string]interface{}{"type": "string", "format": "binary"}},
+		},
+	}
+
+	createLoanPlanGetResponses := errResponses(http.StatusBadRequest, http.StatusInternalServerError)
+	createLoanPlanGetResponses["200"] = createLoanPlanResponses["200"]
+
+	batchResponses := errResponses(
+		http.StatusBadRequest,
+		http.StatusMethodNotAllowed,
+		http.StatusRequestEntityTooLarge,
+		http.StatusUnsupportedMediaType,
+	)
+	batchResponses["200"] = jsonResponse("One result for each item of the request, on the same position", map[string]interface{}{
+		"type":  "array",
+		"items": schemas.ref(LoanPlanResult{}),
+	})
+
+	batchCSVResponses := errResponses(
+		http.StatusBadRequest,
+		http.StatusMethodNotAllowed,
+		http.StatusRequestEntityTooLarge,
+		http.StatusUnsupportedMediaType,
+	)
+	batchCSVResponses["200"] = map[string]interface{}{
+		"description": "The payments of the loan plan of each row of the request, on the same order, or the error of the row",
+		"content": map[string]interface{}{
+			csvContentType: map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+		},
+	}
+
+	compareResponses := errResponses(
+		http.StatusBadRequest,
+		http.StatusMethodNotAllowed,
+		http.StatusRequestEntityTooLarge,
+		http.StatusUnsupportedMediaType,
+		http.StatusInternalServerError,
+	)
+	compareResponses["200"] = jsonResponse("The loan plans of both scenarios and their differences", schemas.ref(CompareLoanPlansResponse{}))
+
+	paymentResponses := errResponses(
+		http.StatusBadRequest,
+		http.StatusNotFound,
+		http.StatusMethodNotAllowed,
+		http.StatusInternalServerError,
+	)
+	paymentResponses["200"] = jsonResponse("The payment of the loan plan", schemas.ref(BorrowerPayment{}))
+
+	paymentParameters := append(queryParameters(reflect.TypeOf(CreateLoanPlanRequest{})), map[string]interface{}{
+		"name":        paymentIndexQueryParam,
+		"in":          "query",
+		"required":    true,
+		"description": "Zero based index of the payment on the loan plan",
+		"schema":      map[string]interface{}{"type": "integer", "minimum": 0},
+	})
+
+	validateResponses := errResponses(
+		http.StatusBadRequest,
+		http.StatusMethodNotAllowed,
+		http.StatusRequestEntityTooLarge,
+		http.StatusUnsupportedMediaType,
+		http.StatusInternalServerError,
+	)
+	validateResponses["204"] = map[string]interface{}{"description": "The loan plan parameters are valid"}
+
+	aprResponses := errResponses(
+		http.StatusBadRequest,
+		http.StatusMethodNotAllowed,
+		http.StatusRequestEntityTooLarge,
+		http.StatusUnsupportedMediaType,
+		http.StatusInternalServerError,
+	)
+	aprResponses["200"] = jsonResponse("The APR of the loan plan", schemas.ref(LoanPlanAPRResponse{}))
+
+	aprGetResponses := errResponses(http.StatusBadRequest, http.StatusInternalServerError)
+	aprGetResponses["200"] = aprResponses["200"]
+
+	seriesResponses := errResponses(
+		http.StatusBadRequest,
+		http.StatusMethodNotAllowed,
+		http.StatusInternalServerError,
+	)
+	seriesResponses["200"] = jsonResponse("The loan plan as series", schemas.ref(LoanPlanSeriesResponse{}))
+
+	schemaResponses := errResponses(http.StatusMethodNotAllowed)
+	schemaResponses["200"] = map[string]interface{}{
+		"description": "The JSON Schema of the loan plan creation request body",
+		"content": map[string]interface{}{
+			jsonSchemaContentType: map[string]interface{}{"schema": map[string]interface{}{"type": "object"}},
+		},
+	}
+
+	versionResponses := errResponses(http.StatusMethodNotAllowed)
+	versionResponses["200"] = jsonResponse("The version of the service", schemas.ref(VersionResponse{}))
+
+	capabilitiesResponses := errResponses(http.StatusMethodNotAllowed)
+	capabilitiesResponses["200"] = jsonResponse("The loan features supported by the service", schemas.ref(CapabilitiesResponse{}))
+
+	healthResponses := errResponses(http.StatusMethodNotAllowed)
+	healthResponses["200"] = jsonResponse("The service is healthy", schemas.ref(HealthResponse{}))
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Loaner API",
+			"version": version,
+		},
+		"paths": map[string]interface{}{
+			CreateLoanPlanPath: map[string]interface{}{
+				"post": map[string]interface{}{
+					"summary":     "Create a loan plan",
+					"operationId": "createLoanPlan",
+					"parameters":  responseParameters(),
+					"requestBody": jsonRequestBody(schemas.ref(CreateLoanPlanRequest{})),
+					"responses":   createLoanPlanResponses,
+				},
+				"get": map[string]interface{}{
+					"summary":     "Create a loan plan from query parameters",
+					"operationId": "createLoanPlanFromQuery",
+					"parameters":  append(queryParameters(reflect.TypeOf(CreateLoanPlanRequest{})), responseParameters()...),
+					"responses":   createLoanPlanGetResponses,
+				},
+			},
+			CreateLoanPlansPath: map[string]interface{}{
+				"post": map[string]interface{}{
+					"summary":     "Create multiple loan plans",
+					"operationId": "createLoanPlans",
+					"requestBody": jsonRequestBody(map[string]interface{}{
+						"type":     "array",
+						"maxItems": MaxBatchSize,
+						"items":    schemas.ref(CreateLoanPlanRequest{}),
+					}),
+					"responses": batchResponses,
+				},
+			},
+			CreateLoanPlansCSVPath: map[string]interface{}{
+				"post": map[string]interface{}{
+					"summary":     "Create multiple loan plans from a CSV",
+					"operationId": "createLoanPlansFromCSV",
+					"requestBody": map[string]interface{}{
+						"required": true,
+						"content": map[string]interface{}{
+							csvContentType: map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+						},
+					},
+					"responses": batchCSVResponses,
+				},
+			},
+			CompareLoanPlansPath: map[string]interface{}{
+				"post": map[string]interface{}{
+					"summary":     "Compare the loan plans of two loan scenarios",
+					"operationId": "compareLoanPlans",
+					"requestBody": jsonRequestBody(schemas.ref(CompareLoanPlansRequest{})),
+					"responses":   compareResponses,
+				},
+			},
+			LoanPlanPaymentPath: map[string]interface{}{
+				"get": map[string]interface{}{
+					"summary":     "Get a single payment of a loan plan",
+					"operationId": "getLoanPlanPayment",
+					"parameters":  paymentParameters,
+					"responses":   paymentResponses,
+				},
+			},
+			ValidateLoanPlanPath: map[string]interface{}{
+				"post": map[string]interface{}{
+					"summary":     "Validate the parameters of a loan plan without creating it",
+					"operationId": "validateLoanPlan",
+					"requestBody": jsonRequestBody(schemas.ref(CreateLoanPlanRequest{})),
+					"responses":   validateResponses,
+				},
+			},
+			LoanPlanAPRPath: map[string]interface{}{
+				"post": map[string]interface{}{
+					"summary":     "Get the annual percentage rate (APR) of a loan plan",
+					"operationId": "getLoanPlanAPR",
+					"requestBody": jsonRequestBody(schemas.ref(CreateLoanPlanRequest{})),
+					"responses":   aprResponses,
+				},
+				"get": map[string]interface{}{
+					"summary":     "Get the annual percentage rate (APR) of a loan plan from query parameters",
+					"operationId": "getLoanPlanAPRFromQuery",
+					"parameters":  queryParameters(reflect.TypeOf(CreateLoanPlanRequest{})),
+					"responses":   aprGetResponses,
+				},
+			},
+			LoanPlanSeriesPath: map[string]interface{}{
+				"get": map[string]interface{}{
+					"summary":     "Get the loan plan as series, ready to be used on charts",
+					"operationId": "getLoanPlanSeries",
+					"parameters":  queryParameters(reflect.TypeOf(CreateLoanPlanRequest{})),
+					"responses":   seriesResponses,
+				},
+			},
+			LoanPlanSchemaPath: map[string]interface{}{
+				"get": map[string]interface{}{
+					"summary":     "Get the JSON Schema of the loan plan creation request body",
+					"operationId": "getLoanPlanSchema",
+					"responses":   schemaResponses,
+				},
+			},
+			VersionPath: map[string]interface{}{
+				"get": map[string]interface{}{
+					"summary":     "Get the version and build information of the service",
+					"operationId": "version",
+					"responses":   versionResponses,
+				},
+			},
+			CapabilitiesPath: map[string]interface{}{
+				"get": map[string]interface{}{
+					"summary":     "List the loan features supported by the service",
+					"operationId": "capabilities",
+					"responses":   capabilitiesResponses,
+				},
+			},
+			HealthPath: map[string]interface{}{
+				"get": map[string]interface{}{
+					"summary":     "Check the health of the service",
+					"operationId": "health",
+					"responses":   healthResponses,
+				},
+			},
+		},
+		"components": map[string]interface{}{
+			"schemas": schemas,
+		},
+	}
+}
+
+// openAPISchemas holds the named schemas of the OpenAPI document,
+// indexed by the name of the Go type that they describe.
+type openAPISchemas map[string]interface{}
+
+// ref returns a reference to the schema of the type of the given value,
+// registering it (and all the types it depends on) if necessary.
+func (s openAPISchemas) ref(v interface{}) map[string]interface{} {
+	return s.schemaOf(reflect.TypeOf(v))
+}
+
+func (s openAPISchemas) schemaOf(t reflect.Type) map[string]interface{} {
+	if t == reflect.TypeOf(money.Amount{}) {
+		// Money amounts are written as JSON strings, like "1001.25".
+		return map[string]interface{}{"type": "string"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return s.schemaOf(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": s.schemaOf(t.Elem()),
+		}
+	case reflect.Struct:
+		ref := map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+		if _, ok := s[t.Name()]; ok {
+			return ref
+		}
+		// Registered before the fields are inspected
+		// to support recursive types.
+		s[t.Name()] = nil
+
+		properties := map[string]interface{}{}
+		required := []string{}
+		for _, field := range jsonFields(t) {
+			properties[field.name] = s.schemaOf(field.typ)
+			if !field.optional {
+				required = append(required, field.name)
+			}
+		}
+
+		schema := map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		s[t.Name()] = schema
+		return ref
+	}
+	return map[string]interface{}{}
+}
+
+type jsonField struct {
+	name     string
+	typ      reflect.Type
+	optional bool
+}
+
+// jsonFields returns the fields of the struct type
+// as they are represented on JSON.
+func jsonFields(t reflect.Type) []jsonField {
+	fields := []jsonField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		opts := strings.Split(tag, ",")
+		if opts[0] != "" {
+			name = opts[0]
+		}
+
+		optional := false
+		for _, opt := range opts[1:] {
+			if opt == "omitempty" {
+				optional = true
+			}
+		}
+
+		fields = append(fields, jsonField{name: name, typ: field.Type, optional: optional})
+	}
+	return fields
+}
+
+func queryParameters(t reflect.Type) []interface{} {
+	params := []interface{}{}
+	for _, field := range jsonFields(t) {
+		params = append(params, map[string]interface{}{
+			"name":     field.name,
+			"in":       "query",
+			"required": !field.optional,
+			"schema":   openAPISchemas{}.schemaOf(field.typ),
+		})
+	}
+	return params
+}
+
+// responseParameters are the query parameters used to customize
+// the response of a loan plan, like requesting only a page of the
+// payments, on which order and how money values are formatted.
+func responseParameters() []interface{} {
+	return []interface{}{
+		map[string]interface{}{
+			"name":        offsetQueryParam,
+			"in":          "query",
+			"required":    false,
+			"description": "Number of payments skipped from the start of the loan plan",
+			"schema":      map[string]interface{}{"type": "integer", "minimum": 0},
+		},
+		map[string]interface{}{
+			"name":        limitQueryParam,
+			"in":          "query",
+			"required":    false,
+			"description": "Max number of payments returned, all by default",
+			"schema":      map[string]interface{}{"type": "integer", "minimum": 1},
+		},
+		map[string]interface{}{
+			"name":        orderQueryParam,
+			"in":          "query",
+			"required":    false,
+			"description": "Order of the payments by date, ascending by default",
+			"schema": map[string]interface{}{
+				"type":    "string",
+				"enum":    []string{ascendingOrder, descendingOrder},
+				"default": ascendingOrder,
+			},
+		},
+		map[string]interface{}{
+			"name":        amountFormatQueryParam,
+			"in":          "query",
+			"required":    false,
+			"description": "Format of the money values, fixed has always the minor units of the currency (like 2000.00)",
+			"schema": map[string]interface{}{
+				"type":    "string",
+				"enum":    []string{plainAmountFormat, fixedAmountFormat},
+				"default": plainAmountFormat,
+			},
+		},
+	}
+}
+
+func jsonRequestBody(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"required": true,
+		"content": map[string]interface{}{
+			jsonContentType: map[string]interface{}{"schema": schema},
+		},
+	}
+}
+
+func jsonResponse(description string, schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			jsonContentType: map[string]interface{}{"schema": schema},
+		},
+	}
+}
@@ -0,0 +1,198 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+	"github.com/shopspring/decimal"
+)
+
+type openAPISchema struct {
+	Ref        string                   `json:"$ref"`
+	Type       string                   `json:"type"`
+	Properties map[string]openAPISchema `json:"properties"`
+	Required   []string                 `json:"required"`
+	Items      *openAPISchema           `json:"items"`
+}
+
+type openAPIContent map[string]struct {
+	Schema openAPISchema `json:"schema"`
+}
+
+type openAPIOperation struct {
+	RequestBody struct {
+		Content openAPIContent `json:"content"`
+	} `json:"requestBody"`
+	Parameters []struct {
+		Name     string `json:"name"`
+		In       string `json:"in"`
+		Required bool   `json:"required"`
+	} `json:"parameters"`
+	Responses map[string]struct {
+		Content openAPIContent `json:"content"`
+	} `json:"responses"`
+}
+
+type openAPIDoc struct {
+	OpenAPI string `json:"openapi"`
+	Info    struct {
+		Version string `json:"version"`
+	} `json:"info"`
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		Schemas map[string]openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+func TestOpenAPI(t *testing.T) {
+	service := api.New(func(
+		ctx context.Context,
+		totalLoanAmount decimal.Decimal,
+		annualInterestRate decimal.Decimal,
+		durationInMonths int,
+		start time.Time,
+		currency loan.Currency,
+	) ([]loan.Payment, error) {
+		t.Error("OpenAPI document must not compute loan plans")
+		return nil, nil
+	}, api.WithVersion("1.12.0"))
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	res, err := server.Client().Get(server.URL + api.OpenAPIPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got response %d want %d", res.StatusCode, http.StatusOK)
+	}
+
+	doc := openAPIDoc{}
+	fromJSON(t, res.Body, &doc)
+
+	if doc.OpenAPI != "3.0.3" {
+		t.Errorf("got OpenAPI version %q; want 3.0.3", doc.OpenAPI)
+	}
+	if doc.Info.Version != "1.12.0" {
+		t.Errorf("got API version %q; want 1.12.0", doc.Info.Version)
+	}
+
+	post, ok := doc.Paths[api.CreateLoanPlanPath]["post"]
+	if !ok {
+		t.Fatalf("missing POST %s on paths: %v", api.CreateLoanPlanPath, doc.Paths)
+	}
+
+	wantRef := func(got openAPISchema, schemaName string) {
+		t.Helper()
+		if want := "#/components/schemas/" + schemaName; got.Ref != want {
+			t.Errorf("got schema ref %q; want %q", got.Ref, want)
+		}
+	}
+
+	wantRef(post.RequestBody.Content["application/json"].Schema, "CreateLoanPlanRequest")
+	wantRef(post.Responses["200"].Content["application/json"].Schema, "CreateLoanPlanResponse")
+	for _, statusCode := range []string{"400", "405", "413", "415", "500"} {
+		wantRef(post.Responses[statusCode].Content["application/json"].Schema, "ErrorResponse")
+	}
+
+	wantSchema := func(schemaName string, wantProperties []string, wantRequired []string) {
+		t.Helper()
+
+		schema, ok := doc.Components.Schemas[schemaName]
+		if !ok {
+			t.Errorf("missing schema %q", schemaName)
+			return
+		}
+
+		gotProperties := []string{}
+		for name := range schema.Properties {
+			gotProperties = append(gotProperties, name)
+		}
+		sort.Strings(gotProperties)
+		sort.Strings(wantProperties)
+
+		if diff := cmp.Diff(wantProperties, gotProperties); diff != "" {
+			t.Errorf("schema %q properties mismatch (-want +got):\n%s", schemaName, diff)
+		}
+
+		gotRequired := append([]string{}, schema.Required...)
+		sort.Strings(gotRequired)
+		sort.Strings(wantRequired)
+
+		if diff := cmp.Diff(wantRequired, gotRequired); diff != "" {
+			t.Errorf("schema %q required mismatch (-want +got):\n%s", schemaName, diff)
+		}
+	}
+
+	wantSchema("CreateLoanPlanRequest",
+		[]string{"loanAmount", "price", "downPayment", "nominalRate", "nominalRateBps", "rateIsFraction", "duration", "startDate", "endDate", "currency"},
+		[]string{},
+	)
+	wantSchema("CreateLoanPlanResponse",
+		[]string{"borrowerPayments", "total", "monthlyPayment", "payoffDate", "summary", "warnings"},
+		[]string{"borrowerPayments", "total", "monthlyPayment", "payoffDate", "summary"},
+	)
+	wantSchema("BorrowerPayment",
+		[]string{
+			"date",
+			"borrowerPaymentAmount",
+			"interest",
+			"principal",
+			"initialOutstandingPrincipal",
+			"remainingOutstandingPrincipal",
+			"fee",
+			"daysInPeriod",
+		},
+		[]string{
+			"date",
+			"borrowerPaymentAmount",
+			"interest",
+			"principal",
+			"initialOutstandingPrincipal",
+			"remainingOutstandingPrincipal",
+			"daysInPeriod",
+		},
+	)
+	wantSchema("ErrorResponse", []string{"error"}, []string{"error"})
+	wantSchema("Error",
+		[]string{"code", "message", "traceId", "fields"},
+		[]string{"code", "message", "traceId"},
+	)
+
+	duration := doc.Components.Schemas["CreateLoanPlanRequest"].Properties["duration"]
+	if duration.Type != "integer" {
+		t.Errorf("got duration type %q; want integer", duration.Type)
+	}
+
+	payments := doc.Components.Schemas["CreateLoanPlanResponse"].Properties["borrowerPayments"]
+	if payments.Type != "array" || payments.Items == nil {
+		t.Fatalf("got borrowerPayments schema %+v; want array", payments)
+	}
+	wantRef(*payments.Items, "BorrowerPayment")
+}
+
+func TestOpenAPIMethodNotAllowed(t *testing.T) {
+	service := api.New(loan.CreatePlanForCurrencyContext)
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	request := newRequest(t, http.MethodPost, server.URL+api.OpenAPIPath, nil)
+	res, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("got response %d want %d", res.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
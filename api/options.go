@@ -0,0 +1,257 @@
+package api
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+// Option customizes the service created by New.
+type Option func(*config)
+
+// DefaultMaxBodySize is the default max size, in bytes,
+// of the request bodies accepted by the service.
+const DefaultMaxBodySize = 1 << 20
+
+const (
+	// DefaultMinDuration is the default min duration, in months,
+	// of the loans accepted by the service.
+	DefaultMinDuration = 1
+	// DefaultMaxDuration is the default max duration, in months,
+	// of the loans accepted by the service.
+	DefaultMaxDuration = 360
+)
+
+// DefaultThousandsSeparator is the default thousands separator
+// accepted on the decimal fields of requests, like "5,000.00".
+const DefaultThousandsSeparator = ","
+
+// config has all the configurations of the service.
+type config struct {
+	version     string
+	buildTime   string
+	metrics     bool
+	maxBodySize int64
+	logger      *log.Logger
+	corsOrigins []string
+	limits      limits
+	webhookURL  string
+	now         func() time.Time
+	cacheSize   int
+
+	thousandsSeparator string
+	computationTimeout time.Duration
+	maxInFlight        int
+	drain              *Drain
+
+	idempotencyTTL     time.Duration
+	idempotencyMaxKeys int
+}
+
+// limits are the business limits of the loan parameters, validated
+// by the service before creating loan plans. They are kept apart from
+// the loan package, which only rejects what can't be calculated.
+// Zero amount and rate bounds mean that there is no bound.
+type limits struct {
+	minDuration int
+	maxDuration int
+	minAmount   decimal.Decimal
+	maxAmount   decimal.Decimal
+	minRate     decimal.Decimal
+	maxRate     decimal.Decimal
+}
+
+func defaultConfig() config {
+	return config{
+		maxBodySize: DefaultMaxBodySize,
+		logger:      log.StandardLogger(),
+		now:         time.Now,
+		limits: limits{
+			minDuration: DefaultMinDuration,
+			maxDuration: DefaultMaxDuration,
+		},
+
+		idempotencyTTL:     DefaultIdempotencyTTL,
+		idempotencyMaxKeys: DefaultIdempotencyMaxKeys,
+		thousandsSeparator: DefaultThousandsSeparator,
+	}
+}
+
+// WithVersion sets the version of the service, which
+// is informed on the health check and version responses.
+func WithVersion(version string) Option {
+	return func(cfg *config) {
+		cfg.version = version
+	}
+}
+
+// WithBuildTime sets when the service was built, which
+// is informed on the version response.
+func WithBuildTime(buildTime string) Option {
+	return func(cfg *config) {
+		cfg.buildTime = buildTime
+	}
+}
+
+// WithMetrics enables the metrics endpoint, exposing metrics
+// about the requests handled by the service on the Prometheus text format.
+func WithMetrics() Option {
+	return func(cfg *config) {
+		cfg.metrics = true
+	}
+}
+
+// WithMaxBodySize sets the max size, in bytes, of request bodies.
+// Requests with bigger bodies are rejected with 413 (Request Entity Too Large).
+// The default is DefaultMaxBodySize.
+func WithMaxBodySize(size int64) Option {
+	return func(cfg *config) {
+		cfg.maxBodySize = size
+	}
+}
+
+// WithLogger sets the logger used by the service, giving control over
+// where the logs go and their level. The default is the global
+// logrus logger, which is also used if the given logger is nil.
+func WithLogger(logger *log.Logger) Option {
+	return func(cfg *config) {
+		if logger != nil {
+			cfg.logger = logger
+		}
+	}
+}
+
+// WithCORS enables CORS (Cross-Origin Resource Sharing) for the given
+// origins, like "https://app.example.com", allowing browser clients on
+// these origins to call the API. The origin "*" allows any origin.
+// CORS is disabled by default.
+func WithCORS(allowedOrigins ...string) Option {
+	return func(cfg *config) {
+		cfg.corsOrigins = append(cfg.corsOrigins, allowedOrigins...)
+	}
+}
+
+// WithIdempotencyTTL sets for how long responses of requests with an
+// idempotency key are kept to be replayed. The default is DefaultIdempotencyTTL.
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(cfg *config) {
+		cfg.idempotencyTTL = ttl
+	}
+}
+
+// WithIdempotencyMaxKeys sets the max number of idempotency keys whose
+// responses are kept to be replayed. When the limit is reached the least
+// recently used key is forgotten. The default is DefaultIdempotencyMaxKeys.
+func WithIdempotencyMaxKeys(maxKeys int) Option {
+	return func(cfg *config) {
+		cfg.idempotencyMaxKeys = maxKeys
+	}
+}
+
+// WithDurationBounds sets the min and max duration, in months, of the
+// loans accepted by the service. Requests with a duration out of the bounds
+// are rejected with 400 (Bad Request) before any loan plan is created.
+// The defaults are DefaultMinDuration and DefaultMaxDuration.
+func WithDurationBounds(min int, max int) Option {
+	return func(cfg *config) {
+		cfg.limits.minDuration = min
+		cfg.limits.maxDuration = max
+	}
+}
+
+// WithAmountBounds sets the min and max loan amount accepted by the
+// service. Requests with an amount out of the bounds are rejected with
+// 400 (Bad Request) before any loan plan is created. A zero bound
+// means that there is no bound, the default is to have no bounds.
+func WithAmountBounds(min decimal.Decimal, max decimal.Decimal) Option {
+	return func(cfg *config) {
+		cfg.limits.minAmount = min
+		cfg.limits.maxAmount = max
+	}
+}
+
+// WithRateBounds sets the min and max nominal rate (as a percent, like 5.0)
+// accepted by the service. Requests with a rate out of the bounds are
+// rejected with 400 (Bad Request) before any loan plan is created. A zero
+// bound means that there is no bound, the default is to have no bounds.
+func WithRateBounds(min decimal.Decimal, max decimal.Decimal) Option {
+	return func(cfg *config) {
+		cfg.limits.minRate = min
+		cfg.limits.maxRate = max
+	}
+}
+
+// WithWebhook enables notifying the given URL of every loan plan created
+// on the loan plan resource. The whole loan plan (the CreateLoanPlanResponse,
+// as JSON) is POSTed to the URL in the background after the response is
+// sent, retrying up to DefaultWebhookAttempts times on failures.
+// Webhooks are disabled by default.
+func WithWebhook(url string) Option {
+	return func(cfg *config) {
+		cfg.webhookURL = url
+	}
+}
+
+// WithClock sets the clock used by the service to get the current time,
+// like when defaulting the start date of loans. The default is time.Now,
+// which is also used if the given clock is nil.
+func WithClock(now func() time.Time) Option {
+	return func(cfg *config) {
+		if now != nil {
+			cfg.now = now
+		}
+	}
+}
+
+// WithPlanCache enables a LRU cache, with the given max number of loan
+// plans, in front of the LoanPlanCreator. Identical loan plans are created
+// only once while they are on the cache, responses inform if the loan plans
+// were cached with the CacheHeader. The cache is disabled by default.
+func WithPlanCache(size int) Option {
+	return func(cfg *config) {
+		cfg.cacheSize = size
+	}
+}
+
+// WithThousandsSeparator sets the thousands separator accepted on the
+// decimal fields of requests, like the loan amount and the nominal rate.
+// The separator is removed before parsing the decimals, so it must not be
+// the decimal point. An empty separator disables thousands separators.
+// The default is DefaultThousandsSeparator.
+func WithThousandsSeparator(separator string) Option {
+	return func(cfg *config) {
+		cfg.thousandsSeparator = separator
+	}
+}
+
+// WithComputationTimeout limits for how long a loan plan can be computed
+// on the loan plan resource, independent of the timeouts of the server.
+// Requests that take longer than the timeout fail with 503
+// (Service Unavailable) and their context is canceled, so a
+// context aware LoanPlanCreator stops computing the loan plan.
+// There is no timeout by default.
+func WithComputationTimeout(timeout time.Duration) Option {
+	return func(cfg *config) {
+		cfg.computationTimeout = timeout
+	}
+}
+
+// WithMaxInFlight limits how many requests are handled concurrently.
+// Requests beyond the limit are rejected right away with 503 (Service
+// Unavailable) and a Retry-After header, shedding load instead of
+// queuing unbounded work. The health check and the metrics are never
+// rejected. There is no limit by default.
+func WithMaxInFlight(limit int) Option {
+	return func(cfg *config) {
+		cfg.maxInFlight = limit
+	}
+}
+
+// WithDrain makes the health check fail with 503 (Service Unavailable)
+// once the given drain begins, like when the service starts to shut down.
+func WithDrain(drain *Drain) Option {
+	return func(cfg *config) {
+		cfg.drain = drain
+	}
+}
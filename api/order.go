@@ -0,0 +1,36 @@
+package api
+
+import (
+	"errors"
+	"net/url"
+)
+
+const (
+	orderQueryParam = "order"
+	ascendingOrder  = "asc"
+	descendingOrder = "desc"
+)
+
+// parseOrder parses the order of the payments informed on the order
+// query parameter, returning true when the payments are requested on
+// descending date order. The default is the ascending date order.
+func parseOrder(query url.Values) (bool, []FieldError) {
+	switch query.Get(orderQueryParam) {
+	case "", ascendingOrder:
+		return false, nil
+	case descendingOrder:
+		return true, nil
+	}
+	err := errors.New(orderQueryParam + " must be " + ascendingOrder + " or " + descendingOrder)
+	return false, []FieldError{newFieldError(orderQueryParam, err)}
+}
+
+// reversePayments returns the payments on the reverse order,
+// without changing the given payments.
+func reversePayments(payments []BorrowerPayment) []BorrowerPayment {
+	reversed := make([]BorrowerPayment, len(payments))
+	for i, payment := range payments {
+		reversed[len(payments)-1-i] = payment
+	}
+	return reversed
+}
@@ -0,0 +1,135 @@
+package api_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestLoanPlanPaymentsOrder(t *testing.T) {
+	type Test struct {
+		name          string
+		method        string
+		query         string
+		wantFirstDate string
+		wantLastDate  string
+		wantTotal     int
+	}
+
+	tests := []Test{
+		{
+			name:          "AscendingByDefault",
+			method:        http.MethodGet,
+			query:         "",
+			wantFirstDate: "2018-01-01T00:00:00Z",
+			wantLastDate:  "2018-12-01T00:00:00Z",
+			wantTotal:     12,
+		},
+		{
+			name:          "Ascending",
+			method:        http.MethodGet,
+			query:         "order=asc",
+			wantFirstDate: "2018-01-01T00:00:00Z",
+			wantLastDate:  "2018-12-01T00:00:00Z",
+			wantTotal:     12,
+		},
+		{
+			name:          "Descending",
+			method:        http.MethodGet,
+			query:         "order=desc",
+			wantFirstDate: "2018-12-01T00:00:00Z",
+			wantLastDate:  "2018-01-01T00:00:00Z",
+			wantTotal:     12,
+		},
+		{
+			name:          "DescendingOnPost",
+			method:        http.MethodPost,
+			query:         "order=desc",
+			wantFirstDate: "2018-12-01T00:00:00Z",
+			wantLastDate:  "2018-01-01T00:00:00Z",
+			wantTotal:     12,
+		},
+		{
+			name:          "DescendingIsPaginated",
+			method:        http.MethodGet,
+			query:         "order=desc&offset=1&limit=2",
+			wantFirstDate: "2018-11-01T00:00:00Z",
+			wantLastDate:  "2018-10-01T00:00:00Z",
+			wantTotal:     12,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(loan.CreatePlanForCurrencyContext)
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, newOrderRequest(t, test.method, test.query))
+
+			if res.Code != http.StatusOK {
+				t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+			}
+
+			resp := api.CreateLoanPlanResponse{}
+			fromJSON(t, res.Body, &resp)
+
+			if resp.Total != test.wantTotal {
+				t.Errorf("got total %d; want %d", resp.Total, test.wantTotal)
+			}
+			if len(resp.BorrowerPayments) == 0 {
+				t.Fatal("got no payments")
+			}
+
+			first := resp.BorrowerPayments[0]
+			if first.Date != test.wantFirstDate {
+				t.Errorf("got first payment date %q; want %q", first.Date, test.wantFirstDate)
+			}
+			last := resp.BorrowerPayments[len(resp.BorrowerPayments)-1]
+			if last.Date != test.wantLastDate {
+				t.Errorf("got last payment date %q; want %q", last.Date, test.wantLastDate)
+			}
+		})
+	}
+}
+
+func TestLoanPlanPaymentsInvalidOrder(t *testing.T) {
+	service := api.New(loan.CreatePlanForCurrencyContext)
+	res := httptest.NewRecorder()
+	service.ServeHTTP(res, newOrderRequest(t, http.MethodGet, "order=newest"))
+
+	if res.Code != http.StatusBadRequest {
+		t.Fatalf("got response %d want %d", res.Code, http.StatusBadRequest)
+	}
+
+	errResponse := api.ErrorResponse{}
+	fromJSON(t, res.Body, &errResponse)
+
+	gotFields := []string{}
+	for _, field := range errResponse.Error.Fields {
+		gotFields = append(gotFields, field.Field)
+	}
+	if diff := cmp.Diff([]string{"order"}, gotFields); diff != "" {
+		t.Errorf("error fields mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func newOrderRequest(t *testing.T, method string, query string) *http.Request {
+	t.Helper()
+
+	if method == http.MethodPost {
+		request := api.CreateLoanPlanRequest{
+			LoanAmount:  amountPtr(t, "5000"),
+			NominalRate: amountPtr(t, "5.0"),
+			Duration:    months(12),
+			StartDate:   "2018-01-01T00:00:00Z",
+		}
+		return httptest.NewRequest(method, api.CreateLoanPlanPath+"?"+query, bytes.NewReader(toJSON(t, request)))
+	}
+
+	query = "loanAmount=5000&nominalRate=5.0&duration=12&startDate=2018-01-01T00:00:00Z&" + query
+	return httptest.NewRequest(method, api.CreateLoanPlanPath+"?"+query, nil)
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+const (
+	offsetQueryParam = "offset"
+	limitQueryParam  = "limit"
+)
+
+// page is a page of the payments of a loan plan.
+// A zero limit means that there is no limit.
+type page struct {
+	offset int
+	limit  int
+}
+
+// parsePage parses the page informed on the offset and limit
+// query parameters. When none of them is informed the page
+// has all the payments.
+func parsePage(query url.Values) (page, []FieldError) {
+	var fieldErrs []FieldError
+
+	offset, err := parsePageParam(query, offsetQueryParam, 0)
+	if err != nil {
+		fieldErrs = append(fieldErrs, newFieldError(offsetQueryParam, err))
+	}
+
+	limit, err := parsePageParam(query, limitQueryParam, 1)
+	if err != nil {
+		fieldErrs = append(fieldErrs, newFieldError(limitQueryParam, err))
+	}
+
+	return page{offset: offset, limit: limit}, fieldErrs
+}
+
+func parsePageParam(query url.Values, name string, min int) (int, error) {
+	val := query.Get(name)
+	if val == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, err
+	}
+	if n < min {
+		return 0, errors.New(name + " must be at least " + strconv.Itoa(min))
+	}
+	return n, nil
+}
+
+// apply returns only the payments of the page. Pages beyond
+// the last payment have no payments.
+func (p page) apply(payments []BorrowerPayment) []BorrowerPayment {
+	if p.offset >= len(payments) {
+		return []BorrowerPayment{}
+	}
+	payments = payments[p.offset:]
+	if p.limit > 0 && p.limit < len(payments) {
+		payments = payments[:p.limit]
+	}
+	return payments
+}
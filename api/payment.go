@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// LoanPlanPaymentPath is the resource path used to get a single
+	// payment of a loan plan, identified by its index on the plan.
+	LoanPlanPaymentPath = "/loan-plan/payment"
+
+	paymentIndexQueryParam = "index"
+)
+
+// paymentHandler gets a single payment of a loan plan. The loan plan
+// parameters are informed as query parameters, like on GET /loan-plan,
+// together with the zero based index of the payment on the plan.
+//
+// Plans are created through the injected LoanPlanCreator, so the whole
+// plan is created and the payment is picked from it.
+func paymentHandler(cfg config, createLoanPlan LoanPlanCreator) http.HandlerFunc {
+	pathLogger := cfg.logger.WithFields(log.Fields{"path": LoanPlanPaymentPath})
+
+	return func(res http.ResponseWriter, req *http.Request) {
+		logger := pathLogger.WithFields(log.Fields{"requestID": requestID(req)})
+
+		if req.Method != http.MethodGet {
+			handleMethodNotAllowed(logger, res, req, http.MethodGet)
+			return
+		}
+
+		query := req.URL.Query()
+		parsedReq, fieldErrs := parseCreateLoanPlanQuery(query)
+
+		index, err := parsePaymentIndex(query.Get(paymentIndexQueryParam))
+		if err != nil {
+			fieldErrs = append(fieldErrs, newFieldError(paymentIndexQueryParam, err))
+		}
+
+		resp, statusCode, apiErr := planLoan(req.Context(), logger, createLoanPlan, cfg, parsedReq, fieldErrs)
+		if apiErr != nil {
+			writeErrorResponse(logger, res, req, statusCode, *apiErr)
+			return
+		}
+
+		if index >= len(resp.BorrowerPayments) {
+			msg := fmt.Sprintf("payment %d not found, the loan plan has %d payments", index, len(resp.BorrowerPayments))
+			writeErrorResponse(logger, res, req, http.StatusNotFound, Error{
+				Code:    ErrorCodeNotFound,
+				Message: msg,
+			})
+			logger.WithFields(log.Fields{"error": msg}).Warning("payment not found")
+			return
+		}
+
+		res.Header().Set("Content-Type", jsonContentType)
+		res.WriteHeader(http.StatusOK)
+		logResponseBodyWrite(logger, res, toJSON(logger, resp.BorrowerPayments[index]))
+	}
+}
+
+func parsePaymentIndex(val string) (int, error) {
+	index, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, err
+	}
+	if index < 0 {
+		return 0, errors.New("index must not be negative")
+	}
+	return index, nil
+}
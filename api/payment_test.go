@@ -0,0 +1,140 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestLoanPlanPayment(t *testing.T) {
+	type Test struct {
+		name           string
+		method         string
+		query          url.Values
+		wantStatusCode int
+		wantErrCode    api.ErrorCode
+		want           api.BorrowerPayment
+	}
+
+	loanQuery := func(index string) url.Values {
+		query := url.Values{}
+		query.Set("loanAmount", "5000")
+		query.Set("nominalRate", "5.0")
+		query.Set("duration", "24")
+		query.Set("startDate", "2018-01-01T00:00:00Z")
+		if index != "" {
+			query.Set("index", index)
+		}
+		return query
+	}
+
+	tests := []Test{
+		{
+			name:           "FirstPayment",
+			method:         http.MethodGet,
+			query:          loanQuery("0"),
+			wantStatusCode: http.StatusOK,
+			want: api.BorrowerPayment{
+				Date:                          "2018-01-01T00:00:00Z",
+				PaymentAmount:                 amount(t, "219.36"),
+				Interest:                      amount(t, "20.83"),
+				Principal:                     amount(t, "198.53"),
+				InitialOutstandingPrincipal:   amount(t, "5000"),
+				RemainingOutstandingPrincipal: amount(t, "4801.47"),
+				DaysInPeriod:                  30,
+			},
+		},
+		{
+			name:           "MiddlePayment",
+			method:         http.MethodGet,
+			query:          loanQuery("12"),
+			wantStatusCode: http.StatusOK,
+			want: api.BorrowerPayment{
+				Date:                          "2019-01-01T00:00:00Z",
+				PaymentAmount:                 amount(t, "219.36"),
+				Interest:                      amount(t, "10.68"),
+				Principal:                     amount(t, "208.68"),
+				InitialOutstandingPrincipal:   amount(t, "2562.31"),
+				RemainingOutstandingPrincipal: amount(t, "2353.63"),
+				DaysInPeriod:                  30,
+			},
+		},
+		{
+			name:           "IndexOutOfRange",
+			method:         http.MethodGet,
+			query:          loanQuery("24"),
+			wantStatusCode: http.StatusNotFound,
+			wantErrCode:    api.ErrorCodeNotFound,
+		},
+		{
+			name:           "NegativeIndex",
+			method:         http.MethodGet,
+			query:          loanQuery("-1"),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+		},
+		{
+			name:           "InvalidIndex",
+			method:         http.MethodGet,
+			query:          loanQuery("first"),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+		},
+		{
+			name:           "MissingIndex",
+			method:         http.MethodGet,
+			query:          loanQuery(""),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+		},
+		{
+			name:           "MissingLoanParameters",
+			method:         http.MethodGet,
+			query:          url.Values{"index": []string{"0"}},
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeMissingField,
+		},
+		{
+			name:           "MethodNotAllowedForPost",
+			method:         http.MethodPost,
+			query:          loanQuery("0"),
+			wantStatusCode: http.StatusMethodNotAllowed,
+			wantErrCode:    api.ErrorCodeMethodNotAllowed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(loan.CreatePlanForCurrencyContext)
+
+			path := api.LoanPlanPaymentPath + "?" + test.query.Encode()
+			req := httptest.NewRequest(test.method, path, nil)
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			if res.Code != test.wantStatusCode {
+				t.Fatalf("got response %d want %d; body: %s", res.Code, test.wantStatusCode, res.Body)
+			}
+
+			if test.wantStatusCode != http.StatusOK {
+				errResponse := api.ErrorResponse{}
+				fromJSON(t, res.Body, &errResponse)
+				if errResponse.Error.Code != test.wantErrCode {
+					t.Errorf("got error code %q; want %q", errResponse.Error.Code, test.wantErrCode)
+				}
+				return
+			}
+
+			got := api.BorrowerPayment{}
+			fromJSON(t, res.Body, &got)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("payment mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const pdfContentType = "application/pdf"
+
+// Layout of the PDF pages (A4), in points.
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 50
+	pdfFontSize     = 9
+	pdfLineHeight   = 12
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLineHeight
+)
+
+// toPDF renders the loan plan as a printable PDF statement, with a
+// header with the loan parameters, a table with one row for each
+// payment and the totals of the plan at the end.
+func toPDF(parsedReq CreateLoanPlanRequest, resp CreateLoanPlanResponse) []byte {
+	row := func(columns ...string) string {
+		return fmt.Sprintf("%-12s %14s %12s %14s %14s %14s", toInterfaces(columns)...)
+	}
+
+	// When the price is informed the loan amount is the financed
+	// amount, which is the total principal of the loan plan.
+	loanAmount := parsedReq.text("loanAmount", parsedReq.LoanAmount)
+	if parsedReq.text("price", parsedReq.Price) != "" {
+		loanAmount = resp.Summary.TotalPrincipal.String()
+	}
+	if parsedReq.Currency != "" {
+		loanAmount += " " + parsedReq.Currency
+	}
+
+	nominalRate := parsedReq.text("nominalRate", parsedReq.NominalRate)
+	if parsedReq.RateIsFraction {
+		if rate, err := decimal.NewFromString(nominalRate); err == nil {
+			nominalRate = fractionToPercent(rate).String()
+		}
+	}
+	if parsedReq.NominalRateBps != nil {
+		nominalRate = bpsToPercent(*parsedReq.NominalRateBps).String()
+	}
+
+	duration := "End date: " + parsedReq.EndDate
+	if parsedReq.Duration != nil {
+		duration = fmt.Sprintf("Duration: %d months", *parsedReq.Duration)
+	}
+
+	header := []string{
+		"Loan plan",
+		"",
+		"Loan amount: " + loanAmount,
+		"Nominal rate: " + nominalRate + "%",
+		duration,
+		"Start date: " + parsedReq.StartDate,
+		"Monthly payment: " + resp.MonthlyPayment.String(),
+		"",
+	}
+	tableHeader := []string{
+		row("Date", "Payment", "Interest", "Principal", "Initial", "Remaining"),
+		strings.Repeat("-", 85),
+	}
+
+	var pages [][]string
+	page := append([]string{}, header...)
+	page = append(page, tableHeader...)
+
+	for _, p := range resp.BorrowerPayments {
+		if len(page) == pdfLinesPerPage {
+			pages = append(pages, page)
+			page = append([]string{}, tableHeader...)
+		}
+		date := p.Date
+		if len(date) > 10 {
+			date = date[:10]
+		}
+		page = append(page, row(
+			date,
+			p.PaymentAmount.String(),
+			p.Interest.String(),
+			p.Principal.String(),
+			p.InitialOutstandingPrincipal.String(),
+			p.RemainingOutstandingPrincipal.String(),
+		))
+	}
+
+	summary := []string{
+		"",
+		"Total principal: " + resp.Summary.TotalPrincipal.String(),
+		"Total interest: " + resp.Summary.TotalInterest.String(),
+		"Total payment: " + resp.Summary.TotalPayment.String(),
+	}
+	if len(page)+len(summary) > pdfLinesPerPage {
+		pages = append(pages, page)
+		page = nil
+	}
+	pages = append(pages, append(page, summary...))
+
+	return renderPDF(pages)
+}
+
+// renderPDF renders a PDF document with one page for each given list
+// of text lines, using a monospaced font so tables can be aligned with
+// spaces. Only what is needed for simple text documents is supported,
+// which avoids depending on a PDF library.
+func renderPDF(pages [][]string) []byte {
+	buf := &bytes.Buffer{}
+	var offsets []int
+
+	writeObject := func(object string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(buf, "%d 0 obj\n%s\nendobj\n", len(offsets), object)
+	}
+
+	// Objects 1, 2 and 3 are the catalog, the page tree and the font,
+	// followed by a page object and its content for each page.
+	const firstPageObject = 4
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObject+2*i)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+	writeObject("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	writeObject("<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>")
+
+	for i, lines := range pages {
+		content := &bytes.Buffer{}
+		fmt.Fprintf(content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLineHeight, pdfMargin, pdfPageHeight-pdfMargin)
+		for _, line := range lines {
+			fmt.Fprintf(content, "(%s) '\n", escapePDFString(line))
+		}
+		content.WriteString("ET")
+
+		writeObject(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth,
+			pdfPageHeight,
+			firstPageObject+2*i+1,
+		))
+		writeObject(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+// escapePDFString escapes the characters that are special inside
+// PDF literal strings. Non ASCII characters are replaced since the
+// standard fonts don't support them.
+func escapePDFString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteRune('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func toInterfaces(values []string) []interface{} {
+	res := make([]interface{}, len(values))
+	for i, v := range values {
+		res[i] = v
+	}
+	return res
+}
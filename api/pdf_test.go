@@ -0,0 +1,77 @@
+package api_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestLoanPlanCreationAsPDF(t *testing.T) {
+	server := httptest.NewServer(api.New(loan.CreatePlanForCurrencyContext))
+	defer server.Close()
+
+	body := []byte(`{"loanAmount":"5000","nominalRate":"5.0","duration":120,"startDate":"2018-01-01T00:00:00Z","currency":"EUR"}`)
+	request := newRequest(t, http.MethodPost, server.URL+api.CreateLoanPlanPath, body)
+	request.Header.Set("Accept", "application/pdf")
+
+	res, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got response %d want %d", res.StatusCode, http.StatusOK)
+	}
+
+	if got := res.Header.Get("Content-Type"); got != "application/pdf" {
+		t.Errorf("got content type %q; want %q", got, "application/pdf")
+	}
+
+	pdf, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+		t.Fatalf("got body without PDF magic header: %q", pdf)
+	}
+	if !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Errorf("got PDF without end of file marker: %q", pdf)
+	}
+
+	// The startxref must point to the cross reference table.
+	lines := strings.Split(strings.TrimSpace(string(pdf)), "\n")
+	xref, err := strconv.Atoi(lines[len(lines)-2])
+	if err != nil {
+		t.Fatalf("can't parse startxref from %q: %v", lines[len(lines)-2], err)
+	}
+	if xref >= len(pdf) || !bytes.HasPrefix(pdf[xref:], []byte("xref")) {
+		t.Errorf("startxref %d doesn't point to the xref table", xref)
+	}
+
+	// 120 payments don't fit on a single page
+	if !bytes.Contains(pdf, []byte("/Count 3")) {
+		t.Errorf("got PDF without 3 pages: %q", pdf)
+	}
+
+	for _, want := range []string{
+		"Loan amount: 5000 EUR",
+		"Nominal rate: 5.0%",
+		"Duration: 120 months",
+		"Start date: 2018-01-01T00:00:00Z",
+		"2027-12-01",
+		"Total principal: 5000",
+	} {
+		if !bytes.Contains(pdf, []byte(want)) {
+			t.Errorf("got PDF without %q", want)
+		}
+	}
+}
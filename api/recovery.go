@@ -0,0 +1,67 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// withRecovery recovers from panics on the handling of requests, so a bug
+// fails only the request that triggered it instead of crashing the service.
+// The panic is logged and, if no response was written yet, an internal
+// error response is sent to the client.
+func withRecovery(logger *log.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		tracker := &writeTracker{ResponseWriter: res}
+
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				// Used to abort responses on purpose, the http.Server
+				// handles it without logging a stack trace.
+				panic(recovered)
+			}
+
+			requestLogger := logger.WithFields(log.Fields{
+				"path":      req.URL.Path,
+				"requestID": requestID(req),
+				"panic":     fmt.Sprint(recovered),
+				"stack":     string(debug.Stack()),
+			})
+			requestLogger.Error("panic handling request")
+
+			if tracker.written {
+				// Too late to send an error response,
+				// the client gets a truncated response.
+				return
+			}
+			writeErrorResponse(requestLogger, res, req, http.StatusInternalServerError, Error{
+				Code:    ErrorCodeInternal,
+				Message: "internal server error",
+			})
+		}()
+
+		next.ServeHTTP(tracker, req)
+	})
+}
+
+// writeTracker tracks if anything was written on the response.
+type writeTracker struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *writeTracker) WriteHeader(status int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *writeTracker) Write(data []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(data)
+}
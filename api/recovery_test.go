@@ -0,0 +1,99 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+func TestPanicRecovery(t *testing.T) {
+	logs := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.SetOutput(logs)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+
+	calls := 0
+	service := api.New(func(
+		ctx context.Context,
+		totalLoanAmount decimal.Decimal,
+		annualInterestRate decimal.Decimal,
+		durationInMonths int,
+		start time.Time,
+		currency loan.Currency,
+	) ([]loan.Payment, error) {
+		calls++
+		if calls == 1 {
+			panic("injected panic")
+		}
+		return loan.CreatePlanForCurrencyContext(ctx, totalLoanAmount, annualInterestRate, durationInMonths, start, currency)
+	}, api.WithLogger(logger))
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	createLoanPlan := func() *http.Response {
+		request := newRequest(t, http.MethodPost, server.URL+api.CreateLoanPlanPath, validCreateLoanRequestBody(t))
+		res, err := server.Client().Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	res := createLoanPlan()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("got response %d want %d", res.StatusCode, http.StatusInternalServerError)
+	}
+
+	errResponse := api.ErrorResponse{}
+	fromJSON(t, res.Body, &errResponse)
+
+	wantErr := api.Error{
+		Code:    api.ErrorCodeInternal,
+		Message: "internal server error",
+		TraceID: res.Header.Get(api.RequestIDHeader),
+	}
+	if diff := cmp.Diff(wantErr, errResponse.Error); diff != "" {
+		t.Errorf("error response mismatch (-want +got):\n%s", diff)
+	}
+
+	type logEntry struct {
+		Level     string `json:"level"`
+		Msg       string `json:"msg"`
+		Panic     string `json:"panic"`
+		Path      string `json:"path"`
+		RequestID string `json:"requestID"`
+	}
+
+	gotLog := logEntry{}
+	fromJSON(t, logs, &gotLog)
+
+	wantLog := logEntry{
+		Level:     "error",
+		Msg:       "panic handling request",
+		Panic:     "injected panic",
+		Path:      api.CreateLoanPlanPath,
+		RequestID: res.Header.Get(api.RequestIDHeader),
+	}
+	if diff := cmp.Diff(wantLog, gotLog); diff != "" {
+		t.Errorf("log entry mismatch (-want +got):\n%s", diff)
+	}
+
+	// The service must be up and serving requests after the panic
+	res = createLoanPlan()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got response %d after panic; want %d", res.StatusCode, http.StatusOK)
+	}
+}
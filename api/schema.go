@@ -0,0 +1,123 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// LoanPlanSchemaPath is the resource path where the JSON Schema
+	// of the create loan plan request body is served.
+	LoanPlanSchemaPath = "/loan-plan/schema"
+
+	jsonSchemaContentType = "application/schema+json"
+	jsonSchemaDraft       = "http://json-schema.org/draft-07/schema#"
+
+	// decimalPattern matches the decimals accepted on requests,
+	// like "5000", "-1.5" or "5e3".
+	decimalPattern = `^-?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?$`
+)
+
+// schemaHandler serves the JSON Schema of the create loan plan request body,
+// so clients can validate requests before sending them.
+// The schema is built only once, when the handler is created.
+func schemaHandler(cfg config) http.HandlerFunc {
+	pathLogger := cfg.logger.WithFields(log.Fields{"path": LoanPlanSchemaPath})
+	schema := toJSON(pathLogger, newCreateLoanPlanRequestSchema(cfg.limits))
+
+	return func(res http.ResponseWriter, req *http.Request) {
+		logger := pathLogger.WithFields(log.Fields{"requestID": requestID(req)})
+
+		if req.Method != http.MethodGet {
+			handleMethodNotAllowed(logger, res, req, http.MethodGet)
+			return
+		}
+
+		res.Header().Set("Content-Type", jsonSchemaContentType)
+		res.WriteHeader(http.StatusOK)
+		logResponseBodyWrite(logger, res, schema)
+	}
+}
+
+// newCreateLoanPlanRequestSchema builds the JSON Schema of CreateLoanPlanRequest.
+//
+// The properties are generated from the JSON fields of the struct, like the
+// OpenAPI schemas, refined with the formats that the fields are parsed with.
+func newCreateLoanPlanRequestSchema(lim limits) map[string]interface{} {
+	decimalSchema := map[string]interface{}{
+		"type":    []string{"string", "number"},
+		"pattern": decimalPattern,
+	}
+	formats := map[string]map[string]interface{}{
+		"loanAmount":  decimalSchema,
+		"price":       decimalSchema,
+		"downPayment": decimalSchema,
+		"nominalRate": decimalSchema,
+		"duration": {
+			"type":    "integer",
+			"minimum": lim.minDuration,
+			"maximum": lim.maxDuration,
+		},
+		"startDate": {
+			"type":   "string",
+			"format": "date-time",
+		},
+		"endDate": {
+			"type":   "string",
+			"format": "date-time",
+		},
+		"currency": {
+			"type":    "string",
+			"pattern": "^[A-Z]{3}$",
+		},
+	}
+
+	properties := map[string]interface{}{}
+	required := []string{}
+	for _, field := range jsonFields(reflect.TypeOf(CreateLoanPlanRequest{})) {
+		schema, ok := formats[field.name]
+		if !ok {
+			schema = openAPISchemas{}.schemaOf(field.typ)
+		}
+		properties[field.name] = schema
+		if !field.optional {
+			required = append(required, field.name)
+		}
+	}
+
+	return map[string]interface{}{
+		"$schema":    jsonSchemaDraft,
+		"title":      "CreateLoanPlanRequest",
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+		"allOf": []interface{}{
+			// The loan amount is required, but it can be informed
+			// either directly or as a price and a down payment.
+			map[string]interface{}{
+				"oneOf": []interface{}{
+					map[string]interface{}{"required": []string{"loanAmount"}},
+					map[string]interface{}{"required": []string{"price"}},
+				},
+			},
+			// The nominal rate is required, but it can be
+			// informed either as a percent or in basis points.
+			map[string]interface{}{
+				"oneOf": []interface{}{
+					map[string]interface{}{"required": []string{"nominalRate"}},
+					map[string]interface{}{"required": []string{"nominalRateBps"}},
+				},
+			},
+			// The duration is required, but it can be informed
+			// either in months or as the end date of the loan.
+			map[string]interface{}{
+				"oneOf": []interface{}{
+					map[string]interface{}{"required": []string{"duration"}},
+					map[string]interface{}{"required": []string{"endDate"}},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,122 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestLoanPlanSchema(t *testing.T) {
+	server := httptest.NewServer(api.New(loan.CreatePlanForCurrencyContext, api.WithDurationBounds(1, 120)))
+	defer server.Close()
+
+	res, err := server.Client().Get(server.URL + api.LoanPlanSchemaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got response %d want %d", res.StatusCode, http.StatusOK)
+	}
+
+	if got := res.Header.Get("Content-Type"); got != "application/schema+json" {
+		t.Errorf("got content type %q; want %q", got, "application/schema+json")
+	}
+
+	type property struct {
+		Type    interface{} `json:"type"`
+		Format  string      `json:"format"`
+		Pattern string      `json:"pattern"`
+		Minimum int         `json:"minimum"`
+		Maximum int         `json:"maximum"`
+	}
+	type requirement struct {
+		Required []string `json:"required"`
+	}
+	type jsonSchema struct {
+		Schema     string              `json:"$schema"`
+		Type       string              `json:"type"`
+		Properties map[string]property `json:"properties"`
+		Required   []string            `json:"required"`
+		AllOf      []struct {
+			OneOf []requirement `json:"oneOf"`
+		} `json:"allOf"`
+	}
+
+	schema := jsonSchema{}
+	fromJSON(t, res.Body, &schema)
+
+	if schema.Schema == "" {
+		t.Error("missing $schema on JSON Schema")
+	}
+	if schema.Type != "object" {
+		t.Errorf("got schema type %q; want object", schema.Type)
+	}
+
+	wantRequired := []string{}
+	if diff := cmp.Diff(wantRequired, schema.Required); diff != "" {
+		t.Errorf("required properties mismatch (-want +got):\n%s", diff)
+	}
+
+	wantOneOfs := [][]requirement{
+		{
+			{Required: []string{"loanAmount"}},
+			{Required: []string{"price"}},
+		},
+		{
+			{Required: []string{"nominalRate"}},
+			{Required: []string{"nominalRateBps"}},
+		},
+		{
+			{Required: []string{"duration"}},
+			{Required: []string{"endDate"}},
+		},
+	}
+	gotOneOfs := [][]requirement{}
+	for _, all := range schema.AllOf {
+		gotOneOfs = append(gotOneOfs, all.OneOf)
+	}
+	if diff := cmp.Diff(wantOneOfs, gotOneOfs); diff != "" {
+		t.Errorf("oneOf mismatch (-want +got):\n%s", diff)
+	}
+
+	decimalType := []interface{}{"string", "number"}
+	decimalPattern := `^-?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?$`
+
+	wantProperties := map[string]property{
+		"loanAmount":     {Type: decimalType, Pattern: decimalPattern},
+		"price":          {Type: decimalType, Pattern: decimalPattern},
+		"downPayment":    {Type: decimalType, Pattern: decimalPattern},
+		"nominalRate":    {Type: decimalType, Pattern: decimalPattern},
+		"nominalRateBps": {Type: "integer"},
+		"rateIsFraction": {Type: "boolean"},
+		"duration":       {Type: "integer", Minimum: 1, Maximum: 120},
+		"startDate":      {Type: "string", Format: "date-time"},
+		"endDate":        {Type: "string", Format: "date-time"},
+		"currency":       {Type: "string", Pattern: "^[A-Z]{3}$"},
+	}
+	if diff := cmp.Diff(wantProperties, schema.Properties); diff != "" {
+		t.Errorf("properties mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoanPlanSchemaMethodNotAllowed(t *testing.T) {
+	server := httptest.NewServer(api.New(loan.CreatePlanForCurrencyContext))
+	defer server.Close()
+
+	request := newRequest(t, http.MethodPost, server.URL+api.LoanPlanSchemaPath, nil)
+	res, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("got response %d want %d", res.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
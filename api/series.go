@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/katcipis/loaner/money"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// LoanPlanSeriesPath is the resource path used to get the
+	// loan plan as series, ready to be used on charts.
+	LoanPlanSeriesPath = "/loan-plan/series"
+)
+
+// LoanPlanSeriesResponse is the response of the loan plan series request.
+// It has one series for each value of the payments, all of them with one
+// item per payment, on the same order of the payments: the Labels are the
+// dates of the payments and the Balance is the remaining outstanding
+// principal after each payment.
+type LoanPlanSeriesResponse struct {
+	Labels    []string       `json:"labels"`
+	Interest  []money.Amount `json:"interest"`
+	Principal []money.Amount `json:"principal"`
+	Balance   []money.Amount `json:"balance"`
+}
+
+// seriesHandler gets the loan plan as series, like the interest and
+// principal of all the payments, which is handy to draw charts.
+// The loan plan parameters are informed as query parameters, like
+// on GET /loan-plan.
+func seriesHandler(cfg config, createLoanPlan LoanPlanCreator) http.HandlerFunc {
+	pathLogger := cfg.logger.WithFields(log.Fields{"path": LoanPlanSeriesPath})
+
+	return func(res http.ResponseWriter, req *http.Request) {
+		logger := pathLogger.WithFields(log.Fields{"requestID": requestID(req)})
+
+		if req.Method != http.MethodGet {
+			handleMethodNotAllowed(logger, res, req, http.MethodGet)
+			return
+		}
+
+		parsedReq, fieldErrs := parseCreateLoanPlanQuery(req.URL.Query())
+		resp, statusCode, apiErr := planLoan(req.Context(), logger, createLoanPlan, cfg, parsedReq, fieldErrs)
+		if apiErr != nil {
+			writeErrorResponse(logger, res, req, statusCode, *apiErr)
+			return
+		}
+
+		res.Header().Set("Content-Type", jsonContentType)
+		res.WriteHeader(http.StatusOK)
+		logResponseBodyWrite(logger, res, toJSON(logger, newLoanPlanSeriesResponse(resp.BorrowerPayments)))
+	}
+}
+
+func newLoanPlanSeriesResponse(payments []BorrowerPayment) LoanPlanSeriesResponse {
+	series := LoanPlanSeriesResponse{
+		Labels:    make([]string, len(payments)),
+		Interest:  make([]money.Amount, len(payments)),
+		Principal: make([]money.Amount, len(payments)),
+		Balance:   make([]money.Amount, len(payments)),
+	}
+	for i, p := range payments {
+		series.Labels[i] = p.Date
+		series.Interest[i] = p.Interest
+		series.Principal[i] = p.Principal
+		series.Balance[i] = p.RemainingOutstandingPrincipal
+	}
+	return series
+}
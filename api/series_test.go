@@ -0,0 +1,106 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestLoanPlanSeries(t *testing.T) {
+	service := api.New(loan.CreatePlanForCurrencyContext)
+
+	req := httptest.NewRequest(http.MethodGet, api.LoanPlanSeriesPath+"?"+validCreateLoanRequestQuery(), nil)
+	res := httptest.NewRecorder()
+	service.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+	}
+
+	got := api.LoanPlanSeriesResponse{}
+	fromJSON(t, res.Body, &got)
+
+	planReq := httptest.NewRequest(http.MethodGet, api.CreateLoanPlanPath+"?"+validCreateLoanRequestQuery(), nil)
+	planRes := httptest.NewRecorder()
+	service.ServeHTTP(planRes, planReq)
+
+	plan := api.CreateLoanPlanResponse{}
+	fromJSON(t, planRes.Body, &plan)
+
+	want := api.LoanPlanSeriesResponse{}
+	for _, p := range plan.BorrowerPayments {
+		want.Labels = append(want.Labels, p.Date)
+		want.Interest = append(want.Interest, p.Interest)
+		want.Principal = append(want.Principal, p.Principal)
+		want.Balance = append(want.Balance, p.RemainingOutstandingPrincipal)
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("series mismatch (-want +got):\n%s", diff)
+	}
+
+	for name, length := range map[string]int{
+		"labels":    len(got.Labels),
+		"interest":  len(got.Interest),
+		"principal": len(got.Principal),
+		"balance":   len(got.Balance),
+	} {
+		if length != plan.Total {
+			t.Errorf("got %d items on %s; want %d", length, name, plan.Total)
+		}
+	}
+
+	if last := got.Balance[len(got.Balance)-1]; !last.Decimal().IsZero() {
+		t.Errorf("got last balance %q; want %q", last, "0")
+	}
+}
+
+func TestLoanPlanSeriesFailures(t *testing.T) {
+	type Test struct {
+		name           string
+		method         string
+		query          string
+		wantStatusCode int
+		wantErrCode    api.ErrorCode
+	}
+
+	tests := []Test{
+		{
+			name:           "InvalidParameters",
+			method:         http.MethodGet,
+			query:          "loanAmount=invalid&nominalRate=5.0&duration=24",
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+		},
+		{
+			name:           "MethodNotAllowed",
+			method:         http.MethodPost,
+			wantStatusCode: http.StatusMethodNotAllowed,
+			wantErrCode:    api.ErrorCodeMethodNotAllowed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(loan.CreatePlanForCurrencyContext)
+
+			req := httptest.NewRequest(test.method, api.LoanPlanSeriesPath+"?"+test.query, nil)
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			if res.Code != test.wantStatusCode {
+				t.Fatalf("got response %d want %d; body: %s", res.Code, test.wantStatusCode, res.Body)
+			}
+
+			errResponse := api.ErrorResponse{}
+			fromJSON(t, res.Body, &errResponse)
+			if errResponse.Error.Code != test.wantErrCode {
+				t.Errorf("got error code %q; want %q", errResponse.Error.Code, test.wantErrCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// withTimeout works like http.TimeoutHandler, responding with 503
+// (Service Unavailable) and an ErrorResponse when the handling of a
+// request takes longer than the timeout. The context of the request is
+// canceled on the timeout, so handlers aware of the context (like the
+// ones using loan.CreatePlanContext) stop computing as soon as possible.
+//
+// The response of the handler is buffered and sent only if it
+// finishes before the timeout, writes after it are discarded.
+func withTimeout(cfg config, path string, timeout time.Duration, next http.Handler) http.Handler {
+	pathLogger := cfg.logger.WithFields(log.Fields{"path": path})
+
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		logger := pathLogger.WithFields(log.Fields{"requestID": requestID(req)})
+
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+
+		writer := &timeoutWriter{header: http.Header{}, status: http.StatusOK}
+		done := make(chan struct{})
+		panicked := make(chan interface{}, 1)
+
+		go func() {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					panicked <- recovered
+				}
+			}()
+			next.ServeHTTP(writer, req.WithContext(ctx))
+			close(done)
+		}()
+
+		select {
+		case recovered := <-panicked:
+			// Panics are handled on the goroutine of the request,
+			// like any other panic, instead of crashing the service.
+			panic(recovered)
+		case <-done:
+			writer.mutex.Lock()
+			defer writer.mutex.Unlock()
+
+			for name, values := range writer.header {
+				res.Header()[name] = values
+			}
+			res.WriteHeader(writer.status)
+			logResponseBodyWrite(logger, res, writer.body.Bytes())
+		case <-ctx.Done():
+			writer.mutex.Lock()
+			writer.timedOut = true
+			writer.mutex.Unlock()
+
+			msg := fmt.Sprintf("request timed out after %v", timeout)
+			writeErrorResponse(logger, res, req, http.StatusServiceUnavailable, Error{
+				Code:    ErrorCodeCanceled,
+				Message: msg,
+			})
+			logger.WithFields(log.Fields{"error": msg}).Warning("request timed out")
+		}
+	})
+}
+
+// timeoutWriter buffers the response of a handler
+// until it finishes or the request times out.
+type timeoutWriter struct {
+	mutex       sync.Mutex
+	header      http.Header
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(status int) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.timedOut || w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = status
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.wroteHeader = true
+	return w.body.Write(data)
+}
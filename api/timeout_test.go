@@ -0,0 +1,118 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+	"github.com/shopspring/decimal"
+)
+
+func TestComputationTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	// The slow creator ignores the context, like a long computation
+	// that is not aware of it, so only the timeout can stop the request.
+	slowCreator := func(
+		ctx context.Context,
+		totalLoanAmount decimal.Decimal,
+		annualInterestRate decimal.Decimal,
+		durationInMonths int,
+		start time.Time,
+		currency loan.Currency,
+	) ([]loan.Payment, error) {
+		<-release
+		return nil, errors.New("too late")
+	}
+
+	service := api.New(slowCreator, api.WithComputationTimeout(10*time.Millisecond))
+
+	req := newRequest(t, http.MethodPost, api.CreateLoanPlanPath, validCreateLoanRequestBody(t))
+	req.Header.Set(api.RequestIDHeader, "slow-request")
+	res := httptest.NewRecorder()
+
+	began := time.Now()
+	service.ServeHTTP(res, req)
+	elapsed := time.Since(began)
+
+	if res.Code != http.StatusServiceUnavailable {
+		t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusServiceUnavailable, res.Body)
+	}
+	if elapsed < 10*time.Millisecond {
+		t.Errorf("got response after %v; want after the timeout", elapsed)
+	}
+
+	errResponse := api.ErrorResponse{}
+	fromJSON(t, res.Body, &errResponse)
+
+	if errResponse.Error.Code != api.ErrorCodeCanceled {
+		t.Errorf("got error code %q; want %q", errResponse.Error.Code, api.ErrorCodeCanceled)
+	}
+	if errResponse.Error.TraceID != "slow-request" {
+		t.Errorf("got trace ID %q; want %q", errResponse.Error.TraceID, "slow-request")
+	}
+	if errResponse.Error.Message == "" {
+		t.Error("want error message, got none")
+	}
+}
+
+func TestComputationTimeoutCancelsContext(t *testing.T) {
+	canceled := make(chan error, 1)
+
+	service := api.New(func(
+		ctx context.Context,
+		totalLoanAmount decimal.Decimal,
+		annualInterestRate decimal.Decimal,
+		durationInMonths int,
+		start time.Time,
+		currency loan.Currency,
+	) ([]loan.Payment, error) {
+		<-ctx.Done()
+		canceled <- ctx.Err()
+		return nil, ctx.Err()
+	}, api.WithComputationTimeout(10*time.Millisecond))
+
+	req := newRequest(t, http.MethodPost, api.CreateLoanPlanPath, validCreateLoanRequestBody(t))
+	res := httptest.NewRecorder()
+	service.ServeHTTP(res, req)
+
+	if res.Code != http.StatusServiceUnavailable {
+		t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusServiceUnavailable, res.Body)
+	}
+
+	select {
+	case err := <-canceled:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got context error %v; want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("context of the loan plan creation was not canceled")
+	}
+}
+
+func TestComputationTimeoutNotExceeded(t *testing.T) {
+	want := api.New(loan.CreatePlanForCurrencyContext)
+	service := api.New(loan.CreatePlanForCurrencyContext, api.WithComputationTimeout(time.Minute))
+
+	wantRes := httptest.NewRecorder()
+	want.ServeHTTP(wantRes, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, validCreateLoanRequestBody(t)))
+
+	res := httptest.NewRecorder()
+	service.ServeHTTP(res, newRequest(t, http.MethodPost, api.CreateLoanPlanPath, validCreateLoanRequestBody(t)))
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+	}
+	if got, want := res.Header().Get("Content-Type"), wantRes.Header().Get("Content-Type"); got != want {
+		t.Errorf("got content type %q; want %q", got, want)
+	}
+	if got, want := res.Body.String(), wantRes.Body.String(); got != want {
+		t.Errorf("got body %q; want %q", got, want)
+	}
+}
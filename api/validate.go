@@ -0,0 +1,72 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/katcipis/loaner/loan"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// ValidateLoanPlanPath is the resource path used to validate the
+	// parameters of a loan plan without creating it.
+	ValidateLoanPlanPath = "/loan-plan/validate"
+)
+
+// validateHandler validates the parameters of a loan plan, with the same
+// request body used to create loan plans. The parameters go through the
+// same checks done when a loan plan is created, but no payments are built,
+// so it is cheap enough to be used for live validation of forms.
+//
+// The checks are done with the loan package directly (instead of the
+// injected LoanPlanCreator), since validating doesn't create loan plans.
+func validateHandler(cfg config) http.HandlerFunc {
+	pathLogger := cfg.logger.WithFields(log.Fields{"path": ValidateLoanPlanPath})
+
+	return func(res http.ResponseWriter, req *http.Request) {
+		logger := pathLogger.WithFields(log.Fields{"requestID": requestID(req)})
+
+		if req.Method != http.MethodPost {
+			handleMethodNotAllowed(logger, res, req, http.MethodPost)
+			return
+		}
+
+		parsedReq := CreateLoanPlanRequest{}
+		if !readJSONBody(cfg, logger, res, req, &parsedReq) {
+			return
+		}
+
+		params, fieldErrs := parseLoanPlanParams(parsedReq, cfg)
+		if len(fieldErrs) > 0 {
+			writeErrorResponse(logger, res, req, http.StatusBadRequest, newFieldErrorsError(logger, fieldErrs))
+			return
+		}
+
+		err := loan.ValidatePlanForCurrency(
+			params.loanAmount,
+			params.annualInterestRate,
+			params.durationInMonths,
+			params.startDate,
+			params.currency,
+		)
+		if err != nil {
+			if errors.Is(err, loan.ErrInvalidParameter) {
+				logger.WithError(err).Warning("bad request error")
+				writeErrorResponse(logger, res, req, http.StatusBadRequest, Error{
+					Code:    ErrorCodeInvalidParameter,
+					Message: err.Error(),
+				})
+				return
+			}
+			logger.WithError(err).Error("internal server error")
+			writeErrorResponse(logger, res, req, http.StatusInternalServerError, Error{
+				Code:    ErrorCodeInternal,
+				Message: "internal server error",
+			})
+			return
+		}
+
+		res.WriteHeader(http.StatusNoContent)
+	}
+}
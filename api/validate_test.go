@@ -0,0 +1,203 @@
+package api_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestValidateLoanPlan(t *testing.T) {
+	type Test struct {
+		name           string
+		method         string
+		contentType    string
+		requestBody    []byte
+		wantStatusCode int
+		wantErrCode    api.ErrorCode
+		wantErrFields  []string
+	}
+
+	validRequest := func() api.CreateLoanPlanRequest {
+		return api.CreateLoanPlanRequest{
+			LoanAmount:  amountPtr(t, "5000"),
+			NominalRate: amountPtr(t, "5.0"),
+			Duration:    months(24),
+			StartDate:   "2018-01-01T00:00:00Z",
+		}
+	}
+	requestBody := func(change func(*api.CreateLoanPlanRequest)) []byte {
+		req := validRequest()
+		change(&req)
+		return toJSON(t, req)
+	}
+
+	tests := []Test{
+		{
+			name:           "Valid",
+			requestBody:    toJSON(t, validRequest()),
+			wantStatusCode: http.StatusNoContent,
+		},
+		{
+			name: "ValidWithCurrency",
+			requestBody: requestBody(func(req *api.CreateLoanPlanRequest) {
+				req.Currency = "JPY"
+			}),
+			wantStatusCode: http.StatusNoContent,
+		},
+		{
+			name:           "MethodNotAllowedForGet",
+			method:         http.MethodGet,
+			wantStatusCode: http.StatusMethodNotAllowed,
+			wantErrCode:    api.ErrorCodeMethodNotAllowed,
+		},
+		{
+			name:           "UnsupportedMediaType",
+			contentType:    "text/plain",
+			requestBody:    toJSON(t, validRequest()),
+			wantStatusCode: http.StatusUnsupportedMediaType,
+			wantErrCode:    api.ErrorCodeUnsupportedMediaType,
+		},
+		{
+			name:           "BadRequestIfRequestBodyIsEmpty",
+			requestBody:    []byte{},
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeMalformedJSON,
+		},
+		{
+			name:           "BadRequestIfRequestBodyIsInvalidJSON",
+			requestBody:    []byte("{"),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeMalformedJSON,
+		},
+		{
+			name:           "BadRequestIfLoanAmountIsNotDecimal",
+			requestBody:    []byte(`{"loanAmount":"notADecimal","nominalRate":"5.0","duration":24,"startDate":"2018-01-01T00:00:00Z"}`),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+			wantErrFields:  []string{"loanAmount"},
+		},
+		{
+			name:           "BadRequestIfNominalRateIsNotDecimal",
+			requestBody:    []byte(`{"loanAmount":"5000","nominalRate":"wrongValue","duration":24,"startDate":"2018-01-01T00:00:00Z"}`),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+			wantErrFields:  []string{"nominalRate"},
+		},
+		{
+			name: "BadRequestIfStartDateIsNotValidDate",
+			requestBody: requestBody(func(req *api.CreateLoanPlanRequest) {
+				req.StartDate = "notDate"
+			}),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+			wantErrFields:  []string{"startDate"},
+		},
+		{
+			name: "BadRequestIfCurrencyIsUnknown",
+			requestBody: requestBody(func(req *api.CreateLoanPlanRequest) {
+				req.Currency = "notACurrency"
+			}),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+			wantErrFields:  []string{"currency"},
+		},
+		{
+			name:           "BadRequestReportsAllInvalidFields",
+			requestBody:    []byte(`{"loanAmount":"notADecimal","nominalRate":"5.0","duration":0,"startDate":"2018-01-01T00:00:00Z"}`),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+			wantErrFields:  []string{"loanAmount", "duration"},
+		},
+		{
+			name: "BadRequestIfDurationIsAboveMax",
+			requestBody: requestBody(func(req *api.CreateLoanPlanRequest) {
+				req.Duration = months(361)
+			}),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+			wantErrFields:  []string{"duration"},
+		},
+		{
+			name: "BadRequestIfStartDateDayIsBiggerThan28",
+			requestBody: requestBody(func(req *api.CreateLoanPlanRequest) {
+				req.StartDate = "2018-01-29T00:00:00Z"
+			}),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+		},
+		{
+			name: "BadRequestIfLoanAmountIsZero",
+			requestBody: requestBody(func(req *api.CreateLoanPlanRequest) {
+				req.LoanAmount = amountPtr(t, "0")
+			}),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+		},
+		{
+			name: "BadRequestIfNominalRateIsNegative",
+			requestBody: requestBody(func(req *api.CreateLoanPlanRequest) {
+				req.NominalRate = amountPtr(t, "-1")
+			}),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+		},
+		{
+			name: "BadRequestOnNegativeAmortization",
+			requestBody: requestBody(func(req *api.CreateLoanPlanRequest) {
+				req.LoanAmount = amountPtr(t, "100")
+				req.NominalRate = amountPtr(t, "1000")
+				req.Duration = months(360)
+			}),
+			wantStatusCode: http.StatusBadRequest,
+			wantErrCode:    api.ErrorCodeInvalidParameter,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(loan.CreatePlanForCurrencyContext)
+
+			method := test.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			req := httptest.NewRequest(method, api.ValidateLoanPlanPath, bytes.NewReader(test.requestBody))
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			if res.Code != test.wantStatusCode {
+				t.Fatalf("got response %d want %d; body: %s", res.Code, test.wantStatusCode, res.Body)
+			}
+
+			if test.wantStatusCode == http.StatusNoContent {
+				if res.Body.Len() != 0 {
+					t.Errorf("got response body %q; want no body", res.Body)
+				}
+				return
+			}
+
+			errResponse := api.ErrorResponse{}
+			fromJSON(t, res.Body, &errResponse)
+
+			if errResponse.Error.Code != test.wantErrCode {
+				t.Errorf("got error code %q; want %q", errResponse.Error.Code, test.wantErrCode)
+			}
+
+			var gotFields []string
+			for _, field := range errResponse.Error.Fields {
+				gotFields = append(gotFields, field.Field)
+			}
+			if diff := cmp.Diff(test.wantErrFields, gotFields); diff != "" {
+				t.Errorf("error fields mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"runtime"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// VersionPath is the resource path used to get
+	// the version and build information of the service.
+	VersionPath = "/version"
+)
+
+// VersionResponse is the response of the version request.
+// Unknown information (like when it is not set on the build)
+// is informed as "unknown".
+type VersionResponse struct {
+	Version   string `json:"version"`
+	GoVersion string `json:"goVersion"`
+	BuildTime string `json:"buildTime"`
+}
+
+// versionHandler informs the version and build information of the service.
+func versionHandler(cfg config) http.HandlerFunc {
+	pathLogger := cfg.logger.WithFields(log.Fields{"path": VersionPath})
+	version := VersionResponse{
+		Version:   orUnknown(cfg.version),
+		GoVersion: runtime.Version(),
+		BuildTime: orUnknown(cfg.buildTime),
+	}
+
+	return func(res http.ResponseWriter, req *http.Request) {
+		logger := pathLogger.WithFields(log.Fields{"requestID": requestID(req)})
+
+		if req.Method != http.MethodGet {
+			handleMethodNotAllowed(logger, res, req, http.MethodGet)
+			return
+		}
+
+		res.Header().Set("Content-Type", jsonContentType)
+		res.WriteHeader(http.StatusOK)
+		logResponseBodyWrite(logger, res, toJSON(logger, version))
+	}
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
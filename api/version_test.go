@@ -0,0 +1,85 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestVersion(t *testing.T) {
+	type Test struct {
+		name           string
+		method         string
+		opts           []api.Option
+		wantStatusCode int
+		want           api.VersionResponse
+	}
+
+	tests := []Test{
+		{
+			name:           "InjectedVersion",
+			method:         http.MethodGet,
+			opts:           []api.Option{api.WithVersion("1.12.0"), api.WithBuildTime("2021-01-02T15:04:05Z")},
+			wantStatusCode: http.StatusOK,
+			want: api.VersionResponse{
+				Version:   "1.12.0",
+				GoVersion: runtime.Version(),
+				BuildTime: "2021-01-02T15:04:05Z",
+			},
+		},
+		{
+			name:           "UnknownVersion",
+			method:         http.MethodGet,
+			wantStatusCode: http.StatusOK,
+			want: api.VersionResponse{
+				Version:   "unknown",
+				GoVersion: runtime.Version(),
+				BuildTime: "unknown",
+			},
+		},
+		{
+			name:           "MethodNotAllowedForPost",
+			method:         http.MethodPost,
+			wantStatusCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(api.New(loan.CreatePlanForCurrencyContext, test.opts...))
+			defer server.Close()
+
+			request := newRequest(t, test.method, server.URL+api.VersionPath, nil)
+			res, err := server.Client().Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != test.wantStatusCode {
+				t.Fatalf("got response %d want %d", res.StatusCode, test.wantStatusCode)
+			}
+
+			if test.wantStatusCode != http.StatusOK {
+				errResponse := api.ErrorResponse{}
+				fromJSON(t, res.Body, &errResponse)
+				if errResponse.Error.Code != api.ErrorCodeMethodNotAllowed {
+					t.Errorf("got error code %q; want %q", errResponse.Error.Code, api.ErrorCodeMethodNotAllowed)
+				}
+				return
+			}
+
+			got := api.VersionResponse{}
+			fromJSON(t, res.Body, &got)
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("api: GET %s mismatch (-want +got):\n%s", api.VersionPath, diff)
+			}
+		})
+	}
+}
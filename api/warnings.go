@@ -0,0 +1,43 @@
+package api
+
+import (
+	"github.com/katcipis/loaner/loan"
+)
+
+// Warnings flag loan plans created from unusual, but valid, parameters.
+// They don't fail the request, they are informed on the response so
+// clients can show a soft caution to the borrower.
+const (
+	// WarningLongDuration flags loans of more than 50 years.
+	WarningLongDuration = "very long duration"
+	// WarningSinglePayment flags loans paid on a single payment.
+	WarningSinglePayment = "single payment loan"
+	// WarningNoInterest flags loans with no interest charged,
+	// like loans with a 0% rate.
+	WarningNoInterest = "no interest charged"
+)
+
+// longDurationInMonths is the duration above which loans are
+// considered very long (50 years). It doesn't depend on the duration
+// bounds of the service, so it is only reached when they are raised.
+const longDurationInMonths = 50 * 12
+
+// loanPlanWarnings returns the warnings of the loan plan with
+// the given payments and summary, nil if there is none.
+func loanPlanWarnings(payments []loan.Payment, summary loan.Summary) []string {
+	if len(payments) == 0 {
+		return nil
+	}
+
+	var warnings []string
+	if len(payments) > longDurationInMonths {
+		warnings = append(warnings, WarningLongDuration)
+	}
+	if len(payments) == 1 {
+		warnings = append(warnings, WarningSinglePayment)
+	}
+	if summary.TotalInterest.IsZero() {
+		warnings = append(warnings, WarningNoInterest)
+	}
+	return warnings
+}
@@ -0,0 +1,133 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+	"github.com/shopspring/decimal"
+)
+
+func TestLoanPlanWarnings(t *testing.T) {
+	type Test struct {
+		name         string
+		opts         []api.Option
+		nominalRate  string
+		duration     int
+		wantWarnings []string
+	}
+
+	tests := []Test{
+		{
+			name:        "NormalLoan",
+			nominalRate: "5.0",
+			duration:    24,
+		},
+		{
+			name:        "ThirtyYears",
+			nominalRate: "5.0",
+			duration:    360,
+		},
+		{
+			name:        "FiftyYears",
+			opts:        []api.Option{api.WithDurationBounds(1, 720)},
+			nominalRate: "5.0",
+			duration:    600,
+		},
+		{
+			name:         "LongDuration",
+			opts:         []api.Option{api.WithDurationBounds(1, 720)},
+			nominalRate:  "5.0",
+			duration:     601,
+			wantWarnings: []string{api.WarningLongDuration},
+		},
+		{
+			name:         "LongDurationIsTheSameWithOtherBounds",
+			opts:         []api.Option{api.WithDurationBounds(600, 650)},
+			nominalRate:  "5.0",
+			duration:     601,
+			wantWarnings: []string{api.WarningLongDuration},
+		},
+		{
+			name:         "SinglePayment",
+			nominalRate:  "5.0",
+			duration:     1,
+			wantWarnings: []string{api.WarningSinglePayment},
+		},
+		{
+			name:         "ZeroRate",
+			nominalRate:  "0",
+			duration:     24,
+			wantWarnings: []string{api.WarningNoInterest},
+		},
+		{
+			name:         "SinglePaymentWithZeroRate",
+			nominalRate:  "0",
+			duration:     1,
+			wantWarnings: []string{api.WarningSinglePayment, api.WarningNoInterest},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := api.New(func(
+				ctx context.Context,
+				totalLoanAmount decimal.Decimal,
+				annualInterestRate decimal.Decimal,
+				durationInMonths int,
+				start time.Time,
+				currency loan.Currency,
+			) ([]loan.Payment, error) {
+				// Plans can be longer than loan.DefaultMaxDurationInMonths,
+				// so long durations can be tested with raised bounds.
+				return loan.BuildPlan(
+					totalLoanAmount,
+					annualInterestRate,
+					durationInMonths,
+					start,
+					loan.WithCurrency(currency),
+					loan.WithMaxDuration(720),
+				)
+			}, test.opts...)
+
+			body := toJSON(t, api.CreateLoanPlanRequest{
+				LoanAmount:  amountPtr(t, "5000"),
+				NominalRate: amountPtr(t, test.nominalRate),
+				Duration:    months(test.duration),
+				StartDate:   "2018-01-01T00:00:00Z",
+			})
+			req := newRequest(t, http.MethodPost, api.CreateLoanPlanPath, body)
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			if res.Code != http.StatusOK {
+				t.Fatalf("got response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+			}
+
+			var got map[string]json.RawMessage
+			fromJSON(t, res.Body, &got)
+
+			rawWarnings, ok := got["warnings"]
+			if len(test.wantWarnings) == 0 {
+				if ok {
+					t.Fatalf("got warnings %s; want none", rawWarnings)
+				}
+				return
+			}
+
+			var gotWarnings []string
+			if err := json.Unmarshal(rawWarnings, &gotWarnings); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.wantWarnings, gotWarnings); diff != "" {
+				t.Errorf("warnings mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
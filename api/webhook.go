@@ -0,0 +1,86 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultWebhookAttempts is the max number of times the service tries
+	// to deliver a loan plan to the webhook, counting the first attempt.
+	DefaultWebhookAttempts = 3
+
+	webhookTimeout = 10 * time.Second
+)
+
+// webhookBackoff is how long the service waits before retrying to
+// deliver a loan plan, doubled at each new retry.
+var webhookBackoff = 100 * time.Millisecond
+
+// webhook notifies a downstream service of the loan plans created.
+type webhook struct {
+	url      string
+	attempts int
+	client   *http.Client
+}
+
+func newWebhook(url string) *webhook {
+	return &webhook{
+		url:      url,
+		attempts: DefaultWebhookAttempts,
+		client:   &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// notify POSTs the loan plan to the webhook in the background, so clients
+// never wait for (or fail due to) the webhook. Failed deliveries are retried
+// a few times and then the loan plan is dropped, only logging the failure.
+func (w *webhook) notify(logger *log.Entry, reqID string, resp CreateLoanPlanResponse) {
+	body := toJSON(logger, resp)
+	logger = logger.WithFields(log.Fields{"webhook": w.url})
+
+	go func() {
+		backoff := webhookBackoff
+		for attempt := 1; ; attempt++ {
+			err := w.post(reqID, body)
+			if err == nil {
+				logger.Debug("loan plan delivered to webhook")
+				return
+			}
+			if attempt >= w.attempts {
+				logger.WithError(err).Error("unable to deliver loan plan to webhook, giving up")
+				return
+			}
+			logger.WithError(err).WithFields(log.Fields{"attempt": attempt}).Warning("unable to deliver loan plan to webhook, retrying")
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}()
+}
+
+func (w *webhook) post(reqID string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", jsonContentType)
+	req.Header.Set(RequestIDHeader, reqID)
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	// Draining the body allows the connection to be reused.
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package api_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestWebhookReceivesCreatedLoanPlan(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	payloads := make(chan []byte, 1)
+	target := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Errorf("reading webhook body: %v", err)
+		}
+		received <- req
+		payloads <- body
+	}))
+	defer target.Close()
+
+	service := httptest.NewServer(api.New(loan.CreatePlanForCurrencyContext, api.WithWebhook(target.URL)))
+	defer service.Close()
+
+	res, err := service.Client().Get(service.URL + api.CreateLoanPlanPath +
+		"?loanAmount=5000&nominalRate=5.0&duration=24&startDate=2018-01-01T00:00:00Z&limit=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got response %d want %d", res.StatusCode, http.StatusOK)
+	}
+
+	var req *http.Request
+	select {
+	case req = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the webhook")
+	}
+
+	if req.Method != http.MethodPost {
+		t.Errorf("got webhook method %q; want %q", req.Method, http.MethodPost)
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("got webhook content type %q; want %q", got, "application/json")
+	}
+	if got, want := req.Header.Get(api.RequestIDHeader), res.Header.Get(api.RequestIDHeader); got != want {
+		t.Errorf("got webhook request ID %q; want %q", got, want)
+	}
+
+	got := api.CreateLoanPlanResponse{}
+	if err := json.Unmarshal(<-payloads, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	// The webhook always receives the whole loan plan, ignoring pagination.
+	if len(got.BorrowerPayments) != 24 {
+		t.Errorf("got %d payments on webhook; want 24", len(got.BorrowerPayments))
+	}
+	want := api.BorrowerPayment{
+		Date:                          "2018-01-01T00:00:00Z",
+		PaymentAmount:                 amount(t, "219.36"),
+		Interest:                      amount(t, "20.83"),
+		Principal:                     amount(t, "198.53"),
+		InitialOutstandingPrincipal:   amount(t, "5000"),
+		RemainingOutstandingPrincipal: amount(t, "4801.47"),
+		DaysInPeriod:                  30,
+	}
+	if diff := cmp.Diff(want, got.BorrowerPayments[0]); diff != "" {
+		t.Errorf("webhook payment mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWebhookRetriesFailedDeliveries(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	delivered := make(chan struct{})
+
+	target := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempts++
+		if attempts < api.DefaultWebhookAttempts {
+			res.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		close(delivered)
+	}))
+	defer target.Close()
+
+	service := httptest.NewServer(api.New(loan.CreatePlanForCurrencyContext, api.WithWebhook(target.URL)))
+	defer service.Close()
+
+	res, err := service.Client().Get(service.URL + api.CreateLoanPlanPath +
+		"?loanAmount=5000&nominalRate=5.0&duration=24&startDate=2018-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the webhook retries")
+	}
+}
+
+func TestWebhookNotCalledOnFailedLoanPlans(t *testing.T) {
+	called := make(chan struct{}, 1)
+	target := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		called <- struct{}{}
+	}))
+	defer target.Close()
+
+	service := httptest.NewServer(api.New(loan.CreatePlanForCurrencyContext, api.WithWebhook(target.URL)))
+	defer service.Close()
+
+	res, err := service.Client().Get(service.URL + api.CreateLoanPlanPath + "?loanAmount=invalid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("got response %d want %d", res.StatusCode, http.StatusBadRequest)
+	}
+
+	select {
+	case <-called:
+		t.Fatal("webhook called for a failed loan plan")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+// config holds all the runtime configuration of the service.
+// Each setting can be provided as a flag and falls back to
+// an environment variable, then to the config file (if any)
+// and then to a default when the flag is not provided.
+type config struct {
+	host         string
+	port         int
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+	idleTimeout  time.Duration
+	drainPeriod  time.Duration
+	logFormat    string
+	logLevel     string
+	corsOrigins  []string
+	tlsCert      string
+	tlsKey       string
+	webhookURL   string
+	cacheSize    int
+	version      bool
+
+	computationTimeout time.Duration
+	maxInFlight        int
+
+	minAmount decimal.Decimal
+	maxAmount decimal.Decimal
+	minRate   decimal.Decimal
+	maxRate   decimal.Decimal
+}
+
+// addr returns the network address the service should listen on.
+func (c config) addr() string {
+	return net.JoinHostPort(c.host, strconv.Itoa(c.port))
+}
+
+// parseConfig parses the service config from the given command line
+// arguments (without the program name), using getenv to lookup
+// the environment variables fallbacks (usually os.Getenv).
+func parseConfig(args []string, getenv func(string) string) (config, error) {
+	var fileValues map[string]string
+	source := envSource
+	if path := configFilePath(args, getenv); path != "" {
+		values, err := readConfigFile(path)
+		if err != nil {
+			return config{}, err
+		}
+		fileValues = values
+		source = withConfigFileSource(getenv, values)
+		getenv = withConfigFile(getenv, values)
+	}
+
+	port, err := envInt(getenv, source, "LOANER_PORT", 8080)
+	if err != nil {
+		return config{}, err
+	}
+	readTimeout, err := envDuration(getenv, source, "LOANER_READ_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return config{}, err
+	}
+	writeTimeout, err := envDuration(getenv, source, "LOANER_WRITE_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return config{}, err
+	}
+	idleTimeout, err := envDuration(getenv, source, "LOANER_IDLE_TIMEOUT", 60*time.Second)
+	if err != nil {
+		return config{}, err
+	}
+	drainPeriod, err := envDuration(getenv, source, "LOANER_DRAIN_PERIOD", 5*time.Second)
+	if err != nil {
+		return config{}, err
+	}
+	cacheSize, err := envInt(getenv, source, "LOANER_PLAN_CACHE_SIZE", 0)
+	if err != nil {
+		return config{}, err
+	}
+	computationTimeout, err := envDuration(getenv, source, "LOANER_COMPUTATION_TIMEOUT", 0)
+	if err != nil {
+		return config{}, err
+	}
+	maxInFlight, err := envInt(getenv, source, "LOANER_MAX_IN_FLIGHT", 0)
+	if err != nil {
+		return config{}, err
+	}
+
+	cfg := config{}
+	if cfg.minAmount, err = envDecimal(getenv, source, "LOANER_MIN_AMOUNT"); err != nil {
+		return config{}, err
+	}
+	if cfg.maxAmount, err = envDecimal(getenv, source, "LOANER_MAX_AMOUNT"); err != nil {
+		return config{}, err
+	}
+	if cfg.minRate, err = envDecimal(getenv, source, "LOANER_MIN_RATE"); err != nil {
+		return config{}, err
+	}
+	if cfg.maxRate, err = envDecimal(getenv, source, "LOANER_MAX_RATE"); err != nil {
+		return config{}, err
+	}
+	flags := flag.NewFlagSet("loaner", flag.ContinueOnError)
+
+	flags.BoolVar(&cfg.version, "version", false, "show service version and exit")
+	flags.StringVar(&cfg.host, "host", getenv("LOANER_HOST"), "host/interface where the service will be listening to (env: LOANER_HOST)")
+	flags.IntVar(&cfg.port, "port", port, "port where the service will be listening to (env: LOANER_PORT)")
+	flags.DurationVar(&cfg.readTimeout, "read-timeout", readTimeout, "max duration for reading an entire request (env: LOANER_READ_TIMEOUT)")
+	flags.DurationVar(&cfg.writeTimeout, "write-timeout", writeTimeout, "max duration before timing out writes of a response (env: LOANER_WRITE_TIMEOUT)")
+	flags.DurationVar(&cfg.idleTimeout, "idle-timeout", idleTimeout, "max duration to wait for the next request on keep-alive connections (env: LOANER_IDLE_TIMEOUT)")
+	flags.DurationVar(&cfg.drainPeriod, "drain-period", drainPeriod, "duration that the health check fails on shutdown before the server stops accepting requests (env: LOANER_DRAIN_PERIOD)")
+	flags.StringVar(&cfg.logFormat, "log-format", envString(getenv, "LOANER_LOG_FORMAT", "text"), "log format, text or json (env: LOANER_LOG_FORMAT)")
+	flags.StringVar(&cfg.logLevel, "log-level", envString(getenv, "LOANER_LOG_LEVEL", "info"), "log level, like debug, info or warning (env: LOANER_LOG_LEVEL)")
+
+	flags.String("config", getenv("LOANER_CONFIG"), "path of a config file, with the same settings of the flags (env: LOANER_CONFIG)")
+
+	flags.StringVar(&cfg.tlsCert, "tls-cert", getenv("LOANER_TLS_CERT"), "path of the TLS certificate file (PEM), enables HTTPS together with -tls-key (env: LOANER_TLS_CERT)")
+	flags.StringVar(&cfg.tlsKey, "tls-key", getenv("LOANER_TLS_KEY"), "path of the TLS private key file (PEM), enables HTTPS together with -tls-cert (env: LOANER_TLS_KEY)")
+
+	flags.StringVar(&cfg.webhookURL, "webhook-url", getenv("LOANER_WEBHOOK_URL"), "URL notified with every loan plan created, disabled if empty (env: LOANER_WEBHOOK_URL)")
+
+	flags.IntVar(&cfg.cacheSize, "plan-cache-size", cacheSize, "max number of loan plans kept on the plan cache, disabled if 0 (env: LOANER_PLAN_CACHE_SIZE)")
+	flags.DurationVar(&cfg.computationTimeout, "computation-timeout", computationTimeout, "max duration for computing a loan plan, no limit if 0 (env: LOANER_COMPUTATION_TIMEOUT)")
+	flags.IntVar(&cfg.maxInFlight, "max-in-flight", maxInFlight, "max number of requests handled concurrently, no limit if 0 (env: LOANER_MAX_IN_FLIGHT)")
+
+	flags.Var((*decimalValue)(&cfg.minAmount), "min-amount", "min loan amount accepted, no bound if 0 (env: LOANER_MIN_AMOUNT)")
+	flags.Var((*decimalValue)(&cfg.maxAmount), "max-amount", "max loan amount accepted, no bound if 0 (env: LOANER_MAX_AMOUNT)")
+	flags.Var((*decimalValue)(&cfg.minRate), "min-rate", "min nominal rate (percent) accepted, no bound if 0 (env: LOANER_MIN_RATE)")
+	flags.Var((*decimalValue)(&cfg.maxRate), "max-rate", "max nominal rate (percent) accepted, no bound if 0 (env: LOANER_MAX_RATE)")
+
+	corsOrigins := flags.String("cors-origins", getenv("LOANER_CORS_ORIGINS"), "comma separated list of origins allowed to call the service through CORS, disabled if empty (env: LOANER_CORS_ORIGINS)")
+
+	for key := range fileValues {
+		if key == "config" || key == "version" || flags.Lookup(key) == nil {
+			return config{}, fmt.Errorf("unknown setting %q on config file", key)
+		}
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return config{}, err
+	}
+
+	if (cfg.tlsCert == "") != (cfg.tlsKey == "") {
+		return config{}, errors.New("the TLS certificate and key must be provided together (or none of them)")
+	}
+
+	if err := validateBounds("amount", cfg.minAmount, cfg.maxAmount); err != nil {
+		return config{}, err
+	}
+	if err := validateBounds("rate", cfg.minRate, cfg.maxRate); err != nil {
+		return config{}, err
+	}
+
+	cfg.corsOrigins = parseList(*corsOrigins)
+	return cfg, nil
+}
+
+// validateBounds validates that the min and max bounds of the given
+// setting are not negative and that min is not bigger than max.
+// Zero bounds mean that there is no bound.
+func validateBounds(name string, min decimal.Decimal, max decimal.Decimal) error {
+	if min.IsNegative() || max.IsNegative() {
+		return fmt.Errorf("the min and max %s can't be negative", name)
+	}
+	if !max.IsZero() && min.GreaterThan(max) {
+		return fmt.Errorf("the min %s %s can't be bigger than the max %s %s", name, min, name, max)
+	}
+	return nil
+}
+
+// tlsConfig returns the TLS config of the service with the
+// configured certificate, or nil if TLS is not enabled.
+func (c config) tlsConfig() (*tls.Config, error) {
+	if c.tlsCert == "" {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.tlsCert, c.tlsKey)
+	if err != nil {
+		return nil, fmt.Errorf("can't load TLS certificate:%v", err)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
+
+// setupLogging configures the global logger with
+// the log format and level of the config.
+func (c config) setupLogging() error {
+	formatter, err := newLogFormatter(c.logFormat)
+	if err != nil {
+		return err
+	}
+	level, err := log.ParseLevel(c.logLevel)
+	if err != nil {
+		return fmt.Errorf("invalid log level %q:%v", c.logLevel, err)
+	}
+	log.SetFormatter(formatter)
+	log.SetLevel(level)
+	return nil
+}
+
+func newLogFormatter(format string) (log.Formatter, error) {
+	switch format {
+	case "text":
+		return &log.TextFormatter{}, nil
+	case "json":
+		return &log.JSONFormatter{}, nil
+	}
+	return nil, fmt.Errorf("invalid log format %q, must be text or json", format)
+}
+
+// parseList parses a comma separated list, ignoring empty items.
+func parseList(list string) []string {
+	var parsed []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			parsed = append(parsed, item)
+		}
+	}
+	return parsed
+}
+
+func envString(getenv func(string) string, name string, def string) string {
+	if val := getenv(name); val != "" {
+		return val
+	}
+	return def
+}
+
+// envSource describes the source of the setting of
+// the given environment variable, which is the variable itself.
+func envSource(name string) string {
+	return "env var " + name
+}
+
+func envInt(getenv func(string) string, source func(string) string, name string, def int) (int, error) {
+	val := getenv(name)
+	if val == "" {
+		return def, nil
+	}
+	parsed, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s=%q:%v", source(name), val, err)
+	}
+	return parsed, nil
+}
+
+func envDuration(getenv func(string) string, source func(string) string, name string, def time.Duration) (time.Duration, error) {
+	val := getenv(name)
+	if val == "" {
+		return def, nil
+	}
+	parsed, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s=%q:%v", source(name), val, err)
+	}
+	return parsed, nil
+}
+
+func envDecimal(getenv func(string) string, source func(string) string, name string) (decimal.Decimal, error) {
+	val := getenv(name)
+	if val == "" {
+		return decimal.Zero, nil
+	}
+	parsed, err := decimal.NewFromString(val)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid %s=%q:%v", source(name), val, err)
+	}
+	return parsed, nil
+}
+
+// decimalValue is a flag.Value of decimal flags.
+type decimalValue decimal.Decimal
+
+func (d *decimalValue) String() string {
+	return decimal.Decimal(*d).String()
+}
+
+func (d *decimalValue) Set(val string) error {
+	parsed, err := decimal.NewFromString(val)
+	if err != nil {
+		return err
+	}
+	*d = decimalValue(parsed)
+	return nil
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestParseConfig(t *testing.T) {
+	type Test struct {
+		name string
+		args []string
+		env  map[string]string
+		want config
+	}
+
+	defaults := config{
+		port:         8080,
+		readTimeout:  10 * time.Second,
+		writeTimeout: 10 * time.Second,
+		idleTimeout:  60 * time.Second,
+		drainPeriod:  5 * time.Second,
+		logFormat:    "text",
+		logLevel:     "info",
+	}
+
+	tests := []Test{
+		{
+			name: "Defaults",
+			want: defaults,
+		},
+		{
+			name: "Flags",
+			args: []string{
+				"-host", "127.0.0.1",
+				"-port", "9090",
+				"-read-timeout", "5s",
+				"-write-timeout", "1m",
+				"-idle-timeout", "2m",
+				"-log-format", "json",
+				"-log-level", "debug",
+				"-cors-origins", "https://a.example.com, https://b.example.com",
+			},
+			want: config{
+				host:         "127.0.0.1",
+				port:         9090,
+				readTimeout:  5 * time.Second,
+				writeTimeout: time.Minute,
+				idleTimeout:  2 * time.Minute,
+				drainPeriod:  5 * time.Second,
+				logFormat:    "json",
+				logLevel:     "debug",
+				corsOrigins:  []string{"https://a.example.com", "https://b.example.com"},
+			},
+		},
+		{
+			name: "Env",
+			env: map[string]string{
+				"LOANER_HOST":          "localhost",
+				"LOANER_PORT":          "7070",
+				"LOANER_READ_TIMEOUT":  "3s",
+				"LOANER_WRITE_TIMEOUT": "4s",
+				"LOANER_IDLE_TIMEOUT":  "5s",
+				"LOANER_LOG_FORMAT":    "json",
+				"LOANER_LOG_LEVEL":     "warning",
+				"LOANER_CORS_ORIGINS":  "*",
+			},
+			want: config{
+				host:         "localhost",
+				port:         7070,
+				readTimeout:  3 * time.Second,
+				writeTimeout: 4 * time.Second,
+				idleTimeout:  5 * time.Second,
+				drainPeriod:  5 * time.Second,
+				logFormat:    "json",
+				logLevel:     "warning",
+				corsOrigins:  []string{"*"},
+			},
+		},
+		{
+			name: "FlagsOverrideEnv",
+			args: []string{"-port", "9090", "-read-timeout", "30s"},
+			env: map[string]string{
+				"LOANER_PORT":         "7070",
+				"LOANER_READ_TIMEOUT": "3s",
+			},
+			want: config{
+				port:         9090,
+				readTimeout:  30 * time.Second,
+				writeTimeout: 10 * time.Second,
+				idleTimeout:  60 * time.Second,
+				drainPeriod:  5 * time.Second,
+				logFormat:    "text",
+				logLevel:     "info",
+			},
+		},
+		{
+			name: "TLS",
+			args: []string{"-tls-cert", "cert.pem", "-tls-key", "key.pem"},
+			want: config{
+				port:         8080,
+				readTimeout:  10 * time.Second,
+				writeTimeout: 10 * time.Second,
+				idleTimeout:  60 * time.Second,
+				drainPeriod:  5 * time.Second,
+				logFormat:    "text",
+				logLevel:     "info",
+				tlsCert:      "cert.pem",
+				tlsKey:       "key.pem",
+			},
+		},
+		{
+			name: "TLSEnv",
+			env: map[string]string{
+				"LOANER_TLS_CERT": "/etc/loaner/cert.pem",
+				"LOANER_TLS_KEY":  "/etc/loaner/key.pem",
+			},
+			want: config{
+				port:         8080,
+				readTimeout:  10 * time.Second,
+				writeTimeout: 10 * time.Second,
+				idleTimeout:  60 * time.Second,
+				drainPeriod:  5 * time.Second,
+				logFormat:    "text",
+				logLevel:     "info",
+				tlsCert:      "/etc/loaner/cert.pem",
+				tlsKey:       "/etc/loaner/key.pem",
+			},
+		},
+		{
+			name: "WebhookEnv",
+			env: map[string]string{
+				"LOANER_WEBHOOK_URL": "https://accounting.example.com/loan-plans",
+			},
+			want: config{
+				port:         8080,
+				readTimeout:  10 * time.Second,
+				writeTimeout: 10 * time.Second,
+				idleTimeout:  60 * time.Second,
+				drainPeriod:  5 * time.Second,
+				logFormat:    "text",
+				logLevel:     "info",
+				webhookURL:   "https://accounting.example.com/loan-plans",
+			},
+		},
+		{
+			name: "PlanCacheSize",
+			args: []string{"-plan-cache-size", "1000"},
+			want: config{
+				port:         8080,
+				readTimeout:  10 * time.Second,
+				writeTimeout: 10 * time.Second,
+				idleTimeout:  60 * time.Second,
+				drainPeriod:  5 * time.Second,
+				logFormat:    "text",
+				logLevel:     "info",
+				cacheSize:    1000,
+			},
+		},
+		{
+			name: "ComputationTimeoutEnv",
+			env:  map[string]string{"LOANER_COMPUTATION_TIMEOUT": "2s"},
+			want: config{
+				port:               8080,
+				readTimeout:        10 * time.Second,
+				writeTimeout:       10 * time.Second,
+				idleTimeout:        60 * time.Second,
+				drainPeriod:        5 * time.Second,
+				logFormat:          "text",
+				logLevel:           "info",
+				computationTimeout: 2 * time.Second,
+			},
+		},
+		{
+			name: "MaxInFlight",
+			args: []string{"-max-in-flight", "64"},
+			want: config{
+				port:         8080,
+				readTimeout:  10 * time.Second,
+				writeTimeout: 10 * time.Second,
+				idleTimeout:  60 * time.Second,
+				drainPeriod:  5 * time.Second,
+				logFormat:    "text",
+				logLevel:     "info",
+				maxInFlight:  64,
+			},
+		},
+		{
+			name: "DrainPeriodEnv",
+			env:  map[string]string{"LOANER_DRAIN_PERIOD": "15s"},
+			want: config{
+				port:         8080,
+				readTimeout:  10 * time.Second,
+				writeTimeout: 10 * time.Second,
+				idleTimeout:  60 * time.Second,
+				drainPeriod:  15 * time.Second,
+				logFormat:    "text",
+				logLevel:     "info",
+			},
+		},
+		{
+			name: "AmountAndRateBounds",
+			args: []string{
+				"-min-amount", "1000",
+				"-max-amount", "500000.50",
+				"-min-rate", "0.5",
+				"-max-rate", "30",
+			},
+			want: config{
+				port:         8080,
+				readTimeout:  10 * time.Second,
+				writeTimeout: 10 * time.Second,
+				idleTimeout:  60 * time.Second,
+				drainPeriod:  5 * time.Second,
+				logFormat:    "text",
+				logLevel:     "info",
+				minAmount:    decimal.RequireFromString("1000"),
+				maxAmount:    decimal.RequireFromString("500000.50"),
+				minRate:      decimal.RequireFromString("0.5"),
+				maxRate:      decimal.RequireFromString("30"),
+			},
+		},
+		{
+			name: "AmountAndRateBoundsEnv",
+			env: map[string]string{
+				"LOANER_MIN_AMOUNT": "1000",
+				"LOANER_MAX_AMOUNT": "500000",
+				"LOANER_MAX_RATE":   "30",
+			},
+			args: []string{"-max-amount", "100000"},
+			want: config{
+				port:         8080,
+				readTimeout:  10 * time.Second,
+				writeTimeout: 10 * time.Second,
+				idleTimeout:  60 * time.Second,
+				drainPeriod:  5 * time.Second,
+				logFormat:    "text",
+				logLevel:     "info",
+				minAmount:    decimal.RequireFromString("1000"),
+				maxAmount:    decimal.RequireFromString("100000"),
+				maxRate:      decimal.RequireFromString("30"),
+			},
+		},
+		{
+			name: "Version",
+			args: []string{"-version"},
+			want: config{
+				port:         8080,
+				readTimeout:  10 * time.Second,
+				writeTimeout: 10 * time.Second,
+				idleTimeout:  60 * time.Second,
+				drainPeriod:  5 * time.Second,
+				logFormat:    "text",
+				logLevel:     "info",
+				version:      true,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseConfig(test.args, fakeEnv(test.env))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, got, cmp.AllowUnexported(config{})); diff != "" {
+				t.Fatalf("parseConfig() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	type Test struct {
+		name string
+		args []string
+		env  map[string]string
+	}
+
+	tests := []Test{
+		{
+			name: "InvalidPortFlag",
+			args: []string{"-port", "nope"},
+		},
+		{
+			name: "InvalidTimeoutFlag",
+			args: []string{"-read-timeout", "10"},
+		},
+		{
+			name: "InvalidPortEnv",
+			env:  map[string]string{"LOANER_PORT": "nope"},
+		},
+		{
+			name: "InvalidTimeoutEnv",
+			env:  map[string]string{"LOANER_IDLE_TIMEOUT": "forever"},
+		},
+		{
+			name: "TLSCertWithoutKey",
+			args: []string{"-tls-cert", "cert.pem"},
+		},
+		{
+			name: "TLSKeyWithoutCert",
+			args: []string{"-tls-key", "key.pem"},
+		},
+		{
+			name: "TLSKeyEnvWithoutCert",
+			env:  map[string]string{"LOANER_TLS_KEY": "key.pem"},
+		},
+		{
+			name: "InvalidAmountFlag",
+			args: []string{"-min-amount", "lots"},
+		},
+		{
+			name: "InvalidRateEnv",
+			env:  map[string]string{"LOANER_MAX_RATE": "5%"},
+		},
+		{
+			name: "MinAmountBiggerThanMax",
+			args: []string{"-min-amount", "5000", "-max-amount", "1000"},
+		},
+		{
+			name: "MinRateEnvBiggerThanMax",
+			env:  map[string]string{"LOANER_MIN_RATE": "10", "LOANER_MAX_RATE": "5"},
+		},
+		{
+			name: "NegativeAmount",
+			args: []string{"-min-amount", "-1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseConfig(test.args, fakeEnv(test.env))
+			if err == nil {
+				t.Fatal("expected error, got none")
+			}
+		})
+	}
+}
+
+func TestConfigTLS(t *testing.T) {
+	tlsConfig, err := config{}.tlsConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tlsConfig != nil {
+		t.Errorf("got TLS config %v; want none when TLS is disabled", tlsConfig)
+	}
+
+	cfg := config{tlsCert: "missing-cert.pem", tlsKey: "missing-key.pem"}
+	if _, err := cfg.tlsConfig(); err == nil {
+		t.Error("expected error on missing TLS certificate files")
+	}
+}
+
+func TestConfigAddr(t *testing.T) {
+	cfg := config{host: "127.0.0.1", port: 8080}
+	if got, want := cfg.addr(), "127.0.0.1:8080"; got != want {
+		t.Errorf("got addr %q; want %q", got, want)
+	}
+
+	cfg = config{port: 8080}
+	if got, want := cfg.addr(), ":8080"; got != want {
+		t.Errorf("got addr %q; want %q", got, want)
+	}
+}
+
+func TestNewLogFormatter(t *testing.T) {
+	if _, ok := mustLogFormatter(t, "text").(*log.TextFormatter); !ok {
+		t.Error("text log format must use the logrus text formatter")
+	}
+	if _, ok := mustLogFormatter(t, "json").(*log.JSONFormatter); !ok {
+		t.Error("json log format must use the logrus JSON formatter")
+	}
+	if _, err := newLogFormatter("xml"); err == nil {
+		t.Error("expected error on invalid log format")
+	}
+}
+
+func TestSetupLoggingFailures(t *testing.T) {
+	cfg := config{logFormat: "xml", logLevel: "info"}
+	if err := cfg.setupLogging(); err == nil {
+		t.Error("expected error on invalid log format")
+	}
+
+	cfg = config{logFormat: "json", logLevel: "chatty"}
+	if err := cfg.setupLogging(); err == nil {
+		t.Error("expected error on invalid log level")
+	}
+}
+
+func mustLogFormatter(t *testing.T, format string) log.Formatter {
+	t.Helper()
+
+	formatter, err := newLogFormatter(format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return formatter
+}
+
+func fakeEnv(env map[string]string) func(string) string {
+	return func(name string) string {
+		return env[name]
+	}
+}
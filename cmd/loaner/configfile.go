@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// configFilePath returns the path of the config file, informed
+// with the -config flag or the LOANER_CONFIG environment variable.
+// The flag needs to be found before the flags are parsed since the
+// config file provides the defaults of all the other flags.
+func configFilePath(args []string, getenv func(string) string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if name == "config" && i+1 < len(args) {
+			return args[i+1]
+		}
+		if strings.HasPrefix(name, "config=") {
+			return strings.TrimPrefix(name, "config=")
+		}
+	}
+	return getenv("LOANER_CONFIG")
+}
+
+// readConfigFile reads the config file at the given path.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read config file:%v", err)
+	}
+	values, err := parseConfigFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %q:%v", path, err)
+	}
+	return values, nil
+}
+
+// parseConfigFile parses a config file, which has one setting per
+// line in the "key = value" (TOML) or "key: value" (YAML) forms. The keys
+// are the names of the flags, like "read-timeout" (or "read_timeout"),
+// and the values have the same format of the flags, optionally quoted.
+// Empty lines and comments, starting with #, are ignored.
+//
+// Only this flat subset of TOML/YAML is supported, which is enough
+// for the settings of the service and avoids any dependencies.
+func parseConfigFile(data []byte) (map[string]string, error) {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		sep := strings.IndexAny(line, "=:")
+		if sep <= 0 {
+			return nil, fmt.Errorf("line %d: expected %q or %q, got %q", lineNumber, "key = value", "key: value", line)
+		}
+
+		key := strings.ReplaceAll(strings.TrimSpace(line[:sep]), "_", "-")
+		value, err := parseConfigValue(strings.TrimSpace(line[sep+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		if _, ok := values[key]; ok {
+			return nil, fmt.Errorf("line %d: duplicated setting %q", lineNumber, key)
+		}
+		values[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// parseConfigValue removes the quotes of quoted values or the
+// comment after unquoted values.
+func parseConfigValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	quote := value[0]
+	if quote != '"' && quote != '\'' {
+		if comment := strings.Index(value, " #"); comment >= 0 {
+			value = strings.TrimSpace(value[:comment])
+		}
+		return value, nil
+	}
+
+	end := strings.IndexByte(value[1:], quote)
+	if end < 0 {
+		return "", fmt.Errorf("unterminated quoted value %s", value)
+	}
+	rest := strings.TrimSpace(value[end+2:])
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", fmt.Errorf("unexpected %q after quoted value", rest)
+	}
+	return value[1 : end+1], nil
+}
+
+// withConfigFile returns a lookup of settings that tries the
+// environment variables first and falls back to the config file.
+// The config file keys are the flag names of the environment
+// variables, like "read-timeout" for LOANER_READ_TIMEOUT.
+func withConfigFile(getenv func(string) string, values map[string]string) func(string) string {
+	return func(name string) string {
+		if val := getenv(name); val != "" {
+			return val
+		}
+		return values[configFileKey(name)]
+	}
+}
+
+// withConfigFileSource returns a description of where the setting of
+// the given environment variable comes from, like withConfigFile looks
+// it up, so errors on invalid values point to the right place.
+func withConfigFileSource(getenv func(string) string, values map[string]string) func(string) string {
+	return func(name string) string {
+		key := configFileKey(name)
+		if _, ok := values[key]; ok && getenv(name) == "" {
+			return fmt.Sprintf("config file key %q", key)
+		}
+		return envSource(name)
+	}
+}
+
+// configFileKey is the config file key of the setting
+// with the given environment variable.
+func configFileKey(name string) string {
+	key := strings.ToLower(strings.TrimPrefix(name, "LOANER_"))
+	return strings.ReplaceAll(key, "_", "-")
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shopspring/decimal"
+)
+
+func TestParseConfigWithConfigFile(t *testing.T) {
+	type Test struct {
+		name string
+		file string
+		args []string
+		env  map[string]string
+		want config
+	}
+
+	const yamlFile = `
+# loaner config
+host: "127.0.0.1"
+port: 9090
+read-timeout: 5s
+write_timeout: '1m'
+idle-timeout: 2m # keep-alive
+log-format: json
+log-level: debug
+cors-origins: https://a.example.com,https://b.example.com
+`
+
+	const tomlFile = `
+host = "localhost"
+port = 7070
+log_level = "warning"
+`
+
+	tests := []Test{
+		{
+			name: "YAML",
+			file: yamlFile,
+			want: config{
+				host:         "127.0.0.1",
+				port:         9090,
+				readTimeout:  5 * time.Second,
+				writeTimeout: time.Minute,
+				idleTimeout:  2 * time.Minute,
+				drainPeriod:  5 * time.Second,
+				logFormat:    "json",
+				logLevel:     "debug",
+				corsOrigins:  []string{"https://a.example.com", "https://b.example.com"},
+			},
+		},
+		{
+			name: "TOML",
+			file: tomlFile,
+			want: config{
+				host:         "localhost",
+				port:         7070,
+				readTimeout:  10 * time.Second,
+				writeTimeout: 10 * time.Second,
+				idleTimeout:  60 * time.Second,
+				drainPeriod:  5 * time.Second,
+				logFormat:    "text",
+				logLevel:     "warning",
+			},
+		},
+		{
+			name: "FlagsOverrideFile",
+			file: yamlFile,
+			args: []string{"-port", "8000", "-log-level", "error"},
+			want: config{
+				host:         "127.0.0.1",
+				port:         8000,
+				readTimeout:  5 * time.Second,
+				writeTimeout: time.Minute,
+				idleTimeout:  2 * time.Minute,
+				drainPeriod:  5 * time.Second,
+				logFormat:    "json",
+				logLevel:     "error",
+				corsOrigins:  []string{"https://a.example.com", "https://b.example.com"},
+			},
+		},
+		{
+			name: "EnvOverridesFile",
+			file: tomlFile,
+			env:  map[string]string{"LOANER_PORT": "6060"},
+			want: config{
+				host:         "localhost",
+				port:         6060,
+				readTimeout:  10 * time.Second,
+				writeTimeout: 10 * time.Second,
+				idleTimeout:  60 * time.Second,
+				drainPeriod:  5 * time.Second,
+				logFormat:    "text",
+				logLevel:     "warning",
+			},
+		},
+		{
+			name: "AmountAndRateBounds",
+			file: "min-amount: 1000\nmax_amount: \"500000\"\nmin-rate: 0.5\nmax-rate: 30\n",
+			want: config{
+				port:         8080,
+				readTimeout:  10 * time.Second,
+				writeTimeout: 10 * time.Second,
+				idleTimeout:  60 * time.Second,
+				drainPeriod:  5 * time.Second,
+				logFormat:    "text",
+				logLevel:     "info",
+				minAmount:    decimal.RequireFromString("1000"),
+				maxAmount:    decimal.RequireFromString("500000"),
+				minRate:      decimal.RequireFromString("0.5"),
+				maxRate:      decimal.RequireFromString("30"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeConfigFile(t, test.file)
+			args := append([]string{"-config", path}, test.args...)
+
+			got, err := parseConfig(args, fakeEnv(test.env))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, got, cmp.AllowUnexported(config{})); diff != "" {
+				t.Fatalf("parseConfig() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigFileFromEnv(t *testing.T) {
+	path := writeConfigFile(t, "port = 7070")
+
+	got, err := parseConfig(nil, fakeEnv(map[string]string{"LOANER_CONFIG": path}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.port != 7070 {
+		t.Errorf("got port %d; want 7070", got.port)
+	}
+}
+
+func TestConfigFileErrors(t *testing.T) {
+	type Test struct {
+		name string
+		file string
+	}
+
+	tests := []Test{
+		{name: "UnknownSetting", file: "timeout = 10s"},
+		{name: "NotASetting", file: "port"},
+		{name: "InvalidValue", file: "port = nope"},
+		{name: "UnterminatedQuote", file: `host = "localhost`},
+		{name: "DuplicatedSetting", file: "port = 1\nport = 2"},
+		{name: "VersionIsNotASetting", file: "version = true"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeConfigFile(t, test.file)
+			_, err := parseConfig([]string{"-config=" + path}, fakeEnv(nil))
+			if err == nil {
+				t.Fatal("expected error, got none")
+			}
+		})
+	}
+
+	t.Run("MissingFile", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "missing.yaml")
+		if _, err := parseConfig([]string{"-config", path}, fakeEnv(nil)); err == nil {
+			t.Fatal("expected error, got none")
+		}
+	})
+}
+
+func TestConfigFileInvalidValueErrorNamesTheSource(t *testing.T) {
+	type Test struct {
+		name    string
+		file    string
+		env     map[string]string
+		wantErr string
+	}
+
+	tests := []Test{
+		{
+			name:    "FromConfigFile",
+			file:    "read_timeout = 10",
+			wantErr: `invalid config file key "read-timeout"="10"`,
+		},
+		{
+			name:    "FromEnvOverridingConfigFile",
+			file:    "port = 7070",
+			env:     map[string]string{"LOANER_PORT": "nope"},
+			wantErr: `invalid env var LOANER_PORT="nope"`,
+		},
+		{
+			name:    "DecimalFromConfigFile",
+			file:    "max-rate = 5%",
+			wantErr: `invalid config file key "max-rate"="5%"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeConfigFile(t, test.file)
+			_, err := parseConfig([]string{"-config", path}, fakeEnv(test.env))
+			if err == nil {
+				t.Fatal("expected error, got none")
+			}
+			if !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("got error %q; want it to contain %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func writeConfigFile(t *testing.T, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "loaner.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
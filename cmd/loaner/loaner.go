@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/katcipis/loaner/api"
@@ -14,34 +19,149 @@ import (
 // VersionString version of the service
 var VersionString = "no version info"
 
+// BuildTime is when the service was built
+var BuildTime string
+
 func main() {
-	const timeout = 10 * time.Second
+	const shutdownTimeout = 30 * time.Second
 
-	var port int
-	var version bool
+	if len(os.Args) > 1 && os.Args[1] == planCmd {
+		if err := runPlan(os.Args[2:], os.Stdout); err != nil {
+			if err == flag.ErrHelp {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "%s: %v\n", planCmd, err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	flag.BoolVar(&version, "version", false, "show service version and exit")
-	flag.IntVar(&port, "port", 8080, "port where the service will be listening to")
-	flag.Parse()
+	cfg, err := parseConfig(os.Args[1:], os.Getenv)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		log.Fatal(err)
+	}
 
-	if version {
+	if cfg.version {
 		fmt.Printf("loaner version: %q\n", VersionString)
 		return
 	}
 
-	service := api.New(loan.CreatePlan)
+	if err := cfg.setupLogging(); err != nil {
+		log.Fatal(err)
+	}
+
+	drain := &api.Drain{}
+	service := api.New(
+		loan.CreatePlanForCurrencyContext,
+		api.WithVersion(VersionString),
+		api.WithBuildTime(BuildTime),
+		api.WithMetrics(),
+		api.WithCORS(cfg.corsOrigins...),
+		api.WithWebhook(cfg.webhookURL),
+		api.WithPlanCache(cfg.cacheSize),
+		api.WithComputationTimeout(cfg.computationTimeout),
+		api.WithMaxInFlight(cfg.maxInFlight),
+		api.WithAmountBounds(cfg.minAmount, cfg.maxAmount),
+		api.WithRateBounds(cfg.minRate, cfg.maxRate),
+		api.WithDrain(drain),
+	)
 	// A global timeout for an http server may not be the best fit
 	// for all scenarios. I worked on streaming APIs in the past and
 	// the stream can be long lived (both audio/media and also documents like
 	// a JSON stream). So a config like that must be used with care to
-	// not cause very odd bugs (like streams being cut short automatically).
+	// not cause very odd bugs (like streams being cut short automatically),
+	// that is why the timeouts are configurable per environment.
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	server := &http.Server{
-		Addr:         fmt.Sprintf(":%d", port),
+		Addr:         cfg.addr(),
+		TLSConfig:    tlsConfig,
 		Handler:      service,
-		ReadTimeout:  timeout,
-		WriteTimeout: timeout,
+		ReadTimeout:  cfg.readTimeout,
+		WriteTimeout: cfg.writeTimeout,
+		IdleTimeout:  cfg.idleTimeout,
+	}
+
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go shutdownOnSignal(signals, drain, cfg.drainPeriod, cancel)
+
+	log.Infof("running loaner service, listening on %s (TLS: %t)", server.Addr, tlsConfig != nil)
+	if err := serve(ctx, server, listener, shutdownTimeout); err != nil {
+		log.Fatal(err)
+	}
+	log.Info("loaner service stopped")
+}
+
+// shutdownOnSignal waits for a signal and then begins the drain, so the
+// health check fails from then on. The server keeps serving requests
+// for the drain period, giving load balancers the time to notice the
+// failing health check and stop routing requests to it, and only then
+// the shutdown starts (by cancelling the context given to serve).
+func shutdownOnSignal(
+	signals <-chan os.Signal,
+	drain *api.Drain,
+	drainPeriod time.Duration,
+	cancel context.CancelFunc,
+) {
+	sig := <-signals
+	log.Infof("received signal %q, draining for %v before shutting down", sig, drainPeriod)
+	drain.Begin()
+	time.Sleep(drainPeriod)
+	cancel()
+}
+
+// serve will serve HTTP requests on the given listener until the
+// context is cancelled. If the server has a TLS config the requests
+// are served with HTTPS, using the certificates of the config.
+//
+// When the context is cancelled the server stops accepting new
+// connections and waits (up to the shutdown timeout) for the in-flight
+// requests to finish, which allows zero-downtime deploys.
+func serve(
+	ctx context.Context,
+	server *http.Server,
+	listener net.Listener,
+	shutdownTimeout time.Duration,
+) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		if server.TLSConfig != nil {
+			serveErr <- server.ServeTLS(listener, "", "")
+			return
+		}
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Infof("draining in-flight requests, waiting up to %v", shutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("can't gracefully shutdown server:%w", err)
 	}
 
-	log.Infof("running loaner service, listening on port %d", port)
-	log.Fatal(server.ListenAndServe())
+	log.Info("all in-flight requests drained")
+	return nil
 }
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestServeGracefulShutdown(t *testing.T) {
+	requestStarted := make(chan struct{})
+	releaseRequest := make(chan struct{})
+
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			close(requestStarted)
+			<-releaseRequest
+			res.WriteHeader(http.StatusOK)
+		}),
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- serve(ctx, server, listener, 10*time.Second)
+	}()
+
+	inFlightStatus := make(chan int, 1)
+	go func() {
+		res, err := http.Get("http://" + addr)
+		if err != nil {
+			t.Errorf("in-flight request failed: %v", err)
+			inFlightStatus <- 0
+			return
+		}
+		res.Body.Close()
+		inFlightStatus <- res.StatusCode
+	}()
+
+	<-requestStarted
+	cancel()
+
+	// The server must stop accepting new connections
+	// while the in-flight request is still running.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			break
+		}
+		conn.Close()
+
+		if time.Now().After(deadline) {
+			t.Fatal("server still accepting connections after shutdown started")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case err := <-serveErr:
+		t.Fatalf("serve returned before in-flight request finished: %v", err)
+	default:
+	}
+
+	close(releaseRequest)
+
+	if status := <-inFlightStatus; status != http.StatusOK {
+		t.Errorf("got in-flight response %d; want %d", status, http.StatusOK)
+	}
+
+	if err := <-serveErr; err != nil {
+		t.Errorf("unexpected serve error: %v", err)
+	}
+}
+
+func TestServeShutdownTimeout(t *testing.T) {
+	requestStarted := make(chan struct{})
+	releaseRequest := make(chan struct{})
+	defer close(releaseRequest)
+
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			close(requestStarted)
+			<-releaseRequest
+		}),
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- serve(ctx, server, listener, 10*time.Millisecond)
+	}()
+
+	go func() {
+		res, err := http.Get("http://" + listener.Addr().String())
+		if err == nil {
+			res.Body.Close()
+		}
+	}()
+
+	<-requestStarted
+	cancel()
+
+	if err := <-serveErr; err == nil {
+		t.Error("expected error when in-flight requests exceed the shutdown timeout")
+	}
+}
+
+func TestServeTLS(t *testing.T) {
+	// The test server is used only to get a certificate
+	// and a client that trusts it.
+	testServer := httptest.NewTLSServer(http.NotFoundHandler())
+	client := testServer.Client()
+	certificates := testServer.TLS.Certificates
+	testServer.Close()
+
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			if req.TLS == nil {
+				t.Error("expected request to be served with TLS")
+			}
+			res.WriteHeader(http.StatusOK)
+		}),
+		TLSConfig: &tls.Config{Certificates: certificates},
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- serve(ctx, server, listener, 10*time.Second)
+	}()
+
+	res, err := client.Get("https://" + listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("got response %d; want %d", res.StatusCode, http.StatusOK)
+	}
+
+	cancel()
+	if err := <-serveErr; err != nil {
+		t.Errorf("unexpected serve error: %v", err)
+	}
+}
+
+func TestShutdownOnSignalServesDrainingHealthCheck(t *testing.T) {
+	const drainPeriod = 500 * time.Millisecond
+
+	drain := &api.Drain{}
+	server := &http.Server{
+		Handler: api.New(loan.CreatePlanForCurrencyContext, api.WithDrain(drain)),
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	healthURL := "http://" + listener.Addr().String() + api.HealthPath
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- serve(ctx, server, listener, 10*time.Second)
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signals <- syscall.SIGTERM
+	shutdownStarted := time.Now()
+	go shutdownOnSignal(signals, drain, drainPeriod, cancel)
+
+	// The health check fails during the drain period, while
+	// the server is still accepting new connections.
+	deadline := shutdownStarted.Add(drainPeriod)
+	for {
+		res, err := http.Get(healthURL)
+		if err != nil {
+			t.Fatalf("health check failed during the drain period: %v", err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode == http.StatusServiceUnavailable {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got health check response %d; want %d during the drain period",
+				res.StatusCode, http.StatusServiceUnavailable)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case err := <-serveErr:
+		t.Fatalf("serve returned before the drain period: %v", err)
+	default:
+	}
+
+	if err := <-serveErr; err != nil {
+		t.Errorf("unexpected serve error: %v", err)
+	}
+	if elapsed := time.Since(shutdownStarted); elapsed < drainPeriod {
+		t.Errorf("server shutdown after %v; want it after the drain period of %v", elapsed, drainPeriod)
+	}
+}
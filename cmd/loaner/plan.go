@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+	"github.com/katcipis/loaner/money"
+	"github.com/shopspring/decimal"
+)
+
+// planCmd is the subcommand that prints a loan plan
+// and exits, without starting the service.
+const planCmd = "plan"
+
+// planDateLayout is the layout of dates on the plan subcommand,
+// RFC 3339 dates (like the ones on the API) are also accepted.
+const planDateLayout = "2006-01-02"
+
+// planArgs are the arguments of the plan subcommand.
+type planArgs struct {
+	amount   decimal.Decimal
+	rate     decimal.Decimal
+	duration int
+	start    time.Time
+	json     bool
+}
+
+// parsePlanArgs parses the plan subcommand arguments
+// (without the program and subcommand names).
+func parsePlanArgs(args []string) (planArgs, error) {
+	var amount, rate, start string
+
+	parsed := planArgs{}
+	flags := flag.NewFlagSet(planCmd, flag.ContinueOnError)
+
+	flags.StringVar(&amount, "amount", "", "total loan amount, like 5000 (required)")
+	flags.StringVar(&rate, "rate", "", "annual nominal interest rate as a percent, like 5.0 (required)")
+	flags.IntVar(&parsed.duration, "duration", 0, "duration of the loan in months (required)")
+	flags.StringVar(&start, "start", "", "start date of the loan, like 2018-01-01 (required)")
+	flags.BoolVar(&parsed.json, "json", false, "print the plan as JSON instead of a table")
+
+	if err := flags.Parse(args); err != nil {
+		return planArgs{}, err
+	}
+
+	if flags.NArg() > 0 {
+		return planArgs{}, fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+
+	var err error
+
+	if amount == "" {
+		return planArgs{}, errors.New("missing required flag: -amount")
+	}
+	parsed.amount, err = decimal.NewFromString(amount)
+	if err != nil {
+		return planArgs{}, fmt.Errorf("invalid amount %q:%v", amount, err)
+	}
+
+	if rate == "" {
+		return planArgs{}, errors.New("missing required flag: -rate")
+	}
+	parsed.rate, err = decimal.NewFromString(rate)
+	if err != nil {
+		return planArgs{}, fmt.Errorf("invalid rate %q:%v", rate, err)
+	}
+
+	if parsed.duration == 0 {
+		return planArgs{}, errors.New("missing required flag: -duration")
+	}
+
+	if start == "" {
+		return planArgs{}, errors.New("missing required flag: -start")
+	}
+	parsed.start, err = parsePlanDate(start)
+	if err != nil {
+		return planArgs{}, fmt.Errorf("invalid start date %q:%v", start, err)
+	}
+
+	return parsed, nil
+}
+
+func parsePlanDate(date string) (time.Time, error) {
+	parsed, err := time.Parse(planDateLayout, date)
+	if err == nil {
+		return parsed, nil
+	}
+	return time.Parse(time.RFC3339, date)
+}
+
+// runPlan runs the plan subcommand, writing the loan plan on the
+// given writer as a table or, if requested, as JSON (with the same
+// schema of the API responses).
+func runPlan(args []string, out io.Writer) error {
+	parsed, err := parsePlanArgs(args)
+	if err != nil {
+		return err
+	}
+
+	payments, err := loan.CreatePlan(parsed.amount, parsed.rate, parsed.duration, parsed.start)
+	if err != nil {
+		return err
+	}
+
+	if parsed.json {
+		annuity, err := loan.CalculateAnnuity(parsed.amount, parsed.rate, parsed.duration)
+		if err != nil {
+			return err
+		}
+		resp := api.NewCreateLoanPlanResponse(payments)
+		resp.MonthlyPayment = money.New(annuity)
+
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "    ")
+		return enc.Encode(resp)
+	}
+	_, err = io.WriteString(out, loan.FormatTable(payments))
+	return err
+}
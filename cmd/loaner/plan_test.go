@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/money"
+	"github.com/shopspring/decimal"
+)
+
+func TestParsePlanArgs(t *testing.T) {
+	type Test struct {
+		name    string
+		args    []string
+		want    planArgs
+		wantErr bool
+	}
+
+	tests := []Test{
+		{
+			name: "AllArgs",
+			args: []string{"--amount", "5000", "--rate", "5.0", "--duration", "24", "--start", "2018-01-01"},
+			want: planArgs{
+				amount:   decimal.NewFromInt(5000),
+				rate:     decimal.NewFromFloat(5.0),
+				duration: 24,
+				start:    time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "JSON",
+			args: []string{"-amount=5000", "-rate=5.0", "-duration=24", "-start=2018-01-01", "-json"},
+			want: planArgs{
+				amount:   decimal.NewFromInt(5000),
+				rate:     decimal.NewFromFloat(5.0),
+				duration: 24,
+				start:    time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC),
+				json:     true,
+			},
+		},
+		{
+			name: "RFC3339StartDate",
+			args: []string{"--amount", "5000", "--rate", "5.0", "--duration", "24", "--start", "2018-01-01T00:00:01Z"},
+			want: planArgs{
+				amount:   decimal.NewFromInt(5000),
+				rate:     decimal.NewFromFloat(5.0),
+				duration: 24,
+				start:    time.Date(2018, time.January, 1, 0, 0, 1, 0, time.UTC),
+			},
+		},
+		{
+			name:    "MissingAmount",
+			args:    []string{"--rate", "5.0", "--duration", "24", "--start", "2018-01-01"},
+			wantErr: true,
+		},
+		{
+			name:    "MissingRate",
+			args:    []string{"--amount", "5000", "--duration", "24", "--start", "2018-01-01"},
+			wantErr: true,
+		},
+		{
+			name:    "MissingDuration",
+			args:    []string{"--amount", "5000", "--rate", "5.0", "--start", "2018-01-01"},
+			wantErr: true,
+		},
+		{
+			name:    "MissingStart",
+			args:    []string{"--amount", "5000", "--rate", "5.0", "--duration", "24"},
+			wantErr: true,
+		},
+		{
+			name:    "InvalidAmount",
+			args:    []string{"--amount", "lots", "--rate", "5.0", "--duration", "24", "--start", "2018-01-01"},
+			wantErr: true,
+		},
+		{
+			name:    "InvalidRate",
+			args:    []string{"--amount", "5000", "--rate", "high", "--duration", "24", "--start", "2018-01-01"},
+			wantErr: true,
+		},
+		{
+			name:    "InvalidDuration",
+			args:    []string{"--amount", "5000", "--rate", "5.0", "--duration", "long", "--start", "2018-01-01"},
+			wantErr: true,
+		},
+		{
+			name:    "InvalidStart",
+			args:    []string{"--amount", "5000", "--rate", "5.0", "--duration", "24", "--start", "01/01/2018"},
+			wantErr: true,
+		},
+		{
+			name:    "UnexpectedArgs",
+			args:    []string{"--amount", "5000", "--rate", "5.0", "--duration", "24", "--start", "2018-01-01", "extra"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parsePlanArgs(test.args)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got args %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, got, cmp.AllowUnexported(planArgs{})); diff != "" {
+				t.Errorf("parsePlanArgs() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRunPlanAsJSON(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := runPlan([]string{"--amount", "2000", "--rate", "1.0", "--duration", "2", "--start", "2018-01-01", "--json"}, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := api.CreateLoanPlanResponse{}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("output is not a valid loan plan response: %v\n%s", err, out)
+	}
+
+	want := api.CreateLoanPlanResponse{
+		BorrowerPayments: []api.BorrowerPayment{
+			{
+				Date:                          "2018-01-01T00:00:00Z",
+				PaymentAmount:                 amount(t, "1001.25"),
+				Interest:                      amount(t, "1.67"),
+				Principal:                     amount(t, "999.58"),
+				InitialOutstandingPrincipal:   amount(t, "2000"),
+				RemainingOutstandingPrincipal: amount(t, "1000.42"),
+				DaysInPeriod:                  30,
+			},
+			{
+				Date:                          "2018-02-01T00:00:00Z",
+				PaymentAmount:                 amount(t, "1001.25"),
+				Interest:                      amount(t, "0.83"),
+				Principal:                     amount(t, "1000.42"),
+				InitialOutstandingPrincipal:   amount(t, "1000.42"),
+				RemainingOutstandingPrincipal: amount(t, "0"),
+				DaysInPeriod:                  30,
+			},
+		},
+		Total:          2,
+		MonthlyPayment: amount(t, "1001.25"),
+		PayoffDate:     "2018-02-01T00:00:00Z",
+		Summary: api.LoanPlanSummary{
+			TotalPrincipal: amount(t, "2000"),
+			TotalInterest:  amount(t, "2.5"),
+			TotalPayment:   amount(t, "2002.5"),
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("plan --json mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunPlanAsTable(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := runPlan([]string{"--amount", "2000", "--rate", "1.0", "--duration", "2", "--start", "2018-01-01"}, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines; want header + 2 payments:\n%s", len(lines), out)
+	}
+
+	wantFields := [][]string{
+		{"2018-01-01", "1001.25", "1.67", "999.58", "1000.42"},
+		{"2018-02-01", "1001.25", "0.83", "1000.42", "0"},
+	}
+	for i, want := range wantFields {
+		if diff := cmp.Diff(want, strings.Fields(lines[i+1])); diff != "" {
+			t.Errorf("payment %d mismatch (-want +got):\n%s", i, diff)
+		}
+	}
+}
+
+func TestRunPlanFailsOnInvalidLoan(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := runPlan([]string{"--amount", "2000", "--rate", "1.0", "--duration", "2", "--start", "2018-01-29"}, out)
+	if err == nil {
+		t.Fatalf("expected error, got output:\n%s", out)
+	}
+}
+
+func amount(t *testing.T, v string) money.Amount {
+	t.Helper()
+
+	a, err := money.Parse(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
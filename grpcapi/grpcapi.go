@@ -0,0 +1,121 @@
+// Package grpcapi implements the loanpb.LoanPlanService, exposing the
+// loan plan creation through gRPC. Just like the api package it is only a
+// delivery mechanism on top of the loan package, and the responses use
+// the same representation of money values of the HTTP API (decimals as
+// strings), so values match byte-for-byte on both APIs.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/loan"
+	"github.com/katcipis/loaner/loanpb"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server is the gRPC implementation of the loanpb.LoanPlanService.
+type Server struct {
+	loanpb.UnimplementedLoanPlanServiceServer
+
+	createLoanPlan api.LoanPlanCreator
+}
+
+// defaultCurrency is used when no currency is informed,
+// rounding money values to 2 decimal places as the HTTP API does.
+var defaultCurrency = loan.Currency{MinorUnits: 2}
+
+// New creates a new gRPC loan plan service that creates the loan
+// plans with the given creator, like loan.CreatePlanForCurrencyContext.
+// It can be registered on a gRPC server with loanpb.RegisterLoanPlanServiceServer.
+func New(createLoanPlan api.LoanPlanCreator) *Server {
+	return &Server{createLoanPlan: createLoanPlan}
+}
+
+// CreateLoanPlan creates the payment plan of an annuity loan.
+// Invalid parameters are reported with codes.InvalidArgument.
+func (s *Server) CreateLoanPlan(
+	ctx context.Context,
+	req *loanpb.CreateLoanPlanRequest,
+) (*loanpb.CreateLoanPlanResponse, error) {
+
+	loanAmount, err := decimal.NewFromString(req.GetLoanAmount())
+	if err != nil {
+		return nil, invalidArgument("loan_amount", err)
+	}
+
+	annualInterestRate, err := decimal.NewFromString(req.GetNominalRate())
+	if err != nil {
+		return nil, invalidArgument("nominal_rate", err)
+	}
+
+	start := loan.DefaultStartDate()
+	if req.GetStartDate() != "" {
+		start, err = time.Parse(time.RFC3339, req.GetStartDate())
+		if err != nil {
+			return nil, invalidArgument("start_date", err)
+		}
+	}
+
+	currency := defaultCurrency
+	if req.GetCurrency() != "" {
+		currency, err = loan.CurrencyFromCode(req.GetCurrency())
+		if err != nil {
+			return nil, invalidArgument("currency", err)
+		}
+	}
+
+	payments, err := s.createLoanPlan(
+		ctx,
+		loanAmount,
+		annualInterestRate,
+		int(req.GetDuration()),
+		start,
+		currency,
+	)
+	if err != nil {
+		if errors.Is(err, loan.ErrInvalidParameter) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, status.FromContextError(ctxErr).Err()
+		}
+		return nil, status.Errorf(codes.Internal, "can't create loan plan:%v", err)
+	}
+
+	return toLoanPlanResponse(api.NewCreateLoanPlanResponse(payments)), nil
+}
+
+func invalidArgument(field string, err error) error {
+	return status.Error(codes.InvalidArgument, fmt.Sprintf("invalid %s:%v", field, err))
+}
+
+func toLoanPlanResponse(resp api.CreateLoanPlanResponse) *loanpb.CreateLoanPlanResponse {
+	payments := make([]*loanpb.BorrowerPayment, len(resp.BorrowerPayments))
+	for i, p := range resp.BorrowerPayments {
+		payments[i] = &loanpb.BorrowerPayment{
+			Date:                          p.Date,
+			BorrowerPaymentAmount:         p.PaymentAmount.String(),
+			Interest:                      p.Interest.String(),
+			Principal:                     p.Principal.String(),
+			InitialOutstandingPrincipal:   p.InitialOutstandingPrincipal.String(),
+			RemainingOutstandingPrincipal: p.RemainingOutstandingPrincipal.String(),
+			DaysInPeriod:                  int32(p.DaysInPeriod),
+		}
+	}
+	return &loanpb.CreateLoanPlanResponse{
+		BorrowerPayments: payments,
+		Total:            int32(resp.Total),
+		PayoffDate:       resp.PayoffDate,
+		Summary: &loanpb.LoanPlanSummary{
+			TotalPrincipal: resp.Summary.TotalPrincipal.String(),
+			TotalInterest:  resp.Summary.TotalInterest.String(),
+			TotalPayment:   resp.Summary.TotalPayment.String(),
+		},
+	}
+}
@@ -0,0 +1,308 @@
+package grpcapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/api"
+	"github.com/katcipis/loaner/grpcapi"
+	"github.com/katcipis/loaner/loan"
+	"github.com/katcipis/loaner/loanpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestLoanPlanCreation(t *testing.T) {
+	type Test struct {
+		name    string
+		request *loanpb.CreateLoanPlanRequest
+		want    *loanpb.CreateLoanPlanResponse
+	}
+
+	// Same expectations of the HTTP API integration tests
+	tests := []Test{
+		{
+			name: "SuccessOn2000LoanWith1.0RateIn2Months",
+			request: &loanpb.CreateLoanPlanRequest{
+				LoanAmount:  "2000.0",
+				NominalRate: "1.0",
+				Duration:    2,
+				StartDate:   "2018-01-01T00:00:00Z",
+			},
+			want: &loanpb.CreateLoanPlanResponse{
+				BorrowerPayments: []*loanpb.BorrowerPayment{
+					{
+						Date:                          "2018-01-01T00:00:00Z",
+						BorrowerPaymentAmount:         "1001.25",
+						Interest:                      "1.67",
+						Principal:                     "999.58",
+						InitialOutstandingPrincipal:   "2000",
+						RemainingOutstandingPrincipal: "1000.42",
+						DaysInPeriod:                  30,
+					},
+					{
+						Date:                          "2018-02-01T00:00:00Z",
+						BorrowerPaymentAmount:         "1001.25",
+						Interest:                      "0.83",
+						Principal:                     "1000.42",
+						InitialOutstandingPrincipal:   "1000.42",
+						RemainingOutstandingPrincipal: "0",
+						DaysInPeriod:                  30,
+					},
+				},
+				Total:      2,
+				PayoffDate: "2018-02-01T00:00:00Z",
+				Summary: &loanpb.LoanPlanSummary{
+					TotalPrincipal: "2000",
+					TotalInterest:  "2.5",
+					TotalPayment:   "2002.5",
+				},
+			},
+		},
+		{
+			name: "SuccessOn200000JPYLoanWith1.0RateIn2Months",
+			request: &loanpb.CreateLoanPlanRequest{
+				LoanAmount:  "200000",
+				NominalRate: "1.0",
+				Duration:    2,
+				StartDate:   "2018-01-01T00:00:00Z",
+				Currency:    "JPY",
+			},
+			want: &loanpb.CreateLoanPlanResponse{
+				BorrowerPayments: []*loanpb.BorrowerPayment{
+					{
+						Date:                          "2018-01-01T00:00:00Z",
+						BorrowerPaymentAmount:         "100125",
+						Interest:                      "167",
+						Principal:                     "99958",
+						InitialOutstandingPrincipal:   "200000",
+						RemainingOutstandingPrincipal: "100042",
+						DaysInPeriod:                  30,
+					},
+					{
+						Date:                          "2018-02-01T00:00:00Z",
+						BorrowerPaymentAmount:         "100125",
+						Interest:                      "83",
+						Principal:                     "100042",
+						InitialOutstandingPrincipal:   "100042",
+						RemainingOutstandingPrincipal: "0",
+						DaysInPeriod:                  30,
+					},
+				},
+				Total:      2,
+				PayoffDate: "2018-02-01T00:00:00Z",
+				Summary: &loanpb.LoanPlanSummary{
+					TotalPrincipal: "200000",
+					TotalInterest:  "250",
+					TotalPayment:   "200250",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := newClient(t)
+
+			got, err := client.CreateLoanPlan(context.Background(), test.request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("loan plan mismatch (-want +got):\n%s", diff)
+			}
+
+			httpResp := createHTTPLoanPlan(t, test.request)
+			if diff := cmp.Diff(httpResp, got, protocmp.Transform()); diff != "" {
+				t.Errorf("gRPC loan plan doesn't match the HTTP API (-http +grpc):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoanPlanCreationInvalidArgument(t *testing.T) {
+	type Test struct {
+		name    string
+		request *loanpb.CreateLoanPlanRequest
+	}
+
+	valid := func() *loanpb.CreateLoanPlanRequest {
+		return &loanpb.CreateLoanPlanRequest{
+			LoanAmount:  "2000.0",
+			NominalRate: "1.0",
+			Duration:    2,
+			StartDate:   "2018-01-01T00:00:00Z",
+		}
+	}
+
+	tests := []Test{
+		{
+			name: "LoanAmountIsNotDecimal",
+			request: func() *loanpb.CreateLoanPlanRequest {
+				req := valid()
+				req.LoanAmount = "notADecimal"
+				return req
+			}(),
+		},
+		{
+			name: "NominalRateIsNotDecimal",
+			request: func() *loanpb.CreateLoanPlanRequest {
+				req := valid()
+				req.NominalRate = "wrongValue"
+				return req
+			}(),
+		},
+		{
+			name: "StartDateIsNotValidDate",
+			request: func() *loanpb.CreateLoanPlanRequest {
+				req := valid()
+				req.StartDate = "notDate"
+				return req
+			}(),
+		},
+		{
+			name: "CurrencyIsUnknown",
+			request: func() *loanpb.CreateLoanPlanRequest {
+				req := valid()
+				req.Currency = "notACurrency"
+				return req
+			}(),
+		},
+		{
+			name: "DurationOfOneBillionMonths",
+			request: func() *loanpb.CreateLoanPlanRequest {
+				req := valid()
+				req.Duration = 1000000000
+				return req
+			}(),
+		},
+		{
+			name: "StartDateDayIsBiggerThan28",
+			request: func() *loanpb.CreateLoanPlanRequest {
+				req := valid()
+				req.StartDate = "2018-01-29T00:00:00Z"
+				return req
+			}(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := newClient(t)
+
+			got, err := client.CreateLoanPlan(context.Background(), test.request)
+			if code := status.Code(err); code != codes.InvalidArgument {
+				t.Fatalf("got code %v (err %v, response %v); want %v", code, err, got, codes.InvalidArgument)
+			}
+		})
+	}
+}
+
+func newClient(t *testing.T) loanpb.LoanPlanServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	loanpb.RegisterLoanPlanServiceServer(server, grpcapi.New(loan.CreatePlanForCurrencyContext))
+
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			t.Errorf("gRPC server failed: %v", err)
+		}
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return loanpb.NewLoanPlanServiceClient(conn)
+}
+
+// createHTTPLoanPlan creates the loan plan of the request through the
+// HTTP API, keeping the money values exactly as they are on the JSON.
+func createHTTPLoanPlan(t *testing.T, req *loanpb.CreateLoanPlanRequest) *loanpb.CreateLoanPlanResponse {
+	t.Helper()
+
+	query := url.Values{}
+	query.Set("loanAmount", req.GetLoanAmount())
+	query.Set("nominalRate", req.GetNominalRate())
+	query.Set("duration", strconv.Itoa(int(req.GetDuration())))
+	query.Set("startDate", req.GetStartDate())
+	if req.GetCurrency() != "" {
+		query.Set("currency", req.GetCurrency())
+	}
+
+	service := api.New(loan.CreatePlanForCurrencyContext)
+	res := httptest.NewRecorder()
+	service.ServeHTTP(res, httptest.NewRequest(http.MethodGet, api.CreateLoanPlanPath+"?"+query.Encode(), nil))
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("got HTTP response %d want %d; body: %s", res.Code, http.StatusOK, res.Body)
+	}
+
+	// Money values are decoded as strings, so they are compared
+	// exactly as they are written by the HTTP API.
+	var httpResp struct {
+		BorrowerPayments []struct {
+			Date                          string `json:"date"`
+			PaymentAmount                 string `json:"borrowerPaymentAmount"`
+			Interest                      string `json:"interest"`
+			Principal                     string `json:"principal"`
+			InitialOutstandingPrincipal   string `json:"initialOutstandingPrincipal"`
+			RemainingOutstandingPrincipal string `json:"remainingOutstandingPrincipal"`
+			DaysInPeriod                  int32  `json:"daysInPeriod"`
+		} `json:"borrowerPayments"`
+		Total      int32  `json:"total"`
+		PayoffDate string `json:"payoffDate"`
+		Summary    struct {
+			TotalPrincipal string `json:"totalPrincipal"`
+			TotalInterest  string `json:"totalInterest"`
+			TotalPayment   string `json:"totalPayment"`
+		} `json:"summary"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&httpResp); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &loanpb.CreateLoanPlanResponse{
+		Total:      httpResp.Total,
+		PayoffDate: httpResp.PayoffDate,
+		Summary: &loanpb.LoanPlanSummary{
+			TotalPrincipal: httpResp.Summary.TotalPrincipal,
+			TotalInterest:  httpResp.Summary.TotalInterest,
+			TotalPayment:   httpResp.Summary.TotalPayment,
+		},
+	}
+	for _, p := range httpResp.BorrowerPayments {
+		want.BorrowerPayments = append(want.BorrowerPayments, &loanpb.BorrowerPayment{
+			Date:                          p.Date,
+			BorrowerPaymentAmount:         p.PaymentAmount,
+			Interest:                      p.Interest,
+			Principal:                     p.Principal,
+			InitialOutstandingPrincipal:   p.InitialOutstandingPrincipal,
+			RemainingOutstandingPrincipal: p.RemainingOutstandingPrincipal,
+			DaysInPeriod:                  p.DaysInPeriod,
+		})
+	}
+	return want
+}
@@ -0,0 +1,53 @@
+package loan
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CreateBalloonPlan will create a payment plan, as a list of payments,
+// throughout the lifetime of a balloon loan.
+//
+// The payments are calculated as an annuity loan amortized over
+// amortizationMonths, but the loan is due in full after termMonths.
+// The last payment includes all the remaining outstanding principal
+// at that point (the balloon).
+//
+// The annual interest rate is informed as a percent, like 5.0, meaning 5 per cent an year.
+//
+// It returns an error if any of the parameters is invalid, like the term
+// being zero or bigger than the amortization months, or the start
+// date has a day bigger than 28.
+func CreateBalloonPlan(
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	amortizationMonths int,
+	termMonths int,
+	start time.Time,
+) ([]Payment, error) {
+
+	if termMonths <= 0 || termMonths > amortizationMonths {
+		return nil, fmt.Errorf(
+			"can't create balloon loan plan:%w: term months should be in the range [1, %d], it is %d",
+			ErrInvalidParameter,
+			amortizationMonths,
+			termMonths,
+		)
+	}
+
+	payments, err := createPlan(context.Background(), totalLoanAmount, annualInterestRate, amortizationMonths, start, defaultPlanConfig())
+	if err != nil {
+		return nil, fmt.Errorf("can't create balloon loan plan:%w", err)
+	}
+
+	payments = payments[:termMonths]
+	balloon := &payments[len(payments)-1]
+	balloon.Principal = balloon.InitialOutstandingPrincipal
+	balloon.PaymentAmount = balloon.Principal.Add(balloon.Interest)
+	balloon.RemainingOutstandingPrincipal = decimal.Zero
+
+	return payments, nil
+}
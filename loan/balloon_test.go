@@ -0,0 +1,121 @@
+package loan_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestCreateBalloonPlan(t *testing.T) {
+
+	type Test struct {
+		name               string
+		amortizationMonths int
+		termMonths         int
+		want               []loan.Payment
+		wantErr            error
+	}
+
+	tests := []Test{
+		{
+			name:               "BalloonOnSecondMonthOf3MonthsAmortization",
+			amortizationMonths: 3,
+			termMonths:         2,
+			want: []loan.Payment{
+				{
+					Date:                          parseTime(t, "2018-01-01T00:00:00Z"),
+					PaymentAmount:                 toDecimal(t, "1001.67"),
+					Interest:                      toDecimal(t, "2.50"),
+					Principal:                     toDecimal(t, "999.17"),
+					InitialOutstandingPrincipal:   toDecimal(t, "3000"),
+					RemainingOutstandingPrincipal: toDecimal(t, "2000.83"),
+					DaysInPeriod:                  30,
+				},
+				{
+					Date:                          parseTime(t, "2018-02-01T00:00:00Z"),
+					PaymentAmount:                 toDecimal(t, "2002.50"),
+					Interest:                      toDecimal(t, "1.67"),
+					Principal:                     toDecimal(t, "2000.83"),
+					InitialOutstandingPrincipal:   toDecimal(t, "2000.83"),
+					RemainingOutstandingPrincipal: toDecimal(t, "0"),
+					DaysInPeriod:                  30,
+				},
+			},
+		},
+		{
+			name:               "TermEqualToAmortizationIsSameAsAnnuity",
+			amortizationMonths: 2,
+			termMonths:         2,
+			want:               createPlan(t, "3000.0", "1.0", 2),
+		},
+		{
+			name:               "ErrorIfTermIsBiggerThanAmortization",
+			amortizationMonths: 2,
+			termMonths:         3,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfTermIsZero",
+			amortizationMonths: 2,
+			termMonths:         0,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := loan.CreateBalloonPlan(
+				toDecimal(t, "3000.0"),
+				toDecimal(t, "1.0"),
+				test.amortizationMonths,
+				test.termMonths,
+				parseTime(t, "2018-01-01T00:00:00Z"),
+			)
+
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("got error %v; want %v", err, test.wantErr)
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("CreateBalloonPlan() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBalloonPaymentIncludesOutstandingPrincipal(t *testing.T) {
+	const termMonths = 60
+
+	amortization := createPlan(t, "200000", "4.0", 360)
+	payments, err := loan.CreateBalloonPlan(
+		toDecimal(t, "200000"),
+		toDecimal(t, "4.0"),
+		360,
+		termMonths,
+		parseTime(t, "2018-01-01T00:00:00Z"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(payments) != termMonths {
+		t.Fatalf("got %d payments; want %d", len(payments), termMonths)
+	}
+
+	if diff := cmp.Diff(amortization[:termMonths-1], payments[:termMonths-1]); diff != "" {
+		t.Errorf("payments before balloon mismatch (-want +got):\n%s", diff)
+	}
+
+	balloon := payments[termMonths-1]
+	wantPrincipal := amortization[termMonths-1].InitialOutstandingPrincipal
+
+	if !balloon.Principal.Equal(wantPrincipal) {
+		t.Errorf("got balloon principal %v; want %v", balloon.Principal, wantPrincipal)
+	}
+
+	if !balloon.RemainingOutstandingPrincipal.IsZero() {
+		t.Errorf("got remaining principal %v on balloon; want 0", balloon.RemainingOutstandingPrincipal)
+	}
+}
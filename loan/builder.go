@@ -0,0 +1,116 @@
+package loan
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PlanOption customizes how a payment plan is built by BuildPlan.
+type PlanOption func(*planConfig)
+
+// WithFrequency sets the frequency of the payments.
+// The default is Monthly.
+func WithFrequency(f Frequency) PlanOption {
+	return func(cfg *planConfig) {
+		cfg.frequency = f
+	}
+}
+
+// WithDayCount sets the day count convention used to calculate
+// the interest of each period. The default is Thirty360.
+func WithDayCount(d DayCount) PlanOption {
+	return func(cfg *planConfig) {
+		cfg.dayCount = d
+	}
+}
+
+// WithRoundingMode sets how the money values of the plan are rounded, the
+// annuity and all values of each payment are rounded with the same mode.
+// The default is HalfEven.
+func WithRoundingMode(m RoundingMode) PlanOption {
+	return func(cfg *planConfig) {
+		cfg.rounding = m
+	}
+}
+
+// WithCurrency rounds all money values of the payments to the minor units
+// of the given currency. The default is to round to 2 decimal places.
+func WithCurrency(c Currency) PlanOption {
+	return func(cfg *planConfig) {
+		cfg.precision = c.MinorUnits
+	}
+}
+
+// WithMaxDuration sets the max duration of the loan in months, plans
+// with more periods than that (for the configured frequency) are rejected.
+// The default is DefaultMaxDurationInMonths.
+func WithMaxDuration(months int) PlanOption {
+	return func(cfg *planConfig) {
+		cfg.maxDurationInMonths = months
+	}
+}
+
+// WithMonthEndDates allows start dates on any day of the month for month
+// based frequencies, instead of only on days 1-28. When the day of the start
+// date doesn't exist on the month of a payment the last day of the month is
+// used, like a plan starting on Jan 31 having payments on Feb 29 on leap years,
+// Feb 28 otherwise and then Mar 31.
+func WithMonthEndDates() PlanOption {
+	return func(cfg *planConfig) {
+		cfg.monthEndDates = true
+	}
+}
+
+// WithFirstPaymentOffset schedules the first payment the given number of
+// months after the start date, like when the loan is disbursed on the start
+// date but the first payment is only due a month later. All payment dates
+// are shifted by the offset.
+//
+// Interest still accrues from the start date, so the interest of the offset
+// months is capitalized (compounded monthly and added to the principal)
+// before the payments are calculated. The first payment informs the
+// capitalized principal as its InitialOutstandingPrincipal.
+// The default is no offset.
+func WithFirstPaymentOffset(months int) PlanOption {
+	return func(cfg *planConfig) {
+		cfg.firstPaymentOffset = months
+	}
+}
+
+// BuildPlan will create a payment plan, as a list of payments,
+// throughout the lifetime of an annuity loan with the given number
+// of periods (payments).
+//
+// It works exactly as CreatePlan if no options are provided, the
+// options can be used to customize how the plan is built, like
+// using a different payment frequency. The periodic interest rate
+// is scaled according to the frequency, like annualInterestRate/52 for
+// weekly payments.
+//
+// The annual interest rate is informed as a percent, like 5.0, meaning 5 per cent an year.
+//
+// A zero start date starts the plan on the current date of the clock (see
+// WithClock), or on the first day of the next month when the current day
+// is bigger than 28 for month based frequencies.
+//
+// It returns an error if any of the parameters is invalid, like the
+// number of periods being zero or the start date has a day bigger than 28
+// for month based frequencies (unless WithMonthEndDates is used).
+func BuildPlan(
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	periods int,
+	start time.Time,
+	opts ...PlanOption,
+) ([]Payment, error) {
+	cfg := defaultPlanConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if start.IsZero() {
+		start = cfg.defaultStartDate()
+	}
+	return createPlan(context.Background(), totalLoanAmount, annualInterestRate, periods, start, cfg)
+}
@@ -0,0 +1,58 @@
+package loan
+
+import "time"
+
+// Clock is the source of the current time used to build payment plans,
+// like when the plan has no start date and starts on the current date.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock is the default Clock, using the time of the system.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// ClockFunc adapts a function, like time.Now, to a Clock.
+type ClockFunc func() time.Time
+
+// Now returns the result of calling the function.
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// WithClock sets the clock used to get the current time, so plans that
+// depend on it can be built deterministically, like on tests.
+// A nil clock is ignored. The default is the time of the system.
+func WithClock(c Clock) PlanOption {
+	return func(cfg *planConfig) {
+		if c != nil {
+			cfg.clock = c
+		}
+	}
+}
+
+// DefaultStartDate returns the start date of plans built without one
+// (with the given options), like on BuildPlan. It depends on the clock
+// (see WithClock), on the frequency and on WithMonthEndDates.
+func DefaultStartDate(opts ...PlanOption) time.Time {
+	cfg := defaultPlanConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg.defaultStartDate()
+}
+
+// defaultStartDate is the start date of plans built without one, which
+// is the current date (UTC) of the clock. For month based frequencies
+// days bigger than 28 are not valid start dates (unless WithMonthEndDates
+// is used), so the plan starts on the first day of the next month instead.
+func (cfg planConfig) defaultStartDate() time.Time {
+	now := cfg.clock.Now().UTC()
+	if now.Day() > 28 && cfg.frequency.monthBased() && !cfg.monthEndDates {
+		return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
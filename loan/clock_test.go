@@ -0,0 +1,105 @@
+package loan_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/katcipis/loaner/loan"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestBuildPlanStartsOnClockDate(t *testing.T) {
+
+	type Test struct {
+		name          string
+		now           string
+		start         time.Time
+		opts          []loan.PlanOption
+		wantStartDate string
+	}
+
+	tests := []Test{
+		{
+			name:          "Today",
+			now:           "2020-03-15T13:45:00Z",
+			wantStartDate: "2020-03-15T00:00:00Z",
+		},
+		{
+			name:          "TodayOnUTC",
+			now:           "2020-03-15T22:00:00-03:00",
+			wantStartDate: "2020-03-16T00:00:00Z",
+		},
+		{
+			name:          "NextMonthAfterDay28",
+			now:           "2020-12-30T10:00:00Z",
+			wantStartDate: "2021-01-01T00:00:00Z",
+		},
+		{
+			name:          "TodayAfterDay28WithMonthEndDates",
+			now:           "2020-01-30T10:00:00Z",
+			opts:          []loan.PlanOption{loan.WithMonthEndDates()},
+			wantStartDate: "2020-01-30T00:00:00Z",
+		},
+		{
+			name:          "TodayAfterDay28WithWeeklyPayments",
+			now:           "2020-01-30T10:00:00Z",
+			opts:          []loan.PlanOption{loan.WithFrequency(loan.Weekly)},
+			wantStartDate: "2020-01-30T00:00:00Z",
+		},
+		{
+			name:          "InformedStartDateIgnoresClock",
+			now:           "2020-03-15T13:45:00Z",
+			start:         parseTime(t, "2018-01-01T00:00:00Z"),
+			wantStartDate: "2018-01-01T00:00:00Z",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clock := fakeClock{now: parseTime(t, test.now)}
+			opts := append([]loan.PlanOption{loan.WithClock(clock)}, test.opts...)
+
+			payments, err := loan.BuildPlan(toDecimal(t, "1000"), toDecimal(t, "5"), 3, test.start, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := parseTime(t, test.wantStartDate)
+			if got := payments[0].Date; !got.Equal(want) {
+				t.Errorf("got first payment on %v; want %v", got, want)
+			}
+			if test.start.IsZero() {
+				if got := loan.DefaultStartDate(opts...); !got.Equal(want) {
+					t.Errorf("got default start date %v; want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestClockFunc(t *testing.T) {
+	now := parseTime(t, "2020-03-15T13:45:00Z")
+	clock := loan.ClockFunc(func() time.Time { return now })
+
+	got := loan.DefaultStartDate(loan.WithClock(clock))
+	if want := parseTime(t, "2020-03-15T00:00:00Z"); !got.Equal(want) {
+		t.Errorf("got default start date %v; want %v", got, want)
+	}
+}
+
+func TestBuildPlanIgnoresNilClock(t *testing.T) {
+	payments, err := loan.BuildPlan(toDecimal(t, "1000"), toDecimal(t, "5"), 3, time.Time{}, loan.WithClock(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payments[0].Date.IsZero() {
+		t.Error("got zero start date; want the current date of the system")
+	}
+}
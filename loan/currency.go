@@ -0,0 +1,47 @@
+package loan
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Currency represents a currency as defined by ISO 4217.
+type Currency struct {
+	// Code is the ISO 4217 alphabetic code of the currency, like "EUR".
+	Code string
+	// MinorUnits is the number of decimal places of the
+	// currency minor unit, like 2 for EUR (cents) and 0 for JPY.
+	MinorUnits int
+}
+
+// Some commonly used currencies.
+var (
+	USD = Currency{Code: "USD", MinorUnits: 2}
+	EUR = Currency{Code: "EUR", MinorUnits: 2}
+	GBP = Currency{Code: "GBP", MinorUnits: 2}
+	BRL = Currency{Code: "BRL", MinorUnits: 2}
+	JPY = Currency{Code: "JPY", MinorUnits: 0}
+	KRW = Currency{Code: "KRW", MinorUnits: 0}
+	BHD = Currency{Code: "BHD", MinorUnits: 3}
+	KWD = Currency{Code: "KWD", MinorUnits: 3}
+)
+
+// CurrencyFromCode returns the currency that has the given ISO 4217 code.
+// The code is case insensitive.
+//
+// It returns an error if the currency is unknown.
+func CurrencyFromCode(code string) (Currency, error) {
+	for _, c := range currencies {
+		if strings.EqualFold(c.Code, code) {
+			return c, nil
+		}
+	}
+	return Currency{}, fmt.Errorf("%w:unknown currency %q", ErrInvalidParameter, code)
+}
+
+var currencies = []Currency{USD, EUR, GBP, BRL, JPY, KRW, BHD, KWD}
+
+// Currencies returns all the currencies known by CurrencyFromCode.
+func Currencies() []Currency {
+	return append([]Currency(nil), currencies...)
+}
@@ -0,0 +1,102 @@
+package loan_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/loan"
+	"github.com/shopspring/decimal"
+)
+
+func TestCreatePlanForZeroMinorUnitsCurrency(t *testing.T) {
+	loanAmount := toDecimal(t, "500000")
+	payments, err := loan.CreatePlanForCurrency(
+		loanAmount,
+		toDecimal(t, "5.0"),
+		24,
+		parseTime(t, "2018-01-01T00:00:00Z"),
+		loan.JPY,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(payments) != 24 {
+		t.Fatalf("got %d payments; want 24", len(payments))
+	}
+
+	totalPrincipal := decimal.Zero
+	for i, p := range payments {
+		moneyValues := []decimal.Decimal{
+			p.PaymentAmount,
+			p.Interest,
+			p.Principal,
+			p.InitialOutstandingPrincipal,
+			p.RemainingOutstandingPrincipal,
+		}
+		for _, v := range moneyValues {
+			if !v.Equal(v.Truncate(0)) {
+				t.Errorf("payment %d: got %v; want a whole number, payment: %v", i, v, p)
+			}
+		}
+		totalPrincipal = totalPrincipal.Add(p.Principal)
+	}
+
+	if !totalPrincipal.Equal(loanAmount) {
+		t.Errorf("got total principal %v; want %v", totalPrincipal, loanAmount)
+	}
+
+	lastPayment := payments[len(payments)-1]
+	if !lastPayment.RemainingOutstandingPrincipal.IsZero() {
+		t.Errorf("got remaining principal %v on last payment; want 0", lastPayment.RemainingOutstandingPrincipal)
+	}
+}
+
+func TestCreatePlanForTwoMinorUnitsCurrencyIsSameAsDefault(t *testing.T) {
+	loanAmount := toDecimal(t, "5000")
+	interestRate := toDecimal(t, "5.0")
+	start := parseTime(t, "2018-01-01T00:00:00Z")
+
+	want, err := loan.CreatePlan(loanAmount, interestRate, 24, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := loan.CreatePlanForCurrency(loanAmount, interestRate, 24, start, loan.EUR)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CreatePlanForCurrency() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCurrencyFromCode(t *testing.T) {
+	type Test struct {
+		code    string
+		want    loan.Currency
+		wantErr error
+	}
+
+	tests := []Test{
+		{code: "EUR", want: loan.EUR},
+		{code: "jpy", want: loan.JPY},
+		{code: "KWD", want: loan.KWD},
+		{code: "XXX", wantErr: loan.ErrInvalidParameter},
+		{code: "", wantErr: loan.ErrInvalidParameter},
+	}
+
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			got, err := loan.CurrencyFromCode(test.code)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("got error %v; want %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("got currency %v; want %v", got, test.want)
+			}
+		})
+	}
+}
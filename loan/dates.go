@@ -0,0 +1,120 @@
+package loan
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CreatePlanWithDates will create a payment plan, as a list of payments,
+// throughout the lifetime of an annuity loan with irregular payment dates,
+// like commercial loans where the lender informs the date of each payment.
+//
+// Unlike CreatePlan the start date is the date when the loan is taken,
+// not the date of the first payment. There is one payment for each of the
+// given dates, and the interest of each payment accrues over the actual
+// days since the previous payment (or since the start date, for the first
+// payment), with the Actual365 day count. All the payments have the same
+// amount, which fully amortizes the loan considering the days of each
+// period, except the last one which absorbs any rounding residual.
+// Time and timezone information of the dates are ignored, like on CreatePlan.
+//
+// The annual interest rate is informed as a percent, like 5.0, meaning 5 per cent an year.
+//
+// It returns an error if any of the parameters is invalid, like no dates
+// or dates that are not strictly increasing and after the start date.
+func CreatePlanWithDates(
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	dates []time.Time,
+	start time.Time,
+) ([]Payment, error) {
+
+	if err := validateParameters(totalLoanAmount, annualInterestRate, len(dates)); err != nil {
+		return nil, fmt.Errorf("can't create loan plan with dates:%w", err)
+	}
+
+	start = toDate(start)
+	periodStarts := make([]time.Time, len(dates))
+	periodEnds := make([]time.Time, len(dates))
+	previous := start
+	for i, date := range dates {
+		date = toDate(date)
+		if !date.After(previous) {
+			return nil, fmt.Errorf(
+				"can't create loan plan with dates:%w: date %d (%v) should be after %v",
+				ErrInvalidParameter,
+				i,
+				date.Format("2006-01-02"),
+				previous.Format("2006-01-02"),
+			)
+		}
+		periodStarts[i] = previous
+		periodEnds[i] = date
+		previous = date
+	}
+
+	rate := fromPercentToDecimal(annualInterestRate)
+	periodicRates := make([]decimal.Decimal, len(dates))
+	for i := range dates {
+		periodicRates[i] = rate.Mul(Actual365.yearFraction(periodStarts[i], periodEnds[i]))
+	}
+	annuity := calculateAnnuityWithPeriodicRates(totalLoanAmount, periodicRates, precision)
+
+	payments := make([]Payment, len(dates))
+	initialOutstandingPrincipal := totalLoanAmount
+
+	for i := range dates {
+		interest := initialOutstandingPrincipal.Mul(periodicRates[i]).RoundBank(precision)
+		if err := validateAmortization(i, annuity, interest); err != nil {
+			return nil, fmt.Errorf("can't create loan plan with dates:%w", err)
+		}
+
+		principal := annuity.Sub(interest)
+		if i == len(dates)-1 || principal.GreaterThan(initialOutstandingPrincipal) {
+			principal = initialOutstandingPrincipal
+		}
+		remainingOutstandingPrincipal := initialOutstandingPrincipal.Sub(principal)
+
+		payments[i] = Payment{
+			Date:                          periodEnds[i],
+			PaymentAmount:                 principal.Add(interest),
+			Interest:                      interest,
+			Principal:                     principal,
+			InitialOutstandingPrincipal:   initialOutstandingPrincipal,
+			RemainingOutstandingPrincipal: remainingOutstandingPrincipal,
+			DaysInPeriod:                  int(daysBetween(periodStarts[i], periodEnds[i]).IntPart()),
+		}
+
+		initialOutstandingPrincipal = remainingOutstandingPrincipal
+	}
+
+	return payments, nil
+}
+
+// calculateAnnuityWithPeriodicRates calculates the annuity of a loan whose
+// periods have different interest rates (in their decimal form), which is
+// the loan amount divided by the sum of the discount factors of all payments.
+// With regular periods it is the same as calculateAnnuity.
+func calculateAnnuityWithPeriodicRates(
+	totalLoanAmount decimal.Decimal,
+	periodicRates []decimal.Decimal,
+	precision int,
+) decimal.Decimal {
+	one := decimal.NewFromInt(1)
+	discount := one
+	discounts := decimal.Zero
+	for _, periodicRate := range periodicRates {
+		discount = discount.Div(one.Add(periodicRate))
+		discounts = discounts.Add(discount)
+	}
+	return totalLoanAmount.Div(discounts).RoundBank(int32(precision))
+}
+
+// toDate returns the date at midnight UTC, ignoring
+// the time after normalizing the date to UTC.
+func toDate(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
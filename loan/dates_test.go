@@ -0,0 +1,179 @@
+package loan_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/loan"
+	"github.com/shopspring/decimal"
+)
+
+func TestCreatePlanWithDates(t *testing.T) {
+	dates := []time.Time{
+		parseTime(t, "2021-01-31T00:00:00Z"),
+		parseTime(t, "2021-04-01T00:00:00Z"),
+		parseTime(t, "2021-04-11T00:00:00Z"),
+	}
+
+	payments, err := loan.CreatePlanWithDates(
+		toDecimal(t, "1000"),
+		toDecimal(t, "10.0"),
+		dates,
+		parseTime(t, "2021-01-01T00:00:00Z"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []loan.Payment{
+		{
+			Date:                          parseTime(t, "2021-01-31T00:00:00Z"),
+			PaymentAmount:                 toDecimal(t, "340.04"),
+			Interest:                      toDecimal(t, "8.22"),
+			Principal:                     toDecimal(t, "331.82"),
+			InitialOutstandingPrincipal:   toDecimal(t, "1000"),
+			RemainingOutstandingPrincipal: toDecimal(t, "668.18"),
+			DaysInPeriod:                  30,
+		},
+		{
+			Date:                          parseTime(t, "2021-04-01T00:00:00Z"),
+			PaymentAmount:                 toDecimal(t, "340.04"),
+			Interest:                      toDecimal(t, "10.98"),
+			Principal:                     toDecimal(t, "329.06"),
+			InitialOutstandingPrincipal:   toDecimal(t, "668.18"),
+			RemainingOutstandingPrincipal: toDecimal(t, "339.12"),
+			DaysInPeriod:                  60,
+		},
+		{
+			Date:                          parseTime(t, "2021-04-11T00:00:00Z"),
+			PaymentAmount:                 toDecimal(t, "340.05"),
+			Interest:                      toDecimal(t, "0.93"),
+			Principal:                     toDecimal(t, "339.12"),
+			InitialOutstandingPrincipal:   toDecimal(t, "339.12"),
+			RemainingOutstandingPrincipal: toDecimal(t, "0"),
+			DaysInPeriod:                  10,
+		},
+	}
+	if diff := cmp.Diff(want, payments); diff != "" {
+		t.Errorf("CreatePlanWithDates() mismatch (-want +got):\n%s", diff)
+	}
+
+	totalPrincipal := decimal.Zero
+	for i, payment := range payments {
+		totalPrincipal = totalPrincipal.Add(payment.Principal)
+
+		// The interest accrues over the actual days of each period.
+		days := decimal.NewFromInt(int64(payment.DaysInPeriod))
+		wantInterest := payment.InitialOutstandingPrincipal.
+			Mul(toDecimal(t, "0.10")).
+			Mul(days).
+			Div(decimal.NewFromInt(365)).
+			RoundBank(2)
+		if !payment.Interest.Equal(wantInterest) {
+			t.Errorf("payment %d: got interest %v; want %v for %d days", i, payment.Interest, wantInterest, payment.DaysInPeriod)
+		}
+	}
+	if !totalPrincipal.Equal(toDecimal(t, "1000")) {
+		t.Errorf("got total principal %v; want the whole loan amount 1000", totalPrincipal)
+	}
+}
+
+func TestCreatePlanWithDatesWithoutInterest(t *testing.T) {
+	payments, err := loan.CreatePlanWithDates(
+		toDecimal(t, "1000"),
+		toDecimal(t, "0"),
+		[]time.Time{
+			parseTime(t, "2021-01-10T00:00:00Z"),
+			parseTime(t, "2021-03-20T00:00:00Z"),
+			parseTime(t, "2021-03-21T00:00:00Z"),
+		},
+		parseTime(t, "2021-01-01T00:00:00Z"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantPrincipals := []string{"333.33", "333.33", "333.34"}
+	for i, payment := range payments {
+		if !payment.Interest.IsZero() {
+			t.Errorf("payment %d: got interest %v; want 0", i, payment.Interest)
+		}
+		if want := toDecimal(t, wantPrincipals[i]); !payment.Principal.Equal(want) {
+			t.Errorf("payment %d: got principal %v; want %v", i, payment.Principal, want)
+		}
+	}
+}
+
+func TestCreatePlanWithDatesFailure(t *testing.T) {
+	type Test struct {
+		name   string
+		amount string
+		rate   string
+		dates  []string
+	}
+
+	tests := []Test{
+		{
+			name:   "NoDates",
+			amount: "1000",
+			rate:   "5.0",
+		},
+		{
+			name:   "DateBeforeStart",
+			amount: "1000",
+			rate:   "5.0",
+			dates:  []string{"2020-12-31T00:00:00Z", "2021-02-01T00:00:00Z"},
+		},
+		{
+			name:   "DateOnStart",
+			amount: "1000",
+			rate:   "5.0",
+			dates:  []string{"2021-01-01T10:00:00Z", "2021-02-01T00:00:00Z"},
+		},
+		{
+			name:   "RepeatedDate",
+			amount: "1000",
+			rate:   "5.0",
+			dates:  []string{"2021-02-01T00:00:00Z", "2021-02-01T00:00:00Z"},
+		},
+		{
+			name:   "DecreasingDates",
+			amount: "1000",
+			rate:   "5.0",
+			dates:  []string{"2021-03-01T00:00:00Z", "2021-02-01T00:00:00Z"},
+		},
+		{
+			name:   "ZeroAmount",
+			amount: "0",
+			rate:   "5.0",
+			dates:  []string{"2021-02-01T00:00:00Z"},
+		},
+		{
+			name:   "NegativeRate",
+			amount: "1000",
+			rate:   "-5.0",
+			dates:  []string{"2021-02-01T00:00:00Z"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dates := make([]time.Time, len(test.dates))
+			for i, date := range test.dates {
+				dates[i] = parseTime(t, date)
+			}
+
+			_, err := loan.CreatePlanWithDates(
+				toDecimal(t, test.amount),
+				toDecimal(t, test.rate),
+				dates,
+				parseTime(t, "2021-01-01T00:00:00Z"),
+			)
+			if !errors.Is(err, loan.ErrInvalidParameter) {
+				t.Fatalf("got error %v; want %v", err, loan.ErrInvalidParameter)
+			}
+		})
+	}
+}
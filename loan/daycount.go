@@ -0,0 +1,113 @@
+package loan
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DayCount is a day count convention, which defines how the
+// interest of each period is calculated from the days of the period.
+type DayCount int
+
+const (
+	// Thirty360 considers all months to have 30 days and years 360 days,
+	// so the interest of all monthly periods is the same fraction of the
+	// annual interest, no matter the actual dates. It is the default.
+	Thirty360 DayCount = iota
+	// Actual365 uses the actual number of days of each period
+	// and considers all years to have 365 days.
+	Actual365
+	// ActualActual uses the actual number of days of each period and
+	// the actual number of days of the years (365 or 366). Periods that
+	// span two years have the days on each year accounted separately
+	// (the ISDA variant of the convention).
+	ActualActual
+	// Thirty365 considers all months to have 30 days, like Thirty360,
+	// but years to have 365 days, so the interest of monthly periods is
+	// 30/365 of the annual interest (90/365 for quarterly periods).
+	// It is unsupported for weekly and biweekly payments.
+	Thirty365
+)
+
+// thirtyDaysInPeriod is the number of days of a monthly
+// period on the 30 days based day counts.
+const thirtyDaysInPeriod = 30
+
+// String returns the name of the day count convention, like "30/360".
+func (d DayCount) String() string {
+	switch d {
+	case Thirty360:
+		return "30/360"
+	case Actual365:
+		return "actual/365"
+	case ActualActual:
+		return "actual/actual"
+	case Thirty365:
+		return "30/365"
+	}
+	return fmt.Sprintf("DayCount(%d)", int(d))
+}
+
+func (d DayCount) validate() error {
+	if d < Thirty360 || d > Thirty365 {
+		return fmt.Errorf("%w:invalid day count convention %v", ErrUnsupportedConvention, d)
+	}
+	return nil
+}
+
+// validateFrequency validates that the day count can be used to
+// calculate the interest of periods of the given frequency. Thirty365
+// relies on months of 30 days, so it is unsupported for weekly periods.
+func (d DayCount) validateFrequency(f Frequency) error {
+	if d == Thirty365 && !f.monthBased() {
+		return fmt.Errorf("%w:day count convention %v is not supported with %v payments", ErrUnsupportedConvention, d, f)
+	}
+	return nil
+}
+
+// thirtyDays returns true if the day count considers all months to have
+// 30 days, ignoring the actual dates of the periods.
+func (d DayCount) thirtyDays() bool {
+	return d == Thirty360 || d == Thirty365
+}
+
+// daysInYear returns the fixed number of days of a year
+// of the 30 days based day counts.
+func (d DayCount) daysInYear() int {
+	if d == Thirty365 {
+		return 365
+	}
+	return 360
+}
+
+// yearFraction returns the fraction of a year between the dates,
+// according to the (actual) day count convention.
+// Both dates are expected to be at midnight UTC.
+func (d DayCount) yearFraction(from time.Time, to time.Time) decimal.Decimal {
+	if d == Actual365 {
+		return daysBetween(from, to).Div(decimal.NewFromInt(365))
+	}
+
+	fraction := decimal.Zero
+	for from.Before(to) {
+		nextYear := time.Date(from.Year()+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+		end := to
+		if nextYear.Before(to) {
+			end = nextYear
+		}
+		fraction = fraction.Add(daysBetween(from, end).Div(daysInYear(from.Year())))
+		from = end
+	}
+	return fraction
+}
+
+func daysBetween(from time.Time, to time.Time) decimal.Decimal {
+	return decimal.NewFromInt(int64(to.Sub(from).Hours() / 24))
+}
+
+func daysInYear(year int) decimal.Decimal {
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return daysBetween(start, start.AddDate(1, 0, 0))
+}
@@ -0,0 +1,288 @@
+package loan_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/loan"
+	"github.com/shopspring/decimal"
+)
+
+func TestDayCountInterest(t *testing.T) {
+
+	type Test struct {
+		name         string
+		startDate    string
+		dayCount     loan.DayCount
+		wantInterest string
+	}
+
+	tests := []Test{
+		{
+			name:         "FebruaryLeapYear",
+			startDate:    "2020-03-01T00:00:00Z",
+			dayCount:     loan.Thirty360,
+			wantInterest: "100",
+		},
+		{
+			name:         "FebruaryLeapYear",
+			startDate:    "2020-03-01T00:00:00Z",
+			dayCount:     loan.Actual365,
+			wantInterest: "95.34",
+		},
+		{
+			name:         "FebruaryLeapYear",
+			startDate:    "2020-03-01T00:00:00Z",
+			dayCount:     loan.ActualActual,
+			wantInterest: "95.08",
+		},
+		{
+			name:         "February",
+			startDate:    "2021-03-01T00:00:00Z",
+			dayCount:     loan.Thirty360,
+			wantInterest: "100",
+		},
+		{
+			name:         "February",
+			startDate:    "2021-03-01T00:00:00Z",
+			dayCount:     loan.Actual365,
+			wantInterest: "92.05",
+		},
+		{
+			name:         "February",
+			startDate:    "2021-03-01T00:00:00Z",
+			dayCount:     loan.ActualActual,
+			wantInterest: "92.05",
+		},
+		{
+			name:         "SpanningYears",
+			startDate:    "2021-01-15T00:00:00Z",
+			dayCount:     loan.Actual365,
+			wantInterest: "101.92",
+		},
+		{
+			name:         "SpanningYears",
+			startDate:    "2021-01-15T00:00:00Z",
+			dayCount:     loan.ActualActual,
+			wantInterest: "101.77",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name+"/"+test.dayCount.String(), func(t *testing.T) {
+			payments, err := loan.BuildPlan(
+				toDecimal(t, "10000"),
+				toDecimal(t, "12.0"),
+				12,
+				parseTime(t, test.startDate),
+				loan.WithDayCount(test.dayCount),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := toDecimal(t, test.wantInterest)
+			got := payments[0].Interest
+			if !got.Equal(want) {
+				t.Errorf("got first interest %v; want %v", got, want)
+			}
+
+			principal := decimal.Zero
+			for _, payment := range payments {
+				principal = principal.Add(payment.Principal)
+			}
+			if !principal.Equal(toDecimal(t, "10000")) {
+				t.Errorf("got total principal %v; want 10000", principal)
+			}
+		})
+	}
+}
+
+func TestDayCountThirty365(t *testing.T) {
+	buildPlan := func(opts ...loan.PlanOption) []loan.Payment {
+		payments, err := loan.BuildPlan(
+			toDecimal(t, "10000"),
+			toDecimal(t, "12.0"),
+			12,
+			parseTime(t, "2021-01-01T00:00:00Z"),
+			opts...,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return payments
+	}
+
+	defaultPlan := buildPlan()
+	plan30360 := buildPlan(loan.WithDayCount(loan.Thirty360))
+	plan30365 := buildPlan(loan.WithDayCount(loan.Thirty365))
+
+	if diff := cmp.Diff(defaultPlan, plan30360); diff != "" {
+		t.Errorf("default plan differs from 30/360 plan (-default +30/360):\n%s", diff)
+	}
+
+	// 10000 * 0.12 * 30 / 365
+	wantInterest := toDecimal(t, "98.63")
+	if got := plan30365[0].Interest; !got.Equal(wantInterest) {
+		t.Errorf("got 30/365 first interest %v; want %v", got, wantInterest)
+	}
+
+	summary30360 := loan.Summarize(plan30360)
+	summary30365 := loan.Summarize(plan30365)
+
+	if !summary30365.TotalInterest.LessThan(summary30360.TotalInterest) {
+		t.Errorf("got 30/365 total interest %v; want less than 30/360 %v", summary30365.TotalInterest, summary30360.TotalInterest)
+	}
+	if !summary30365.TotalPrincipal.Equal(toDecimal(t, "10000")) {
+		t.Errorf("got 30/365 total principal %v; want 10000", summary30365.TotalPrincipal)
+	}
+}
+
+func TestDayCountThirty365Frequencies(t *testing.T) {
+
+	type Test struct {
+		name            string
+		frequency       loan.Frequency
+		periods         int
+		wantInterest    string
+		wantUnsupported bool
+	}
+
+	tests := []Test{
+		{
+			// 10000 * 0.12 * 90 / 365
+			name:         "Quarterly",
+			frequency:    loan.Quarterly,
+			periods:      4,
+			wantInterest: "295.89",
+		},
+		{
+			// 10000 * 0.12 * 360 / 365
+			name:         "Annual",
+			frequency:    loan.Annual,
+			periods:      3,
+			wantInterest: "1183.56",
+		},
+		{
+			name:            "Weekly",
+			frequency:       loan.Weekly,
+			periods:         52,
+			wantUnsupported: true,
+		},
+		{
+			name:            "Biweekly",
+			frequency:       loan.Biweekly,
+			periods:         26,
+			wantUnsupported: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payments, err := loan.BuildPlan(
+				toDecimal(t, "10000"),
+				toDecimal(t, "12.0"),
+				test.periods,
+				parseTime(t, "2021-01-01T00:00:00Z"),
+				loan.WithDayCount(loan.Thirty365),
+				loan.WithFrequency(test.frequency),
+			)
+
+			if test.wantUnsupported {
+				if !errors.Is(err, loan.ErrUnsupportedConvention) {
+					t.Fatalf("got err %v; want ErrUnsupportedConvention", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			wantInterest := toDecimal(t, test.wantInterest)
+			if got := payments[0].Interest; !got.Equal(wantInterest) {
+				t.Errorf("got first interest %v; want %v", got, wantInterest)
+			}
+
+			principal := loan.Summarize(payments).TotalPrincipal
+			if !principal.Equal(toDecimal(t, "10000")) {
+				t.Errorf("got total principal %v; want 10000", principal)
+			}
+		})
+	}
+}
+
+func TestDaysInPeriod(t *testing.T) {
+
+	type Test struct {
+		name      string
+		startDate string
+		dayCount  loan.DayCount
+		frequency loan.Frequency
+		wantDays  []int
+	}
+
+	tests := []Test{
+		{
+			name:      "Thirty360",
+			startDate: "2021-02-01T00:00:00Z",
+			dayCount:  loan.Thirty360,
+			frequency: loan.Monthly,
+			wantDays:  []int{30, 30, 30},
+		},
+		{
+			// The periods end on the payment dates, so the period of the
+			// payment on Mar 1 is February and the one on Apr 1 is March.
+			name:      "Actual365",
+			startDate: "2021-02-01T00:00:00Z",
+			dayCount:  loan.Actual365,
+			frequency: loan.Monthly,
+			wantDays:  []int{31, 28, 31},
+		},
+		{
+			name:      "ActualActualOnLeapYear",
+			startDate: "2020-02-01T00:00:00Z",
+			dayCount:  loan.ActualActual,
+			frequency: loan.Monthly,
+			wantDays:  []int{31, 29, 31},
+		},
+		{
+			name:      "Thirty360Quarterly",
+			startDate: "2021-02-01T00:00:00Z",
+			dayCount:  loan.Thirty360,
+			frequency: loan.Quarterly,
+			wantDays:  []int{90, 90, 90},
+		},
+		{
+			name:      "Thirty360Weekly",
+			startDate: "2021-02-01T00:00:00Z",
+			dayCount:  loan.Thirty360,
+			frequency: loan.Weekly,
+			wantDays:  []int{7, 7, 7},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payments, err := loan.BuildPlan(
+				toDecimal(t, "1000"),
+				toDecimal(t, "5.0"),
+				12,
+				parseTime(t, test.startDate),
+				loan.WithDayCount(test.dayCount),
+				loan.WithFrequency(test.frequency),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var gotDays []int
+			for _, p := range payments[:len(test.wantDays)] {
+				gotDays = append(gotDays, p.DaysInPeriod)
+			}
+			if diff := cmp.Diff(test.wantDays, gotDays); diff != "" {
+				t.Errorf("days in period mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package loan
+
+import "github.com/shopspring/decimal"
+
+// PaymentDiff is the difference between the payments at the
+// same Index of two payment plans. The deltas are the values
+// of the second plan minus the values of the first one.
+type PaymentDiff struct {
+	Index         int
+	PaymentAmount decimal.Decimal
+	Interest      decimal.Decimal
+	Principal     decimal.Decimal
+}
+
+// DiffPlans compares two payment plans, payment by payment, returning
+// the differences of the payments that changed, ordered by index.
+// Payments that are only on one of the plans (when the plans have
+// different durations) are compared to a payment with zero values.
+//
+// It returns no differences if the plans have the same payments.
+func DiffPlans(a, b []Payment) []PaymentDiff {
+	size := len(a)
+	if len(b) > size {
+		size = len(b)
+	}
+
+	var diffs []PaymentDiff
+	for i := 0; i < size; i++ {
+		first, second := paymentAt(a, i), paymentAt(b, i)
+		diff := PaymentDiff{
+			Index:         i,
+			PaymentAmount: second.PaymentAmount.Sub(first.PaymentAmount),
+			Interest:      second.Interest.Sub(first.Interest),
+			Principal:     second.Principal.Sub(first.Principal),
+		}
+		if diff.PaymentAmount.IsZero() && diff.Interest.IsZero() && diff.Principal.IsZero() {
+			continue
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+// paymentAt returns the payment at the index, or a payment
+// with zero values if the index is beyond the plan.
+func paymentAt(payments []Payment, index int) Payment {
+	if index < len(payments) {
+		return payments[index]
+	}
+	return Payment{
+		PaymentAmount: decimal.Zero,
+		Interest:      decimal.Zero,
+		Principal:     decimal.Zero,
+	}
+}
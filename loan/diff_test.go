@@ -0,0 +1,101 @@
+package loan_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestDiffPlansWithDifferentDurations(t *testing.T) {
+	a := createPlan(t, "5000", "5.0", 24)
+	b := createPlan(t, "5000", "5.0", 25)
+
+	diffs := loan.DiffPlans(a, b)
+
+	// The annuity changes with the duration, so all payments change.
+	if len(diffs) != 25 {
+		t.Fatalf("got %d diffs; want 25", len(diffs))
+	}
+	if diffs[0].Index != 0 {
+		t.Errorf("got first differing index %d; want 0", diffs[0].Index)
+	}
+
+	first := diffs[0]
+	if want := b[0].PaymentAmount.Sub(a[0].PaymentAmount); !first.PaymentAmount.Equal(want) {
+		t.Errorf("got payment delta %v; want %v", first.PaymentAmount, want)
+	}
+	if !first.PaymentAmount.IsNegative() {
+		t.Errorf("got payment delta %v; want negative for the longer plan", first.PaymentAmount)
+	}
+	if !first.Interest.IsZero() {
+		t.Errorf("got interest delta %v; want zero since the first interest is the same", first.Interest)
+	}
+
+	// The payment that exists only on the longer plan is compared to nothing.
+	last := diffs[24]
+	want := loan.PaymentDiff{
+		Index:         24,
+		PaymentAmount: b[24].PaymentAmount,
+		Interest:      b[24].Interest,
+		Principal:     b[24].Principal,
+	}
+	if diff := cmp.Diff(want, last); diff != "" {
+		t.Errorf("last diff mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDiffPlans(t *testing.T) {
+	type Test struct {
+		name           string
+		a              []loan.Payment
+		b              []loan.Payment
+		wantLen        int
+		wantFirstIndex int
+	}
+
+	tests := []Test{
+		{
+			name:    "SamePlans",
+			a:       createPlan(t, "5000", "5.0", 24),
+			b:       createPlan(t, "5000", "5.0", 24),
+			wantLen: 0,
+		},
+		{
+			name:    "EmptyPlans",
+			wantLen: 0,
+		},
+		{
+			name:           "DifferentRates",
+			a:              createPlan(t, "5000", "5.0", 24),
+			b:              createPlan(t, "5000", "6.0", 24),
+			wantLen:        24,
+			wantFirstIndex: 0,
+		},
+		{
+			name:           "ShorterSecondPlan",
+			a:              createPlan(t, "5000", "5.0", 24),
+			b:              createPlan(t, "5000", "5.0", 24)[:20],
+			wantLen:        4,
+			wantFirstIndex: 20,
+		},
+		{
+			name:           "EmptyFirstPlan",
+			b:              createPlan(t, "5000", "5.0", 2),
+			wantLen:        2,
+			wantFirstIndex: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diffs := loan.DiffPlans(test.a, test.b)
+			if len(diffs) != test.wantLen {
+				t.Fatalf("got %d diffs; want %d", len(diffs), test.wantLen)
+			}
+			if test.wantLen > 0 && diffs[0].Index != test.wantFirstIndex {
+				t.Errorf("got first differing index %d; want %d", diffs[0].Index, test.wantFirstIndex)
+			}
+		})
+	}
+}
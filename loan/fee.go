@@ -0,0 +1,152 @@
+package loan
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/shopspring/decimal"
+)
+
+// Fee is a one-time origination (processing) fee charged when the
+// loan is disbursed. The fee is the sum of a fixed amount and a percent
+// of the loan amount, any of them may be zero.
+//
+// By default the fee is deducted from the disbursed amount, so the
+// borrower receives the loan amount minus the fee but pays the whole
+// loan amount back. If Financed is true the fee is added to the principal
+// instead, so the borrower receives the whole loan amount and pays
+// the fee (and its interest) back along the plan.
+type Fee struct {
+	Amount   decimal.Decimal
+	Percent  decimal.Decimal
+	Financed bool
+}
+
+// WithFee charges the given origination fee on the loan. The total fee
+// is informed on the Fee field of the first payment of the plan.
+// The default is to charge no fees.
+func WithFee(fee Fee) PlanOption {
+	return func(cfg *planConfig) {
+		cfg.fee = fee
+	}
+}
+
+// WithRecurringFee charges a fixed fee on every payment of the plan, like
+// a monthly servicing fee or an insurance premium. The fee is added to the
+// amount of each payment and informed on its RecurringFee field, the
+// principal and interest of the payments are not affected.
+// The default is to charge no recurring fee.
+func WithRecurringFee(amount decimal.Decimal) PlanOption {
+	return func(cfg *planConfig) {
+		cfg.recurringFee = amount
+	}
+}
+
+func validateRecurringFee(amount decimal.Decimal) error {
+	if amount.IsNegative() {
+		return fmt.Errorf("%w: recurring fee can't be negative, it is %v", ErrInvalidParameter, amount)
+	}
+	return nil
+}
+
+// calculate calculates the total fee for the loan amount,
+// rounded to the given precision.
+func (f Fee) calculate(totalLoanAmount decimal.Decimal, places int32, mode RoundingMode) (decimal.Decimal, error) {
+	if f.Amount.IsNegative() {
+		return decimal.Zero, fmt.Errorf("%w: fee amount can't be negative, it is %v", ErrInvalidParameter, f.Amount)
+	}
+	if f.Percent.IsNegative() {
+		return decimal.Zero, fmt.Errorf("%w: fee percent can't be negative, it is %v", ErrInvalidParameter, f.Percent)
+	}
+
+	fee := mode.round(f.Amount.Add(totalLoanAmount.Mul(fromPercentToDecimal(f.Percent))), places)
+	if !f.Financed && fee.GreaterThanOrEqual(totalLoanAmount) {
+		return decimal.Zero, fmt.Errorf(
+			"%w: deducted fee %v should be smaller than the loan amount %v",
+			ErrInvalidParameter,
+			fee,
+			totalLoanAmount,
+		)
+	}
+	return fee, nil
+}
+
+// maxAPRPeriodicRate is the biggest periodic rate (as a fraction,
+// 1 being 100%) searched when calculating the APR.
+const maxAPRPeriodicRate = 1 << 20
+
+// APR calculates the annual percentage rate of the given payment plan,
+// which is the nominal annual rate that makes the present value of all
+// payments equal to the amount actually disbursed to the borrower.
+// Unlike the nominal interest rate the APR includes the fees, so it
+// reflects the true cost of the loan. The disbursed amount is the
+// outstanding principal of the first payment minus its fee.
+//
+// The payments are expected to have been made with the given frequency,
+// the first one a period after the disbursement. The APR is informed
+// as a percent, like 5.0, meaning 5 per cent an year, rounded
+// to 2 decimal places.
+//
+// It returns an error if the frequency is invalid, the plan has no
+// payments, the payments don't pay the disbursed amount back or
+// the APR can't be found (like when it is absurdly big).
+func APR(payments []Payment, f Frequency) (decimal.Decimal, error) {
+	if err := f.validate(); err != nil {
+		return decimal.Zero, fmt.Errorf("can't calculate APR:%w", err)
+	}
+	if len(payments) == 0 {
+		return decimal.Zero, fmt.Errorf("can't calculate APR:%w: plan has no payments", ErrInvalidParameter)
+	}
+
+	disbursed, _ := payments[0].InitialOutstandingPrincipal.Sub(payments[0].Fee).Float64()
+	amounts := make([]float64, len(payments))
+	total := 0.0
+	for i, p := range payments {
+		amounts[i], _ = p.PaymentAmount.Float64()
+		total += amounts[i]
+	}
+
+	if disbursed <= 0 || total < disbursed {
+		return decimal.Zero, fmt.Errorf(
+			"can't calculate APR:%w: payments total %v doesn't pay back the disbursed amount %v",
+			ErrInvalidParameter,
+			total,
+			disbursed,
+		)
+	}
+
+	presentValue := func(periodicRate float64) float64 {
+		pv := 0.0
+		for i, amount := range amounts {
+			pv += amount / math.Pow(1+periodicRate, float64(i+1))
+		}
+		return pv
+	}
+
+	// The present value decreases as the rate increases, so the rate
+	// is found with a bisection between zero and a rate big enough
+	// to make the present value smaller than the disbursed amount.
+	low, high := 0.0, 1.0
+	for presentValue(high) > disbursed {
+		high *= 2
+		if high > maxAPRPeriodicRate {
+			return decimal.Zero, fmt.Errorf(
+				"can't calculate APR:%w: no rate up to %v per period zeroes the cash flow",
+				ErrNoConvergence,
+				maxAPRPeriodicRate,
+			)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		mid := (low + high) / 2
+		if presentValue(mid) > disbursed {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+
+	periodicRate := (low + high) / 2
+	apr := periodicRate * float64(f.PeriodsPerYear()) * 100
+	return decimal.NewFromFloat(apr).RoundBank(2), nil
+}
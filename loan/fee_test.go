@@ -0,0 +1,272 @@
+package loan_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestPlanWithFee(t *testing.T) {
+
+	type Test struct {
+		name                string
+		fee                 loan.Fee
+		wantFee             string
+		wantPrincipal       string
+		wantFirstPayment    string
+		wantAPR             string
+		wantAPRAboveNominal bool
+	}
+
+	tests := []Test{
+		{
+			name:             "NoFee",
+			fee:              loan.Fee{},
+			wantFee:          "0",
+			wantPrincipal:    "5000",
+			wantFirstPayment: "219.36",
+			wantAPR:          "5",
+		},
+		{
+			name:                "DeductedAmount",
+			fee:                 loan.Fee{Amount: toDecimal(t, "100")},
+			wantFee:             "100",
+			wantPrincipal:       "5000",
+			wantFirstPayment:    "219.36",
+			wantAPR:             "6.99",
+			wantAPRAboveNominal: true,
+		},
+		{
+			name:                "DeductedPercent",
+			fee:                 loan.Fee{Percent: toDecimal(t, "2")},
+			wantFee:             "100",
+			wantPrincipal:       "5000",
+			wantFirstPayment:    "219.36",
+			wantAPR:             "6.99",
+			wantAPRAboveNominal: true,
+		},
+		{
+			name:                "DeductedAmountAndPercent",
+			fee:                 loan.Fee{Amount: toDecimal(t, "50"), Percent: toDecimal(t, "1")},
+			wantFee:             "100",
+			wantPrincipal:       "5000",
+			wantFirstPayment:    "219.36",
+			wantAPR:             "6.99",
+			wantAPRAboveNominal: true,
+		},
+		{
+			name:                "Financed",
+			fee:                 loan.Fee{Amount: toDecimal(t, "100"), Financed: true},
+			wantFee:             "100",
+			wantPrincipal:       "5100",
+			wantFirstPayment:    "223.74",
+			wantAPR:             "6.95",
+			wantAPRAboveNominal: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			nominalRate := toDecimal(t, "5.0")
+			payments, err := loan.BuildPlan(
+				toDecimal(t, "5000"),
+				nominalRate,
+				24,
+				parseTime(t, "2018-01-01T00:00:00Z"),
+				loan.WithFee(test.fee),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			summary := loan.Summarize(payments)
+			if !summary.TotalFees.Equal(toDecimal(t, test.wantFee)) {
+				t.Errorf("got total fees %v; want %v", summary.TotalFees, test.wantFee)
+			}
+			if !summary.TotalPrincipal.Equal(toDecimal(t, test.wantPrincipal)) {
+				t.Errorf("got total principal %v; want %v", summary.TotalPrincipal, test.wantPrincipal)
+			}
+			if !payments[0].PaymentAmount.Equal(toDecimal(t, test.wantFirstPayment)) {
+				t.Errorf("got first payment %v; want %v", payments[0].PaymentAmount, test.wantFirstPayment)
+			}
+
+			apr, err := loan.APR(payments, loan.Monthly)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !apr.Equal(toDecimal(t, test.wantAPR)) {
+				t.Errorf("got APR %v; want %v", apr, test.wantAPR)
+			}
+			if test.wantAPRAboveNominal && !apr.GreaterThan(nominalRate) {
+				t.Errorf("got APR %v; want it above the nominal rate %v", apr, nominalRate)
+			}
+		})
+	}
+}
+
+func TestPlanWithoutFeeIsSameAsCreatePlan(t *testing.T) {
+	got, err := loan.BuildPlan(
+		toDecimal(t, "5000"),
+		toDecimal(t, "5.0"),
+		24,
+		parseTime(t, "2018-01-01T00:00:00Z"),
+		loan.WithFee(loan.Fee{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := createPlan(t, "5000", "5.0", 24)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("BuildPlan() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPlanWithRecurringFee(t *testing.T) {
+	want := createPlan(t, "5000", "5.0", 24)
+	got, err := loan.BuildPlan(
+		toDecimal(t, "5000"),
+		toDecimal(t, "5.0"),
+		24,
+		parseTime(t, "2018-01-01T00:00:00Z"),
+		loan.WithRecurringFee(toDecimal(t, "10")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d payments; want %d", len(got), len(want))
+	}
+
+	fee := toDecimal(t, "10")
+	for i, payment := range got {
+		wantAmount := want[i].PaymentAmount.Add(fee)
+		if !payment.PaymentAmount.Equal(wantAmount) {
+			t.Errorf("payment %d: got amount %v; want %v", i, payment.PaymentAmount, wantAmount)
+		}
+		if !payment.RecurringFee.Equal(fee) {
+			t.Errorf("payment %d: got recurring fee %v; want %v", i, payment.RecurringFee, fee)
+		}
+		if !payment.Principal.Equal(want[i].Principal) {
+			t.Errorf("payment %d: got principal %v; want %v", i, payment.Principal, want[i].Principal)
+		}
+		if !payment.Interest.Equal(want[i].Interest) {
+			t.Errorf("payment %d: got interest %v; want %v", i, payment.Interest, want[i].Interest)
+		}
+	}
+
+	summary := loan.Summarize(got)
+	wantTotalFees := toDecimal(t, "240")
+	if !summary.TotalFees.Equal(wantTotalFees) {
+		t.Errorf("got total fees %v; want %v", summary.TotalFees, wantTotalFees)
+	}
+}
+
+func TestPlanWithNegativeRecurringFee(t *testing.T) {
+	_, err := loan.BuildPlan(
+		toDecimal(t, "1000"),
+		toDecimal(t, "5.0"),
+		12,
+		parseTime(t, "2020-01-01T00:00:00Z"),
+		loan.WithRecurringFee(toDecimal(t, "-10")),
+	)
+	if !errors.Is(err, loan.ErrInvalidParameter) {
+		t.Errorf("got error %v; want %v", err, loan.ErrInvalidParameter)
+	}
+}
+
+func TestPlanWithFeeFailures(t *testing.T) {
+
+	type Test struct {
+		name string
+		fee  loan.Fee
+	}
+
+	tests := []Test{
+		{
+			name: "NegativeAmount",
+			fee:  loan.Fee{Amount: toDecimal(t, "-1")},
+		},
+		{
+			name: "NegativePercent",
+			fee:  loan.Fee{Percent: toDecimal(t, "-1")},
+		},
+		{
+			name: "DeductedFeeEqualToLoanAmount",
+			fee:  loan.Fee{Amount: toDecimal(t, "1000")},
+		},
+		{
+			name: "DeductedFeeBiggerThanLoanAmount",
+			fee:  loan.Fee{Percent: toDecimal(t, "101")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := loan.BuildPlan(
+				toDecimal(t, "1000"),
+				toDecimal(t, "5.0"),
+				12,
+				parseTime(t, "2020-01-01T00:00:00Z"),
+				loan.WithFee(test.fee),
+			)
+			if !errors.Is(err, loan.ErrInvalidParameter) {
+				t.Errorf("got error %v; want %v", err, loan.ErrInvalidParameter)
+			}
+		})
+	}
+}
+
+func TestAPRFailures(t *testing.T) {
+
+	type Test struct {
+		name      string
+		payments  []loan.Payment
+		frequency loan.Frequency
+	}
+
+	tests := []Test{
+		{
+			name:      "NoPayments",
+			payments:  nil,
+			frequency: loan.Monthly,
+		},
+		{
+			name:      "InvalidFrequency",
+			payments:  createPlan(t, "5000", "5.0", 24),
+			frequency: loan.Frequency(666),
+		},
+		{
+			name: "PaymentsDontPayBackDisbursedAmount",
+			payments: []loan.Payment{
+				{
+					PaymentAmount:               toDecimal(t, "50"),
+					InitialOutstandingPrincipal: toDecimal(t, "100"),
+				},
+			},
+			frequency: loan.Monthly,
+		},
+		{
+			name: "DoesNotConverge",
+			payments: []loan.Payment{
+				{
+					PaymentAmount:               toDecimal(t, "1000000000000"),
+					InitialOutstandingPrincipal: toDecimal(t, "1"),
+				},
+			},
+			frequency: loan.Monthly,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := loan.APR(test.payments, test.frequency)
+			if !errors.Is(err, loan.ErrInvalidParameter) {
+				t.Errorf("got error %v; want %v", err, loan.ErrInvalidParameter)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package loan
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Frequency represents how often the payments of a loan are made.
+type Frequency int
+
+const (
+	// Monthly payments, the default frequency.
+	Monthly Frequency = iota
+	// Weekly payments, 52 payments an year.
+	Weekly
+	// Biweekly payments, every two weeks, 26 payments an year.
+	Biweekly
+	// Quarterly payments, every three months, 4 payments an year.
+	Quarterly
+	// Annual payments, 1 payment an year.
+	Annual
+)
+
+// String returns the name of the frequency.
+func (f Frequency) String() string {
+	switch f {
+	case Monthly:
+		return "monthly"
+	case Weekly:
+		return "weekly"
+	case Biweekly:
+		return "biweekly"
+	case Quarterly:
+		return "quarterly"
+	case Annual:
+		return "annual"
+	}
+	return fmt.Sprintf("Frequency(%d)", int(f))
+}
+
+// PeriodsPerYear returns how many payments are made in an year
+// with this frequency.
+func (f Frequency) PeriodsPerYear() int {
+	switch f {
+	case Weekly:
+		return 52
+	case Biweekly:
+		return 26
+	case Quarterly:
+		return 4
+	case Annual:
+		return 1
+	}
+	return 12
+}
+
+// Periods returns the number of periods (payments) of a loan with
+// the given duration in months paid with this frequency, like 3
+// annual payments for a 36 months loan. It returns an error if the
+// duration can't be paid in whole periods, like 18 months paid
+// annually, or if the duration isn't positive.
+func (f Frequency) Periods(durationInMonths int) (int, error) {
+	if err := f.validate(); err != nil {
+		return 0, err
+	}
+	if durationInMonths <= 0 {
+		return 0, fmt.Errorf("%w:duration must be positive, it is %d", ErrInvalidParameter, durationInMonths)
+	}
+	periods := durationInMonths * f.PeriodsPerYear()
+	if periods%12 != 0 {
+		return 0, fmt.Errorf(
+			"%w:duration of %d months can't be paid in whole %v periods",
+			ErrInvalidParameter,
+			durationInMonths,
+			f,
+		)
+	}
+	return periods / 12, nil
+}
+
+func (f Frequency) validate() error {
+	if f < Monthly || f > Annual {
+		return fmt.Errorf("%w:invalid payment frequency %v", ErrInvalidParameter, f)
+	}
+	return nil
+}
+
+// monthBased returns true if the payment dates of the
+// frequency are stepped by months (and not by days).
+func (f Frequency) monthBased() bool {
+	return f != Weekly && f != Biweekly
+}
+
+// periodicInterestRate returns the interest rate of a single period
+// in its decimal form (eg: 0.05 instead of 5.0).
+func (f Frequency) periodicInterestRate(annualInterestRate decimal.Decimal) decimal.Decimal {
+	if f == Monthly {
+		return calculateMonthlyInterestRate(annualInterestRate, Thirty360)
+	}
+	periodsPerYear := decimal.NewFromInt(int64(f.PeriodsPerYear()))
+	return fromPercentToDecimal(annualInterestRate.Div(periodsPerYear))
+}
+
+// calculateInterest calculates the interest of a single period.
+// The (30 days based) day count is used for month based periods,
+// like 90/365 of the annual interest for quarterly periods on Thirty365.
+// Weekly periods are always a fraction of the periods of the year.
+func (f Frequency) calculateInterest(
+	annualInterestRate decimal.Decimal,
+	initialOutstandingPrincipal decimal.Decimal,
+	dayCount DayCount,
+) decimal.Decimal {
+	if !f.monthBased() {
+		return initialOutstandingPrincipal.Mul(f.periodicInterestRate(annualInterestRate))
+	}
+	monthsInPeriod := 12 / f.PeriodsPerYear()
+	rate := annualInterestRate.Mul(decimal.NewFromInt(int64(monthsInPeriod * thirtyDaysInPeriod)))
+	rate = rate.Div(decimal.NewFromInt(int64(dayCount.daysInYear())))
+	return initialOutstandingPrincipal.Mul(fromPercentToDecimal(rate))
+}
+
+// paymentDate returns the date of the payment at the given index
+// (zero based) of a plan starting at the given start date.
+// The start date is normalized to UTC and then its time is ignored,
+// so all payment dates are at midnight UTC. For month based frequencies
+// the day is kept on all payments, or the last day of the month is
+// used when the month doesn't have it (see addMonths).
+func (f Frequency) paymentDate(start time.Time, index int) time.Time {
+	start = start.UTC()
+	date := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	switch f {
+	case Weekly:
+		return date.AddDate(0, 0, 7*index)
+	case Biweekly:
+		return date.AddDate(0, 0, 14*index)
+	case Quarterly:
+		return addMonths(date, 3*index)
+	case Annual:
+		return addMonths(date, 12*index)
+	}
+	return addMonths(date, index)
+}
+
+// addMonths adds the given number of months to the date. If the day of
+// the date doesn't exist on the resulting month the last day of the month
+// is used, like Jan 31 + 1 month being Feb 29 on leap years (Feb 28 otherwise),
+// instead of rolling into March as time.AddDate does.
+func addMonths(date time.Time, months int) time.Time {
+	firstDay := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstDay.AddDate(0, 1, -1).Day()
+	day := date.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstDay.Year(), firstDay.Month(), day, 0, 0, 0, 0, time.UTC)
+}
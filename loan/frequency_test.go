@@ -0,0 +1,498 @@
+package loan_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestBuildPlanPaymentDates(t *testing.T) {
+
+	type Test struct {
+		name      string
+		frequency loan.Frequency
+		startDate string
+		periods   int
+		wantDates []string
+	}
+
+	tests := []Test{
+		{
+			name:      "Monthly",
+			frequency: loan.Monthly,
+			startDate: "2020-11-15T00:00:00Z",
+			periods:   3,
+			wantDates: []string{
+				"2020-11-15T00:00:00Z",
+				"2020-12-15T00:00:00Z",
+				"2021-01-15T00:00:00Z",
+			},
+		},
+		{
+			name:      "WeeklyAcrossMonthBoundary",
+			frequency: loan.Weekly,
+			startDate: "2020-01-22T00:00:00Z",
+			periods:   3,
+			wantDates: []string{
+				"2020-01-22T00:00:00Z",
+				"2020-01-29T00:00:00Z",
+				"2020-02-05T00:00:00Z",
+			},
+		},
+		{
+			name:      "BiweeklyAcrossMonthAndYearBoundaries",
+			frequency: loan.Biweekly,
+			startDate: "2020-12-10T00:00:00Z",
+			periods:   4,
+			wantDates: []string{
+				"2020-12-10T00:00:00Z",
+				"2020-12-24T00:00:00Z",
+				"2021-01-07T00:00:00Z",
+				"2021-01-21T00:00:00Z",
+			},
+		},
+		{
+			name:      "BiweeklyAcrossLeapDay",
+			frequency: loan.Biweekly,
+			startDate: "2020-02-20T00:00:00Z",
+			periods:   2,
+			wantDates: []string{
+				"2020-02-20T00:00:00Z",
+				"2020-03-05T00:00:00Z",
+			},
+		},
+		{
+			name:      "BiweeklyCanStartAfterDay28",
+			frequency: loan.Biweekly,
+			startDate: "2020-12-31T00:00:00Z",
+			periods:   2,
+			wantDates: []string{
+				"2020-12-31T00:00:00Z",
+				"2021-01-14T00:00:00Z",
+			},
+		},
+		{
+			name:      "QuarterlyAcrossYearBoundary",
+			frequency: loan.Quarterly,
+			startDate: "2020-08-01T00:00:00Z",
+			periods:   3,
+			wantDates: []string{
+				"2020-08-01T00:00:00Z",
+				"2020-11-01T00:00:00Z",
+				"2021-02-01T00:00:00Z",
+			},
+		},
+		{
+			name:      "Annual",
+			frequency: loan.Annual,
+			startDate: "2020-02-28T00:00:00Z",
+			periods:   2,
+			wantDates: []string{
+				"2020-02-28T00:00:00Z",
+				"2021-02-28T00:00:00Z",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payments, err := loan.BuildPlan(
+				toDecimal(t, "1000"),
+				toDecimal(t, "5.0"),
+				test.periods,
+				parseTime(t, test.startDate),
+				loan.WithFrequency(test.frequency),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gotDates := make([]string, len(payments))
+			for i, p := range payments {
+				gotDates[i] = p.Date.Format(time.RFC3339)
+			}
+
+			if diff := cmp.Diff(test.wantDates, gotDates); diff != "" {
+				t.Errorf("BuildPlan() dates mismatch (-want +got):\n%s", diff)
+			}
+
+			lastPayment := payments[len(payments)-1]
+			if !lastPayment.RemainingOutstandingPrincipal.IsZero() {
+				t.Errorf("got remaining principal %v on last payment; want 0", lastPayment.RemainingOutstandingPrincipal)
+			}
+		})
+	}
+}
+
+func TestBuildPlanMonthEndPaymentDates(t *testing.T) {
+
+	type Test struct {
+		name      string
+		frequency loan.Frequency
+		startDate string
+		periods   int
+		wantDates []string
+	}
+
+	tests := []Test{
+		{
+			name:      "MonthlyFromJan31OnLeapYear",
+			frequency: loan.Monthly,
+			startDate: "2020-01-31T00:00:00Z",
+			periods:   4,
+			wantDates: []string{
+				"2020-01-31T00:00:00Z",
+				"2020-02-29T00:00:00Z",
+				"2020-03-31T00:00:00Z",
+				"2020-04-30T00:00:00Z",
+			},
+		},
+		{
+			name:      "MonthlyFromJan31OnNonLeapYear",
+			frequency: loan.Monthly,
+			startDate: "2021-01-31T00:00:00Z",
+			periods:   4,
+			wantDates: []string{
+				"2021-01-31T00:00:00Z",
+				"2021-02-28T00:00:00Z",
+				"2021-03-31T00:00:00Z",
+				"2021-04-30T00:00:00Z",
+			},
+		},
+		{
+			name:      "MonthlyFromDay29AcrossYearBoundary",
+			frequency: loan.Monthly,
+			startDate: "2020-12-29T00:00:00Z",
+			periods:   3,
+			wantDates: []string{
+				"2020-12-29T00:00:00Z",
+				"2021-01-29T00:00:00Z",
+				"2021-02-28T00:00:00Z",
+			},
+		},
+		{
+			name:      "MonthlyFromDay30",
+			frequency: loan.Monthly,
+			startDate: "2024-01-30T00:00:00Z",
+			periods:   3,
+			wantDates: []string{
+				"2024-01-30T00:00:00Z",
+				"2024-02-29T00:00:00Z",
+				"2024-03-30T00:00:00Z",
+			},
+		},
+		{
+			name:      "QuarterlyFromNov30",
+			frequency: loan.Quarterly,
+			startDate: "2019-11-30T00:00:00Z",
+			periods:   3,
+			wantDates: []string{
+				"2019-11-30T00:00:00Z",
+				"2020-02-29T00:00:00Z",
+				"2020-05-30T00:00:00Z",
+			},
+		},
+		{
+			name:      "AnnualFromLeapDay",
+			frequency: loan.Annual,
+			startDate: "2020-02-29T00:00:00Z",
+			periods:   5,
+			wantDates: []string{
+				"2020-02-29T00:00:00Z",
+				"2021-02-28T00:00:00Z",
+				"2022-02-28T00:00:00Z",
+				"2023-02-28T00:00:00Z",
+				"2024-02-29T00:00:00Z",
+			},
+		},
+		{
+			name:      "DaysUpTo28AreUnchanged",
+			frequency: loan.Monthly,
+			startDate: "2020-01-28T00:00:00Z",
+			periods:   2,
+			wantDates: []string{
+				"2020-01-28T00:00:00Z",
+				"2020-02-28T00:00:00Z",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payments, err := loan.BuildPlan(
+				toDecimal(t, "1000"),
+				toDecimal(t, "5.0"),
+				test.periods,
+				parseTime(t, test.startDate),
+				loan.WithFrequency(test.frequency),
+				loan.WithMonthEndDates(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gotDates := make([]string, len(payments))
+			for i, p := range payments {
+				gotDates[i] = p.Date.Format(time.RFC3339)
+			}
+
+			if diff := cmp.Diff(test.wantDates, gotDates); diff != "" {
+				t.Errorf("BuildPlan() dates mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBuildPlanScalesInterestByFrequency(t *testing.T) {
+
+	type Test struct {
+		frequency    loan.Frequency
+		wantInterest string
+	}
+
+	tests := []Test{
+		{frequency: loan.Monthly, wantInterest: "21.67"},
+		{frequency: loan.Weekly, wantInterest: "5"},
+		{frequency: loan.Biweekly, wantInterest: "10"},
+		{frequency: loan.Quarterly, wantInterest: "65"},
+		{frequency: loan.Annual, wantInterest: "260"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.frequency.String(), func(t *testing.T) {
+			payments, err := loan.BuildPlan(
+				toDecimal(t, "5200"),
+				toDecimal(t, "5.0"),
+				4,
+				parseTime(t, "2020-01-01T00:00:00Z"),
+				loan.WithFrequency(test.frequency),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := toDecimal(t, test.wantInterest)
+			got := payments[0].Interest
+			if !got.Equal(want) {
+				t.Errorf("got first interest %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestBuildPlanWithoutOptionsIsSameAsCreatePlan(t *testing.T) {
+	got, err := loan.BuildPlan(
+		toDecimal(t, "5000"),
+		toDecimal(t, "5.0"),
+		24,
+		parseTime(t, "2018-01-01T00:00:00Z"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := createPlan(t, "5000", "5.0", 24)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("BuildPlan() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBuildPlanFailures(t *testing.T) {
+
+	type Test struct {
+		name      string
+		startDate string
+		opts      []loan.PlanOption
+	}
+
+	tests := []Test{
+		{
+			name:      "InvalidFrequency",
+			startDate: "2020-01-01T00:00:00Z",
+			opts:      []loan.PlanOption{loan.WithFrequency(loan.Frequency(666))},
+		},
+		{
+			name:      "InvalidDayCount",
+			startDate: "2020-01-01T00:00:00Z",
+			opts:      []loan.PlanOption{loan.WithDayCount(loan.DayCount(666))},
+		},
+		{
+			name:      "MonthlyStartingAfterDay28",
+			startDate: "2020-01-29T00:00:00Z",
+			opts:      []loan.PlanOption{loan.WithFrequency(loan.Monthly)},
+		},
+		{
+			name:      "QuarterlyStartingAfterDay28",
+			startDate: "2020-01-31T00:00:00Z",
+			opts:      []loan.PlanOption{loan.WithFrequency(loan.Quarterly)},
+		},
+		{
+			name:      "PeriodsBiggerThanMaxDuration",
+			startDate: "2020-01-01T00:00:00Z",
+			opts:      []loan.PlanOption{loan.WithMaxDuration(11)},
+		},
+		{
+			name:      "QuarterlyPeriodsBiggerThanMaxDuration",
+			startDate: "2020-01-01T00:00:00Z",
+			opts: []loan.PlanOption{
+				loan.WithFrequency(loan.Quarterly),
+				loan.WithMaxDuration(35),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := loan.BuildPlan(
+				toDecimal(t, "1000"),
+				toDecimal(t, "5.0"),
+				12,
+				parseTime(t, test.startDate),
+				test.opts...,
+			)
+			if !errors.Is(err, loan.ErrInvalidParameter) {
+				t.Errorf("got error %v; want %v", err, loan.ErrInvalidParameter)
+			}
+		})
+	}
+}
+
+func TestBuildPlanMaxDuration(t *testing.T) {
+
+	type Test struct {
+		name    string
+		periods int
+		opts    []loan.PlanOption
+	}
+
+	tests := []Test{
+		{
+			name:    "MonthlyOnDefaultMax",
+			periods: loan.DefaultMaxDurationInMonths,
+		},
+		{
+			name:    "WeeklyFor30Years",
+			periods: 30 * 52,
+			opts:    []loan.PlanOption{loan.WithFrequency(loan.Weekly)},
+		},
+		{
+			name:    "MonthlyWithCustomMax",
+			periods: 1000,
+			opts:    []loan.PlanOption{loan.WithMaxDuration(1000)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payments, err := loan.BuildPlan(
+				toDecimal(t, "100000"),
+				toDecimal(t, "5.0"),
+				test.periods,
+				parseTime(t, "2020-01-01T00:00:00Z"),
+				test.opts...,
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(payments) != test.periods {
+				t.Errorf("got %d payments; want %d", len(payments), test.periods)
+			}
+		})
+	}
+}
+
+func TestFrequencyPeriods(t *testing.T) {
+
+	type Test struct {
+		name             string
+		frequency        loan.Frequency
+		durationInMonths int
+		wantPeriods      int
+		wantErr          bool
+	}
+
+	tests := []Test{
+		{name: "Monthly", frequency: loan.Monthly, durationInMonths: 7, wantPeriods: 7},
+		{name: "Weekly", frequency: loan.Weekly, durationInMonths: 3, wantPeriods: 13},
+		{name: "Biweekly", frequency: loan.Biweekly, durationInMonths: 6, wantPeriods: 13},
+		{name: "Quarterly", frequency: loan.Quarterly, durationInMonths: 24, wantPeriods: 8},
+		{name: "Annual", frequency: loan.Annual, durationInMonths: 36, wantPeriods: 3},
+		{name: "AnnualWithPartialYear", frequency: loan.Annual, durationInMonths: 18, wantErr: true},
+		{name: "QuarterlyWithPartialQuarter", frequency: loan.Quarterly, durationInMonths: 4, wantErr: true},
+		{name: "WeeklyWithPartialWeek", frequency: loan.Weekly, durationInMonths: 1, wantErr: true},
+		{name: "ZeroDuration", frequency: loan.Annual, durationInMonths: 0, wantErr: true},
+		{name: "InvalidFrequency", frequency: loan.Frequency(99), durationInMonths: 12, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.frequency.Periods(test.durationInMonths)
+			if test.wantErr {
+				if !errors.Is(err, loan.ErrInvalidParameter) {
+					t.Fatalf("got error %v; want %v", err, loan.ErrInvalidParameter)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.wantPeriods {
+				t.Errorf("got %d periods; want %d", got, test.wantPeriods)
+			}
+		})
+	}
+}
+
+func TestAnnualPlan(t *testing.T) {
+	periods, err := loan.Annual.Periods(36)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payments, err := loan.BuildPlan(
+		toDecimal(t, "1000"),
+		toDecimal(t, "5.0"),
+		periods,
+		parseTime(t, "2020-01-01T00:00:00Z"),
+		loan.WithFrequency(loan.Annual),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []loan.Payment{
+		{
+			Date:                          parseTime(t, "2020-01-01T00:00:00Z"),
+			PaymentAmount:                 toDecimal(t, "367.21"),
+			Interest:                      toDecimal(t, "50"),
+			Principal:                     toDecimal(t, "317.21"),
+			InitialOutstandingPrincipal:   toDecimal(t, "1000"),
+			RemainingOutstandingPrincipal: toDecimal(t, "682.79"),
+			DaysInPeriod:                  360,
+		},
+		{
+			Date:                          parseTime(t, "2021-01-01T00:00:00Z"),
+			PaymentAmount:                 toDecimal(t, "367.21"),
+			Interest:                      toDecimal(t, "34.14"),
+			Principal:                     toDecimal(t, "333.07"),
+			InitialOutstandingPrincipal:   toDecimal(t, "682.79"),
+			RemainingOutstandingPrincipal: toDecimal(t, "349.72"),
+			DaysInPeriod:                  360,
+		},
+		{
+			Date:                          parseTime(t, "2022-01-01T00:00:00Z"),
+			PaymentAmount:                 toDecimal(t, "367.21"),
+			Interest:                      toDecimal(t, "17.49"),
+			Principal:                     toDecimal(t, "349.72"),
+			InitialOutstandingPrincipal:   toDecimal(t, "349.72"),
+			RemainingOutstandingPrincipal: toDecimal(t, "0"),
+			DaysInPeriod:                  360,
+		},
+	}
+	if diff := cmp.Diff(want, payments); diff != "" {
+		t.Errorf("BuildPlan() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,78 @@
+package loan
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CreatePlanWithGrace will create a payment plan, as a list of payments,
+// throughout the lifetime of an annuity loan that starts with an
+// interest-only grace period.
+//
+// During the first interestOnlyMonths payments only the interest is paid,
+// the principal is untouched. After that the annuity amortization begins
+// over the remaining months of the loan.
+//
+// The annual interest rate is informed as a percent, like 5.0, meaning 5 per cent an year.
+//
+// It returns an error if any of the parameters is invalid, like the duration
+// in months being zero, the interest-only months not being smaller than
+// the duration or the start date has a day bigger than 28.
+func CreatePlanWithGrace(
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	durationInMonths int,
+	interestOnlyMonths int,
+	start time.Time,
+) ([]Payment, error) {
+
+	if err := validateStartDate(start); err != nil {
+		return nil, fmt.Errorf("can't create loan plan with grace:%w", err)
+	}
+
+	if err := validateParameters(totalLoanAmount, annualInterestRate, durationInMonths); err != nil {
+		return nil, fmt.Errorf("can't create loan plan with grace:%w", err)
+	}
+
+	if interestOnlyMonths < 0 || interestOnlyMonths >= durationInMonths {
+		return nil, fmt.Errorf(
+			"can't create loan plan with grace:%w: interest only months should be in the range [0, %d), it is %d",
+			ErrInvalidParameter,
+			durationInMonths,
+			interestOnlyMonths,
+		)
+	}
+
+	amortizationStart := paymentDate(start, interestOnlyMonths)
+	amortization, err := createPlan(
+		context.Background(),
+		totalLoanAmount,
+		annualInterestRate,
+		durationInMonths-interestOnlyMonths,
+		amortizationStart,
+		defaultPlanConfig(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("can't create loan plan with grace:%w", err)
+	}
+
+	payments := make([]Payment, 0, durationInMonths)
+	interest := calculateInterest(annualInterestRate, totalLoanAmount, Thirty360).RoundBank(precision)
+
+	for i := 0; i < interestOnlyMonths; i++ {
+		payments = append(payments, Payment{
+			Date:                          paymentDate(start, i),
+			PaymentAmount:                 interest,
+			Interest:                      interest,
+			Principal:                     decimal.Zero,
+			InitialOutstandingPrincipal:   totalLoanAmount,
+			RemainingOutstandingPrincipal: totalLoanAmount,
+			DaysInPeriod:                  thirtyDaysInPeriod,
+		})
+	}
+
+	return append(payments, amortization...), nil
+}
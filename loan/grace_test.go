@@ -0,0 +1,173 @@
+package loan_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestCreatePlanWithGrace(t *testing.T) {
+
+	type Test struct {
+		name               string
+		totalLoanAmount    string
+		annualInterestRate string
+		durationInMonths   int
+		interestOnlyMonths int
+		start              string
+		want               []loan.Payment
+		wantErr            error
+	}
+
+	tests := []Test{
+		{
+			name:               "SuccessOn2000LoanWith1.0RateIn3MonthsWith1GraceMonth",
+			totalLoanAmount:    "2000.0",
+			annualInterestRate: "1.0",
+			durationInMonths:   3,
+			interestOnlyMonths: 1,
+			want: []loan.Payment{
+				{
+					Date:                          parseTime(t, "2018-01-01T00:00:00Z"),
+					PaymentAmount:                 toDecimal(t, "1.67"),
+					Interest:                      toDecimal(t, "1.67"),
+					Principal:                     toDecimal(t, "0"),
+					InitialOutstandingPrincipal:   toDecimal(t, "2000"),
+					RemainingOutstandingPrincipal: toDecimal(t, "2000"),
+					DaysInPeriod:                  30,
+				},
+				{
+					Date:                          parseTime(t, "2018-02-01T00:00:00Z"),
+					PaymentAmount:                 toDecimal(t, "1001.25"),
+					Interest:                      toDecimal(t, "1.67"),
+					Principal:                     toDecimal(t, "999.58"),
+					InitialOutstandingPrincipal:   toDecimal(t, "2000"),
+					RemainingOutstandingPrincipal: toDecimal(t, "1000.42"),
+					DaysInPeriod:                  30,
+				},
+				{
+					Date:                          parseTime(t, "2018-03-01T00:00:00Z"),
+					PaymentAmount:                 toDecimal(t, "1001.25"),
+					Interest:                      toDecimal(t, "0.83"),
+					Principal:                     toDecimal(t, "1000.42"),
+					InitialOutstandingPrincipal:   toDecimal(t, "1000.42"),
+					RemainingOutstandingPrincipal: toDecimal(t, "0.00"),
+					DaysInPeriod:                  30,
+				},
+			},
+		},
+		{
+			name:               "SuccessWithNoGraceMonthsIsSameAsAnnuity",
+			totalLoanAmount:    "2000.0",
+			annualInterestRate: "1.0",
+			durationInMonths:   2,
+			interestOnlyMonths: 0,
+			want:               createPlan(t, "2000.0", "1.0", 2),
+		},
+		{
+			name:               "ErrorIfInterestOnlyMonthsIsEqualToDuration",
+			totalLoanAmount:    "2000.0",
+			annualInterestRate: "1.0",
+			durationInMonths:   2,
+			interestOnlyMonths: 2,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfInterestOnlyMonthsIsBiggerThanDuration",
+			totalLoanAmount:    "2000.0",
+			annualInterestRate: "1.0",
+			durationInMonths:   2,
+			interestOnlyMonths: 3,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfInterestOnlyMonthsIsNegative",
+			totalLoanAmount:    "2000.0",
+			annualInterestRate: "1.0",
+			durationInMonths:   2,
+			interestOnlyMonths: -1,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfDurationIsOneBillionMonths",
+			totalLoanAmount:    "2000.0",
+			annualInterestRate: "1.0",
+			durationInMonths:   1000000000,
+			interestOnlyMonths: 999999999,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfInterestRateIsNegative",
+			totalLoanAmount:    "2000.0",
+			annualInterestRate: "-1.0",
+			durationInMonths:   3,
+			interestOnlyMonths: 1,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfStartDayIsBiggerThan28",
+			totalLoanAmount:    "2000.0",
+			annualInterestRate: "1.0",
+			durationInMonths:   3,
+			interestOnlyMonths: 1,
+			start:              "2021-01-30T00:00:00Z",
+			wantErr:            loan.ErrInvalidParameter,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start := test.start
+			if start == "" {
+				start = "2018-01-01T00:00:00Z"
+			}
+			got, err := loan.CreatePlanWithGrace(
+				toDecimal(t, test.totalLoanAmount),
+				toDecimal(t, test.annualInterestRate),
+				test.durationInMonths,
+				test.interestOnlyMonths,
+				parseTime(t, start),
+			)
+
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("got error %v; want %v", err, test.wantErr)
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("CreatePlanWithGrace() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGracePeriodIncreasesTotalInterest(t *testing.T) {
+	withoutGrace := loan.Summarize(createPlan(t, "5000.0", "5.0", 24))
+
+	payments, err := loan.CreatePlanWithGrace(
+		toDecimal(t, "5000.0"),
+		toDecimal(t, "5.0"),
+		24,
+		6,
+		parseTime(t, "2018-01-01T00:00:00Z"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withGrace := loan.Summarize(payments)
+
+	if withGrace.NumberOfPayments != withoutGrace.NumberOfPayments {
+		t.Fatalf("got %d payments; want %d", withGrace.NumberOfPayments, withoutGrace.NumberOfPayments)
+	}
+
+	if !withGrace.TotalInterest.GreaterThan(withoutGrace.TotalInterest) {
+		t.Errorf("got total interest %v with grace; want it bigger than %v",
+			withGrace.TotalInterest, withoutGrace.TotalInterest)
+	}
+
+	if !withGrace.TotalPrincipal.Equal(withoutGrace.TotalPrincipal) {
+		t.Errorf("got total principal %v with grace; want %v",
+			withGrace.TotalPrincipal, withoutGrace.TotalPrincipal)
+	}
+}
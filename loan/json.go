@@ -0,0 +1,97 @@
+package loan
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/katcipis/loaner/money"
+	"github.com/shopspring/decimal"
+)
+
+// paymentJSON is the JSON representation of a Payment, the same one of
+// the payments of the loaner API: dates on RFC3339 and decimals as strings,
+// so there is no loss of precision. Fees are omitted when there is no fee.
+type paymentJSON struct {
+	Date                          string        `json:"date"`
+	PaymentAmount                 *money.Amount `json:"borrowerPaymentAmount"`
+	Interest                      *money.Amount `json:"interest"`
+	Principal                     *money.Amount `json:"principal"`
+	InitialOutstandingPrincipal   *money.Amount `json:"initialOutstandingPrincipal"`
+	RemainingOutstandingPrincipal *money.Amount `json:"remainingOutstandingPrincipal"`
+	Fee                           *money.Amount `json:"fee,omitempty"`
+	OriginationFee                *money.Amount `json:"originationFee,omitempty"`
+	DaysInPeriod                  int           `json:"daysInPeriod"`
+}
+
+// MarshalJSON marshals the payment with the same representation
+// of the payments of the loaner API, with dates on RFC3339 and
+// decimals as strings. The recurring fee is the "fee", like on
+// the API, and the origination fee is the "originationFee".
+// Fees are omitted when there is no fee.
+func (p Payment) MarshalJSON() ([]byte, error) {
+	amount := func(value decimal.Decimal) *money.Amount {
+		a := money.New(value)
+		return &a
+	}
+	parsed := paymentJSON{
+		Date:                          p.Date.Format(time.RFC3339),
+		PaymentAmount:                 amount(p.PaymentAmount),
+		Interest:                      amount(p.Interest),
+		Principal:                     amount(p.Principal),
+		InitialOutstandingPrincipal:   amount(p.InitialOutstandingPrincipal),
+		RemainingOutstandingPrincipal: amount(p.RemainingOutstandingPrincipal),
+		DaysInPeriod:                  p.DaysInPeriod,
+	}
+	if !p.RecurringFee.IsZero() {
+		parsed.Fee = amount(p.RecurringFee)
+	}
+	if !p.Fee.IsZero() {
+		parsed.OriginationFee = amount(p.Fee)
+	}
+	return json.Marshal(parsed)
+}
+
+// UnmarshalJSON unmarshals a payment marshalled with MarshalJSON.
+func (p *Payment) UnmarshalJSON(data []byte) error {
+	var parsed paymentJSON
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return fmt.Errorf("can't unmarshal payment:%v", err)
+	}
+
+	date, err := time.Parse(time.RFC3339, parsed.Date)
+	if err != nil {
+		return fmt.Errorf("can't unmarshal payment date:%v", err)
+	}
+
+	var payment Payment
+	payment.Date = date
+	payment.DaysInPeriod = parsed.DaysInPeriod
+
+	amounts := []struct {
+		name     string
+		value    *money.Amount
+		dst      *decimal.Decimal
+		optional bool
+	}{
+		{name: "borrowerPaymentAmount", value: parsed.PaymentAmount, dst: &payment.PaymentAmount},
+		{name: "interest", value: parsed.Interest, dst: &payment.Interest},
+		{name: "principal", value: parsed.Principal, dst: &payment.Principal},
+		{name: "initialOutstandingPrincipal", value: parsed.InitialOutstandingPrincipal, dst: &payment.InitialOutstandingPrincipal},
+		{name: "remainingOutstandingPrincipal", value: parsed.RemainingOutstandingPrincipal, dst: &payment.RemainingOutstandingPrincipal},
+		{name: "fee", value: parsed.Fee, dst: &payment.RecurringFee, optional: true},
+		{name: "originationFee", value: parsed.OriginationFee, dst: &payment.Fee, optional: true},
+	}
+	for _, a := range amounts {
+		if a.value == nil {
+			if a.optional {
+				continue
+			}
+			return fmt.Errorf("can't unmarshal payment:missing %q", a.name)
+		}
+		*a.dst = a.value.Decimal()
+	}
+
+	*p = payment
+	return nil
+}
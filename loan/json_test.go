@@ -0,0 +1,140 @@
+package loan_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestPaymentJSONRoundTrip(t *testing.T) {
+	type Test struct {
+		name string
+		opts []loan.PlanOption
+	}
+
+	tests := []Test{
+		{
+			name: "NoFees",
+		},
+		{
+			name: "WithFees",
+			opts: []loan.PlanOption{
+				loan.WithFee(loan.Fee{Amount: toDecimal(t, "100")}),
+				loan.WithRecurringFee(toDecimal(t, "5.5")),
+			},
+		},
+		{
+			name: "Actual365",
+			opts: []loan.PlanOption{loan.WithDayCount(loan.Actual365)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			want, err := loan.BuildPlan(
+				toDecimal(t, "5000"),
+				toDecimal(t, "5.0"),
+				24,
+				parseTime(t, "2018-01-01T00:00:00Z"),
+				test.opts...,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := json.Marshal(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []loan.Payment
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPaymentJSONRepresentation(t *testing.T) {
+	payment := loan.Payment{
+		Date:                          parseTime(t, "2018-01-01T00:00:00Z"),
+		PaymentAmount:                 toDecimal(t, "224.86"),
+		Interest:                      toDecimal(t, "20.83"),
+		Principal:                     toDecimal(t, "198.53"),
+		InitialOutstandingPrincipal:   toDecimal(t, "5000"),
+		RemainingOutstandingPrincipal: toDecimal(t, "4801.47"),
+		Fee:                           toDecimal(t, "100"),
+		RecurringFee:                  toDecimal(t, "5.5"),
+		DaysInPeriod:                  30,
+	}
+
+	data, err := json.Marshal(payment)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"date":                          "2018-01-01T00:00:00Z",
+		"borrowerPaymentAmount":         "224.86",
+		"interest":                      "20.83",
+		"principal":                     "198.53",
+		"initialOutstandingPrincipal":   "5000",
+		"remainingOutstandingPrincipal": "4801.47",
+		"fee":                           "5.5",
+		"originationFee":                "100",
+		"daysInPeriod":                  float64(30),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("payment JSON mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPaymentJSONUnmarshalFailures(t *testing.T) {
+	type Test struct {
+		name string
+		json string
+	}
+
+	tests := []Test{
+		{
+			name: "InvalidJSON",
+			json: `{`,
+		},
+		{
+			name: "InvalidDate",
+			json: `{"date":"2018-01-01","borrowerPaymentAmount":"1","interest":"1","principal":"1","initialOutstandingPrincipal":"1","remainingOutstandingPrincipal":"0"}`,
+		},
+		{
+			name: "InvalidDecimal",
+			json: `{"date":"2018-01-01T00:00:00Z","borrowerPaymentAmount":"one","interest":"1","principal":"1","initialOutstandingPrincipal":"1","remainingOutstandingPrincipal":"0"}`,
+		},
+		{
+			name: "MissingDecimal",
+			json: `{"date":"2018-01-01T00:00:00Z","borrowerPaymentAmount":"1","principal":"1","initialOutstandingPrincipal":"1","remainingOutstandingPrincipal":"0"}`,
+		},
+		{
+			name: "InvalidFee",
+			json: `{"date":"2018-01-01T00:00:00Z","borrowerPaymentAmount":"1","interest":"1","principal":"1","initialOutstandingPrincipal":"1","remainingOutstandingPrincipal":"0","fee":"ten"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var payment loan.Payment
+			if err := json.Unmarshal([]byte(test.json), &payment); err == nil {
+				t.Errorf("got payment %v; want error", payment)
+			}
+		})
+	}
+}
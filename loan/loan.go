@@ -2,6 +2,7 @@
 package loan
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -16,6 +17,17 @@ type Payment struct {
 	Principal                     decimal.Decimal
 	InitialOutstandingPrincipal   decimal.Decimal
 	RemainingOutstandingPrincipal decimal.Decimal
+	// Fee is the origination fee charged on the loan,
+	// which is informed only on the first payment of the plan.
+	Fee decimal.Decimal
+	// RecurringFee is the fixed fee charged on each payment,
+	// like a servicing fee. It is included on the PaymentAmount.
+	RecurringFee decimal.Decimal
+	// DaysInPeriod is the number of days of the period of the payment
+	// according to the day count used to calculate its interest, like
+	// always 30 for monthly periods with the 30/360 day count or the
+	// actual days of the period with Actual365.
+	DaysInPeriod int
 }
 
 // Error represents an enumeration of errors returned by the loan
@@ -29,15 +41,38 @@ type Error string
 const (
 	// ErrInvalidParameter is returned when on of the parameters passed is invalid.
 	ErrInvalidParameter Error = "invalid parameter"
+	// ErrUnsupportedConvention is returned when a day count convention
+	// is not supported. It is also an ErrInvalidParameter.
+	ErrUnsupportedConvention Error = "unsupported convention"
+	// ErrNoConvergence is returned when a value that is found numerically,
+	// like the APR, can't be found. It is also an ErrInvalidParameter.
+	ErrNoConvergence Error = "no convergence"
+	// ErrNegativeAmortization is returned when the payments of a plan
+	// don't pay its interest, so the outstanding principal would never
+	// decrease. It is also an ErrInvalidParameter.
+	ErrNegativeAmortization Error = "negative amortization"
 )
 
+// DefaultMaxDurationInMonths is the default upper bound for the duration
+// of loans. It avoids huge durations exhausting resources, since
+// a plan has one payment for each period of the loan.
+// Use WithMaxDuration on BuildPlan for a different bound.
+const DefaultMaxDurationInMonths = 600
+
 // CreatePlan will create a payment plan, as a list of payments,
 // throughout the lifetime of an annuity loan.
 //
 // The annual interest rate is informed as a percent, like 5.0, meaning 5 per cent an year.
+// A zero interest rate is valid, in which case only the principal is paid,
+// divided in equal payments.
+//
+// The start date is normalized to UTC before its day is taken, any time
+// information is ignored. So 2018-01-01T23:00:00-05:00 starts
+// the plan on 2018-01-02 (UTC).
 //
 // It returns an error if any of the parameters is invalid, like the duration
-// in months being zero or the start date has a day bigger than 28.
+// in months being zero (or bigger than DefaultMaxDurationInMonths) or
+// the start date has a day bigger than 28 (on UTC).
 func CreatePlan(
 	totalLoanAmount decimal.Decimal,
 	annualInterestRate decimal.Decimal,
@@ -45,45 +80,162 @@ func CreatePlan(
 	start time.Time,
 ) ([]Payment, error) {
 
-	if start.Day() > 28 {
-		return nil, fmt.Errorf(
-			"can't create loan plan:%w:start date %v day can't be bigger than 28",
-			ErrInvalidParameter,
-			start,
-		)
+	return CreatePlanContext(context.Background(), totalLoanAmount, annualInterestRate, durationInMonths, start)
+}
+
+// CreatePlanContext works exactly as CreatePlan but the creation of the
+// plan is aborted if the context is done (like when it is cancelled), in
+// which case the error of the context is returned (wrapped). The context
+// is checked periodically while the payments are calculated, so long plans
+// can be aborted before they are finished.
+func CreatePlanContext(
+	ctx context.Context,
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	durationInMonths int,
+	start time.Time,
+) ([]Payment, error) {
+	return createPlan(ctx, totalLoanAmount, annualInterestRate, durationInMonths, start, defaultPlanConfig())
+}
+
+// IteratePlan works exactly as CreatePlan but instead of building
+// the whole payment plan it calls fn with each payment, in order, one at a
+// time. It is useful to process long plans (like writing them as CSV)
+// without keeping all the payments in memory.
+//
+// If fn returns an error the iteration stops and the error is returned
+// as is. Parameters are validated before any payment is informed to fn.
+func IteratePlan(
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	durationInMonths int,
+	start time.Time,
+	fn func(Payment) error,
+) error {
+	return iteratePlan(context.Background(), totalLoanAmount, annualInterestRate, durationInMonths, start, defaultPlanConfig(), fn)
+}
+
+// CreatePlanForCurrency works exactly as CreatePlan but all money values
+// of the payments are rounded to the minor units of the given currency,
+// instead of the default of 2 decimal places.
+//
+// It returns an error if any of the parameters is invalid, like the duration
+// in months being zero or the start date has a day bigger than 28.
+func CreatePlanForCurrency(
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	durationInMonths int,
+	start time.Time,
+	currency Currency,
+) ([]Payment, error) {
+	return CreatePlanForCurrencyContext(context.Background(), totalLoanAmount, annualInterestRate, durationInMonths, start, currency)
+}
+
+// CreatePlanForCurrencyContext works exactly as CreatePlanForCurrency
+// but the creation of the plan is aborted if the context is done,
+// as detailed on CreatePlanContext.
+func CreatePlanForCurrencyContext(
+	ctx context.Context,
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	durationInMonths int,
+	start time.Time,
+	currency Currency,
+) ([]Payment, error) {
+	cfg := defaultPlanConfig()
+	cfg.precision = currency.MinorUnits
+	return createPlan(ctx, totalLoanAmount, annualInterestRate, durationInMonths, start, cfg)
+}
+
+// ValidatePlan checks if a payment plan can be created by CreatePlan with
+// the given parameters, without calculating the whole plan. It returns
+// the same errors that CreatePlan would return for invalid parameters.
+func ValidatePlan(
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	durationInMonths int,
+	start time.Time,
+) error {
+	return ValidatePlanForCurrency(totalLoanAmount, annualInterestRate, durationInMonths, start, Currency{MinorUnits: precision})
+}
+
+// ValidatePlanForCurrency checks if a payment plan can be created by
+// CreatePlanForCurrency with the given parameters, as detailed on ValidatePlan.
+func ValidatePlanForCurrency(
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	durationInMonths int,
+	start time.Time,
+	currency Currency,
+) error {
+	cfg := defaultPlanConfig()
+	cfg.precision = currency.MinorUnits
+
+	// All parameters are validated before the first payment is
+	// informed, but negative amortization is only detected while
+	// calculating the first payment, so the iteration stops after it.
+	err := iteratePlan(context.Background(), totalLoanAmount, annualInterestRate, durationInMonths, start, cfg, func(Payment) error {
+		return errStopIteration
+	})
+	if err == errStopIteration {
+		return nil
 	}
+	return err
+}
 
-	annuity, err := CalculateAnnuity(totalLoanAmount, annualInterestRate, durationInMonths)
-	if err != nil {
-		return nil, fmt.Errorf("can't create loan plan:%w", err)
+// errStopIteration is used to stop iterating over the payments of a plan.
+const errStopIteration Error = "stop iteration"
+
+// CreateLinearPlan will create a payment plan, as a list of payments,
+// throughout the lifetime of a linear (straight-line) amortization loan.
+//
+// The principal paid on each payment is constant (the loan amount divided
+// by the duration) while the interest decreases with the outstanding
+// principal, so the total payment declines over time. The last payment
+// absorbs any rounding residual so the sum of all principals is exactly
+// the loan amount.
+//
+// The annual interest rate is informed as a percent, like 5.0, meaning 5 per cent an year.
+//
+// It returns an error if any of the parameters is invalid, like the duration
+// in months being zero or the start date has a day bigger than 28.
+func CreateLinearPlan(
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	durationInMonths int,
+	start time.Time,
+) ([]Payment, error) {
+
+	if err := validateStartDate(start); err != nil {
+		return nil, fmt.Errorf("can't create linear loan plan:%w", err)
+	}
+
+	if err := validateParameters(totalLoanAmount, annualInterestRate, durationInMonths); err != nil {
+		return nil, fmt.Errorf("can't create linear loan plan:%w", err)
 	}
 
 	payments := make([]Payment, durationInMonths)
-	year := start.Year()
-	day := start.Day()
-	startMonth := start.Month()
+	monthlyPrincipal := totalLoanAmount.Div(decimal.NewFromInt(int64(durationInMonths))).RoundBank(precision)
 	initialOutstandingPrincipal := totalLoanAmount
 
 	for i := range payments {
-		month := startMonth + time.Month(i)
-		date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
-		interest := calculateInterest(annualInterestRate, initialOutstandingPrincipal).RoundBank(precision)
+		interest := calculateInterest(annualInterestRate, initialOutstandingPrincipal, Thirty360).RoundBank(precision)
 
-		principal := annuity.Sub(interest).RoundBank(precision)
-		if principal.GreaterThan(initialOutstandingPrincipal) {
+		principal := monthlyPrincipal
+		if i == len(payments)-1 || principal.GreaterThan(initialOutstandingPrincipal) {
 			principal = initialOutstandingPrincipal
 		}
 
-		paymentAmount := principal.Add(interest).RoundBank(precision)
-		remainingOutstandingPrincipal := initialOutstandingPrincipal.Sub(principal).RoundBank(precision)
+		remainingOutstandingPrincipal := initialOutstandingPrincipal.Sub(principal)
 
 		payments[i] = Payment{
-			Date:                          date,
-			PaymentAmount:                 paymentAmount,
+			Date:                          paymentDate(start, i),
+			PaymentAmount:                 principal.Add(interest),
 			Interest:                      interest,
 			Principal:                     principal,
 			InitialOutstandingPrincipal:   initialOutstandingPrincipal,
 			RemainingOutstandingPrincipal: remainingOutstandingPrincipal,
+			DaysInPeriod:                  thirtyDaysInPeriod,
 		}
 
 		initialOutstandingPrincipal = remainingOutstandingPrincipal
@@ -96,6 +248,12 @@ func CreatePlan(
 //
 // The annual interest rate is informed as a percent, like 5.0, meaning 5 per cent an year.
 //
+// When the interest rate is zero the annuity is just the loan amount
+// divided by the duration.
+//
+// The result is rounded (with HalfEven) to 2 decimal places, use CalculateAnnuityWithPrecision
+// if you need a different precision.
+//
 // It returns an error if any of the parameters is invalid, like the duration
 // in months being zero.
 func CalculateAnnuity(
@@ -103,68 +261,563 @@ func CalculateAnnuity(
 	annualInterestRate decimal.Decimal,
 	durationInMonths int,
 ) (decimal.Decimal, error) {
+	return CalculateAnnuityWithPrecision(totalLoanAmount, annualInterestRate, durationInMonths, precision)
+}
 
-	if durationInMonths <= 0 {
-		return decimal.Zero, fmt.Errorf(
-			"can't calculate annuity:%w: duration should be bigger than 0, it is %v",
-			ErrInvalidParameter,
-			durationInMonths,
-		)
+// CalculateAnnuityWithPrecision works exactly as CalculateAnnuity but
+// the result is rounded to the given precision (number of decimal places).
+//
+// It returns an error if any of the parameters is invalid, like the duration
+// in months being zero or the precision being negative.
+func CalculateAnnuityWithPrecision(
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	durationInMonths int,
+	precision int,
+) (decimal.Decimal, error) {
+	return CalculateAnnuityWithMaxDuration(
+		totalLoanAmount,
+		annualInterestRate,
+		durationInMonths,
+		precision,
+		DefaultMaxDurationInMonths,
+	)
+}
+
+// CalculateAnnuityWithMaxDuration works exactly as CalculateAnnuityWithPrecision
+// but the duration is validated against the given max duration in months,
+// instead of DefaultMaxDurationInMonths, like plans built with WithMaxDuration.
+func CalculateAnnuityWithMaxDuration(
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	durationInMonths int,
+	precision int,
+	maxDurationInMonths int,
+) (decimal.Decimal, error) {
+
+	if err := validateParametersWithMaxDuration(
+		totalLoanAmount,
+		annualInterestRate,
+		durationInMonths,
+		maxDurationInMonths,
+	); err != nil {
+		return decimal.Zero, fmt.Errorf("can't calculate annuity:%w", err)
 	}
 
-	if totalLoanAmount.LessThanOrEqual(decimal.Zero) {
+	if precision < 0 {
 		return decimal.Zero, fmt.Errorf(
-			"can't calculate annuity:%w: loan amount should be bigger than 0, it is %v",
+			"can't calculate annuity:%w: precision can't be negative, it is %v",
 			ErrInvalidParameter,
-			totalLoanAmount,
+			precision,
 		)
 	}
 
-	if annualInterestRate.LessThanOrEqual(decimal.Zero) {
+	monthlyInterestRate := calculateMonthlyInterestRate(annualInterestRate, Thirty360)
+	return calculateAnnuity(totalLoanAmount, monthlyInterestRate, durationInMonths, precision, HalfEven), nil
+}
+
+// CalculateAnnuityFromPeriodicRate works exactly as CalculateAnnuity but
+// the interest rate is already the rate of a single period (like a monthly
+// rate), so there is no conversion from an annual rate. It is informed as
+// a percent, like 0.5, meaning 0.5 per cent per period.
+//
+// The result is rounded to 2 decimal places.
+//
+// It returns an error if any of the parameters is invalid, like the number
+// of periods being zero (or bigger than the periods of a weekly plan with
+// DefaultMaxDurationInMonths) or the periodic rate being negative.
+func CalculateAnnuityFromPeriodicRate(
+	totalLoanAmount decimal.Decimal,
+	periodicInterestRate decimal.Decimal,
+	periods int,
+) (decimal.Decimal, error) {
+
+	maxPeriods := DefaultMaxDurationInMonths * Weekly.PeriodsPerYear() / 12
+	if err := validateParametersWithMaxDuration(
+		totalLoanAmount,
+		periodicInterestRate,
+		periods,
+		maxPeriods,
+	); err != nil {
+		return decimal.Zero, fmt.Errorf("can't calculate annuity:%w", err)
+	}
+
+	return calculateAnnuity(totalLoanAmount, fromPercentToDecimal(periodicInterestRate), periods, precision, HalfEven), nil
+}
+
+// MaxLoanForPayment will calculate the maximum loan amount that can be paid
+// with the given monthly payment, inverting the annuity formula used
+// by CalculateAnnuity.
+//
+// The annual interest rate is informed as a percent, like 5.0, meaning 5 per cent an year.
+//
+// The result is rounded to 2 decimal places, so the annuity of the
+// returned loan amount may differ by a cent from the monthly payment.
+//
+// It returns an error if any of the parameters is invalid, like the duration
+// in months or the monthly payment being zero.
+func MaxLoanForPayment(
+	monthlyPayment decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	durationInMonths int,
+) (decimal.Decimal, error) {
+
+	if monthlyPayment.LessThanOrEqual(decimal.Zero) {
 		return decimal.Zero, fmt.Errorf(
-			"can't calculate annuity:%w: interest rate should be bigger than 0, it is %v",
+			"can't calculate max loan:%w: monthly payment should be bigger than 0, it is %v",
 			ErrInvalidParameter,
-			annualInterestRate,
+			monthlyPayment,
 		)
 	}
 
-	// Assuming for all calculation that the default precision of 16 is enough
-	// Only the final result is rounded.
-	monthlyInterestRate := fromPercentToDecimal(calculateMonthlyInterestRate(annualInterestRate))
+	if err := validateParameters(monthlyPayment, annualInterestRate, durationInMonths); err != nil {
+		return decimal.Zero, fmt.Errorf("can't calculate max loan:%w", err)
+	}
+
+	durationDecimal := decimal.NewFromInt(int64(durationInMonths))
+	monthlyInterestRate := calculateMonthlyInterestRate(annualInterestRate, Thirty360)
+	if monthlyInterestRate.IsZero() {
+		return monthlyPayment.Mul(durationDecimal).RoundBank(precision), nil
+	}
+
 	one := decimal.NewFromInt(1)
-	numerator := totalLoanAmount.Mul(monthlyInterestRate)
-	denominator := one.Add(monthlyInterestRate)
-	denominator = denominator.Pow(decimal.NewFromInt(int64(durationInMonths)).Neg())
-	denominator = one.Sub(denominator)
+	numerator := pow(one.Add(monthlyInterestRate), -durationInMonths)
+	numerator = monthlyPayment.Mul(one.Sub(numerator))
 
-	return numerator.Div(denominator).RoundBank(precision), nil
+	return numerator.Div(monthlyInterestRate).RoundBank(precision), nil
 }
 
 func (e Error) Error() string {
 	return string(e)
 }
 
+// Is reports whether the error matches the target, so the more
+// specific errors, like ErrNegativeAmortization, are also matched
+// by errors.Is against ErrInvalidParameter.
+func (e Error) Is(target error) bool {
+	if target != ErrInvalidParameter {
+		return false
+	}
+	switch e {
+	case ErrUnsupportedConvention, ErrNoConvergence, ErrNegativeAmortization:
+		return true
+	}
+	return false
+}
+
 const precision = 2
 
-func calculateMonthlyInterestRate(annualInterestRate decimal.Decimal) decimal.Decimal {
-	monthsInYear := decimal.NewFromInt(12)
-	return annualInterestRate.Div(monthsInYear)
-}
+// Decimals are immutable, so the constants used on every
+// payment are allocated only once.
+var hundred = decimal.NewFromInt(100)
 
 func fromPercentToDecimal(percentVal decimal.Decimal) decimal.Decimal {
-	return percentVal.Div(decimal.NewFromInt(100))
+	return percentVal.Div(hundred)
 }
 
+// calculateMonthlyInterestRate calculates the interest rate of a
+// monthly period, in its decimal form (eg: 0.05 instead of 5.0),
+// according to the given (30 days based) day count. With the default
+// Thirty360 day count it is the annual rate divided by 12.
+// It is the only place where the monthly rate is derived, so the
+// annuity and the interest of each payment always agree.
+func calculateMonthlyInterestRate(
+	annualInterestRate decimal.Decimal,
+	dayCount DayCount,
+) decimal.Decimal {
+	rate := annualInterestRate.Mul(decimal.NewFromInt(thirtyDaysInPeriod))
+	rate = rate.Div(decimal.NewFromInt(int64(dayCount.daysInYear())))
+	return fromPercentToDecimal(rate)
+}
+
+// calculateInterest calculates the interest of a monthly period
+// according to the given (30 days based) day count.
 func calculateInterest(
 	annualInterestRate decimal.Decimal,
 	initialOutstandingPrincipal decimal.Decimal,
+	dayCount DayCount,
 ) decimal.Decimal {
-	const (
-		daysInMonth = 30
-		daysInYear  = 360
-	)
+	return initialOutstandingPrincipal.Mul(calculateMonthlyInterestRate(annualInterestRate, dayCount))
+}
+
+// calculateAnnuity calculates the annuity given a periodic interest
+// rate already in its decimal form (eg: 0.05 instead of 5.0).
+// No validation is performed on the parameters.
+func calculateAnnuity(
+	totalLoanAmount decimal.Decimal,
+	periodicInterestRate decimal.Decimal,
+	periods int,
+	precision int,
+	mode RoundingMode,
+) decimal.Decimal {
+	// Assuming for all calculation that the default precision of 16 is enough
+	// Only the final result is rounded.
+	if periodicInterestRate.IsZero() {
+		// The annuity formula divides by zero when there is no interest,
+		// in that case only the principal is paid, in equal parts.
+		return mode.round(totalLoanAmount.Div(decimal.NewFromInt(int64(periods))), int32(precision))
+	}
+
+	one := decimal.NewFromInt(1)
+	numerator := totalLoanAmount.Mul(periodicInterestRate)
+	denominator := pow(one.Add(periodicInterestRate), -periods)
+	denominator = one.Sub(denominator)
+
+	return mode.round(numerator.Div(denominator), int32(precision))
+}
+
+// pow raises base to an integer exponent, by squaring. It does the same
+// operations (so it has the same results) of decimal.Decimal.Pow, but
+// the exponent is halved as an int instead of being divided as a decimal
+// on each step, which made the pow the hot path of creating plans.
+func pow(base decimal.Decimal, exp int) decimal.Decimal {
+	if exp == 0 {
+		return decimal.NewFromInt(1)
+	}
+	half := pow(base, exp/2)
+	if exp%2 == 0 {
+		return half.Mul(half)
+	}
+	if exp > 0 {
+		return half.Mul(half).Mul(base)
+	}
+	return half.Mul(half).Div(base)
+}
+
+// planConfig has all the configurations required to
+// create a payment plan.
+type planConfig struct {
+	frequency           Frequency
+	dayCount            DayCount
+	rounding            RoundingMode
+	fee                 Fee
+	recurringFee        decimal.Decimal
+	precision           int
+	maxDurationInMonths int
+	monthEndDates       bool
+	firstPaymentOffset  int
+	clock               Clock
+}
+
+func defaultPlanConfig() planConfig {
+	return planConfig{
+		frequency:           Monthly,
+		dayCount:            Thirty360,
+		rounding:            HalfEven,
+		recurringFee:        decimal.Zero,
+		precision:           precision,
+		maxDurationInMonths: DefaultMaxDurationInMonths,
+		clock:               realClock{},
+	}
+}
+
+// calculateInterest calculates the interest of the payment at the
+// given index (zero based) of a plan starting at the given start date.
+// The period of a payment starts on the date of the previous payment
+// (or one period before the start date, for the first payment).
+func (cfg planConfig) calculateInterest(
+	annualInterestRate decimal.Decimal,
+	initialOutstandingPrincipal decimal.Decimal,
+	start time.Time,
+	index int,
+) decimal.Decimal {
+	if cfg.dayCount.thirtyDays() {
+		return cfg.frequency.calculateInterest(annualInterestRate, initialOutstandingPrincipal, cfg.dayCount)
+	}
+	from := cfg.frequency.paymentDate(start, index-1)
+	to := cfg.frequency.paymentDate(start, index)
 	rate := fromPercentToDecimal(annualInterestRate)
-	numerator := rate.Mul(decimal.NewFromInt(daysInMonth))
-	numerator = numerator.Mul(initialOutstandingPrincipal)
-	return numerator.Div(decimal.NewFromInt(daysInYear))
+	return initialOutstandingPrincipal.Mul(rate).Mul(cfg.dayCount.yearFraction(from, to))
+}
+
+// daysInPeriod returns the number of days of the period of the payment at
+// the given index (zero based), according to the day count of the plan.
+// With the 30 days based day counts all months have 30 days, while weekly
+// periods always have the actual days.
+func (cfg planConfig) daysInPeriod(start time.Time, index int) int {
+	if cfg.dayCount.thirtyDays() {
+		switch cfg.frequency {
+		case Monthly:
+			return thirtyDaysInPeriod
+		case Quarterly:
+			return 3 * 30
+		case Annual:
+			return 12 * 30
+		}
+	}
+	from := cfg.frequency.paymentDate(start, index-1)
+	to := cfg.frequency.paymentDate(start, index)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// maxPeriods is the max number of periods of a plan, which
+// is the max duration in months scaled by the frequency.
+func (cfg planConfig) maxPeriods() int {
+	return cfg.maxDurationInMonths * cfg.frequency.PeriodsPerYear() / 12
+}
+
+func createPlan(
+	ctx context.Context,
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	periods int,
+	start time.Time,
+	cfg planConfig,
+) ([]Payment, error) {
+
+	var payments []Payment
+	if periods > 0 && periods <= cfg.maxPeriods() {
+		payments = make([]Payment, 0, periods)
+	}
+
+	err := iteratePlan(ctx, totalLoanAmount, annualInterestRate, periods, start, cfg, func(p Payment) error {
+		payments = append(payments, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// contextCheckInterval is the number of payments
+// calculated between checks of the context.
+const contextCheckInterval = 64
+
+func iteratePlan(
+	ctx context.Context,
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	periods int,
+	start time.Time,
+	cfg planConfig,
+	fn func(Payment) error,
+) error {
+
+	if err := cfg.frequency.validate(); err != nil {
+		return fmt.Errorf("can't create loan plan:%w", err)
+	}
+
+	if err := cfg.dayCount.validate(); err != nil {
+		return fmt.Errorf("can't create loan plan:%w", err)
+	}
+
+	if err := cfg.dayCount.validateFrequency(cfg.frequency); err != nil {
+		return fmt.Errorf("can't create loan plan:%w", err)
+	}
+
+	if err := cfg.rounding.validate(); err != nil {
+		return fmt.Errorf("can't create loan plan:%w", err)
+	}
+
+	if cfg.frequency.monthBased() && !cfg.monthEndDates {
+		if err := validateStartDate(start); err != nil {
+			return fmt.Errorf("can't create loan plan:%w", err)
+		}
+	}
+
+	if err := validateParametersWithMaxDuration(
+		totalLoanAmount,
+		annualInterestRate,
+		periods,
+		cfg.maxPeriods(),
+	); err != nil {
+		return fmt.Errorf("can't create loan plan:%w", err)
+	}
+
+	if cfg.precision < 0 {
+		return fmt.Errorf(
+			"can't create loan plan:%w: precision can't be negative, it is %v",
+			ErrInvalidParameter,
+			cfg.precision,
+		)
+	}
+
+	places := int32(cfg.precision)
+
+	fee, err := cfg.fee.calculate(totalLoanAmount, places, cfg.rounding)
+	if err != nil {
+		return fmt.Errorf("can't create loan plan:%w", err)
+	}
+	if cfg.fee.Financed {
+		totalLoanAmount = totalLoanAmount.Add(fee)
+	}
+
+	if err := validateRecurringFee(cfg.recurringFee); err != nil {
+		return fmt.Errorf("can't create loan plan:%w", err)
+	}
+
+	if cfg.firstPaymentOffset < 0 {
+		return fmt.Errorf(
+			"can't create loan plan:%w: first payment offset can't be negative, it is %v",
+			ErrInvalidParameter,
+			cfg.firstPaymentOffset,
+		)
+	}
+	if cfg.firstPaymentOffset > 0 {
+		totalLoanAmount = capitalizeInterest(totalLoanAmount, annualInterestRate, cfg.firstPaymentOffset, places, cfg.rounding)
+		start = addMonths(start.UTC(), cfg.firstPaymentOffset)
+	}
+	recurringFee := cfg.rounding.round(cfg.recurringFee, places)
+
+	periodicInterestRate := cfg.frequency.periodicInterestRate(annualInterestRate)
+	annuity := calculateAnnuity(totalLoanAmount, periodicInterestRate, periods, cfg.precision, cfg.rounding)
+
+	initialOutstandingPrincipal := totalLoanAmount
+
+	for i := 0; i < periods; i++ {
+		if i%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("can't create loan plan:%w", err)
+			}
+		}
+
+		date := cfg.frequency.paymentDate(start, i)
+		interest := cfg.rounding.round(cfg.calculateInterest(annualInterestRate, initialOutstandingPrincipal, start, i), places)
+
+		// With the 30/360 day count the interest only decreases along
+		// the plan, so negative amortization is always detected on the
+		// first payment, before any payment is yielded.
+		principal := cfg.rounding.round(annuity.Sub(interest), places)
+		if err := validateAmortization(i, annuity, interest); err != nil {
+			return fmt.Errorf("can't create loan plan:%w", err)
+		}
+
+		// The last payment absorbs any residual from the accumulated
+		// rounding of the previous payments, guaranteeing that the
+		// loan is fully paid.
+		if i == periods-1 || principal.GreaterThan(initialOutstandingPrincipal) {
+			principal = initialOutstandingPrincipal
+		}
+
+		paymentAmount := cfg.rounding.round(principal.Add(interest).Add(recurringFee), places)
+		paymentFee := decimal.Zero
+		if i == 0 {
+			paymentFee = fee
+		}
+		remainingOutstandingPrincipal := cfg.rounding.round(initialOutstandingPrincipal.Sub(principal), places)
+
+		err := fn(Payment{
+			Date:                          date,
+			PaymentAmount:                 paymentAmount,
+			Interest:                      interest,
+			Principal:                     principal,
+			InitialOutstandingPrincipal:   initialOutstandingPrincipal,
+			RemainingOutstandingPrincipal: remainingOutstandingPrincipal,
+			Fee:                           paymentFee,
+			RecurringFee:                  recurringFee,
+			DaysInPeriod:                  cfg.daysInPeriod(start, i),
+		})
+		if err != nil {
+			return err
+		}
+
+		initialOutstandingPrincipal = remainingOutstandingPrincipal
+	}
+	return nil
+}
+
+// capitalizeInterest adds to the principal the interest accrued
+// (compounded monthly) during the given number of months.
+func capitalizeInterest(
+	principal decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	months int,
+	places int32,
+	mode RoundingMode,
+) decimal.Decimal {
+	growth := decimal.NewFromInt(1).Add(Monthly.periodicInterestRate(annualInterestRate))
+	return mode.round(principal.Mul(pow(growth, months)), places)
+}
+
+// validateAmortization checks if the payment at the given index
+// amortizes the loan. When the interest of a period is not smaller than
+// the payment the principal is never paid (or even grows), which
+// is called negative amortization.
+func validateAmortization(index int, payment decimal.Decimal, interest decimal.Decimal) error {
+	if interest.GreaterThanOrEqual(payment) {
+		return fmt.Errorf(
+			"%w on payment %d, interest %v is not smaller than the payment %v",
+			ErrNegativeAmortization,
+			index,
+			interest,
+			payment,
+		)
+	}
+	return nil
+}
+
+// validateStartDate validates the day of the start date, which
+// is always taken after normalizing the start date to UTC, like
+// 2018-01-28T23:00:00-05:00 which is day 29 (UTC).
+func validateStartDate(start time.Time) error {
+	if start.UTC().Day() > 28 {
+		return fmt.Errorf(
+			"%w:start date %v day can't be bigger than 28",
+			ErrInvalidParameter,
+			start,
+		)
+	}
+	return nil
+}
+
+func validateParameters(
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	durationInMonths int,
+) error {
+	return validateParametersWithMaxDuration(
+		totalLoanAmount,
+		annualInterestRate,
+		durationInMonths,
+		DefaultMaxDurationInMonths,
+	)
+}
+
+func validateParametersWithMaxDuration(
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	duration int,
+	maxDuration int,
+) error {
+	if duration <= 0 {
+		return fmt.Errorf(
+			"%w: duration should be bigger than 0, it is %v",
+			ErrInvalidParameter,
+			duration,
+		)
+	}
+
+	if duration > maxDuration {
+		return fmt.Errorf(
+			"%w: duration should not be bigger than %v, it is %v",
+			ErrInvalidParameter,
+			maxDuration,
+			duration,
+		)
+	}
+
+	if totalLoanAmount.LessThanOrEqual(decimal.Zero) {
+		return fmt.Errorf(
+			"%w: loan amount should be bigger than 0, it is %v",
+			ErrInvalidParameter,
+			totalLoanAmount,
+		)
+	}
+
+	if annualInterestRate.IsNegative() {
+		return fmt.Errorf(
+			"%w: interest rate can't be negative, it is %v",
+			ErrInvalidParameter,
+			annualInterestRate,
+		)
+	}
+	return nil
+}
+
+// paymentDate returns the date of the payment at the given index
+// (zero based) of a monthly plan starting at the given start date.
+// Time and timezone information of the start date are ignored.
+func paymentDate(start time.Time, index int) time.Time {
+	return Monthly.paymentDate(start, index)
 }
@@ -1,7 +1,9 @@
 package loan_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -44,6 +46,7 @@ func TestCreatePlan(t *testing.T) {
 					Principal:                     toDecimal(t, "999.58"),
 					InitialOutstandingPrincipal:   toDecimal(t, "2000"),
 					RemainingOutstandingPrincipal: toDecimal(t, "1000.42"),
+					DaysInPeriod:                  30,
 				},
 				{
 					Date:                          parseTime(t, "2018-02-01T00:00:00Z"),
@@ -52,6 +55,7 @@ func TestCreatePlan(t *testing.T) {
 					Principal:                     toDecimal(t, "1000.42"),
 					InitialOutstandingPrincipal:   toDecimal(t, "1000.42"),
 					RemainingOutstandingPrincipal: toDecimal(t, "0.00"),
+					DaysInPeriod:                  30,
 				},
 			},
 		},
@@ -69,6 +73,7 @@ func TestCreatePlan(t *testing.T) {
 					Principal:                     toDecimal(t, "999.58"),
 					InitialOutstandingPrincipal:   toDecimal(t, "2000"),
 					RemainingOutstandingPrincipal: toDecimal(t, "1000.42"),
+					DaysInPeriod:                  30,
 				},
 				{
 					Date:                          parseTime(t, "2018-02-01T00:00:00Z"),
@@ -77,6 +82,61 @@ func TestCreatePlan(t *testing.T) {
 					Principal:                     toDecimal(t, "1000.42"),
 					InitialOutstandingPrincipal:   toDecimal(t, "1000.42"),
 					RemainingOutstandingPrincipal: toDecimal(t, "0.00"),
+					DaysInPeriod:                  30,
+				},
+			},
+		},
+		{
+			name:               "StartDateIsNormalizedToUTCOnNegativeOffset",
+			startDate:          parseTime(t, "2018-01-01T23:00:00-05:00"),
+			totalLoanAmount:    "2000.0",
+			annualInterestRate: "1.0",
+			durationInMonths:   2,
+			want: []loan.Payment{
+				{
+					Date:                          parseTime(t, "2018-01-02T00:00:00Z"),
+					PaymentAmount:                 toDecimal(t, "1001.25"),
+					Interest:                      toDecimal(t, "1.67"),
+					Principal:                     toDecimal(t, "999.58"),
+					InitialOutstandingPrincipal:   toDecimal(t, "2000"),
+					RemainingOutstandingPrincipal: toDecimal(t, "1000.42"),
+					DaysInPeriod:                  30,
+				},
+				{
+					Date:                          parseTime(t, "2018-02-02T00:00:00Z"),
+					PaymentAmount:                 toDecimal(t, "1001.25"),
+					Interest:                      toDecimal(t, "0.83"),
+					Principal:                     toDecimal(t, "1000.42"),
+					InitialOutstandingPrincipal:   toDecimal(t, "1000.42"),
+					RemainingOutstandingPrincipal: toDecimal(t, "0.00"),
+					DaysInPeriod:                  30,
+				},
+			},
+		},
+		{
+			name:               "StartDateIsNormalizedToUTCOnPositiveOffset",
+			startDate:          parseTime(t, "2018-01-29T01:00:00+02:00"),
+			totalLoanAmount:    "2000.0",
+			annualInterestRate: "1.0",
+			durationInMonths:   2,
+			want: []loan.Payment{
+				{
+					Date:                          parseTime(t, "2018-01-28T00:00:00Z"),
+					PaymentAmount:                 toDecimal(t, "1001.25"),
+					Interest:                      toDecimal(t, "1.67"),
+					Principal:                     toDecimal(t, "999.58"),
+					InitialOutstandingPrincipal:   toDecimal(t, "2000"),
+					RemainingOutstandingPrincipal: toDecimal(t, "1000.42"),
+					DaysInPeriod:                  30,
+				},
+				{
+					Date:                          parseTime(t, "2018-02-28T00:00:00Z"),
+					PaymentAmount:                 toDecimal(t, "1001.25"),
+					Interest:                      toDecimal(t, "0.83"),
+					Principal:                     toDecimal(t, "1000.42"),
+					InitialOutstandingPrincipal:   toDecimal(t, "1000.42"),
+					RemainingOutstandingPrincipal: toDecimal(t, "0.00"),
+					DaysInPeriod:                  30,
 				},
 			},
 		},
@@ -94,6 +154,7 @@ func TestCreatePlan(t *testing.T) {
 					Principal:                     toDecimal(t, "999.58"),
 					InitialOutstandingPrincipal:   toDecimal(t, "2000"),
 					RemainingOutstandingPrincipal: toDecimal(t, "1000.42"),
+					DaysInPeriod:                  30,
 				},
 				{
 					Date:                          parseTime(t, "2021-01-28T00:00:00Z"),
@@ -102,6 +163,7 @@ func TestCreatePlan(t *testing.T) {
 					Principal:                     toDecimal(t, "1000.42"),
 					InitialOutstandingPrincipal:   toDecimal(t, "1000.42"),
 					RemainingOutstandingPrincipal: toDecimal(t, "0.00"),
+					DaysInPeriod:                  30,
 				},
 			},
 		},
@@ -130,13 +192,55 @@ func TestCreatePlan(t *testing.T) {
 			wantErr:            loan.ErrInvalidParameter,
 		},
 		{
-			name:               "ErrorZeroInterestRate",
+			name:               "ErrorOnStartDateDay29AfterNormalizingToUTC",
+			totalLoanAmount:    "5000.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   3,
+			startDate:          parseTime(t, "2020-12-28T23:00:00-05:00"),
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorNegativeInterestRate",
 			totalLoanAmount:    "5000.0",
-			annualInterestRate: "0.0",
+			annualInterestRate: "-1.0",
 			durationInMonths:   3,
 			startDate:          parseTime(t, "2020-12-01T00:00:00Z"),
 			wantErr:            loan.ErrInvalidParameter,
 		},
+		{
+			// The monthly interest (1000/12 per cent) is equal to the annuity
+			// after rounding, so the principal would never be paid.
+			name:               "ErrorOnNegativeAmortization",
+			totalLoanAmount:    "100.0",
+			annualInterestRate: "1000.0",
+			durationInMonths:   600,
+			startDate:          parseTime(t, "2020-12-01T00:00:00Z"),
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorOnLoanAmountTooSmallToBeAmortized",
+			totalLoanAmount:    "0.01",
+			annualInterestRate: "5.0",
+			durationInMonths:   24,
+			startDate:          parseTime(t, "2020-12-01T00:00:00Z"),
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfDurationIsBiggerThanMax",
+			totalLoanAmount:    "5000.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   loan.DefaultMaxDurationInMonths + 1,
+			startDate:          parseTime(t, "2020-12-01T00:00:00Z"),
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfDurationIsOneBillionMonths",
+			totalLoanAmount:    "5000.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   1000000000,
+			startDate:          parseTime(t, "2020-12-01T00:00:00Z"),
+			wantErr:            loan.ErrInvalidParameter,
+		},
 	}
 
 	for _, test := range tests {
@@ -158,148 +262,1305 @@ func TestCreatePlan(t *testing.T) {
 			if diff := cmp.Diff(test.want, got); diff != "" {
 				t.Errorf("CreatePlan() mismatch (-want +got):\n%s", diff)
 			}
+
+			err = loan.ValidatePlan(
+				loanAmount,
+				interestRate,
+				test.durationInMonths,
+				test.startDate,
+			)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("ValidatePlan() got error %v; want %v", err, test.wantErr)
+			}
 		})
 	}
 }
 
-func TestAnnuityCalculation(t *testing.T) {
-
-	// Test represents a single test case for the annuity calculation
-	// We use strings here to make the test descriptor more concise and
-	// easier to read as a high level behavior descriptor.
+func TestSinglePaymentPlan(t *testing.T) {
 	type Test struct {
 		name               string
 		totalLoanAmount    string
 		annualInterestRate string
-		durationInMonths   int
-		want               string
-		wantErr            error
+		wantInterest       string
 	}
 
 	tests := []Test{
 		{
-			name:               "SuccessOn5000LoanWith5.0RateIn24Months",
-			totalLoanAmount:    "5000.0",
+			name:               "5000LoanWith5.0Rate",
+			totalLoanAmount:    "5000",
 			annualInterestRate: "5.0",
-			durationInMonths:   24,
-			want:               "219.36",
+			wantInterest:       "20.83",
 		},
 		{
-			name:               "SuccessOn5000LoanWith5.0RateIn2Months",
-			totalLoanAmount:    "5000.0",
-			annualInterestRate: "5.0",
-			durationInMonths:   2,
-			want:               "2515.64",
+			name:               "RoundingOnHalfCent",
+			totalLoanAmount:    "1001",
+			annualInterestRate: "6.0",
+			wantInterest:       "5.00",
 		},
 		{
-			name:               "SuccessOn1LoanWith5.0RateIn2Months",
-			totalLoanAmount:    "1.0",
-			annualInterestRate: "5.0",
-			durationInMonths:   2,
-			want:               "0.5",
+			name:               "BigLoanWithHighRate",
+			totalLoanAmount:    "123456.78",
+			annualInterestRate: "19.99",
+			wantInterest:       "2056.58",
 		},
 		{
-			name:               "SuccessOn1LoanWith5.0RateIn500Months",
-			totalLoanAmount:    "1.0",
+			name:               "InterestSmallerThanACent",
+			totalLoanAmount:    "0.01",
 			annualInterestRate: "5.0",
-			durationInMonths:   50,
-			want:               "0.02",
+			wantInterest:       "0",
 		},
 		{
-			name:               "SuccessOn5000LoanWith5.0RateIn1Month",
-			totalLoanAmount:    "5000.0",
-			annualInterestRate: "5.0",
-			durationInMonths:   1,
-			want:               "5020.83",
+			name:               "ZeroRate",
+			totalLoanAmount:    "1000",
+			annualInterestRate: "0",
+			wantInterest:       "0",
 		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payments := createPlan(t, test.totalLoanAmount, test.annualInterestRate, 1)
+			if len(payments) != 1 {
+				t.Fatalf("got %d payments; want 1", len(payments))
+			}
+
+			annuity, err := loan.CalculateAnnuity(
+				toDecimal(t, test.totalLoanAmount),
+				toDecimal(t, test.annualInterestRate),
+				1,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := loan.Payment{
+				Date:                          parseTime(t, "2018-01-01T00:00:00Z"),
+				PaymentAmount:                 annuity,
+				Interest:                      toDecimal(t, test.wantInterest),
+				Principal:                     toDecimal(t, test.totalLoanAmount),
+				InitialOutstandingPrincipal:   toDecimal(t, test.totalLoanAmount),
+				RemainingOutstandingPrincipal: toDecimal(t, "0"),
+				DaysInPeriod:                  30,
+			}
+			if diff := cmp.Diff(want, payments[0]); diff != "" {
+				t.Errorf("single payment mismatch (-want +got):\n%s", diff)
+			}
+			if !payments[0].RemainingOutstandingPrincipal.IsZero() {
+				t.Errorf("got remaining principal %v; want exactly zero", payments[0].RemainingOutstandingPrincipal)
+			}
+		})
+	}
+}
+
+func TestFirstPaymentInterestUsesMonthlyRate(t *testing.T) {
+	type Test struct {
+		name               string
+		totalLoanAmount    string
+		annualInterestRate string
+		durationInMonths   int
+	}
+
+	tests := []Test{
 		{
-			name:               "SuccessOn5000LoanWith0.1RateIn24Months",
-			totalLoanAmount:    "5000.0",
-			annualInterestRate: "0.1",
+			name:               "5000LoanWith5.0Rate",
+			totalLoanAmount:    "5000",
+			annualInterestRate: "5.0",
 			durationInMonths:   24,
-			want:               "208.55",
 		},
 		{
-			name:               "ErrorIfDurationIsZero",
-			totalLoanAmount:    "500.00",
-			annualInterestRate: "3.0",
-			durationInMonths:   0,
-			wantErr:            loan.ErrInvalidParameter,
+			name:               "RateNotDivisibleBy12",
+			totalLoanAmount:    "123456.78",
+			annualInterestRate: "19.99",
+			durationInMonths:   360,
 		},
 		{
-			name:               "ErrorIfDurationIsNegative",
-			totalLoanAmount:    "500.00",
-			annualInterestRate: "3.0",
-			durationInMonths:   -1,
-			wantErr:            loan.ErrInvalidParameter,
+			name:               "SmallRate",
+			totalLoanAmount:    "777.77",
+			annualInterestRate: "0.7",
+			durationInMonths:   7,
 		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payments := createPlan(t, test.totalLoanAmount, test.annualInterestRate, test.durationInMonths)
+
+			balance := toDecimal(t, test.totalLoanAmount)
+			monthlyRate := toDecimal(t, test.annualInterestRate).
+				Div(decimal.NewFromInt(12)).
+				Div(decimal.NewFromInt(100))
+			want := balance.Mul(monthlyRate).RoundBank(2)
+
+			got := payments[0].Interest
+			if !got.Equal(want) {
+				t.Errorf("got first interest %v; want %v (balance %v * monthly rate %v)", got, want, balance, monthlyRate)
+			}
+		})
+	}
+}
+
+func TestPlanLastPaymentReachesZero(t *testing.T) {
+	type Test struct {
+		name               string
+		totalLoanAmount    string
+		annualInterestRate string
+		durationInMonths   int
+		currency           loan.Currency
+	}
+
+	tests := []Test{
 		{
-			name:               "ErrorIfLoanAmountIsZero",
-			totalLoanAmount:    "0.00",
-			annualInterestRate: "3.0",
-			durationInMonths:   2,
-			wantErr:            loan.ErrInvalidParameter,
+			name:               "5000LoanWith5.0RateIn24Months",
+			totalLoanAmount:    "5000.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   24,
+			currency:           loan.EUR,
 		},
 		{
-			name:               "ErrorIfLoanAmountIsNegative",
-			totalLoanAmount:    "-10.00",
-			annualInterestRate: "3.0",
-			durationInMonths:   2,
-			wantErr:            loan.ErrInvalidParameter,
+			name:               "1000LoanWith5.0RateIn3MonthsOnThreeMinorUnitsCurrency",
+			totalLoanAmount:    "1000.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   3,
+			currency:           loan.KWD,
 		},
 		{
-			name:               "ErrorIfInterestRateIsNegative",
-			totalLoanAmount:    "10.00",
-			annualInterestRate: "-1.0",
-			durationInMonths:   2,
-			wantErr:            loan.ErrInvalidParameter,
+			name:               "1234.56LoanWith7.3RateIn37Months",
+			totalLoanAmount:    "1234.56",
+			annualInterestRate: "7.3",
+			durationInMonths:   37,
+			currency:           loan.EUR,
 		},
 		{
-			name:               "ErrorIfInterestRateIsZero",
-			totalLoanAmount:    "10.00",
-			annualInterestRate: "0",
-			durationInMonths:   2,
-			wantErr:            loan.ErrInvalidParameter,
+			name:               "999999LoanWith3.9RateIn360Months",
+			totalLoanAmount:    "999999",
+			annualInterestRate: "3.9",
+			durationInMonths:   360,
+			currency:           loan.JPY,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			loanAmount := toDecimal(t, test.totalLoanAmount)
-			interestRate := toDecimal(t, test.annualInterestRate)
-			want := decimal.Decimal{}
-
-			if test.want != "" {
-				want = toDecimal(t, test.want)
+			payments, err := loan.CreatePlanForCurrency(
+				loanAmount,
+				toDecimal(t, test.annualInterestRate),
+				test.durationInMonths,
+				parseTime(t, "2018-01-01T00:00:00Z"),
+				test.currency,
+			)
+			if err != nil {
+				t.Fatal(err)
 			}
 
-			got, err := loan.CalculateAnnuity(loanAmount, interestRate, test.durationInMonths)
-
-			if !errors.Is(err, test.wantErr) {
-				t.Errorf("got error %v; want %v", err, test.wantErr)
+			lastPayment := payments[len(payments)-1]
+			if !lastPayment.RemainingOutstandingPrincipal.IsZero() {
+				t.Errorf("got remaining principal %v on last payment; want 0", lastPayment.RemainingOutstandingPrincipal)
 			}
 
-			if !got.Equal(want) {
-				t.Errorf("got result %v; want %v", got, test.want)
+			totalPrincipal := decimal.Zero
+			for _, p := range payments {
+				totalPrincipal = totalPrincipal.Add(p.Principal)
+			}
+			if !totalPrincipal.Equal(loanAmount) {
+				t.Errorf("got total principal %v; want %v", totalPrincipal, loanAmount)
 			}
 		})
 	}
 }
 
-func toDecimal(t *testing.T, v string) decimal.Decimal {
-	t.Helper()
-	d, err := decimal.NewFromString(v)
+func TestIteratePlanMatchesCreatePlan(t *testing.T) {
+	amount := toDecimal(t, "999999")
+	rate := toDecimal(t, "3.9")
+	start := parseTime(t, "2018-01-01T00:00:00Z")
+
+	want, err := loan.CreatePlan(amount, rate, 360, start)
 	if err != nil {
 		t.Fatal(err)
 	}
-	return d
-}
 
-func parseTime(t *testing.T, s string) time.Time {
-	t.Helper()
-	v, err := time.Parse(time.RFC3339, s)
+	got := []loan.Payment{}
+	err = loan.IteratePlan(amount, rate, 360, start, func(p loan.Payment) error {
+		got = append(got, p)
+		return nil
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
-	return v
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("IteratePlan() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestIteratePlanStopsOnError(t *testing.T) {
+	errStop := errors.New("stop")
+	iterations := 0
+
+	err := loan.IteratePlan(
+		toDecimal(t, "5000"),
+		toDecimal(t, "5.0"),
+		24,
+		parseTime(t, "2018-01-01T00:00:00Z"),
+		func(p loan.Payment) error {
+			iterations++
+			if iterations == 3 {
+				return errStop
+			}
+			return nil
+		},
+	)
+
+	if !errors.Is(err, errStop) {
+		t.Errorf("got error %v; want %v", err, errStop)
+	}
+	if iterations != 3 {
+		t.Errorf("got %d iterations; want 3", iterations)
+	}
+}
+
+func TestIteratePlanValidatesParametersBeforeIterating(t *testing.T) {
+	err := loan.IteratePlan(
+		toDecimal(t, "5000"),
+		toDecimal(t, "5.0"),
+		1000000000,
+		parseTime(t, "2018-01-01T00:00:00Z"),
+		func(p loan.Payment) error {
+			t.Fatal("no payment should be informed on invalid parameters")
+			return nil
+		},
+	)
+
+	if !errors.Is(err, loan.ErrInvalidParameter) {
+		t.Errorf("got error %v; want %v", err, loan.ErrInvalidParameter)
+	}
+}
+
+func TestZeroInterestRatePlans(t *testing.T) {
+	type PlanCreator func(
+		totalLoanAmount decimal.Decimal,
+		annualInterestRate decimal.Decimal,
+		durationInMonths int,
+		start time.Time,
+	) ([]loan.Payment, error)
+
+	creators := map[string]PlanCreator{
+		"Annuity": loan.CreatePlan,
+		"Linear":  loan.CreateLinearPlan,
+	}
+
+	for name, createPlan := range creators {
+		t.Run(name, func(t *testing.T) {
+			payments, err := createPlan(
+				toDecimal(t, "1200"),
+				toDecimal(t, "0"),
+				12,
+				parseTime(t, "2020-01-01T00:00:00Z"),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(payments) != 12 {
+				t.Fatalf("got %d payments; want 12", len(payments))
+			}
+
+			hundred := toDecimal(t, "100")
+			for i, p := range payments {
+				if !p.PaymentAmount.Equal(hundred) || !p.Principal.Equal(hundred) {
+					t.Errorf("payment %d: got amount %v and principal %v; want 100", i, p.PaymentAmount, p.Principal)
+				}
+				if !p.Interest.IsZero() {
+					t.Errorf("payment %d: got interest %v; want 0", i, p.Interest)
+				}
+				wantRemaining := decimal.NewFromInt(int64(1100 - 100*i))
+				if !p.RemainingOutstandingPrincipal.Equal(wantRemaining) {
+					t.Errorf("payment %d: got remaining %v; want %v", i, p.RemainingOutstandingPrincipal, wantRemaining)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateLinearPlan(t *testing.T) {
+
+	type Test struct {
+		name               string
+		totalLoanAmount    string
+		annualInterestRate string
+		durationInMonths   int
+		startDate          time.Time
+		want               []loan.Payment
+		wantErr            error
+	}
+
+	tests := []Test{
+		{
+			name:               "SuccessOn1000LoanWith12.0RateIn3Months",
+			totalLoanAmount:    "1000.0",
+			annualInterestRate: "12.0",
+			durationInMonths:   3,
+			startDate:          parseTime(t, "2018-01-01T00:00:00Z"),
+			want: []loan.Payment{
+				{
+					Date:                          parseTime(t, "2018-01-01T00:00:00Z"),
+					PaymentAmount:                 toDecimal(t, "343.33"),
+					Interest:                      toDecimal(t, "10.00"),
+					Principal:                     toDecimal(t, "333.33"),
+					InitialOutstandingPrincipal:   toDecimal(t, "1000"),
+					RemainingOutstandingPrincipal: toDecimal(t, "666.67"),
+					DaysInPeriod:                  30,
+				},
+				{
+					Date:                          parseTime(t, "2018-02-01T00:00:00Z"),
+					PaymentAmount:                 toDecimal(t, "340.00"),
+					Interest:                      toDecimal(t, "6.67"),
+					Principal:                     toDecimal(t, "333.33"),
+					InitialOutstandingPrincipal:   toDecimal(t, "666.67"),
+					RemainingOutstandingPrincipal: toDecimal(t, "333.34"),
+					DaysInPeriod:                  30,
+				},
+				{
+					Date:                          parseTime(t, "2018-03-01T00:00:00Z"),
+					PaymentAmount:                 toDecimal(t, "336.67"),
+					Interest:                      toDecimal(t, "3.33"),
+					Principal:                     toDecimal(t, "333.34"),
+					InitialOutstandingPrincipal:   toDecimal(t, "333.34"),
+					RemainingOutstandingPrincipal: toDecimal(t, "0"),
+					DaysInPeriod:                  30,
+				},
+			},
+		},
+		{
+			name:               "ErrorOnStartDateDay29",
+			totalLoanAmount:    "5000.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   3,
+			startDate:          parseTime(t, "2020-12-29T00:00:00Z"),
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfDurationIsZero",
+			totalLoanAmount:    "5000.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   0,
+			startDate:          parseTime(t, "2020-12-01T00:00:00Z"),
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfLoanAmountIsNegative",
+			totalLoanAmount:    "-10.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   3,
+			startDate:          parseTime(t, "2020-12-01T00:00:00Z"),
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorNegativeInterestRate",
+			totalLoanAmount:    "5000.0",
+			annualInterestRate: "-1.0",
+			durationInMonths:   3,
+			startDate:          parseTime(t, "2020-12-01T00:00:00Z"),
+			wantErr:            loan.ErrInvalidParameter,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			loanAmount := toDecimal(t, test.totalLoanAmount)
+			interestRate := toDecimal(t, test.annualInterestRate)
+
+			got, err := loan.CreateLinearPlan(
+				loanAmount,
+				interestRate,
+				test.durationInMonths,
+				test.startDate,
+			)
+
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("got error %v; want %v", err, test.wantErr)
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("CreateLinearPlan() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLinearPlanPrincipalSumsToLoanAmount(t *testing.T) {
+	loanAmounts := []string{"1000.0", "5000.0", "1234.57", "0.05"}
+	durations := []int{1, 3, 7, 24, 360}
+
+	for _, amount := range loanAmounts {
+		for _, duration := range durations {
+			loanAmount := toDecimal(t, amount)
+			payments, err := loan.CreateLinearPlan(
+				loanAmount,
+				toDecimal(t, "5.0"),
+				duration,
+				parseTime(t, "2018-01-01T00:00:00Z"),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			totalPrincipal := decimal.Zero
+			for _, payment := range payments {
+				totalPrincipal = totalPrincipal.Add(payment.Principal)
+			}
+
+			if !totalPrincipal.Equal(loanAmount) {
+				t.Errorf("amount %s duration %d: got total principal %v; want %v",
+					amount, duration, totalPrincipal, loanAmount)
+			}
+		}
+	}
+}
+
+func TestAnnuityCalculation(t *testing.T) {
+
+	// Test represents a single test case for the annuity calculation
+	// We use strings here to make the test descriptor more concise and
+	// easier to read as a high level behavior descriptor.
+	type Test struct {
+		name               string
+		totalLoanAmount    string
+		annualInterestRate string
+		durationInMonths   int
+		want               string
+		wantErr            error
+	}
+
+	tests := []Test{
+		{
+			name:               "SuccessOn5000LoanWith5.0RateIn24Months",
+			totalLoanAmount:    "5000.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   24,
+			want:               "219.36",
+		},
+		{
+			name:               "SuccessOn5000LoanWith5.0RateIn2Months",
+			totalLoanAmount:    "5000.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   2,
+			want:               "2515.64",
+		},
+		{
+			name:               "SuccessOn1LoanWith5.0RateIn2Months",
+			totalLoanAmount:    "1.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   2,
+			want:               "0.5",
+		},
+		{
+			name:               "SuccessOn1LoanWith5.0RateIn500Months",
+			totalLoanAmount:    "1.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   50,
+			want:               "0.02",
+		},
+		{
+			name:               "SuccessOn5000LoanWith5.0RateIn1Month",
+			totalLoanAmount:    "5000.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   1,
+			want:               "5020.83",
+		},
+		{
+			name:               "SuccessOn5000LoanWith0.1RateIn24Months",
+			totalLoanAmount:    "5000.0",
+			annualInterestRate: "0.1",
+			durationInMonths:   24,
+			want:               "208.55",
+		},
+		{
+			name:               "ErrorIfDurationIsZero",
+			totalLoanAmount:    "500.00",
+			annualInterestRate: "3.0",
+			durationInMonths:   0,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfDurationIsNegative",
+			totalLoanAmount:    "500.00",
+			annualInterestRate: "3.0",
+			durationInMonths:   -1,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfDurationIsOneBillionMonths",
+			totalLoanAmount:    "500.00",
+			annualInterestRate: "3.0",
+			durationInMonths:   1000000000,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfLoanAmountIsZero",
+			totalLoanAmount:    "0.00",
+			annualInterestRate: "3.0",
+			durationInMonths:   2,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfLoanAmountIsNegative",
+			totalLoanAmount:    "-10.00",
+			annualInterestRate: "3.0",
+			durationInMonths:   2,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfInterestRateIsNegative",
+			totalLoanAmount:    "10.00",
+			annualInterestRate: "-1.0",
+			durationInMonths:   2,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "SuccessOn1200LoanWith0RateIn12Months",
+			totalLoanAmount:    "1200.00",
+			annualInterestRate: "0",
+			durationInMonths:   12,
+			want:               "100",
+		},
+		{
+			name:               "SuccessOn1000LoanWith0RateIn3Months",
+			totalLoanAmount:    "1000.00",
+			annualInterestRate: "0",
+			durationInMonths:   3,
+			want:               "333.33",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			loanAmount := toDecimal(t, test.totalLoanAmount)
+			interestRate := toDecimal(t, test.annualInterestRate)
+			want := decimal.Decimal{}
+
+			if test.want != "" {
+				want = toDecimal(t, test.want)
+			}
+
+			got, err := loan.CalculateAnnuity(loanAmount, interestRate, test.durationInMonths)
+
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("got error %v; want %v", err, test.wantErr)
+			}
+
+			if !got.Equal(want) {
+				t.Errorf("got result %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAnnuityCalculationFromPeriodicRate(t *testing.T) {
+
+	type Test struct {
+		name            string
+		totalLoanAmount string
+		periodicRate    string
+		periods         int
+		want            string
+		wantErr         error
+	}
+
+	tests := []Test{
+		{
+			name:            "SuccessOn5000LoanWith0.5RateIn24Periods",
+			totalLoanAmount: "5000.00",
+			periodicRate:    "0.5",
+			periods:         24,
+			want:            "221.60",
+		},
+		{
+			name:            "SuccessOn1200LoanWith0RateIn12Periods",
+			totalLoanAmount: "1200.00",
+			periodicRate:    "0",
+			periods:         12,
+			want:            "100",
+		},
+		{
+			name:            "SuccessOnWeeklyPeriodsFor30Years",
+			totalLoanAmount: "100000.00",
+			periodicRate:    "0.1",
+			periods:         30 * 52,
+			want:            "126.63",
+		},
+		{
+			name:            "ErrorIfPeriodsIsZero",
+			totalLoanAmount: "500.00",
+			periodicRate:    "0.5",
+			periods:         0,
+			wantErr:         loan.ErrInvalidParameter,
+		},
+		{
+			name:            "ErrorIfPeriodsIsOneBillion",
+			totalLoanAmount: "500.00",
+			periodicRate:    "0.5",
+			periods:         1000000000,
+			wantErr:         loan.ErrInvalidParameter,
+		},
+		{
+			name:            "ErrorIfLoanAmountIsZero",
+			totalLoanAmount: "0",
+			periodicRate:    "0.5",
+			periods:         2,
+			wantErr:         loan.ErrInvalidParameter,
+		},
+		{
+			name:            "ErrorIfRateIsNegative",
+			totalLoanAmount: "500.00",
+			periodicRate:    "-0.5",
+			periods:         2,
+			wantErr:         loan.ErrInvalidParameter,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			want := decimal.Decimal{}
+			if test.want != "" {
+				want = toDecimal(t, test.want)
+			}
+
+			got, err := loan.CalculateAnnuityFromPeriodicRate(
+				toDecimal(t, test.totalLoanAmount),
+				toDecimal(t, test.periodicRate),
+				test.periods,
+			)
+
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("got error %v; want %v", err, test.wantErr)
+			}
+
+			if !got.Equal(want) {
+				t.Errorf("got result %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAnnuityFromPeriodicRateMatchesAnnualRate(t *testing.T) {
+	type Test struct {
+		totalLoanAmount    string
+		annualInterestRate string
+		durationInMonths   int
+	}
+
+	tests := []Test{
+		{totalLoanAmount: "5000", annualInterestRate: "5.0", durationInMonths: 24},
+		{totalLoanAmount: "2000", annualInterestRate: "1.0", durationInMonths: 2},
+		{totalLoanAmount: "1234.56", annualInterestRate: "7.3", durationInMonths: 37},
+		{totalLoanAmount: "999999", annualInterestRate: "3.9", durationInMonths: 360},
+		{totalLoanAmount: "1200", annualInterestRate: "0", durationInMonths: 12},
+	}
+
+	for _, test := range tests {
+		amount := toDecimal(t, test.totalLoanAmount)
+		annualRate := toDecimal(t, test.annualInterestRate)
+
+		want, err := loan.CalculateAnnuity(amount, annualRate, test.durationInMonths)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		monthlyRate := annualRate.Div(decimal.NewFromInt(12))
+		got, err := loan.CalculateAnnuityFromPeriodicRate(amount, monthlyRate, test.durationInMonths)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !got.Equal(want) {
+			t.Errorf("%+v: got annuity %v from periodic rate; want %v", test, got, want)
+		}
+	}
+}
+
+func TestAnnuityCalculationWithPrecision(t *testing.T) {
+
+	type Test struct {
+		name               string
+		totalLoanAmount    string
+		annualInterestRate string
+		durationInMonths   int
+		precision          int
+		want               string
+		wantErr            error
+	}
+
+	tests := []Test{
+		{
+			name:               "SuccessOn5000LoanWith5.0RateIn24MonthsWithPrecision0",
+			totalLoanAmount:    "5000.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   24,
+			precision:          0,
+			want:               "219",
+		},
+		{
+			name:               "SuccessOn5000LoanWith5.0RateIn2MonthsWithPrecision0",
+			totalLoanAmount:    "5000.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   2,
+			precision:          0,
+			want:               "2516",
+		},
+		{
+			name:               "SuccessOn5000LoanWith5.0RateIn24MonthsWithPrecision4",
+			totalLoanAmount:    "5000.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   24,
+			precision:          4,
+			want:               "219.3569",
+		},
+		{
+			name:               "SuccessOn5000LoanWith5.0RateIn1MonthWithPrecision4",
+			totalLoanAmount:    "5000.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   1,
+			precision:          4,
+			want:               "5020.8333",
+		},
+		{
+			name:               "SuccessOn5000LoanWith5.0RateIn24MonthsWithPrecision2",
+			totalLoanAmount:    "5000.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   24,
+			precision:          2,
+			want:               "219.36",
+		},
+		{
+			name:               "ErrorIfPrecisionIsNegative",
+			totalLoanAmount:    "5000.0",
+			annualInterestRate: "5.0",
+			durationInMonths:   24,
+			precision:          -1,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			loanAmount := toDecimal(t, test.totalLoanAmount)
+			interestRate := toDecimal(t, test.annualInterestRate)
+			want := decimal.Decimal{}
+
+			if test.want != "" {
+				want = toDecimal(t, test.want)
+			}
+
+			got, err := loan.CalculateAnnuityWithPrecision(
+				loanAmount,
+				interestRate,
+				test.durationInMonths,
+				test.precision,
+			)
+
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("got error %v; want %v", err, test.wantErr)
+			}
+
+			if !got.Equal(want) {
+				t.Errorf("got result %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCalculateAnnuityWithMaxDuration(t *testing.T) {
+	const maxDuration = 720
+
+	plan, err := loan.BuildPlan(toDecimal(t, "100000"), toDecimal(t, "5.0"), 700, parseTime(t, "2018-01-01T00:00:00Z"), loan.WithMaxDuration(maxDuration))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := loan.CalculateAnnuityWithMaxDuration(toDecimal(t, "100000"), toDecimal(t, "5.0"), 700, 2, maxDuration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(plan[0].PaymentAmount) {
+		t.Errorf("got annuity %v; want the plan payment %v", got, plan[0].PaymentAmount)
+	}
+
+	_, err = loan.CalculateAnnuityWithMaxDuration(toDecimal(t, "100000"), toDecimal(t, "5.0"), maxDuration+1, 2, maxDuration)
+	if !errors.Is(err, loan.ErrInvalidParameter) {
+		t.Errorf("got error %v for a duration above the max; want %v", err, loan.ErrInvalidParameter)
+	}
+
+	_, err = loan.CalculateAnnuityWithPrecision(toDecimal(t, "100000"), toDecimal(t, "5.0"), 700, 2)
+	if !errors.Is(err, loan.ErrInvalidParameter) {
+		t.Errorf("got error %v for a duration above the default max; want %v", err, loan.ErrInvalidParameter)
+	}
+}
+
+func TestMaxLoanForPayment(t *testing.T) {
+
+	type Test struct {
+		name               string
+		monthlyPayment     string
+		annualInterestRate string
+		durationInMonths   int
+		want               string
+		wantErr            error
+	}
+
+	tests := []Test{
+		{
+			name:               "SuccessOn219.36PaymentWith5.0RateIn24Months",
+			monthlyPayment:     "219.36",
+			annualInterestRate: "5.0",
+			durationInMonths:   24,
+			want:               "5000.07",
+		},
+		{
+			name:               "SuccessOn500PaymentWith6.0RateIn36Months",
+			monthlyPayment:     "500",
+			annualInterestRate: "6.0",
+			durationInMonths:   36,
+			want:               "16435.51",
+		},
+		{
+			name:               "SuccessOn100PaymentWith0RateIn12Months",
+			monthlyPayment:     "100",
+			annualInterestRate: "0",
+			durationInMonths:   12,
+			want:               "1200",
+		},
+		{
+			name:               "ErrorIfPaymentIsZero",
+			monthlyPayment:     "0",
+			annualInterestRate: "6.0",
+			durationInMonths:   36,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfPaymentIsNegative",
+			monthlyPayment:     "-1",
+			annualInterestRate: "6.0",
+			durationInMonths:   36,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfDurationIsZero",
+			monthlyPayment:     "500",
+			annualInterestRate: "6.0",
+			durationInMonths:   0,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfDurationIsNegative",
+			monthlyPayment:     "500",
+			annualInterestRate: "6.0",
+			durationInMonths:   -1,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfInterestRateIsNegative",
+			monthlyPayment:     "500",
+			annualInterestRate: "-6.0",
+			durationInMonths:   36,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			want := decimal.Decimal{}
+			if test.want != "" {
+				want = toDecimal(t, test.want)
+			}
+
+			got, err := loan.MaxLoanForPayment(
+				toDecimal(t, test.monthlyPayment),
+				toDecimal(t, test.annualInterestRate),
+				test.durationInMonths,
+			)
+
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("got error %v; want %v", err, test.wantErr)
+			}
+
+			if !got.Equal(want) {
+				t.Errorf("got result %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMaxLoanForPaymentRoundTripsWithAnnuity(t *testing.T) {
+
+	type Test struct {
+		monthlyPayment     string
+		annualInterestRate string
+		durationInMonths   int
+	}
+
+	tests := []Test{
+		{monthlyPayment: "219.36", annualInterestRate: "5.0", durationInMonths: 24},
+		{monthlyPayment: "500", annualInterestRate: "6.0", durationInMonths: 36},
+		{monthlyPayment: "1001.25", annualInterestRate: "1.0", durationInMonths: 2},
+		{monthlyPayment: "1500", annualInterestRate: "3.5", durationInMonths: 360},
+		{monthlyPayment: "5020.83", annualInterestRate: "5.0", durationInMonths: 1},
+	}
+
+	for _, test := range tests {
+		monthlyPayment := toDecimal(t, test.monthlyPayment)
+		interestRate := toDecimal(t, test.annualInterestRate)
+
+		maxLoan, err := loan.MaxLoanForPayment(monthlyPayment, interestRate, test.durationInMonths)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		annuity, err := loan.CalculateAnnuity(maxLoan, interestRate, test.durationInMonths)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !annuity.Equal(monthlyPayment) {
+			t.Errorf("%v: got annuity %v for max loan %v; want %v",
+				test, annuity, maxLoan, monthlyPayment)
+		}
+	}
+}
+
+func TestCreatePlanContext(t *testing.T) {
+	got, err := loan.CreatePlanContext(
+		context.Background(),
+		toDecimal(t, "5000"),
+		toDecimal(t, "5.0"),
+		24,
+		parseTime(t, "2018-01-01T00:00:00Z"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := createPlan(t, "5000", "5.0", 24)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CreatePlanContext() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCreatePlanContextCancellation(t *testing.T) {
+
+	type Test struct {
+		name       string
+		validUntil int
+	}
+
+	tests := []Test{
+		{
+			name:       "CanceledBeforeStart",
+			validUntil: 0,
+		},
+		{
+			name:       "CanceledPartwayThrough",
+			validUntil: 3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := &cancelAfterChecks{Context: context.Background(), validUntil: test.validUntil}
+			payments, err := loan.CreatePlanContext(
+				ctx,
+				toDecimal(t, "5000"),
+				toDecimal(t, "5.0"),
+				loan.DefaultMaxDurationInMonths,
+				parseTime(t, "2018-01-01T00:00:00Z"),
+			)
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("got error %v; want %v", err, context.Canceled)
+			}
+			if payments != nil {
+				t.Errorf("got payments %v; want none", payments)
+			}
+			if ctx.checks != test.validUntil+1 {
+				t.Errorf("got %d context checks; want %d", ctx.checks, test.validUntil+1)
+			}
+		})
+	}
+}
+
+func TestPlanWithFirstPaymentOffset(t *testing.T) {
+	got, err := loan.BuildPlan(
+		toDecimal(t, "5000"),
+		toDecimal(t, "5.0"),
+		24,
+		parseTime(t, "2018-01-01T00:00:00Z"),
+		loan.WithFirstPaymentOffset(1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// One extra month of interest from the disbursement:
+	// 5000 * (1 + 0.05/12) = 5020.83
+	want, err := loan.CreatePlan(
+		toDecimal(t, "5020.83"),
+		toDecimal(t, "5.0"),
+		24,
+		parseTime(t, "2018-02-01T00:00:00Z"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("plan with first payment offset mismatch (-want +got):\n%s", diff)
+	}
+
+	wantFirstDate := parseTime(t, "2018-02-01T00:00:00Z")
+	if !got[0].Date.Equal(wantFirstDate) {
+		t.Errorf("got first payment date %v; want %v", got[0].Date, wantFirstDate)
+	}
+
+	noOffset := createPlan(t, "5000", "5.0", 24)
+	if !got[0].Interest.GreaterThan(noOffset[0].Interest) {
+		t.Errorf("got first interest %v; want more than %v", got[0].Interest, noOffset[0].Interest)
+	}
+	if !got[0].PaymentAmount.GreaterThan(noOffset[0].PaymentAmount) {
+		t.Errorf("got payment amount %v; want more than %v", got[0].PaymentAmount, noOffset[0].PaymentAmount)
+	}
+}
+
+func TestPlanWithZeroFirstPaymentOffset(t *testing.T) {
+	got, err := loan.BuildPlan(
+		toDecimal(t, "5000"),
+		toDecimal(t, "5.0"),
+		24,
+		parseTime(t, "2018-01-01T00:00:00Z"),
+		loan.WithFirstPaymentOffset(0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := createPlan(t, "5000", "5.0", 24)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("plan without first payment offset mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPlanWithNegativeFirstPaymentOffset(t *testing.T) {
+	_, err := loan.BuildPlan(
+		toDecimal(t, "5000"),
+		toDecimal(t, "5.0"),
+		24,
+		parseTime(t, "2018-01-01T00:00:00Z"),
+		loan.WithFirstPaymentOffset(-1),
+	)
+	if !errors.Is(err, loan.ErrInvalidParameter) {
+		t.Errorf("got error %v; want %v", err, loan.ErrInvalidParameter)
+	}
+}
+
+func TestErrorSentinels(t *testing.T) {
+
+	type Test struct {
+		name string
+		run  func() error
+		want error
+	}
+
+	start := parseTime(t, "2020-01-01T00:00:00Z")
+	sentinels := []error{
+		loan.ErrUnsupportedConvention,
+		loan.ErrNoConvergence,
+		loan.ErrNegativeAmortization,
+	}
+
+	tests := []Test{
+		{
+			name: "NegativeAmortization",
+			run: func() error {
+				_, err := loan.CreatePlan(toDecimal(t, "100"), toDecimal(t, "1000"), 360, start)
+				return err
+			},
+			want: loan.ErrNegativeAmortization,
+		},
+		{
+			name: "UnsupportedDayCount",
+			run: func() error {
+				_, err := loan.BuildPlan(toDecimal(t, "1000"), toDecimal(t, "5"), 12, start, loan.WithDayCount(loan.DayCount(42)))
+				return err
+			},
+			want: loan.ErrUnsupportedConvention,
+		},
+		{
+			name: "APRNoConvergence",
+			run: func() error {
+				_, err := loan.APR([]loan.Payment{
+					{
+						PaymentAmount:               toDecimal(t, "1000000000000"),
+						InitialOutstandingPrincipal: toDecimal(t, "1"),
+					},
+				}, loan.Monthly)
+				return err
+			},
+			want: loan.ErrNoConvergence,
+		},
+		{
+			name: "InvalidParameter",
+			run: func() error {
+				_, err := loan.CreatePlan(toDecimal(t, "1000"), toDecimal(t, "5"), 0, start)
+				return err
+			},
+			want: loan.ErrInvalidParameter,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.run()
+
+			if !errors.Is(err, test.want) {
+				t.Errorf("got error %v; want %v", err, test.want)
+			}
+			// All errors of the package are invalid parameters.
+			if !errors.Is(err, loan.ErrInvalidParameter) {
+				t.Errorf("got error %v; want it to also be %v", err, loan.ErrInvalidParameter)
+			}
+			for _, sentinel := range sentinels {
+				if sentinel != test.want && errors.Is(err, sentinel) {
+					t.Errorf("got error %v; want it to not be %v", err, sentinel)
+				}
+			}
+		})
+	}
+}
+
+func toDecimal(t *testing.T, v string) decimal.Decimal {
+	t.Helper()
+	d, err := decimal.NewFromString(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func parseTime(t *testing.T, s string) time.Time {
+	t.Helper()
+	v, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// cancelAfterChecks is a context that is canceled
+// after its error is checked validUntil times.
+type cancelAfterChecks struct {
+	context.Context
+	validUntil int
+	checks     int
+}
+
+func (c *cancelAfterChecks) Err() error {
+	c.checks++
+	if c.checks > c.validUntil {
+		return context.Canceled
+	}
+	return nil
+}
+
+// TestAnnuityCalculationIsPinned pins the annuity of several durations
+// and rates, any optimization of the annuity calculation must not
+// change its results, not even by a cent.
+func TestAnnuityCalculationIsPinned(t *testing.T) {
+	type Test struct {
+		annualInterestRate string
+		durationInMonths   int
+		want               string
+	}
+
+	tests := []Test{
+		{annualInterestRate: "5.0", durationInMonths: 1, want: "5020.83"},
+		{annualInterestRate: "5.0", durationInMonths: 2, want: "2515.64"},
+		{annualInterestRate: "5.0", durationInMonths: 7, want: "726.24"},
+		{annualInterestRate: "5.0", durationInMonths: 12, want: "428.04"},
+		{annualInterestRate: "5.0", durationInMonths: 24, want: "219.36"},
+		{annualInterestRate: "5.0", durationInMonths: 45, want: "122.08"},
+		{annualInterestRate: "5.0", durationInMonths: 60, want: "94.36"},
+		{annualInterestRate: "5.0", durationInMonths: 120, want: "53.03"},
+		{annualInterestRate: "5.0", durationInMonths: 240, want: "33"},
+		{annualInterestRate: "5.0", durationInMonths: 359, want: "26.87"},
+		{annualInterestRate: "5.0", durationInMonths: 360, want: "26.84"},
+		{annualInterestRate: "0.1", durationInMonths: 1, want: "5000.42"},
+		{annualInterestRate: "0.1", durationInMonths: 2, want: "2500.31"},
+		{annualInterestRate: "0.1", durationInMonths: 7, want: "714.52"},
+		{annualInterestRate: "0.1", durationInMonths: 12, want: "416.89"},
+		{annualInterestRate: "0.1", durationInMonths: 24, want: "208.55"},
+		{annualInterestRate: "0.1", durationInMonths: 45, want: "111.32"},
+		{annualInterestRate: "0.1", durationInMonths: 60, want: "83.55"},
+		{annualInterestRate: "0.1", durationInMonths: 120, want: "41.88"},
+		{annualInterestRate: "0.1", durationInMonths: 240, want: "21.04"},
+		{annualInterestRate: "0.1", durationInMonths: 359, want: "14.14"},
+		{annualInterestRate: "0.1", durationInMonths: 360, want: "14.1"},
+		{annualInterestRate: "19.99", durationInMonths: 1, want: "5083.29"},
+		{annualInterestRate: "19.99", durationInMonths: 2, want: "2562.64"},
+		{annualInterestRate: "19.99", durationInMonths: 7, want: "762.67"},
+		{annualInterestRate: "19.99", durationInMonths: 12, want: "463.15"},
+		{annualInterestRate: "19.99", durationInMonths: 24, want: "254.45"},
+		{annualInterestRate: "19.99", durationInMonths: 45, want: "158.79"},
+		{annualInterestRate: "19.99", durationInMonths: 60, want: "132.44"},
+		{annualInterestRate: "19.99", durationInMonths: 120, want: "96.59"},
+		{annualInterestRate: "19.99", durationInMonths: 240, want: "84.9"},
+		{annualInterestRate: "19.99", durationInMonths: 359, want: "83.51"},
+		{annualInterestRate: "19.99", durationInMonths: 360, want: "83.51"},
+	}
+
+	for _, test := range tests {
+		name := fmt.Sprintf("%sRateIn%dMonths", test.annualInterestRate, test.durationInMonths)
+		t.Run(name, func(t *testing.T) {
+			got, err := loan.CalculateAnnuity(toDecimal(t, "5000"), toDecimal(t, test.annualInterestRate), test.durationInMonths)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(toDecimal(t, test.want)) {
+				t.Errorf("got annuity %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLongPlanIsPinned(t *testing.T) {
+	payments := createPlan(t, "250000", "3.5", 360)
+
+	want := []string{"1122.61", "1123.79", "154140.78"}
+	got := []string{
+		payments[0].PaymentAmount.String(),
+		payments[len(payments)-1].PaymentAmount.String(),
+		loan.Summarize(payments).TotalInterest.String(),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("first payment, last payment and total interest mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func BenchmarkCalculateAnnuity(b *testing.B) {
+	totalLoanAmount := decimal.RequireFromString("250000")
+	annualInterestRate := decimal.RequireFromString("3.5")
+
+	for i := 0; i < b.N; i++ {
+		if _, err := loan.CalculateAnnuity(totalLoanAmount, annualInterestRate, 360); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCreatePlan(b *testing.B) {
+	totalLoanAmount := decimal.RequireFromString("250000")
+	annualInterestRate := decimal.RequireFromString("3.5")
+	start := time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < b.N; i++ {
+		if _, err := loan.CreatePlan(totalLoanAmount, annualInterestRate, 360, start); err != nil {
+			b.Fatal(err)
+		}
+	}
 }
@@ -0,0 +1,62 @@
+package loan
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PayoffAmount calculates how much the borrower owes to pay off the loan
+// on the given date, which is the outstanding principal plus the interest
+// accrued since the last payment.
+//
+// Scheduled payments that happen on or before the date are considered
+// paid, so on a payment date the payoff amount is the remaining outstanding
+// principal of that payment. Between payments the interest of the next
+// payment (calculated according to the day count of the plan) is
+// pro-rated by the days elapsed since the last payment.
+//
+// It returns an error if the plan has no payments or the date is
+// before the first payment or after the last payment of the plan.
+func PayoffAmount(payments []Payment, asOf time.Time) (decimal.Decimal, error) {
+	if len(payments) == 0 {
+		return decimal.Zero, fmt.Errorf("can't calculate payoff amount:%w: plan has no payments", ErrInvalidParameter)
+	}
+
+	first := payments[0].Date
+	last := payments[len(payments)-1].Date
+	if asOf.Before(first) || asOf.After(last) {
+		return decimal.Zero, fmt.Errorf(
+			"can't calculate payoff amount:%w: date %v should be in the range [%v, %v]",
+			ErrInvalidParameter,
+			asOf,
+			first,
+			last,
+		)
+	}
+
+	i := 0
+	for i+1 < len(payments) && !payments[i+1].Date.After(asOf) {
+		i++
+	}
+
+	paid := payments[i]
+	if i == len(payments)-1 || paid.Date.Equal(asOf) {
+		return paid.RemainingOutstandingPrincipal, nil
+	}
+
+	next := payments[i+1]
+	elapsed := daysBetween(paid.Date, asOf)
+	period := daysBetween(paid.Date, next.Date)
+
+	// The money values of a plan are all rounded to the same
+	// precision, so the accrued interest is rounded like them.
+	places := -next.Interest.Exponent()
+	if places < 0 {
+		places = 0
+	}
+	accrued := next.Interest.Mul(elapsed).Div(period).RoundBank(places)
+
+	return paid.RemainingOutstandingPrincipal.Add(accrued), nil
+}
@@ -0,0 +1,101 @@
+package loan_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestPayoffAmount(t *testing.T) {
+
+	type Test struct {
+		name string
+		asOf string
+		want string
+	}
+
+	tests := []Test{
+		{
+			name: "OnFirstPaymentDate",
+			asOf: "2018-01-01T00:00:00Z",
+			want: "4801.47",
+		},
+		{
+			name: "OnPaymentDate",
+			asOf: "2018-02-01T00:00:00Z",
+			want: "4602.12",
+		},
+		{
+			name: "MidPeriod",
+			asOf: "2018-01-16T00:00:00Z",
+			want: "4811.15",
+		},
+		{
+			name: "DayBeforePayment",
+			asOf: "2018-01-31T00:00:00Z",
+			want: "4820.83",
+		},
+		{
+			name: "MidPeriodWithTimeZone",
+			asOf: "2018-01-16T02:00:00+01:00",
+			want: "4811.15",
+		},
+		{
+			name: "OnLastPaymentDate",
+			asOf: "2019-12-01T00:00:00Z",
+			want: "0",
+		},
+	}
+
+	payments := createPlan(t, "5000", "5.0", 24)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := loan.PayoffAmount(payments, parseTime(t, test.asOf))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := toDecimal(t, test.want)
+			if !got.Equal(want) {
+				t.Errorf("got payoff amount %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestPayoffAmountFailures(t *testing.T) {
+
+	type Test struct {
+		name     string
+		payments []loan.Payment
+		asOf     string
+	}
+
+	tests := []Test{
+		{
+			name:     "NoPayments",
+			payments: nil,
+			asOf:     "2018-01-01T00:00:00Z",
+		},
+		{
+			name:     "BeforeFirstPayment",
+			payments: createPlan(t, "5000", "5.0", 24),
+			asOf:     "2017-12-31T23:59:59Z",
+		},
+		{
+			name:     "AfterLastPayment",
+			payments: createPlan(t, "5000", "5.0", 24),
+			asOf:     "2019-12-01T00:00:01Z",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := loan.PayoffAmount(test.payments, parseTime(t, test.asOf))
+			if !errors.Is(err, loan.ErrInvalidParameter) {
+				t.Errorf("got error %v; want %v", err, loan.ErrInvalidParameter)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package loan
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Prepayment represents an extra payment of principal made by
+// the borrower on a given date, besides the scheduled payments.
+type Prepayment struct {
+	Date   time.Time
+	Amount decimal.Decimal
+}
+
+// CreatePlanWithPrepayments will create a payment plan, as a list of payments,
+// throughout the lifetime of an annuity loan where the borrower makes
+// extra principal payments (prepayments).
+//
+// Each prepayment is applied to the outstanding principal together with
+// the first scheduled payment that happens on or after the prepayment
+// date, so the principal of that payment includes the prepayment amount.
+// The annuity is not recalculated, so prepayments shorten the plan, which
+// stops as soon as the outstanding principal reaches zero.
+//
+// The annual interest rate is informed as a percent, like 5.0, meaning 5 per cent an year.
+//
+// It returns an error if any of the parameters is invalid, like the duration
+// in months being zero, the start date has a day bigger than 28 or a
+// prepayment that is not positive or is out of the range of the plan dates.
+func CreatePlanWithPrepayments(
+	totalLoanAmount decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	durationInMonths int,
+	start time.Time,
+	prepayments []Prepayment,
+) ([]Payment, error) {
+
+	if err := validateStartDate(start); err != nil {
+		return nil, fmt.Errorf("can't create loan plan with prepayments:%w", err)
+	}
+
+	annuity, err := CalculateAnnuity(totalLoanAmount, annualInterestRate, durationInMonths)
+	if err != nil {
+		return nil, fmt.Errorf("can't create loan plan with prepayments:%w", err)
+	}
+
+	firstDate := paymentDate(start, 0)
+	lastDate := paymentDate(start, durationInMonths-1)
+	prepayments = append([]Prepayment(nil), prepayments...)
+
+	for _, p := range prepayments {
+		if p.Amount.LessThanOrEqual(decimal.Zero) {
+			return nil, fmt.Errorf(
+				"can't create loan plan with prepayments:%w: prepayment amount should be bigger than 0, it is %v",
+				ErrInvalidParameter,
+				p.Amount,
+			)
+		}
+		if p.Date.Before(firstDate) || p.Date.After(lastDate) {
+			return nil, fmt.Errorf(
+				"can't create loan plan with prepayments:%w: prepayment date %v should be in the range [%v, %v]",
+				ErrInvalidParameter,
+				p.Date,
+				firstDate,
+				lastDate,
+			)
+		}
+	}
+
+	sort.Slice(prepayments, func(i, j int) bool {
+		return prepayments[i].Date.Before(prepayments[j].Date)
+	})
+
+	payments := []Payment{}
+	initialOutstandingPrincipal := totalLoanAmount
+
+	for i := 0; i < durationInMonths && initialOutstandingPrincipal.IsPositive(); i++ {
+		date := paymentDate(start, i)
+		interest := calculateInterest(annualInterestRate, initialOutstandingPrincipal, Thirty360).RoundBank(precision)
+		principal := annuity.Sub(interest).RoundBank(precision)
+		if err := validateAmortization(i, annuity, interest); err != nil {
+			return nil, fmt.Errorf("can't create loan plan with prepayments:%w", err)
+		}
+
+		for len(prepayments) > 0 && !prepayments[0].Date.After(date) {
+			principal = principal.Add(prepayments[0].Amount)
+			prepayments = prepayments[1:]
+		}
+
+		if i == durationInMonths-1 || principal.GreaterThan(initialOutstandingPrincipal) {
+			principal = initialOutstandingPrincipal
+		}
+
+		paymentAmount := principal.Add(interest).RoundBank(precision)
+		remainingOutstandingPrincipal := initialOutstandingPrincipal.Sub(principal).RoundBank(precision)
+
+		payments = append(payments, Payment{
+			Date:                          date,
+			PaymentAmount:                 paymentAmount,
+			Interest:                      interest,
+			Principal:                     principal,
+			InitialOutstandingPrincipal:   initialOutstandingPrincipal,
+			RemainingOutstandingPrincipal: remainingOutstandingPrincipal,
+			DaysInPeriod:                  thirtyDaysInPeriod,
+		})
+
+		initialOutstandingPrincipal = remainingOutstandingPrincipal
+	}
+	return payments, nil
+}
@@ -0,0 +1,185 @@
+package loan_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestCreatePlanWithPrepayments(t *testing.T) {
+
+	type Test struct {
+		name        string
+		prepayments []loan.Prepayment
+		want        []loan.Payment
+		wantErr     error
+	}
+
+	tests := []Test{
+		{
+			name: "PrepaymentOfRemainingPrincipalEndsPlanEarly",
+			prepayments: []loan.Prepayment{
+				{
+					Date:   parseTime(t, "2018-01-01T00:00:00Z"),
+					Amount: toDecimal(t, "1000.42"),
+				},
+			},
+			want: []loan.Payment{
+				{
+					Date:                          parseTime(t, "2018-01-01T00:00:00Z"),
+					PaymentAmount:                 toDecimal(t, "2001.67"),
+					Interest:                      toDecimal(t, "1.67"),
+					Principal:                     toDecimal(t, "2000"),
+					InitialOutstandingPrincipal:   toDecimal(t, "2000"),
+					RemainingOutstandingPrincipal: toDecimal(t, "0"),
+					DaysInPeriod:                  30,
+				},
+			},
+		},
+		{
+			name: "PrepaymentBiggerThanPrincipalIsCapped",
+			prepayments: []loan.Prepayment{
+				{
+					Date:   parseTime(t, "2018-01-01T00:00:00Z"),
+					Amount: toDecimal(t, "5000"),
+				},
+			},
+			want: []loan.Payment{
+				{
+					Date:                          parseTime(t, "2018-01-01T00:00:00Z"),
+					PaymentAmount:                 toDecimal(t, "2001.67"),
+					Interest:                      toDecimal(t, "1.67"),
+					Principal:                     toDecimal(t, "2000"),
+					InitialOutstandingPrincipal:   toDecimal(t, "2000"),
+					RemainingOutstandingPrincipal: toDecimal(t, "0"),
+					DaysInPeriod:                  30,
+				},
+			},
+		},
+		{
+			name: "PrepaymentBetweenPaymentsIsAppliedOnNextPayment",
+			prepayments: []loan.Prepayment{
+				{
+					Date:   parseTime(t, "2018-01-15T00:00:00Z"),
+					Amount: toDecimal(t, "500"),
+				},
+			},
+			want: []loan.Payment{
+				{
+					Date:                          parseTime(t, "2018-01-01T00:00:00Z"),
+					PaymentAmount:                 toDecimal(t, "1001.25"),
+					Interest:                      toDecimal(t, "1.67"),
+					Principal:                     toDecimal(t, "999.58"),
+					InitialOutstandingPrincipal:   toDecimal(t, "2000"),
+					RemainingOutstandingPrincipal: toDecimal(t, "1000.42"),
+					DaysInPeriod:                  30,
+				},
+				{
+					Date:                          parseTime(t, "2018-02-01T00:00:00Z"),
+					PaymentAmount:                 toDecimal(t, "1001.25"),
+					Interest:                      toDecimal(t, "0.83"),
+					Principal:                     toDecimal(t, "1000.42"),
+					InitialOutstandingPrincipal:   toDecimal(t, "1000.42"),
+					RemainingOutstandingPrincipal: toDecimal(t, "0"),
+					DaysInPeriod:                  30,
+				},
+			},
+		},
+		{
+			name:        "NoPrepaymentsIsSameAsAnnuity",
+			prepayments: nil,
+			want:        createPlan(t, "2000.0", "1.0", 2),
+		},
+		{
+			name: "ErrorIfPrepaymentIsZero",
+			prepayments: []loan.Prepayment{
+				{
+					Date:   parseTime(t, "2018-01-01T00:00:00Z"),
+					Amount: toDecimal(t, "0"),
+				},
+			},
+			wantErr: loan.ErrInvalidParameter,
+		},
+		{
+			name: "ErrorIfPrepaymentIsBeforeStart",
+			prepayments: []loan.Prepayment{
+				{
+					Date:   parseTime(t, "2017-12-31T00:00:00Z"),
+					Amount: toDecimal(t, "10"),
+				},
+			},
+			wantErr: loan.ErrInvalidParameter,
+		},
+		{
+			name: "ErrorIfPrepaymentIsAfterLastPayment",
+			prepayments: []loan.Prepayment{
+				{
+					Date:   parseTime(t, "2018-02-02T00:00:00Z"),
+					Amount: toDecimal(t, "10"),
+				},
+			},
+			wantErr: loan.ErrInvalidParameter,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := loan.CreatePlanWithPrepayments(
+				toDecimal(t, "2000.0"),
+				toDecimal(t, "1.0"),
+				2,
+				parseTime(t, "2018-01-01T00:00:00Z"),
+				test.prepayments,
+			)
+
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("got error %v; want %v", err, test.wantErr)
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("CreatePlanWithPrepayments() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPrepaymentReducesInterestAndEndsPlanEarly(t *testing.T) {
+	withoutPrepayment := loan.Summarize(createPlan(t, "5000.0", "5.0", 24))
+
+	payments, err := loan.CreatePlanWithPrepayments(
+		toDecimal(t, "5000.0"),
+		toDecimal(t, "5.0"),
+		24,
+		parseTime(t, "2018-01-01T00:00:00Z"),
+		[]loan.Prepayment{
+			{
+				Date:   parseTime(t, "2018-02-01T00:00:00Z"),
+				Amount: toDecimal(t, "2500"),
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withPrepayment := loan.Summarize(payments)
+
+	if withPrepayment.NumberOfPayments >= 24 {
+		t.Errorf("got %d payments; want less than 24", withPrepayment.NumberOfPayments)
+	}
+
+	if !withPrepayment.TotalInterest.LessThan(withoutPrepayment.TotalInterest) {
+		t.Errorf("got total interest %v with prepayment; want less than %v",
+			withPrepayment.TotalInterest, withoutPrepayment.TotalInterest)
+	}
+
+	if !withPrepayment.TotalPrincipal.Equal(toDecimal(t, "5000")) {
+		t.Errorf("got total principal %v; want 5000", withPrepayment.TotalPrincipal)
+	}
+
+	lastPayment := payments[len(payments)-1]
+	if !lastPayment.RemainingOutstandingPrincipal.IsZero() {
+		t.Errorf("got remaining principal %v on last payment; want 0", lastPayment.RemainingOutstandingPrincipal)
+	}
+}
@@ -0,0 +1,46 @@
+package loan
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// EffectiveAnnualRate will calculate the effective annual rate (EAR)
+// of the given nominal annual rate when the interest is compounded
+// compoundingPerYear times an year, using the formula (1 + r/n)^n - 1.
+//
+// Both the nominal annual rate and the result are informed as a percent,
+// like 5.0, meaning 5 per cent an year. The result is not rounded.
+//
+// A zero nominal rate, like the one of zero-interest plans, has a zero
+// effective rate. It returns an error if any of the parameters is invalid,
+// like the compounding per year being zero or a negative nominal rate.
+func EffectiveAnnualRate(
+	nominalAnnualRate decimal.Decimal,
+	compoundingPerYear int,
+) (decimal.Decimal, error) {
+
+	if compoundingPerYear <= 0 {
+		return decimal.Zero, fmt.Errorf(
+			"can't calculate effective annual rate:%w: compounding per year should be bigger than 0, it is %v",
+			ErrInvalidParameter,
+			compoundingPerYear,
+		)
+	}
+
+	if nominalAnnualRate.IsNegative() {
+		return decimal.Zero, fmt.Errorf(
+			"can't calculate effective annual rate:%w: nominal rate can't be negative, it is %v",
+			ErrInvalidParameter,
+			nominalAnnualRate,
+		)
+	}
+
+	one := decimal.NewFromInt(1)
+	n := decimal.NewFromInt(int64(compoundingPerYear))
+	periodicRate := fromPercentToDecimal(nominalAnnualRate).Div(n)
+	effectiveRate := pow(one.Add(periodicRate), compoundingPerYear).Sub(one)
+
+	return effectiveRate.Mul(decimal.NewFromInt(100)), nil
+}
@@ -0,0 +1,93 @@
+package loan_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestEffectiveAnnualRate(t *testing.T) {
+
+	type Test struct {
+		name               string
+		nominalAnnualRate  string
+		compoundingPerYear int
+		// want is compared after rounding the result to 3 decimal places
+		want    string
+		wantErr error
+	}
+
+	tests := []Test{
+		{
+			name:               "MonthlyCompoundingOf5.0",
+			nominalAnnualRate:  "5.0",
+			compoundingPerYear: 12,
+			want:               "5.116",
+		},
+		{
+			name:               "AnnualCompoundingIsTheNominalRate",
+			nominalAnnualRate:  "5.0",
+			compoundingPerYear: 1,
+			want:               "5",
+		},
+		{
+			name:               "QuarterlyCompoundingOf8.0",
+			nominalAnnualRate:  "8.0",
+			compoundingPerYear: 4,
+			want:               "8.243",
+		},
+		{
+			name:               "DailyCompoundingOf10.0",
+			nominalAnnualRate:  "10.0",
+			compoundingPerYear: 365,
+			want:               "10.516",
+		},
+		{
+			name:               "ZeroNominalRateIsZero",
+			nominalAnnualRate:  "0",
+			compoundingPerYear: 12,
+			want:               "0",
+		},
+		{
+			name:               "ErrorIfCompoundingIsZero",
+			nominalAnnualRate:  "5.0",
+			compoundingPerYear: 0,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfCompoundingIsNegative",
+			nominalAnnualRate:  "5.0",
+			compoundingPerYear: -12,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+		{
+			name:               "ErrorIfNominalRateIsNegative",
+			nominalAnnualRate:  "-5.0",
+			compoundingPerYear: 12,
+			wantErr:            loan.ErrInvalidParameter,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := loan.EffectiveAnnualRate(
+				toDecimal(t, test.nominalAnnualRate),
+				test.compoundingPerYear,
+			)
+
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("got error %v; want %v", err, test.wantErr)
+			}
+
+			if test.wantErr != nil {
+				return
+			}
+
+			want := toDecimal(t, test.want)
+			if !got.Round(3).Equal(want) {
+				t.Errorf("got result %v; want %v", got, want)
+			}
+		})
+	}
+}
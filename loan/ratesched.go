@@ -0,0 +1,80 @@
+package loan
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// RatePeriod is a period of a loan with a stepped (variable) interest rate,
+// where the annual interest rate is fixed during the given number of months.
+type RatePeriod struct {
+	AnnualInterestRate decimal.Decimal
+	Months             int
+}
+
+// CreatePlanWithRateSchedule will create a payment plan, as a list of
+// payments, throughout the lifetime of an annuity loan whose interest rate
+// changes on known dates, like a teaser rate on the first year followed
+// by a higher rate.
+//
+// The duration of the loan is the sum of the months of all the rate periods,
+// which are applied in order. At the start of each rate period the annuity
+// is calculated again, with the rate of the period, amortizing the remaining
+// outstanding principal over the remaining months of the loan.
+//
+// The annual interest rates are informed as a percent, like 5.0, meaning 5 per cent an year.
+//
+// It returns an error if any of the parameters is invalid, like no rate
+// periods, a rate period with no months or the start date has a day
+// bigger than 28.
+func CreatePlanWithRateSchedule(
+	totalLoanAmount decimal.Decimal,
+	rates []RatePeriod,
+	start time.Time,
+) ([]Payment, error) {
+
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("can't create loan plan with rate schedule:%w: no rate periods", ErrInvalidParameter)
+	}
+
+	durationInMonths := 0
+	for i, rate := range rates {
+		if rate.Months <= 0 {
+			return nil, fmt.Errorf(
+				"can't create loan plan with rate schedule:%w: rate period %d should have at least 1 month, it has %d",
+				ErrInvalidParameter,
+				i,
+				rate.Months,
+			)
+		}
+		durationInMonths += rate.Months
+	}
+
+	payments := make([]Payment, 0, durationInMonths)
+	outstandingPrincipal := totalLoanAmount
+
+	for _, rate := range rates {
+		elapsedMonths := len(payments)
+		remaining, err := createPlan(
+			context.Background(),
+			outstandingPrincipal,
+			rate.AnnualInterestRate,
+			durationInMonths-elapsedMonths,
+			paymentDate(start, elapsedMonths),
+			defaultPlanConfig(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("can't create loan plan with rate schedule:%w", err)
+		}
+
+		// Only the payments of the rate period are used, the rest
+		// of the plan is calculated again with the next rate.
+		payments = append(payments, remaining[:rate.Months]...)
+		outstandingPrincipal = payments[len(payments)-1].RemainingOutstandingPrincipal
+	}
+
+	return payments, nil
+}
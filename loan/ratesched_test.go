@@ -0,0 +1,140 @@
+package loan_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/loan"
+	"github.com/shopspring/decimal"
+)
+
+func TestCreatePlanWithRateSchedule(t *testing.T) {
+	start := parseTime(t, "2020-01-01T00:00:00Z")
+	got, err := loan.CreatePlanWithRateSchedule(
+		toDecimal(t, "10000"),
+		[]loan.RatePeriod{
+			{AnnualInterestRate: toDecimal(t, "2.0"), Months: 12},
+			{AnnualInterestRate: toDecimal(t, "6.0"), Months: 12},
+		},
+		start,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 24 {
+		t.Fatalf("got %d payments; want 24", len(got))
+	}
+
+	// The teaser period is the same of a plan with the teaser rate.
+	teaser, err := loan.CreatePlan(toDecimal(t, "10000"), toDecimal(t, "2.0"), 24, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(teaser[:12], got[:12]); diff != "" {
+		t.Errorf("teaser period mismatch (-want +got):\n%s", diff)
+	}
+
+	// After the boundary the remaining principal is amortized
+	// with the new rate over the remaining months.
+	boundary := got[12]
+	wantAnnuity, err := loan.CalculateAnnuity(boundary.InitialOutstandingPrincipal, toDecimal(t, "6.0"), 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !boundary.InitialOutstandingPrincipal.Equal(got[11].RemainingOutstandingPrincipal) {
+		t.Errorf("got principal %v at the boundary; want %v", boundary.InitialOutstandingPrincipal, got[11].RemainingOutstandingPrincipal)
+	}
+	if !boundary.PaymentAmount.Equal(wantAnnuity) {
+		t.Errorf("got payment %v at the boundary; want %v", boundary.PaymentAmount, wantAnnuity)
+	}
+	if !boundary.PaymentAmount.GreaterThan(got[11].PaymentAmount) {
+		t.Errorf("got payment %v at the boundary; want more than %v", boundary.PaymentAmount, got[11].PaymentAmount)
+	}
+
+	totalPrincipal := decimal.Zero
+	for i, payment := range got {
+		totalPrincipal = totalPrincipal.Add(payment.Principal)
+
+		wantDate := start.AddDate(0, i, 0)
+		if !payment.Date.Equal(wantDate) {
+			t.Errorf("payment %d: got date %v; want %v", i, payment.Date, wantDate)
+		}
+	}
+
+	if !totalPrincipal.Equal(toDecimal(t, "10000")) {
+		t.Errorf("got total principal %v; want 10000", totalPrincipal)
+	}
+	if last := got[len(got)-1]; !last.RemainingOutstandingPrincipal.IsZero() {
+		t.Errorf("got remaining principal %v on last payment; want zero", last.RemainingOutstandingPrincipal)
+	}
+}
+
+func TestCreatePlanWithSingleRatePeriod(t *testing.T) {
+	start := parseTime(t, "2020-01-01T00:00:00Z")
+	got, err := loan.CreatePlanWithRateSchedule(
+		toDecimal(t, "5000"),
+		[]loan.RatePeriod{{AnnualInterestRate: toDecimal(t, "5.0"), Months: 24}},
+		start,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := loan.CreatePlan(toDecimal(t, "5000"), toDecimal(t, "5.0"), 24, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("plan mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCreatePlanWithRateScheduleFailures(t *testing.T) {
+
+	type Test struct {
+		name      string
+		rates     []loan.RatePeriod
+		startDate string
+	}
+
+	tests := []Test{
+		{
+			name:      "NoRatePeriods",
+			startDate: "2020-01-01T00:00:00Z",
+		},
+		{
+			name: "RatePeriodWithoutMonths",
+			rates: []loan.RatePeriod{
+				{AnnualInterestRate: toDecimal(t, "2.0"), Months: 12},
+				{AnnualInterestRate: toDecimal(t, "6.0"), Months: 0},
+			},
+			startDate: "2020-01-01T00:00:00Z",
+		},
+		{
+			name: "NegativeRate",
+			rates: []loan.RatePeriod{
+				{AnnualInterestRate: toDecimal(t, "2.0"), Months: 12},
+				{AnnualInterestRate: toDecimal(t, "-1.0"), Months: 12},
+			},
+			startDate: "2020-01-01T00:00:00Z",
+		},
+		{
+			name: "StartDateDayBiggerThan28",
+			rates: []loan.RatePeriod{
+				{AnnualInterestRate: toDecimal(t, "2.0"), Months: 12},
+			},
+			startDate: "2020-01-29T00:00:00Z",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := loan.CreatePlanWithRateSchedule(toDecimal(t, "1000"), test.rates, parseTime(t, test.startDate))
+			if !errors.Is(err, loan.ErrInvalidParameter) {
+				t.Errorf("got error %v; want %v", err, loan.ErrInvalidParameter)
+			}
+		})
+	}
+}
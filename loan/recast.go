@@ -0,0 +1,46 @@
+package loan
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// RecreatePlanFromBalance re-amortizes a loan from its current outstanding
+// balance, building a fresh annuity plan over the remaining term. It is
+// useful for servicing loans whose actual balance diverged from the original
+// plan, like after prepayments or missed payments.
+//
+// The annual interest rate is informed as a percent, like 5.0, meaning 5 per cent an year.
+// The plan has remainingMonths payments and the first one happens on the
+// next payment date, which has the same constraints of the start date
+// of CreatePlan.
+//
+// Feeding back the remaining outstanding principal of a plan and its
+// remaining term reproduces the rest of the original plan, besides
+// differences of a cent due to the annuity being rounded again.
+//
+// It returns an error if any of the parameters is invalid, like the
+// current balance not being positive or the remaining months being zero.
+func RecreatePlanFromBalance(
+	currentBalance decimal.Decimal,
+	annualInterestRate decimal.Decimal,
+	remainingMonths int,
+	nextPaymentDate time.Time,
+) ([]Payment, error) {
+
+	payments, err := createPlan(
+		context.Background(),
+		currentBalance,
+		annualInterestRate,
+		remainingMonths,
+		nextPaymentDate,
+		defaultPlanConfig(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("can't recreate plan from balance:%w", err)
+	}
+	return payments, nil
+}
@@ -0,0 +1,147 @@
+package loan_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/katcipis/loaner/loan"
+	"github.com/shopspring/decimal"
+)
+
+func TestRecreatePlanFromBalanceReproducesOriginalPlanTail(t *testing.T) {
+
+	type Test struct {
+		name     string
+		amount   string
+		rate     string
+		duration int
+		paid     int
+	}
+
+	tests := []Test{
+		{
+			name:     "AfterFirstPayment",
+			amount:   "5000",
+			rate:     "5.0",
+			duration: 24,
+			paid:     1,
+		},
+		{
+			name:     "HalfwayThrough",
+			amount:   "5000",
+			rate:     "5.0",
+			duration: 24,
+			paid:     12,
+		},
+		{
+			name:     "BeforeLastPayment",
+			amount:   "5000",
+			rate:     "5.0",
+			duration: 24,
+			paid:     23,
+		},
+		{
+			name:     "ZeroInterest",
+			amount:   "1200",
+			rate:     "0",
+			duration: 12,
+			paid:     6,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			original := createPlan(t, test.amount, test.rate, test.duration)
+			next := original[test.paid]
+
+			got, err := loan.RecreatePlanFromBalance(
+				next.InitialOutstandingPrincipal,
+				toDecimal(t, test.rate),
+				test.duration-test.paid,
+				next.Date,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The annuity is rounded again on the recreated plan, so
+			// payments may differ by a cent, with the last payment
+			// absorbing the accumulated difference.
+			want := original[test.paid:]
+			if len(got) != len(want) {
+				t.Fatalf("got %d payments; want %d", len(got), len(want))
+			}
+
+			cent := toDecimal(t, "0.01")
+			principal := decimal.Zero
+			for i, payment := range got {
+				principal = principal.Add(payment.Principal)
+
+				if !payment.Date.Equal(want[i].Date) {
+					t.Errorf("payment %d: got date %v; want %v", i, payment.Date, want[i].Date)
+				}
+				if i == len(got)-1 {
+					continue
+				}
+				if diff := payment.PaymentAmount.Sub(want[i].PaymentAmount).Abs(); diff.GreaterThan(cent) {
+					t.Errorf("payment %d: got amount %v; want %v", i, payment.PaymentAmount, want[i].PaymentAmount)
+				}
+			}
+
+			if !principal.Equal(next.InitialOutstandingPrincipal) {
+				t.Errorf("got total principal %v; want %v", principal, next.InitialOutstandingPrincipal)
+			}
+		})
+	}
+}
+
+func TestRecreatePlanFromBalanceFailures(t *testing.T) {
+
+	type Test struct {
+		name            string
+		balance         string
+		remainingMonths int
+		nextPaymentDate string
+	}
+
+	tests := []Test{
+		{
+			name:            "ZeroBalance",
+			balance:         "0",
+			remainingMonths: 12,
+			nextPaymentDate: "2020-01-01T00:00:00Z",
+		},
+		{
+			name:            "NegativeBalance",
+			balance:         "-100",
+			remainingMonths: 12,
+			nextPaymentDate: "2020-01-01T00:00:00Z",
+		},
+		{
+			name:            "ZeroRemainingMonths",
+			balance:         "1000",
+			remainingMonths: 0,
+			nextPaymentDate: "2020-01-01T00:00:00Z",
+		},
+		{
+			name:            "NextPaymentDayBiggerThan28",
+			balance:         "1000",
+			remainingMonths: 12,
+			nextPaymentDate: "2020-01-29T00:00:00Z",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := loan.RecreatePlanFromBalance(
+				toDecimal(t, test.balance),
+				toDecimal(t, "5.0"),
+				test.remainingMonths,
+				parseTime(t, test.nextPaymentDate),
+			)
+			if !errors.Is(err, loan.ErrInvalidParameter) {
+				t.Errorf("got error %v; want %v", err, loan.ErrInvalidParameter)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package loan
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// RoundingMode defines how money values are rounded to the
+// precision of a plan.
+type RoundingMode int
+
+const (
+	// HalfEven rounds to the nearest value and, when the value is exactly
+	// halfway, to the even one (also known as bankers rounding), so 2.345
+	// is rounded to 2.34 and 2.355 to 2.36. It is the default.
+	HalfEven RoundingMode = iota
+	// HalfUp rounds to the nearest value and, when the value is exactly
+	// halfway, away from zero, so 2.345 is rounded to 2.35.
+	HalfUp
+)
+
+// String returns the name of the rounding mode, like "half-even".
+func (m RoundingMode) String() string {
+	switch m {
+	case HalfEven:
+		return "half-even"
+	case HalfUp:
+		return "half-up"
+	}
+	return fmt.Sprintf("RoundingMode(%d)", int(m))
+}
+
+func (m RoundingMode) validate() error {
+	if m < HalfEven || m > HalfUp {
+		return fmt.Errorf("%w:invalid rounding mode %v", ErrInvalidParameter, m)
+	}
+	return nil
+}
+
+func (m RoundingMode) round(d decimal.Decimal, places int32) decimal.Decimal {
+	if m == HalfUp {
+		return d.Round(places)
+	}
+	return d.RoundBank(places)
+}
@@ -0,0 +1,88 @@
+package loan_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestPlanRoundingMode(t *testing.T) {
+
+	type Test struct {
+		name         string
+		mode         loan.RoundingMode
+		wantInterest string
+	}
+
+	// The interest of the first payment is 1001 * 0.06 / 12 = 5.005,
+	// exactly halfway between two cents.
+	tests := []Test{
+		{
+			name:         "HalfEven",
+			mode:         loan.HalfEven,
+			wantInterest: "5.00",
+		},
+		{
+			name:         "HalfUp",
+			mode:         loan.HalfUp,
+			wantInterest: "5.01",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payments, err := loan.BuildPlan(
+				toDecimal(t, "1001"),
+				toDecimal(t, "6"),
+				12,
+				parseTime(t, "2020-01-01T00:00:00Z"),
+				loan.WithRoundingMode(test.mode),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			wantInterest := toDecimal(t, test.wantInterest)
+			if !payments[0].Interest.Equal(wantInterest) {
+				t.Errorf("got interest %v; want %v", payments[0].Interest, wantInterest)
+			}
+
+			last := payments[len(payments)-1]
+			if !last.RemainingOutstandingPrincipal.IsZero() {
+				t.Errorf("got remaining principal %v on last payment; want zero", last.RemainingOutstandingPrincipal)
+			}
+		})
+	}
+}
+
+func TestPlanDefaultRoundingModeIsHalfEven(t *testing.T) {
+	start := parseTime(t, "2020-01-01T00:00:00Z")
+	want, err := loan.BuildPlan(toDecimal(t, "1001"), toDecimal(t, "6"), 12, start, loan.WithRoundingMode(loan.HalfEven))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := loan.CreatePlan(toDecimal(t, "1001"), toDecimal(t, "6"), 12, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range want {
+		if !got[i].Interest.Equal(want[i].Interest) || !got[i].Principal.Equal(want[i].Principal) {
+			t.Errorf("payment %d: got %v; want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestPlanWithInvalidRoundingMode(t *testing.T) {
+	_, err := loan.BuildPlan(
+		toDecimal(t, "1000"),
+		toDecimal(t, "5.0"),
+		12,
+		parseTime(t, "2020-01-01T00:00:00Z"),
+		loan.WithRoundingMode(loan.RoundingMode(42)),
+	)
+	if !errors.Is(err, loan.ErrInvalidParameter) {
+		t.Errorf("got error %v; want %v", err, loan.ErrInvalidParameter)
+	}
+}
@@ -0,0 +1,69 @@
+package loan
+
+import "github.com/shopspring/decimal"
+
+// Summary represents an at-a-glance view of a payment plan,
+// with the totals of all its payments. The TotalFees includes
+// both the origination fee and the recurring fees.
+type Summary struct {
+	TotalPrincipal   decimal.Decimal
+	TotalInterest    decimal.Decimal
+	TotalPaid        decimal.Decimal
+	TotalFees        decimal.Decimal
+	NumberOfPayments int
+}
+
+// Summarize will summarize the given payments.
+//
+// The totals are computed from the (already rounded) values of
+// each payment, so they always reconcile with the values that
+// the borrower actually sees on the payment plan.
+func Summarize(payments []Payment) Summary {
+	summary := Summary{
+		TotalPrincipal:   decimal.Zero,
+		TotalInterest:    decimal.Zero,
+		TotalPaid:        decimal.Zero,
+		TotalFees:        decimal.Zero,
+		NumberOfPayments: len(payments),
+	}
+	for _, p := range payments {
+		summary.TotalPrincipal = summary.TotalPrincipal.Add(p.Principal)
+		summary.TotalInterest = summary.TotalInterest.Add(p.Interest)
+		summary.TotalPaid = summary.TotalPaid.Add(p.PaymentAmount)
+		summary.TotalFees = summary.TotalFees.Add(p.Fee).Add(p.RecurringFee)
+	}
+	return summary
+}
+
+// ConvertSummary converts the money totals of the summary to another
+// currency, like a reporting currency, given the exchange rate
+// (the amount of the other currency worth one unit of the summary
+// currency, like 1.1 to convert from EUR to USD when 1 EUR is 1.1 USD).
+//
+// The totals are multiplied by the rate and rounded again (with HalfEven)
+// to 2 decimal places, so converting a summary back with the inverse rate
+// may differ by a cent from the original. The rate should be positive,
+// fetching the exchange rates is up to the caller.
+func ConvertSummary(s Summary, rate decimal.Decimal) Summary {
+	return convertSummary(s, rate, precision)
+}
+
+// ConvertSummaryTo works as ConvertSummary but rounds the totals to the
+// minor units of the currency converted to (like WithCurrency does on
+// plans), instead of 2 decimal places.
+func ConvertSummaryTo(s Summary, rate decimal.Decimal, to Currency) Summary {
+	return convertSummary(s, rate, int32(to.MinorUnits))
+}
+
+func convertSummary(s Summary, rate decimal.Decimal, places int32) Summary {
+	convert := func(amount decimal.Decimal) decimal.Decimal {
+		return amount.Mul(rate).RoundBank(places)
+	}
+	return Summary{
+		TotalPrincipal:   convert(s.TotalPrincipal),
+		TotalInterest:    convert(s.TotalInterest),
+		TotalPaid:        convert(s.TotalPaid),
+		TotalFees:        convert(s.TotalFees),
+		NumberOfPayments: s.NumberOfPayments,
+	}
+}
@@ -0,0 +1,157 @@
+package loan_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/loan"
+	"github.com/shopspring/decimal"
+)
+
+func TestSummarize(t *testing.T) {
+
+	type Test struct {
+		name     string
+		payments []loan.Payment
+		want     loan.Summary
+	}
+
+	tests := []Test{
+		{
+			name:     "SummaryOf2000LoanWith1.0RateIn2Months",
+			payments: createPlan(t, "2000.0", "1.0", 2),
+			want: loan.Summary{
+				TotalPrincipal:   toDecimal(t, "2000"),
+				TotalInterest:    toDecimal(t, "2.50"),
+				TotalPaid:        toDecimal(t, "2002.50"),
+				NumberOfPayments: 2,
+			},
+		},
+		{
+			name:     "SummaryOf5000LoanWith5.0RateIn24Months",
+			payments: createPlan(t, "5000.0", "5.0", 24),
+			want: loan.Summary{
+				TotalPrincipal:   toDecimal(t, "5000"),
+				TotalInterest:    toDecimal(t, "264.56"),
+				TotalPaid:        toDecimal(t, "5264.56"),
+				NumberOfPayments: 24,
+			},
+		},
+		{
+			name:     "SummaryOfEmptyPlan",
+			payments: nil,
+			want: loan.Summary{
+				TotalPrincipal:   toDecimal(t, "0"),
+				TotalInterest:    toDecimal(t, "0"),
+				TotalPaid:        toDecimal(t, "0"),
+				NumberOfPayments: 0,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := loan.Summarize(test.payments)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Summarize() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConvertSummary(t *testing.T) {
+	eur := loan.Summary{
+		TotalPrincipal:   toDecimal(t, "5000"),
+		TotalInterest:    toDecimal(t, "264.56"),
+		TotalPaid:        toDecimal(t, "5364.56"),
+		TotalFees:        toDecimal(t, "100"),
+		NumberOfPayments: 24,
+	}
+	eurToUSD := toDecimal(t, "1.1")
+
+	usd := loan.ConvertSummary(eur, eurToUSD)
+
+	wantUSD := loan.Summary{
+		TotalPrincipal:   toDecimal(t, "5500"),
+		TotalInterest:    toDecimal(t, "291.02"),
+		TotalPaid:        toDecimal(t, "5901.02"),
+		TotalFees:        toDecimal(t, "110"),
+		NumberOfPayments: 24,
+	}
+	if diff := cmp.Diff(wantUSD, usd); diff != "" {
+		t.Errorf("ConvertSummary() to USD mismatch (-want +got):\n%s", diff)
+	}
+
+	usdToEUR := decimal.NewFromInt(1).Div(eurToUSD)
+	got := loan.ConvertSummary(usd, usdToEUR)
+	if diff := cmp.Diff(eur, got); diff != "" {
+		t.Errorf("ConvertSummary() back to EUR mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConvertSummaryToRoundsToCurrencyMinorUnits(t *testing.T) {
+	eur := loan.Summary{
+		TotalPrincipal:   toDecimal(t, "5000"),
+		TotalInterest:    toDecimal(t, "264.56"),
+		TotalPaid:        toDecimal(t, "5364.56"),
+		TotalFees:        toDecimal(t, "100"),
+		NumberOfPayments: 24,
+	}
+
+	type Test struct {
+		name     string
+		rate     string
+		currency loan.Currency
+		want     loan.Summary
+	}
+
+	tests := []Test{
+		{
+			name:     "NoMinorUnits",
+			rate:     "130",
+			currency: loan.JPY,
+			want: loan.Summary{
+				TotalPrincipal:   toDecimal(t, "650000"),
+				TotalInterest:    toDecimal(t, "34393"),
+				TotalPaid:        toDecimal(t, "697393"),
+				TotalFees:        toDecimal(t, "13000"),
+				NumberOfPayments: 24,
+			},
+		},
+		{
+			name:     "ThreeMinorUnits",
+			rate:     "0.3345",
+			currency: loan.KWD,
+			want: loan.Summary{
+				TotalPrincipal:   toDecimal(t, "1672.5"),
+				TotalInterest:    toDecimal(t, "88.495"),
+				TotalPaid:        toDecimal(t, "1794.445"),
+				TotalFees:        toDecimal(t, "33.45"),
+				NumberOfPayments: 24,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := loan.ConvertSummaryTo(eur, toDecimal(t, test.rate), test.currency)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ConvertSummaryTo() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func createPlan(t *testing.T, totalLoanAmount string, annualInterestRate string, durationInMonths int) []loan.Payment {
+	t.Helper()
+	payments, err := loan.CreatePlan(
+		toDecimal(t, totalLoanAmount),
+		toDecimal(t, annualInterestRate),
+		durationInMonths,
+		parseTime(t, "2018-01-01T00:00:00Z"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payments
+}
@@ -0,0 +1,34 @@
+package loan
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// tableDateLayout is the layout of the dates on tables.
+const tableDateLayout = "2006-01-02"
+
+// FormatTable formats the payments as a human readable amortization
+// table, with one row per payment and aligned columns (the date,
+// payment amount, interest, principal and remaining balance), suitable
+// for terminal output. The first row is the header of the columns.
+func FormatTable(payments []Payment) string {
+	var table strings.Builder
+
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Date\tPayment\tInterest\tPrincipal\tBalance\t")
+	for _, p := range payments {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\n",
+			p.Date.Format(tableDateLayout),
+			p.PaymentAmount.String(),
+			p.Interest.String(),
+			p.Principal.String(),
+			p.RemainingOutstandingPrincipal.String(),
+		)
+	}
+	// Writing to a strings.Builder never fails.
+	_ = w.Flush()
+
+	return table.String()
+}
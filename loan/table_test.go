@@ -0,0 +1,46 @@
+package loan_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/katcipis/loaner/loan"
+)
+
+func TestFormatTable(t *testing.T) {
+	payments := createPlan(t, "5000", "5.0", 24)
+
+	table := loan.FormatTable(payments)
+	rows := strings.Split(strings.TrimSuffix(table, "\n"), "\n")
+
+	if len(rows) != len(payments)+1 {
+		t.Fatalf("got %d rows; want %d (header plus one row per payment):\n%s", len(rows), len(payments)+1, table)
+	}
+
+	wantHeader := []string{"Date", "Payment", "Interest", "Principal", "Balance"}
+	if diff := cmp.Diff(wantHeader, strings.Fields(rows[0])); diff != "" {
+		t.Errorf("header mismatch (-want +got):\n%s", diff)
+	}
+
+	wantFirstRow := []string{"2018-01-01", "219.36", "20.83", "198.53", "4801.47"}
+	if diff := cmp.Diff(wantFirstRow, strings.Fields(rows[1])); diff != "" {
+		t.Errorf("first row mismatch (-want +got):\n%s", diff)
+	}
+
+	// All the rows are aligned, having the same width.
+	for i, row := range rows {
+		if len(row) != len(rows[0]) {
+			t.Errorf("row %d has width %d; want %d:\n%s", i, len(row), len(rows[0]), table)
+		}
+	}
+}
+
+func TestFormatEmptyTable(t *testing.T) {
+	table := loan.FormatTable(nil)
+	rows := strings.Split(strings.TrimSuffix(table, "\n"), "\n")
+
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows; want only the header:\n%s", len(rows), table)
+	}
+}
@@ -0,0 +1,450 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: loanpb/loan.proto
+
+package loanpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CreateLoanPlanRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Total amount of the loan, like "5000".
+	LoanAmount string `protobuf:"bytes,1,opt,name=loan_amount,json=loanAmount,proto3" json:"loan_amount,omitempty"`
+	// Annual interest rate as a percent, like "5.0" for 5%.
+	NominalRate string `protobuf:"bytes,2,opt,name=nominal_rate,json=nominalRate,proto3" json:"nominal_rate,omitempty"`
+	// Duration of the loan in months.
+	Duration int32 `protobuf:"varint,3,opt,name=duration,proto3" json:"duration,omitempty"`
+	// Date of the first payment on RFC 3339, like "2018-01-01T00:00:00Z".
+	// When empty the first day of the next month is used.
+	StartDate string `protobuf:"bytes,4,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	// ISO 4217 code of the currency of the loan, like "EUR".
+	// When empty the money values have 2 decimal places.
+	Currency      string `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateLoanPlanRequest) Reset() {
+	*x = CreateLoanPlanRequest{}
+	mi := &file_loanpb_loan_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateLoanPlanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateLoanPlanRequest) ProtoMessage() {}
+
+func (x *CreateLoanPlanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_loanpb_loan_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateLoanPlanRequest.ProtoReflect.Descriptor instead.
+func (*CreateLoanPlanRequest) Descriptor() ([]byte, []int) {
+	return file_loanpb_loan_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateLoanPlanRequest) GetLoanAmount() string {
+	if x != nil {
+		return x.LoanAmount
+	}
+	return ""
+}
+
+func (x *CreateLoanPlanRequest) GetNominalRate() string {
+	if x != nil {
+		return x.NominalRate
+	}
+	return ""
+}
+
+func (x *CreateLoanPlanRequest) GetDuration() int32 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *CreateLoanPlanRequest) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *CreateLoanPlanRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type BorrowerPayment struct {
+	state                         protoimpl.MessageState `protogen:"open.v1"`
+	Date                          string                 `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	BorrowerPaymentAmount         string                 `protobuf:"bytes,2,opt,name=borrower_payment_amount,json=borrowerPaymentAmount,proto3" json:"borrower_payment_amount,omitempty"`
+	Interest                      string                 `protobuf:"bytes,3,opt,name=interest,proto3" json:"interest,omitempty"`
+	Principal                     string                 `protobuf:"bytes,4,opt,name=principal,proto3" json:"principal,omitempty"`
+	InitialOutstandingPrincipal   string                 `protobuf:"bytes,5,opt,name=initial_outstanding_principal,json=initialOutstandingPrincipal,proto3" json:"initial_outstanding_principal,omitempty"`
+	RemainingOutstandingPrincipal string                 `protobuf:"bytes,6,opt,name=remaining_outstanding_principal,json=remainingOutstandingPrincipal,proto3" json:"remaining_outstanding_principal,omitempty"`
+	DaysInPeriod                  int32                  `protobuf:"varint,7,opt,name=days_in_period,json=daysInPeriod,proto3" json:"days_in_period,omitempty"`
+	unknownFields                 protoimpl.UnknownFields
+	sizeCache                     protoimpl.SizeCache
+}
+
+func (x *BorrowerPayment) Reset() {
+	*x = BorrowerPayment{}
+	mi := &file_loanpb_loan_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BorrowerPayment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BorrowerPayment) ProtoMessage() {}
+
+func (x *BorrowerPayment) ProtoReflect() protoreflect.Message {
+	mi := &file_loanpb_loan_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BorrowerPayment.ProtoReflect.Descriptor instead.
+func (*BorrowerPayment) Descriptor() ([]byte, []int) {
+	return file_loanpb_loan_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BorrowerPayment) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *BorrowerPayment) GetBorrowerPaymentAmount() string {
+	if x != nil {
+		return x.BorrowerPaymentAmount
+	}
+	return ""
+}
+
+func (x *BorrowerPayment) GetInterest() string {
+	if x != nil {
+		return x.Interest
+	}
+	return ""
+}
+
+func (x *BorrowerPayment) GetPrincipal() string {
+	if x != nil {
+		return x.Principal
+	}
+	return ""
+}
+
+func (x *BorrowerPayment) GetInitialOutstandingPrincipal() string {
+	if x != nil {
+		return x.InitialOutstandingPrincipal
+	}
+	return ""
+}
+
+func (x *BorrowerPayment) GetRemainingOutstandingPrincipal() string {
+	if x != nil {
+		return x.RemainingOutstandingPrincipal
+	}
+	return ""
+}
+
+func (x *BorrowerPayment) GetDaysInPeriod() int32 {
+	if x != nil {
+		return x.DaysInPeriod
+	}
+	return 0
+}
+
+type LoanPlanSummary struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TotalPrincipal string                 `protobuf:"bytes,1,opt,name=total_principal,json=totalPrincipal,proto3" json:"total_principal,omitempty"`
+	TotalInterest  string                 `protobuf:"bytes,2,opt,name=total_interest,json=totalInterest,proto3" json:"total_interest,omitempty"`
+	TotalPayment   string                 `protobuf:"bytes,3,opt,name=total_payment,json=totalPayment,proto3" json:"total_payment,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *LoanPlanSummary) Reset() {
+	*x = LoanPlanSummary{}
+	mi := &file_loanpb_loan_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoanPlanSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoanPlanSummary) ProtoMessage() {}
+
+func (x *LoanPlanSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_loanpb_loan_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoanPlanSummary.ProtoReflect.Descriptor instead.
+func (*LoanPlanSummary) Descriptor() ([]byte, []int) {
+	return file_loanpb_loan_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LoanPlanSummary) GetTotalPrincipal() string {
+	if x != nil {
+		return x.TotalPrincipal
+	}
+	return ""
+}
+
+func (x *LoanPlanSummary) GetTotalInterest() string {
+	if x != nil {
+		return x.TotalInterest
+	}
+	return ""
+}
+
+func (x *LoanPlanSummary) GetTotalPayment() string {
+	if x != nil {
+		return x.TotalPayment
+	}
+	return ""
+}
+
+type CreateLoanPlanResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	BorrowerPayments []*BorrowerPayment     `protobuf:"bytes,1,rep,name=borrower_payments,json=borrowerPayments,proto3" json:"borrower_payments,omitempty"`
+	Total            int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	PayoffDate       string                 `protobuf:"bytes,3,opt,name=payoff_date,json=payoffDate,proto3" json:"payoff_date,omitempty"`
+	Summary          *LoanPlanSummary       `protobuf:"bytes,4,opt,name=summary,proto3" json:"summary,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *CreateLoanPlanResponse) Reset() {
+	*x = CreateLoanPlanResponse{}
+	mi := &file_loanpb_loan_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateLoanPlanResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateLoanPlanResponse) ProtoMessage() {}
+
+func (x *CreateLoanPlanResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_loanpb_loan_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateLoanPlanResponse.ProtoReflect.Descriptor instead.
+func (*CreateLoanPlanResponse) Descriptor() ([]byte, []int) {
+	return file_loanpb_loan_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateLoanPlanResponse) GetBorrowerPayments() []*BorrowerPayment {
+	if x != nil {
+		return x.BorrowerPayments
+	}
+	return nil
+}
+
+func (x *CreateLoanPlanResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *CreateLoanPlanResponse) GetPayoffDate() string {
+	if x != nil {
+		return x.PayoffDate
+	}
+	return ""
+}
+
+func (x *CreateLoanPlanResponse) GetSummary() *LoanPlanSummary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+var File_loanpb_loan_proto protoreflect.FileDescriptor
+
+var file_loanpb_loan_proto_rawDesc = string([]byte{
+	0x0a, 0x11, 0x6c, 0x6f, 0x61, 0x6e, 0x70, 0x62, 0x2f, 0x6c, 0x6f, 0x61, 0x6e, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x09, 0x6c, 0x6f, 0x61, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x22, 0xb2,
+	0x01, 0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x61, 0x6e, 0x50, 0x6c, 0x61,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x6f, 0x61, 0x6e,
+	0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6c,
+	0x6f, 0x61, 0x6e, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x6e, 0x6f, 0x6d,
+	0x69, 0x6e, 0x61, 0x6c, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x6e, 0x6f, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x61, 0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08,
+	0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08,
+	0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x44, 0x61, 0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65,
+	0x6e, 0x63, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65,
+	0x6e, 0x63, 0x79, 0x22, 0xc9, 0x02, 0x0a, 0x0f, 0x42, 0x6f, 0x72, 0x72, 0x6f, 0x77, 0x65, 0x72,
+	0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65, 0x12, 0x36, 0x0a, 0x17, 0x62,
+	0x6f, 0x72, 0x72, 0x6f, 0x77, 0x65, 0x72, 0x5f, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x5f,
+	0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x15, 0x62, 0x6f,
+	0x72, 0x72, 0x6f, 0x77, 0x65, 0x72, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x41, 0x6d, 0x6f,
+	0x75, 0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x12,
+	0x1c, 0x0a, 0x09, 0x70, 0x72, 0x69, 0x6e, 0x63, 0x69, 0x70, 0x61, 0x6c, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x70, 0x72, 0x69, 0x6e, 0x63, 0x69, 0x70, 0x61, 0x6c, 0x12, 0x42, 0x0a,
+	0x1d, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x6f, 0x75, 0x74, 0x73, 0x74, 0x61, 0x6e,
+	0x64, 0x69, 0x6e, 0x67, 0x5f, 0x70, 0x72, 0x69, 0x6e, 0x63, 0x69, 0x70, 0x61, 0x6c, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x1b, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x4f, 0x75, 0x74,
+	0x73, 0x74, 0x61, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x50, 0x72, 0x69, 0x6e, 0x63, 0x69, 0x70, 0x61,
+	0x6c, 0x12, 0x46, 0x0a, 0x1f, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x6f,
+	0x75, 0x74, 0x73, 0x74, 0x61, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x70, 0x72, 0x69, 0x6e, 0x63,
+	0x69, 0x70, 0x61, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x1d, 0x72, 0x65, 0x6d, 0x61,
+	0x69, 0x6e, 0x69, 0x6e, 0x67, 0x4f, 0x75, 0x74, 0x73, 0x74, 0x61, 0x6e, 0x64, 0x69, 0x6e, 0x67,
+	0x50, 0x72, 0x69, 0x6e, 0x63, 0x69, 0x70, 0x61, 0x6c, 0x12, 0x24, 0x0a, 0x0e, 0x64, 0x61, 0x79,
+	0x73, 0x5f, 0x69, 0x6e, 0x5f, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0c, 0x64, 0x61, 0x79, 0x73, 0x49, 0x6e, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x22,
+	0x86, 0x01, 0x0a, 0x0f, 0x4c, 0x6f, 0x61, 0x6e, 0x50, 0x6c, 0x61, 0x6e, 0x53, 0x75, 0x6d, 0x6d,
+	0x61, 0x72, 0x79, 0x12, 0x27, 0x0a, 0x0f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x70, 0x72, 0x69,
+	0x6e, 0x63, 0x69, 0x70, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x74, 0x6f,
+	0x74, 0x61, 0x6c, 0x50, 0x72, 0x69, 0x6e, 0x63, 0x69, 0x70, 0x61, 0x6c, 0x12, 0x25, 0x0a, 0x0e,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x49, 0x6e, 0x74, 0x65, 0x72,
+	0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x70, 0x61, 0x79,
+	0x6d, 0x65, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x74, 0x6f, 0x74, 0x61,
+	0x6c, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0xce, 0x01, 0x0a, 0x16, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x4c, 0x6f, 0x61, 0x6e, 0x50, 0x6c, 0x61, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x47, 0x0a, 0x11, 0x62, 0x6f, 0x72, 0x72, 0x6f, 0x77, 0x65, 0x72, 0x5f,
+	0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x6c, 0x6f, 0x61, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6f, 0x72, 0x72, 0x6f,
+	0x77, 0x65, 0x72, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x10, 0x62, 0x6f, 0x72, 0x72,
+	0x6f, 0x77, 0x65, 0x72, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x74, 0x6f, 0x74,
+	0x61, 0x6c, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x61, 0x79, 0x6f, 0x66, 0x66, 0x5f, 0x64, 0x61, 0x74,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x61, 0x79, 0x6f, 0x66, 0x66, 0x44,
+	0x61, 0x74, 0x65, 0x12, 0x34, 0x0a, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x6c, 0x6f, 0x61, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x4c, 0x6f, 0x61, 0x6e, 0x50, 0x6c, 0x61, 0x6e, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79,
+	0x52, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x32, 0x68, 0x0a, 0x0f, 0x4c, 0x6f, 0x61,
+	0x6e, 0x50, 0x6c, 0x61, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x55, 0x0a, 0x0e,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x61, 0x6e, 0x50, 0x6c, 0x61, 0x6e, 0x12, 0x20,
+	0x2e, 0x6c, 0x6f, 0x61, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x4c, 0x6f, 0x61, 0x6e, 0x50, 0x6c, 0x61, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x21, 0x2e, 0x6c, 0x6f, 0x61, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x4c, 0x6f, 0x61, 0x6e, 0x50, 0x6c, 0x61, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x42, 0x23, 0x5a, 0x21, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x6b, 0x61, 0x74, 0x63, 0x69, 0x70, 0x69, 0x73, 0x2f, 0x6c, 0x6f, 0x61, 0x6e, 0x65,
+	0x72, 0x2f, 0x6c, 0x6f, 0x61, 0x6e, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_loanpb_loan_proto_rawDescOnce sync.Once
+	file_loanpb_loan_proto_rawDescData []byte
+)
+
+func file_loanpb_loan_proto_rawDescGZIP() []byte {
+	file_loanpb_loan_proto_rawDescOnce.Do(func() {
+		file_loanpb_loan_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_loanpb_loan_proto_rawDesc), len(file_loanpb_loan_proto_rawDesc)))
+	})
+	return file_loanpb_loan_proto_rawDescData
+}
+
+var file_loanpb_loan_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_loanpb_loan_proto_goTypes = []any{
+	(*CreateLoanPlanRequest)(nil),  // 0: loaner.v1.CreateLoanPlanRequest
+	(*BorrowerPayment)(nil),        // 1: loaner.v1.BorrowerPayment
+	(*LoanPlanSummary)(nil),        // 2: loaner.v1.LoanPlanSummary
+	(*CreateLoanPlanResponse)(nil), // 3: loaner.v1.CreateLoanPlanResponse
+}
+var file_loanpb_loan_proto_depIdxs = []int32{
+	1, // 0: loaner.v1.CreateLoanPlanResponse.borrower_payments:type_name -> loaner.v1.BorrowerPayment
+	2, // 1: loaner.v1.CreateLoanPlanResponse.summary:type_name -> loaner.v1.LoanPlanSummary
+	0, // 2: loaner.v1.LoanPlanService.CreateLoanPlan:input_type -> loaner.v1.CreateLoanPlanRequest
+	3, // 3: loaner.v1.LoanPlanService.CreateLoanPlan:output_type -> loaner.v1.CreateLoanPlanResponse
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_loanpb_loan_proto_init() }
+func file_loanpb_loan_proto_init() {
+	if File_loanpb_loan_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_loanpb_loan_proto_rawDesc), len(file_loanpb_loan_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_loanpb_loan_proto_goTypes,
+		DependencyIndexes: file_loanpb_loan_proto_depIdxs,
+		MessageInfos:      file_loanpb_loan_proto_msgTypes,
+	}.Build()
+	File_loanpb_loan_proto = out.File
+	file_loanpb_loan_proto_goTypes = nil
+	file_loanpb_loan_proto_depIdxs = nil
+}
@@ -0,0 +1,133 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: loanpb/loan.proto
+
+package loanpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	LoanPlanService_CreateLoanPlan_FullMethodName = "/loaner.v1.LoanPlanService/CreateLoanPlan"
+)
+
+// LoanPlanServiceClient is the client API for LoanPlanService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// LoanPlanService creates loan plans, mirroring the HTTP API
+// (POST /loan-plan). Decimal values are represented as strings,
+// like "1001.25", exactly as they are on the HTTP API.
+type LoanPlanServiceClient interface {
+	// CreateLoanPlan creates the payment plan of an annuity loan.
+	// Invalid parameters are reported with the INVALID_ARGUMENT code.
+	CreateLoanPlan(ctx context.Context, in *CreateLoanPlanRequest, opts ...grpc.CallOption) (*CreateLoanPlanResponse, error)
+}
+
+type loanPlanServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLoanPlanServiceClient(cc grpc.ClientConnInterface) LoanPlanServiceClient {
+	return &loanPlanServiceClient{cc}
+}
+
+func (c *loanPlanServiceClient) CreateLoanPlan(ctx context.Context, in *CreateLoanPlanRequest, opts ...grpc.CallOption) (*CreateLoanPlanResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateLoanPlanResponse)
+	err := c.cc.Invoke(ctx, LoanPlanService_CreateLoanPlan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LoanPlanServiceServer is the server API for LoanPlanService service.
+// All implementations must embed UnimplementedLoanPlanServiceServer
+// for forward compatibility.
+//
+// LoanPlanService creates loan plans, mirroring the HTTP API
+// (POST /loan-plan). Decimal values are represented as strings,
+// like "1001.25", exactly as they are on the HTTP API.
+type LoanPlanServiceServer interface {
+	// CreateLoanPlan creates the payment plan of an annuity loan.
+	// Invalid parameters are reported with the INVALID_ARGUMENT code.
+	CreateLoanPlan(context.Context, *CreateLoanPlanRequest) (*CreateLoanPlanResponse, error)
+	mustEmbedUnimplementedLoanPlanServiceServer()
+}
+
+// UnimplementedLoanPlanServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLoanPlanServiceServer struct{}
+
+func (UnimplementedLoanPlanServiceServer) CreateLoanPlan(context.Context, *CreateLoanPlanRequest) (*CreateLoanPlanResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateLoanPlan not implemented")
+}
+func (UnimplementedLoanPlanServiceServer) mustEmbedUnimplementedLoanPlanServiceServer() {}
+func (UnimplementedLoanPlanServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeLoanPlanServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LoanPlanServiceServer will
+// result in compilation errors.
+type UnsafeLoanPlanServiceServer interface {
+	mustEmbedUnimplementedLoanPlanServiceServer()
+}
+
+func RegisterLoanPlanServiceServer(s grpc.ServiceRegistrar, srv LoanPlanServiceServer) {
+	// If the following call pancis, it indicates UnimplementedLoanPlanServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&LoanPlanService_ServiceDesc, srv)
+}
+
+func _LoanPlanService_CreateLoanPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateLoanPlanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoanPlanServiceServer).CreateLoanPlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LoanPlanService_CreateLoanPlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoanPlanServiceServer).CreateLoanPlan(ctx, req.(*CreateLoanPlanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// LoanPlanService_ServiceDesc is the grpc.ServiceDesc for LoanPlanService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LoanPlanService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "loaner.v1.LoanPlanService",
+	HandlerType: (*LoanPlanServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateLoanPlan",
+			Handler:    _LoanPlanService_CreateLoanPlan_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "loanpb/loan.proto",
+}
@@ -0,0 +1,156 @@
+// Package money has the representation of money amounts shared by the
+// loan and api packages, so amounts are always parsed and marshalled
+// to JSON the same way, with consistent error messages.
+package money
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Amount is an amount of money. On JSON it is represented as a string,
+// like "1001.25", so there is no loss of precision, but it can also be
+// unmarshalled from a JSON number. The zero value is the amount zero.
+//
+// By default amounts are written with only the decimal places they need,
+// see Fixed and Localized for other formats.
+type Amount struct {
+	value  decimal.Decimal
+	format format
+}
+
+// format is how an amount is written as text.
+type format struct {
+	fixed      bool
+	places     int32
+	decimalSep string
+	groupSep   string
+}
+
+// New creates an amount with the given decimal value.
+func New(value decimal.Decimal) Amount {
+	return Amount{value: value}
+}
+
+// Parse parses the amount from its text, like "1001.25".
+func Parse(text string) (Amount, error) {
+	value, err := decimal.NewFromString(text)
+	if err != nil {
+		return Amount{}, fmt.Errorf("can't parse amount %q:%v", text, err)
+	}
+	return New(value), nil
+}
+
+// Decimal returns the decimal value of the amount.
+func (a Amount) Decimal() decimal.Decimal {
+	return a.value
+}
+
+// String returns the text of the amount, like "1001.25",
+// with only the decimal places it needs (no trailing zeros), unless
+// the amount is Fixed or Localized.
+func (a Amount) String() string {
+	text := a.value.String()
+	if a.format.fixed {
+		text = a.value.StringFixed(a.format.places)
+	}
+	if a.format.decimalSep == "" {
+		return text
+	}
+	return localize(text, a.format.decimalSep, a.format.groupSep)
+}
+
+// Fixed returns the amount written always with the given decimal
+// places, like "2000.00" with 2 places, instead of "2000".
+func (a Amount) Fixed(places int32) Amount {
+	a.format.fixed = true
+	a.format.places = places
+	return a
+}
+
+// Localized returns the amount written with the given decimal and
+// group (thousands) separators, like "1.001,25" with "," and ".".
+// Localized amounts are meant to be displayed, they can't be parsed.
+func (a Amount) Localized(decimalSep string, groupSep string) Amount {
+	a.format.decimalSep = decimalSep
+	a.format.groupSep = groupSep
+	return a
+}
+
+// Equal returns true if both amounts have the same value,
+// no matter how they are written.
+func (a Amount) Equal(b Amount) bool {
+	return a.value.Equal(b.value)
+}
+
+// localize writes the invariant text of an amount (like "1001.25")
+// with the given decimal and group separators.
+func localize(text string, decimalSep string, groupSep string) string {
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+
+	integer, fraction := text, ""
+	if i := strings.Index(text, "."); i >= 0 {
+		integer, fraction = text[:i], text[i+1:]
+	}
+
+	grouped := &strings.Builder{}
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteString(groupSep)
+		}
+		grouped.WriteRune(digit)
+	}
+
+	if fraction == "" {
+		return sign + grouped.String()
+	}
+	return sign + grouped.String() + decimalSep + fraction
+}
+
+// MarshalJSON marshals the amount as a JSON string.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON unmarshals the amount from a JSON string or number.
+// Like on the standard library, a JSON null is a no-op.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	text, err := JSONText(data)
+	if err != nil {
+		return err
+	}
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// JSONText returns the text of an amount informed as a JSON string
+// or number, without parsing it, so it can be normalized before being
+// parsed. Numbers are kept as their original text, so there is no
+// loss of precision (as there would be if they were parsed as floats).
+func JSONText(data []byte) (string, error) {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return "", fmt.Errorf("can't parse amount %s:%v", data, err)
+		}
+		return s, nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return "", fmt.Errorf("amount must be a JSON string or number, got %s", data)
+	}
+	return string(n), nil
+}
@@ -0,0 +1,202 @@
+package money_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/katcipis/loaner/money"
+	"github.com/shopspring/decimal"
+)
+
+func TestAmountMarshalJSON(t *testing.T) {
+	type Test struct {
+		name   string
+		amount string
+		want   string
+	}
+
+	tests := []Test{
+		{name: "Integer", amount: "2000", want: `"2000"`},
+		{name: "Cents", amount: "1001.25", want: `"1001.25"`},
+		{name: "TrailingZerosAreDropped", amount: "1000.50", want: `"1000.5"`},
+		{name: "Negative", amount: "-0.01", want: `"-0.01"`},
+		{name: "Zero", amount: "0", want: `"0"`},
+		{name: "ManyDecimalPlaces", amount: "0.1234567890123456789", want: `"0.1234567890123456789"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			amount, err := money.Parse(test.amount)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := json.Marshal(amount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got JSON %s; want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAmountUnmarshalJSON(t *testing.T) {
+	type Test struct {
+		name string
+		json string
+		want string
+	}
+
+	tests := []Test{
+		{name: "String", json: `"1001.25"`, want: "1001.25"},
+		{name: "Number", json: `1001.25`, want: "1001.25"},
+		{name: "NumberKeepsPrecision", json: `0.1234567890123456789`, want: "0.1234567890123456789"},
+		{name: "NumberWithExponent", json: `1e3`, want: "1000"},
+		{name: "Negative", json: `"-5"`, want: "-5"},
+		{name: "NullIsNoop", json: `null`, want: "0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got money.Amount
+			if err := json.Unmarshal([]byte(test.json), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			want := decimal.RequireFromString(test.want)
+			if !got.Decimal().Equal(want) {
+				t.Errorf("got amount %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestAmountUnmarshalJSONFailures(t *testing.T) {
+	type Test struct {
+		name    string
+		json    string
+		wantErr string
+	}
+
+	tests := []Test{
+		{name: "InvalidString", json: `"lots"`, wantErr: `can't parse amount "lots"`},
+		{name: "EmptyString", json: `""`, wantErr: `can't parse amount ""`},
+		{name: "Bool", json: `true`, wantErr: "amount must be a JSON string or number, got true"},
+		{name: "Object", json: `{"amount":"10"}`, wantErr: "amount must be a JSON string or number"},
+		{name: "Array", json: `["10"]`, wantErr: "amount must be a JSON string or number"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got money.Amount
+			err := json.Unmarshal([]byte(test.json), &got)
+			if err == nil {
+				t.Fatalf("got amount %v; want error", got)
+			}
+			if !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("got error %q; want it to contain %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestAmountRoundTrip(t *testing.T) {
+	type payment struct {
+		Amount money.Amount `json:"amount"`
+	}
+
+	want := payment{Amount: money.New(decimal.RequireFromString("219.36"))}
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got payment
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Amount.Decimal().Equal(want.Amount.Decimal()) {
+		t.Errorf("got amount %v; want %v", got.Amount, want.Amount)
+	}
+}
+
+func TestAmountFormats(t *testing.T) {
+	type Test struct {
+		name   string
+		amount string
+		format func(money.Amount) money.Amount
+		want   string
+	}
+
+	tests := []Test{
+		{
+			name:   "Plain",
+			amount: "2000.50",
+			format: func(a money.Amount) money.Amount { return a },
+			want:   "2000.5",
+		},
+		{
+			name:   "Fixed",
+			amount: "2000",
+			format: func(a money.Amount) money.Amount { return a.Fixed(2) },
+			want:   "2000.00",
+		},
+		{
+			name:   "FixedRounds",
+			amount: "0.125",
+			format: func(a money.Amount) money.Amount { return a.Fixed(2) },
+			want:   "0.13",
+		},
+		{
+			name:   "FixedWithNoPlaces",
+			amount: "500000",
+			format: func(a money.Amount) money.Amount { return a.Fixed(0) },
+			want:   "500000",
+		},
+		{
+			name:   "Localized",
+			amount: "1001.25",
+			format: func(a money.Amount) money.Amount { return a.Localized(",", ".") },
+			want:   "1.001,25",
+		},
+		{
+			name:   "LocalizedMillions",
+			amount: "-1234567",
+			format: func(a money.Amount) money.Amount { return a.Localized(".", ",") },
+			want:   "-1,234,567",
+		},
+		{
+			name:   "FixedAndLocalized",
+			amount: "2000",
+			format: func(a money.Amount) money.Amount { return a.Fixed(2).Localized(",", " ") },
+			want:   "2 000,00",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			amount, err := money.Parse(test.amount)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			formatted := test.format(amount)
+			if got := formatted.String(); got != test.want {
+				t.Errorf("got %q; want %q", got, test.want)
+			}
+			data, err := json.Marshal(formatted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := `"` + test.want + `"`; string(data) != want {
+				t.Errorf("got JSON %s; want %s", data, want)
+			}
+			if !formatted.Equal(amount) {
+				t.Errorf("formatted amount %v not equal to %v", formatted, amount)
+			}
+		})
+	}
+}